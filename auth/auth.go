@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/krunal4amity/tronicscorp/ratelimit"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	TokenAcesso    = "access"
+	TokenRenovacao = "refresh"
+
+	ChaveAtor   = "ator"   //identificador de quem faz a requisição, usado pela auditoria
+	ChaveClaims = "claims" //*Claims do token validado
+)
+
+var (
+	ErrCredenciaisInvalidas = errors.New("invalid credentials")
+	ErrContaBloqueada       = errors.New("account locked")
+	ErrContaInexistente     = errors.New("account not found")
+)
+
+// Identidade é quem se autenticou, como fica registrado nos tokens.
+type Identidade struct {
+	Usuario     string
+	Papel       string
+	AlunoID     string   //papel aluno: o próprio documento em /alunos
+	ProfessorID string   //papel professor: o próprio documento em /professores
+	Vinculados  []string //papel responsavel: alunos pelos quais responde
+}
+
+// Credenciais verifica usuário e senha no login e recarrega a conta na renovação dos tokens.
+type Credenciais interface {
+	Verificar(ctx context.Context, usuario, senha string) (Identidade, error)
+	//Buscar devolve a conta como está agora, ou ErrContaInexistente se ela foi removida, está na lixeira ou
+	//foi anonimizada
+	Buscar(ctx context.Context, usuario string) (Identidade, error)
+}
+
+// CredenciaisFixas é o usuário administrador definido na configuração, usado para o primeiro acesso.
+type CredenciaisFixas struct {
+	Usuario string
+	Senha   string
+}
+
+func (f CredenciaisFixas) Verificar(ctx context.Context, usuario, senha string) (Identidade, error) {
+	if f.Senha == "" || !iguais(usuario, f.Usuario) || !iguais(senha, f.Senha) {
+		return Identidade{}, ErrCredenciaisInvalidas
+	}
+	return Identidade{Usuario: usuario, Papel: PapelSecretaria}, nil
+}
+
+func (f CredenciaisFixas) Buscar(ctx context.Context, usuario string) (Identidade, error) {
+	if f.Senha == "" || usuario != f.Usuario {
+		return Identidade{}, ErrContaInexistente
+	}
+	return Identidade{Usuario: usuario, Papel: PapelSecretaria}, nil
+}
+
+// iguais compara dois textos em tempo constante; os resumos têm o mesmo tamanho, então nem o tamanho do
+// segredo transparece no tempo da comparação.
+func iguais(a, b string) bool {
+	x, y := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(x[:], y[:]) == 1
+}
+
+// Cadeia tenta cada fonte de credenciais em ordem. Uma conta bloqueada encerra a busca.
+type Cadeia []Credenciais
+
+func (c Cadeia) Verificar(ctx context.Context, usuario, senha string) (Identidade, error) {
+	for _, credenciais := range c {
+		identidade, err := credenciais.Verificar(ctx, usuario, senha)
+		if err == nil || err == ErrContaBloqueada {
+			return identidade, err
+		}
+	}
+	return Identidade{}, ErrCredenciaisInvalidas
+}
+
+// Buscar devolve a conta da primeira fonte que a conhece; um erro que não seja ErrContaInexistente, como uma
+// falha do banco, encerra a busca.
+func (c Cadeia) Buscar(ctx context.Context, usuario string) (Identidade, error) {
+	for _, credenciais := range c {
+		identidade, err := credenciais.Buscar(ctx, usuario)
+		if err != ErrContaInexistente {
+			return identidade, err
+		}
+	}
+	return Identidade{}, ErrContaInexistente
+}
+
+type Claims struct {
+	Tipo        string   `json:"tipo"`
+	Papel       string   `json:"papel,omitempty"`
+	AlunoID     string   `json:"alunoId,omitempty"`
+	ProfessorID string   `json:"professorId,omitempty"`
+	Vinculados  []string `json:"vinculados,omitempty"`
+	Escopos     []string `json:"escopos,omitempty"` //apenas chaves de API; nil em tokens de usuários
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) Identidade() Identidade {
+	return Identidade{
+		Usuario:     c.Subject,
+		Papel:       c.Papel,
+		AlunoID:     c.AlunoID,
+		ProfessorID: c.ProfessorID,
+		Vinculados:  c.Vinculados,
+	}
+}
+
+type Tokens = modelos.Tokens
+
+// Servico emite e valida os tokens. Chaves mapeia o kid para o segredo HMAC: tokens são assinados com a
+// ChaveAtiva e continuam válidos com qualquer chave listada, o que permite a rotação sem derrubar sessões.
+type Servico struct {
+	Chaves           map[string][]byte
+	ChaveAtiva       string
+	Emissor          string
+	DuracaoAcesso    time.Duration
+	DuracaoRenovacao time.Duration
+	Credenciais      Credenciais
+	Revogacoes       *Revogacoes
+	OIDC             *OIDC //provedor externo opcional; nil desliga o login por OIDC
+	ChavesAPI        VerificadorChaves
+	Limites          ratelimit.Store //limites por chave de API
+}
+
+func init() {
+	//iat com milissegundos, para que um token emitido no mesmo segundo de uma revogação do usuário, mas depois
+	//dela, continue válido
+	jwt.TimePrecision = time.Millisecond
+}
+
+func novoID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (s *Servico) assinar(identidade Identidade, tipo string, duracao time.Duration) (string, error) {
+	agora := time.Now()
+	claims := Claims{
+		Tipo:        tipo,
+		Papel:       identidade.Papel,
+		AlunoID:     identidade.AlunoID,
+		ProfessorID: identidade.ProfessorID,
+		Vinculados:  identidade.Vinculados,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        novoID(),
+			Subject:   identidade.Usuario,
+			Issuer:    s.Emissor,
+			IssuedAt:  jwt.NewNumericDate(agora),
+			ExpiresAt: jwt.NewNumericDate(agora.Add(duracao)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = s.ChaveAtiva
+	return token.SignedString(s.Chaves[s.ChaveAtiva])
+}
+
+func (s *Servico) Emitir(identidade Identidade) (Tokens, error) {
+	acesso, err := s.assinar(identidade, TokenAcesso, s.DuracaoAcesso)
+	if err != nil {
+		return Tokens{}, err
+	}
+	renovacao, err := s.assinar(identidade, TokenRenovacao, s.DuracaoRenovacao)
+	if err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{
+		AccessToken:  acesso,
+		RefreshToken: renovacao,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.DuracaoAcesso.Seconds()),
+	}, nil
+}
+
+// chaveHMAC devolve o segredo do kid para os tokens assinados pela API, recusando outros algoritmos e kids
+// desconhecidos.
+func (s *Servico) chaveHMAC(t *jwt.Token) (interface{}, error) {
+	if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+	}
+	kid, _ := t.Header["kid"].(string)
+	chave, ok := s.Chaves[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return chave, nil
+}
+
+// Validar confere assinatura, expiração, emissor, tipo e revogação do token.
+func (s *Servico) Validar(ctx context.Context, tokenString, tipo string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, s.chaveHMAC)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Tipo != tipo {
+		return nil, fmt.Errorf("expected a %s token", tipo)
+	}
+	if !claims.VerifyIssuer(s.Emissor, true) {
+		return nil, errors.New("unexpected issuer")
+	}
+	revogado, err := s.Revogacoes.Revogado(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revogado {
+		return nil, errors.New("token revoked")
+	}
+	return claims, nil
+}
+
+func tokenDoCabecalho(c echo.Context) string {
+	cabecalho := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(cabecalho, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(cabecalho, "Bearer ")
+}
+
+// Middleware exige um access token válido em Authorization: Bearer, exceto nas rotas públicas informadas.
+// Com OIDC configurado, access tokens do provedor também são aceitos. Integrações enviam X-API-Key no lugar
+// do token e alcançam apenas as rotas cobertas pelos escopos da chave, dentro do limite por minuto dela.
+func (s *Servico) Middleware(publicas ...string) echo.MiddlewareFunc {
+	livres := make(map[string]bool)
+	for _, rota := range publicas {
+		livres[rota] = true
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if livres[c.Path()] {
+				return next(c)
+			}
+			token := tokenDoCabecalho(c)
+			if valor := c.Request().Header.Get(CabecalhoChaveAPI); token == "" && valor != "" && s.ChavesAPI != nil {
+				return s.autorizarChave(c, valor, next)
+			}
+			if token == "" {
+				return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenAusente, "Missing bearer token").HTTP()
+			}
+			ctx := c.Request().Context()
+			claims, err := s.Validar(ctx, token, TokenAcesso)
+			if err != nil && s.OIDC != nil { //não é um token da API: pode ser um access token do provedor
+				var errOIDC error
+				if claims, errOIDC = s.OIDC.ValidarAcesso(ctx, token); errOIDC == nil {
+					err = nil
+				} else {
+					err = fmt.Errorf("%v; as an OIDC token: %v", err, errOIDC)
+				}
+			}
+			if err != nil {
+				log.Debugf("Rejected access token: %v", err)
+				return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenInvalido, "Invalid or expired token").HTTP()
+			}
+			c.Set(ChaveClaims, claims)
+			c.Set(ChaveAtor, claims.Subject)
+			return next(c)
+		}
+	}
+}
+
+// RequisicaoLogin é o corpo de POST /auth/login.
+type RequisicaoLogin = modelos.RequisicaoLogin
+
+// RequisicaoRenovacao é o corpo de POST /auth/refresh e POST /auth/logout.
+type RequisicaoRenovacao = modelos.RequisicaoRenovacao
+
+// Login atende POST /auth/login.
+func (s *Servico) Login(c echo.Context) error {
+	var req RequisicaoLogin
+	if err := c.Bind(&req); err != nil {
+		return problema.CorpoInvalido().HTTP()
+	}
+	identidade, err := s.Credenciais.Verificar(c.Request().Context(), req.Usuario, req.Senha)
+	if err == ErrContaBloqueada {
+		log.Infof("Login attempt for locked account %q from %s", req.Usuario, c.RealIP())
+		return problema.Novo(http.StatusLocked, problema.CodigoContaBloqueada, "Account temporarily locked after repeated failed logins").HTTP()
+	}
+	if err != nil {
+		log.Infof("Failed login for %q from %s: %v", req.Usuario, c.RealIP(), err)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoCredenciais, "Invalid username or password").HTTP()
+	}
+	tokens, err := s.Emitir(identidade)
+	if err != nil {
+		log.Errorf("Unable to sign the tokens: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to issue the tokens")
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Renovar atende POST /auth/refresh. O refresh token usado é revogado e substituído por um novo par, emitido
+// a partir da conta como está agora: papel e vínculos alterados valem já na renovação, e uma conta removida,
+// na lixeira ou anonimizada não renova. Logins OIDC sem conta local passam de novo pelo provedor.
+func (s *Servico) Renovar(c echo.Context) error {
+	var req RequisicaoRenovacao
+	if err := c.Bind(&req); err != nil {
+		return problema.CorpoInvalido().HTTP()
+	}
+	ctx := c.Request().Context()
+	claims, err := s.Validar(ctx, req.RefreshToken, TokenRenovacao)
+	if err != nil {
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenInvalido, "Invalid or expired refresh token").HTTP()
+	}
+	identidade, err := s.Credenciais.Buscar(ctx, claims.Subject)
+	if err == ErrContaInexistente {
+		log.Infof("Refresh token %s of %q refused: the account no longer exists", claims.ID, claims.Subject)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenInvalido, "Invalid or expired refresh token").HTTP()
+	}
+	if err != nil {
+		log.Errorf("Unable to load the account %q: %v", claims.Subject, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to renew the tokens")
+	}
+	revogado, err := s.Revogacoes.Revogar(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		log.Errorf("Unable to revoke the refresh token: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to renew the tokens")
+	}
+	if !revogado { //outra renovação com o mesmo token chegou primeiro
+		log.Infof("Refresh token %s of %q was reused", claims.ID, claims.Subject)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenInvalido, "Invalid or expired refresh token").HTTP()
+	}
+	tokens, err := s.Emitir(identidade)
+	if err != nil {
+		log.Errorf("Unable to sign the tokens: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to issue the tokens")
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout atende POST /auth/logout: revoga o access token da requisição e, se enviado, o refresh token.
+func (s *Servico) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := c.Get(ChaveClaims).(*Claims)
+	if !ok {
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenAusente, "Missing bearer token").HTTP()
+	}
+	if _, err := s.Revogacoes.Revogar(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		log.Errorf("Unable to revoke the access token: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to revoke the token")
+	}
+	var req RequisicaoRenovacao
+	if err := c.Bind(&req); err == nil && req.RefreshToken != "" {
+		if renovacao, err := s.Validar(ctx, req.RefreshToken, TokenRenovacao); err == nil && renovacao.Subject == claims.Subject {
+			if _, err := s.Revogacoes.Revogar(ctx, renovacao.ID, renovacao.ExpiresAt.Time); err != nil {
+				log.Errorf("Unable to revoke the refresh token: %v", err)
+			}
+		}
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// colecaoRevogados guarda as revogações em memória, com a chave única do _id que o MongoDB garante. Entende
+// apenas as consultas de Revogacoes.
+type colecaoRevogados struct {
+	dbiface.Collection
+	mu   sync.Mutex
+	docs map[string]TokensRevogados
+}
+
+func (c *colecaoRevogados) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := document.(TokensRevogados)
+	if _, ok := c.docs[t.ID]; ok {
+		return nil, mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}}
+	}
+	c.docs[t.ID] = t
+	return &mongo.InsertOneResult{InsertedID: t.ID}, nil
+}
+
+func (c *colecaoRevogados) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := filter.(bson.M)["_id"].(string)
+	set := update.(bson.M)["$set"].(bson.M)
+	emitidosAte := set["emitidosAte"].(time.Time).Truncate(time.Millisecond) //a precisão das datas no MongoDB
+	c.docs[id] = TokensRevogados{ID: id, ExpiraEm: set["expiraEm"].(time.Time), EmitidosAte: &emitidosAte}
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (c *colecaoRevogados) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, alternativa := range filter.(bson.M)["$or"].([]bson.M) {
+		t, ok := c.docs[alternativa["_id"].(string)]
+		if !ok {
+			continue
+		}
+		if emitidos, ok := alternativa["emitidosAte"].(bson.M); ok && (t.EmitidosAte == nil || t.EmitidosAte.Before(emitidos["$gte"].(time.Time))) {
+			continue
+		}
+		return mongo.NewSingleResultFromDocument(t, nil, nil)
+	}
+	return mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+}
+
+// contasTeste são contas cadastradas, sem senha: servem apenas à renovação dos tokens.
+type contasTeste map[string]Identidade
+
+func (c contasTeste) Verificar(ctx context.Context, usuario, senha string) (Identidade, error) {
+	return Identidade{}, ErrCredenciaisInvalidas
+}
+
+func (c contasTeste) Buscar(ctx context.Context, usuario string) (Identidade, error) {
+	identidade, ok := c[usuario]
+	if !ok {
+		return Identidade{}, ErrContaInexistente
+	}
+	return identidade, nil
+}
+
+// contaBloqueada recusa todo login como se a conta estivesse bloqueada.
+type contaBloqueada struct{ contasTeste }
+
+func (contaBloqueada) Verificar(ctx context.Context, usuario, senha string) (Identidade, error) {
+	return Identidade{}, ErrContaBloqueada
+}
+
+func novoServico() *Servico {
+	return &Servico{
+		Chaves:           map[string][]byte{"k1": []byte("segredo-um"), "k2": []byte("segredo-dois")},
+		ChaveAtiva:       "k1",
+		Emissor:          "escola",
+		DuracaoAcesso:    time.Minute,
+		DuracaoRenovacao: time.Hour,
+		Credenciais: Cadeia{CredenciaisFixas{Usuario: "admin", Senha: "s3nha"},
+			contasTeste{"ana": {Usuario: "ana", Papel: PapelSecretaria}}},
+		Revogacoes: &Revogacoes{Col: &colecaoRevogados{docs: make(map[string]TokensRevogados)}, Validade: time.Hour},
+	}
+}
+
+func TestEmitirEValidar(t *testing.T) {
+	s := novoServico()
+	ctx := context.Background()
+	tokens, err := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso)
+	if err != nil {
+		t.Fatalf("a fresh access token should be valid: %v", err)
+	}
+	if claims.Subject != "ana" || claims.Papel != PapelSecretaria || claims.ID == "" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if _, err := s.Validar(ctx, tokens.AccessToken, TokenRenovacao); err == nil {
+		t.Error("an access token should not be accepted as a refresh token")
+	}
+	if _, err := s.Validar(ctx, tokens.RefreshToken, TokenRenovacao); err != nil {
+		t.Errorf("a fresh refresh token should be valid: %v", err)
+	}
+
+	//rotação: tokens assinados com uma chave ainda listada continuam válidos
+	s.ChaveAtiva = "k2"
+	if _, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso); err != nil {
+		t.Errorf("a token signed with a listed key should stay valid: %v", err)
+	}
+	delete(s.Chaves, "k1")
+	if _, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso); err == nil {
+		t.Error("a token signed with a removed key should be rejected")
+	}
+}
+
+func TestValidarRecusaTokensForjados(t *testing.T) {
+	s := novoServico()
+	ctx := context.Background()
+	assinar := func(metodo jwt.SigningMethod, kid string, chave interface{}, claims Claims) string {
+		token := jwt.NewWithClaims(metodo, claims)
+		token.Header["kid"] = kid
+		assinado, err := token.SignedString(chave)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return assinado
+	}
+	validos := Claims{Tipo: TokenAcesso, RegisteredClaims: jwt.RegisteredClaims{ID: "1", Subject: "ana", Issuer: "escola",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+	expirados := validos
+	expirados.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	outroEmissor := validos
+	outroEmissor.Issuer = "outro"
+
+	casos := map[string]string{
+		"unknown kid":     assinar(jwt.SigningMethodHS256, "k9", []byte{}, validos),
+		"wrong secret":    assinar(jwt.SigningMethodHS256, "k1", []byte("outro"), validos),
+		"alg none":        assinar(jwt.SigningMethodNone, "k1", jwt.UnsafeAllowNoneSignatureType, validos),
+		"expired":         assinar(jwt.SigningMethodHS256, "k1", []byte("segredo-um"), expirados),
+		"another issuer":  assinar(jwt.SigningMethodHS256, "k1", []byte("segredo-um"), outroEmissor),
+		"not even a JWT":  "abc",
+		"truncated token": strings.Join(strings.Split(assinar(jwt.SigningMethodHS256, "k1", []byte("segredo-um"), validos), ".")[:2], "."),
+	}
+	for caso, token := range casos {
+		if _, err := s.Validar(ctx, token, TokenAcesso); err == nil {
+			t.Errorf("%s: the token should be rejected", caso)
+		}
+	}
+	if _, err := s.Validar(ctx, assinar(jwt.SigningMethodHS256, "k1", []byte("segredo-um"), validos), TokenAcesso); err != nil {
+		t.Errorf("the control token should be valid: %v", err)
+	}
+}
+
+func TestCredenciaisFixas(t *testing.T) {
+	f := CredenciaisFixas{Usuario: "admin", Senha: "s3nha"}
+	if _, err := f.Verificar(context.Background(), "admin", "s3nha"); err != nil {
+		t.Errorf("the right password was refused: %v", err)
+	}
+	for _, tentativa := range [][2]string{{"admin", "s3nh"}, {"admin", "s3nhaa"}, {"Admin", "s3nha"}, {"admin", ""}} {
+		if _, err := f.Verificar(context.Background(), tentativa[0], tentativa[1]); err != ErrCredenciaisInvalidas {
+			t.Errorf("%q/%q: got %v, want ErrCredenciaisInvalidas", tentativa[0], tentativa[1], err)
+		}
+	}
+	if _, err := (CredenciaisFixas{Usuario: "admin"}).Verificar(context.Background(), "admin", ""); err == nil {
+		t.Error("an empty configured password should disable the account")
+	}
+}
+
+func TestLoginContaBloqueada(t *testing.T) {
+	s := novoServico()
+	s.Credenciais = contaBloqueada{}
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"usuario":"ana","senha":"Senha123"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err := s.Login(echo.New().NewContext(req, httptest.NewRecorder()))
+	herr, ok := err.(*echo.HTTPError)
+	if !ok || herr.Code != http.StatusLocked || problema.Converter(err).Codigo != problema.CodigoContaBloqueada {
+		t.Errorf("got %#v, want a 423 HTTP error with code %s", err, problema.CodigoContaBloqueada)
+	}
+}
+
+// renovar chama POST /auth/refresh com o refresh token e devolve o status.
+func renovar(s *Servico, refresh string) (int, Tokens) {
+	e := echo.New()
+	corpo, _ := json.Marshal(RequisicaoRenovacao{RefreshToken: refresh})
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(string(corpo)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	err := s.Renovar(e.NewContext(req, rec))
+	if herr, ok := err.(*echo.HTTPError); ok {
+		return herr.Code, Tokens{}
+	}
+	var tokens Tokens
+	json.Unmarshal(rec.Body.Bytes(), &tokens)
+	return rec.Code, tokens
+}
+
+func TestRenovarAceitaCadaTokenUmaVez(t *testing.T) {
+	s := novoServico()
+	tokens, err := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		aceitos int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, _ := renovar(s, tokens.RefreshToken); status == http.StatusOK {
+				mu.Lock()
+				aceitos++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if aceitos != 1 {
+		t.Errorf("the same refresh token was accepted %d times", aceitos)
+	}
+	if status, _ := renovar(s, tokens.RefreshToken); status != http.StatusUnauthorized {
+		t.Errorf("reusing a refresh token: got %d, want 401", status)
+	}
+}
+
+func TestRenovarRecarregaAConta(t *testing.T) {
+	s := novoServico()
+	ctx := context.Background()
+	contas := contasTeste{"ana": {Usuario: "ana", Papel: PapelProfessor, ProfessorID: "p1"}}
+	s.Credenciais = Cadeia{CredenciaisFixas{Usuario: "admin", Senha: "s3nha"}, contas}
+	antigos, _ := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	status, tokens := renovar(s, antigos.RefreshToken)
+	if status != http.StatusOK {
+		t.Fatalf("got %d", status)
+	}
+	claims, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso)
+	if err != nil || claims.Papel != PapelProfessor || claims.ProfessorID != "p1" {
+		t.Errorf("the renewed token should carry the current role: %+v, %v", claims, err)
+	}
+
+	delete(contas, "ana") //removida, na lixeira ou anonimizada
+	if status, _ := renovar(s, tokens.RefreshToken); status != http.StatusUnauthorized {
+		t.Errorf("renewing for a removed account: got %d, want 401", status)
+	}
+	admin, _ := s.Emitir(Identidade{Usuario: "admin", Papel: PapelSecretaria})
+	if status, _ := renovar(s, admin.RefreshToken); status != http.StatusOK {
+		t.Errorf("the configured administrator should renew, got %d", status)
+	}
+}
+
+func TestLogoutRevogaOsTokens(t *testing.T) {
+	s := novoServico()
+	ctx := context.Background()
+	tokens, _ := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	claims, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	corpo := `{"refreshToken":"` + tokens.RefreshToken + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(corpo))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.Set(ChaveClaims, claims)
+	if err := s.Logout(c); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Validar(ctx, tokens.AccessToken, TokenAcesso); err == nil {
+		t.Error("the access token should be revoked after the logout")
+	}
+	if status, _ := renovar(s, tokens.RefreshToken); status != http.StatusUnauthorized {
+		t.Errorf("the refresh token should be revoked after the logout, got %d", status)
+	}
+}
+
+func TestRevogarUsuario(t *testing.T) {
+	s := novoServico()
+	ctx := context.Background()
+	antigos, _ := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	outro, _ := s.Emitir(Identidade{Usuario: "bia", Papel: PapelSecretaria})
+	if err := s.Revogacoes.RevogarUsuario(ctx, "ana"); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []struct{ valor, tipo string }{{antigos.AccessToken, TokenAcesso}, {antigos.RefreshToken, TokenRenovacao}} {
+		if _, err := s.Validar(ctx, token.valor, token.tipo); err == nil {
+			t.Errorf("a %s token issued before the revocation should be rejected", token.tipo)
+		}
+	}
+	if _, err := s.Validar(ctx, outro.AccessToken, TokenAcesso); err != nil {
+		t.Errorf("the tokens of other users should stay valid: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	novos, _ := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	if _, err := s.Validar(ctx, novos.AccessToken, TokenAcesso); err != nil {
+		t.Errorf("a token issued after the revocation should be valid: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/krunal4amity/tronicscorp/ratelimit"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+const CabecalhoChaveAPI = "X-API-Key"
+
+var ErrChaveInvalida = errors.New("invalid or expired API key")
+
+// ChaveAPI é uma integração autenticada por chave em vez de login. Ela acessa apenas as rotas cobertas
+// pelos Escopos, no formato "read:recurso" ou "write:recurso".
+type ChaveAPI struct {
+	ID              string
+	Nome            string
+	Escopos         []string
+	LimitePorMinuto int //0 desliga o limite da chave
+}
+
+// VerificadorChaves encontra a chave ativa correspondente ao valor enviado pela integração.
+type VerificadorChaves interface {
+	VerificarChave(ctx context.Context, chave string) (ChaveAPI, error)
+}
+
+// subrecursos dão escopo próprio às rotas de um recurso que expõem mais que o documento atual: o histórico
+// de alterações e as versões mostram quem alterou o quê e os valores antigos, e a lixeira mostra os excluídos.
+var subrecursos = map[string]string{
+	"historico-alteracoes": "auditoria",
+	"versoes":              "auditoria",
+	"lixeira":              "lixeira",
+	"restaurar":            "lixeira", //POST /alunos/:id/restaurar tira o documento da lixeira
+}
+
+// EscopoDaRota é o escopo exigido de uma chave para a rota: leitura em GET e HEAD, escrita nos demais
+// métodos, sobre o primeiro segmento do caminho ("GET /alunos/:id" exige "read:alunos"). O histórico, as
+// versões e a lixeira exigem "auditoria" ou "lixeira" no lugar do recurso ("GET /alunos/:id/versoes" exige
+// "read:auditoria"). O POST /graphql só faz consultas e por isso exige leitura.
+func EscopoDaRota(metodo, caminho string) string {
+	segmentos := strings.Split(strings.TrimPrefix(caminho, "/"), "/")
+	recurso := segmentos[0]
+	for _, segmento := range segmentos[1:] {
+		if sub, ok := subrecursos[segmento]; ok {
+			recurso = sub
+			break
+		}
+	}
+	if metodo == http.MethodGet || metodo == http.MethodHead || caminho == "/graphql" {
+		return "read:" + recurso
+	}
+	return "write:" + recurso
+}
+
+// autorizarChave autentica a integração pela chave e só a deixa seguir nas rotas cobertas pelos escopos.
+func (s *Servico) autorizarChave(c echo.Context, valor string, next echo.HandlerFunc) error {
+	claims, err := s.autenticarChave(c, valor)
+	if herr, ok := err.(*echo.HTTPError); ok {
+		return herr
+	}
+	if err != nil {
+		log.Debugf("Rejected API key: %v", err)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoChaveInvalida, "Invalid or expired API key").HTTP()
+	}
+	escopo := EscopoDaRota(c.Request().Method, c.Path())
+	if !contem(claims.Escopos, escopo) {
+		return problema.Novo(http.StatusForbidden, problema.CodigoEscopoAusente, fmt.Sprintf("The API key lacks the scope %s", escopo)).HTTP()
+	}
+	c.Set(ChaveClaims, claims)
+	c.Set(ChaveAtor, claims.Subject)
+	return next(c)
+}
+
+// autenticarChave valida a chave do cabeçalho e aplica o limite de requisições dela.
+func (s *Servico) autenticarChave(c echo.Context, valor string) (*Claims, error) {
+	chave, err := s.ChavesAPI.VerificarChave(c.Request().Context(), valor)
+	if err != nil {
+		return nil, err
+	}
+	if chave.LimitePorMinuto > 0 && s.Limites != nil {
+		limite := ratelimit.Limite{Requisicoes: chave.LimitePorMinuto, Periodo: time.Minute}
+		res, err := s.Limites.Consumir(c.Request().Context(), "chave:"+chave.ID, limite)
+		if err != nil {
+			log.Errorf("Unable to check the API key rate limit: %v", err)
+		} else {
+			ratelimit.Cabecalhos(c, res)
+			if !res.Permitido {
+				return nil, echo.NewHTTPError(http.StatusTooManyRequests, "API key rate limit exceeded")
+			}
+		}
+	}
+	escopos := chave.Escopos
+	if escopos == nil {
+		escopos = []string{}
+	}
+	return &Claims{
+		Tipo:             TokenAcesso,
+		Escopos:          escopos,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "chave:" + chave.Nome, ID: chave.ID},
+	}, nil
+}
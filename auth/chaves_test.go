@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/krunal4amity/tronicscorp/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// chavesFalsas são as chaves ativas pelo valor; uma chave revogada simplesmente não está no mapa.
+type chavesFalsas map[string]ChaveAPI
+
+func (f chavesFalsas) VerificarChave(ctx context.Context, valor string) (ChaveAPI, error) {
+	chave, ok := f[valor]
+	if !ok {
+		return ChaveAPI{}, ErrChaveInvalida
+	}
+	return chave, nil
+}
+
+func TestMiddlewareComChaveAPI(t *testing.T) {
+	s := novoServico()
+	s.ChavesAPI = chavesFalsas{
+		"ss_leitura": {ID: "1", Nome: "portal", Escopos: []string{"read:alunos"}},
+		"ss_limite":  {ID: "2", Nome: "robo", Escopos: []string{"read:alunos"}, LimitePorMinuto: 2},
+	}
+	s.Limites = ratelimit.NovaMemoria()
+	e := echo.New()
+	e.Use(s.Middleware("/auth/login"))
+	responder := func(c echo.Context) error { return c.String(http.StatusOK, c.Get(ChaveAtor).(string)) }
+	e.GET("/alunos", responder)
+	e.POST("/alunos", responder)
+	e.GET("/usuarios", responder)
+	e.GET("/alunos/lixeira", responder)
+	e.GET("/alunos/:id/historico-alteracoes", responder)
+	e.GET("/alunos/:id/versoes", responder)
+
+	requisitar := func(metodo, caminho, chave string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(metodo, caminho, nil)
+		req.Header.Set(CabecalhoChaveAPI, chave)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	casos := []struct {
+		nome, metodo, caminho, chave string
+		status                       int
+	}{
+		{"valid key", http.MethodGet, "/alunos", "ss_leitura", http.StatusOK},
+		{"revoked key", http.MethodGet, "/alunos", "ss_revogada", http.StatusUnauthorized},
+		{"missing write scope", http.MethodPost, "/alunos", "ss_leitura", http.StatusForbidden},
+		{"route outside the scopes", http.MethodGet, "/usuarios", "ss_leitura", http.StatusForbidden},
+		{"trash of a readable resource", http.MethodGet, "/alunos/lixeira", "ss_leitura", http.StatusForbidden},
+		{"change history of a readable resource", http.MethodGet, "/alunos/1/historico-alteracoes", "ss_leitura", http.StatusForbidden},
+		{"versions of a readable resource", http.MethodGet, "/alunos/1/versoes", "ss_leitura", http.StatusForbidden},
+	}
+	for _, caso := range casos {
+		if rec := requisitar(caso.metodo, caso.caminho, caso.chave); rec.Code != caso.status {
+			t.Errorf("%s: got %d, want %d", caso.nome, rec.Code, caso.status)
+		}
+	}
+	if rec := requisitar(http.MethodGet, "/alunos", "ss_leitura"); rec.Body.String() != "chave:portal" {
+		t.Errorf("the actor should be the key, got %q", rec.Body.String())
+	}
+
+	//o limite é de cada chave: a terceira requisição da chave limitada recebe 429, a outra chave segue
+	for i, esperado := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rec := requisitar(http.MethodGet, "/alunos", "ss_limite")
+		if rec.Code != esperado {
+			t.Errorf("request %d with the limited key: got %d, want %d", i+1, rec.Code, esperado)
+		}
+		if rec.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q", i+1, rec.Header().Get("RateLimit-Limit"))
+		}
+	}
+	if rec := requisitar(http.MethodGet, "/alunos", "ss_leitura"); rec.Code != http.StatusOK {
+		t.Errorf("another key should not share the limit, got %d", rec.Code)
+	}
+}
+
+func TestEscopoDaRota(t *testing.T) {
+	casos := []struct{ metodo, caminho, escopo string }{
+		{http.MethodGet, "/alunos", "read:alunos"},
+		{http.MethodGet, "/alunos/:id", "read:alunos"},
+		{http.MethodPut, "/alunos/:id", "write:alunos"},
+		{http.MethodPost, "/alunos/importar", "write:alunos"},
+		{http.MethodGet, "/alunos/:id/historico-alteracoes", "read:auditoria"},
+		{http.MethodGet, "/alunos/:id/versoes", "read:auditoria"},
+		{http.MethodPost, "/alunos/:id/versoes/:v/restaurar", "write:auditoria"},
+		{http.MethodGet, "/alunos/lixeira", "read:lixeira"},
+		{http.MethodPost, "/alunos/:id/restaurar", "write:lixeira"},
+		{http.MethodPost, "/graphql", "read:graphql"},
+	}
+	for _, caso := range casos {
+		if escopo := EscopoDaRota(caso.metodo, caso.caminho); escopo != caso.escopo {
+			t.Errorf("%s %s: got %s, want %s", caso.metodo, caso.caminho, escopo, caso.escopo)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+const cookieEstadoOIDC = "oidc_estado"
+
+// ContaExterna identifica a conta de quem entrou pelo provedor.
+type ContaExterna struct {
+	Emissor         string
+	Sub             string
+	Email           string
+	EmailVerificado bool //email_verified: só um e-mail confirmado pelo provedor pode vincular uma conta local
+}
+
+// Vinculador encontra a conta local correspondente a uma conta do provedor, para reaproveitar o papel e os
+// vínculos (aluno, professor, responsável) cadastrados nela.
+type Vinculador interface {
+	BuscarIdentidade(ctx context.Context, conta ContaExterna) (Identidade, bool)
+}
+
+// OIDC autentica pelo provedor de identidade da escola (authorization code + PKCE) e valida os tokens
+// emitidos por ele com as chaves publicadas no JWKS. Emissor pode apontar para um provedor de testes local.
+type OIDC struct {
+	Emissor      string
+	ClientID     string
+	ClientSecret string //opcional com PKCE
+	RedirectURL  string
+	Escopos      []string
+	Audiencia    string            //aud esperado nos access tokens; vazio usa o ClientID
+	ClaimPapeis  string            //claim com os grupos/papéis no provedor, por exemplo "roles"
+	MapaPapeis   map[string]string //valor no provedor -> papel da API
+	Vinculador   Vinculador
+	Cliente      *http.Client
+
+	mu          sync.Mutex
+	descoberta  *descobertaOIDC
+	chaves      map[string]*rsa.PublicKey
+	atualizacao time.Time
+}
+
+type descobertaOIDC struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (o *OIDC) cliente() *http.Client {
+	if o.Cliente != nil {
+		return o.Cliente
+	}
+	return http.DefaultClient
+}
+
+func (o *OIDC) buscarJSON(ctx context.Context, endereco string, destino interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endereco, nil)
+	if err != nil {
+		return err
+	}
+	res, err := o.cliente().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", endereco, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(destino)
+}
+
+// configuracao lê o documento de descoberta do provedor na primeira vez que é necessário.
+func (o *OIDC) configuracao(ctx context.Context) (*descobertaOIDC, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.descoberta != nil {
+		return o.descoberta, nil
+	}
+	var d descobertaOIDC
+	if err := o.buscarJSON(ctx, strings.TrimSuffix(o.Emissor, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, err
+	}
+	if d.Issuer != o.Emissor {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", d.Issuer, o.Emissor)
+	}
+	o.descoberta = &d
+	return o.descoberta, nil
+}
+
+// chave devolve a chave pública do kid, recarregando o JWKS quando o kid é desconhecido (rotação no
+// provedor), no máximo uma vez por minuto.
+func (o *OIDC) chave(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	d, err := o.configuracao(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if chave, ok := o.chaves[kid]; ok {
+		return chave, nil
+	}
+	if time.Since(o.atualizacao) < time.Minute {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	o.atualizacao = time.Now()
+
+	var conjunto struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := o.buscarJSON(ctx, d.JWKSURI, &conjunto); err != nil {
+		return nil, err
+	}
+	chaves := make(map[string]*rsa.PublicKey)
+	for _, k := range conjunto.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		chaves[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	o.chaves = chaves
+	chave, ok := chaves[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return chave, nil
+}
+
+func contemAudiencia(claims jwt.MapClaims, esperada string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return aud == esperada
+	case []interface{}:
+		for _, a := range aud {
+			if a == esperada {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validar confere assinatura, expiração, emissor e audiência de um token do provedor.
+func (o *OIDC) validar(ctx context.Context, tokenString, audiencia string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return o.chave(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyIssuer(o.Emissor, true) {
+		return nil, errors.New("unexpected issuer")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("token without expiration")
+	}
+	if !contemAudiencia(claims, audiencia) {
+		return nil, errors.New("unexpected audience")
+	}
+	return claims, nil
+}
+
+// identidade mapeia os claims do provedor para a identidade da API. O papel vem do ClaimPapeis pelo
+// MapaPapeis; se o provedor não informar um papel conhecido, vale o da conta local vinculada. Sem conta
+// vinculada, o usuário recebe o prefixo "oidc:", para nunca se passar por uma conta local de mesmo login.
+func (o *OIDC) identidade(ctx context.Context, claims jwt.MapClaims) (Identidade, error) {
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return Identidade{}, errors.New("token without subject")
+	}
+	conta := ContaExterna{Emissor: o.Emissor, Sub: sub}
+	conta.Email, _ = claims["email"].(string)
+	conta.EmailVerificado, _ = claims["email_verified"].(bool)
+
+	login, _ := claims["preferred_username"].(string)
+	if login == "" {
+		login = sub
+	}
+	identidade := Identidade{Usuario: "oidc:" + login}
+	if o.Vinculador != nil {
+		if local, ok := o.Vinculador.BuscarIdentidade(ctx, conta); ok {
+			identidade = local
+		}
+	}
+
+	var valores []interface{}
+	switch v := claims[o.ClaimPapeis].(type) {
+	case string:
+		valores = []interface{}{v}
+	case []interface{}:
+		valores = v
+	}
+	for _, valor := range valores {
+		if papel, ok := o.MapaPapeis[fmt.Sprint(valor)]; ok {
+			identidade.Papel = papel
+			break
+		}
+	}
+	if identidade.Papel == "" {
+		return identidade, fmt.Errorf("no role mapped for %q", login)
+	}
+	return identidade, nil
+}
+
+// ValidarAcesso aceita um access token do provedor no lugar de um token da API; o Middleware o usa quando o
+// token não é da API.
+func (o *OIDC) ValidarAcesso(ctx context.Context, tokenString string) (*Claims, error) {
+	audiencia := o.Audiencia
+	if audiencia == "" {
+		audiencia = o.ClientID
+	}
+	mc, err := o.validar(ctx, tokenString, audiencia)
+	if err != nil {
+		return nil, err
+	}
+	identidade, err := o.identidade(ctx, mc)
+	if err != nil {
+		return nil, err
+	}
+	jti, _ := mc["jti"].(string)
+	exp, _ := mc["exp"].(float64)
+	return &Claims{
+		Tipo:        TokenAcesso,
+		Papel:       identidade.Papel,
+		AlunoID:     identidade.AlunoID,
+		ProfessorID: identidade.ProfessorID,
+		Vinculados:  identidade.Vinculados,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   identidade.Usuario,
+			Issuer:    o.Emissor,
+			ExpiresAt: jwt.NewNumericDate(time.Unix(int64(exp), 0)),
+		},
+	}, nil
+}
+
+func aleatorio() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+type estadoOIDC struct {
+	Estado      string `json:"estado"`
+	Verificador string `json:"verificador"`
+	Nonce       string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// LoginOIDC atende GET /auth/oidc/login redirecionando para o provedor. O estado, o code_verifier do PKCE
+// e o nonce ficam em um cookie assinado com a chave ativa da API, sem estado no servidor.
+func (s *Servico) LoginOIDC(c echo.Context) error {
+	ctx := c.Request().Context()
+	d, err := s.OIDC.configuracao(ctx)
+	if err != nil {
+		log.Errorf("Unable to read the OIDC discovery document: %v", err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Unable to reach the identity provider")
+	}
+	estado := estadoOIDC{
+		Estado:           aleatorio(),
+		Verificador:      aleatorio(),
+		Nonce:            aleatorio(),
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * time.Minute))},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, estado)
+	token.Header["kid"] = s.ChaveAtiva
+	assinado, err := token.SignedString(s.Chaves[s.ChaveAtiva])
+	if err != nil {
+		log.Errorf("Unable to sign the OIDC state: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to start the login")
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     cookieEstadoOIDC,
+		Value:    assinado,
+		Path:     "/auth/oidc",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	desafio := sha256.Sum256([]byte(estado.Verificador))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.OIDC.ClientID},
+		"redirect_uri":          {s.OIDC.RedirectURL},
+		"scope":                 {strings.Join(s.OIDC.Escopos, " ")},
+		"state":                 {estado.Estado},
+		"nonce":                 {estado.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(desafio[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separador := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separador = "&"
+	}
+	return c.Redirect(http.StatusFound, d.AuthorizationEndpoint+separador+q.Encode())
+}
+
+// CallbackOIDC atende GET /auth/oidc/callback: troca o code pelo ID token, valida-o e emite os tokens da API.
+func (s *Servico) CallbackOIDC(c echo.Context) error {
+	ctx := c.Request().Context()
+	if erro := c.QueryParam("error"); erro != "" {
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoLogin, "The identity provider refused the login: "+erro).HTTP()
+	}
+	cookie, err := c.Cookie(cookieEstadoOIDC)
+	if err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoLogin, "Missing login state").HTTP()
+	}
+	var estado estadoOIDC
+	_, err = jwt.ParseWithClaims(cookie.Value, &estado, s.chaveHMAC)
+	if err != nil || estado.Estado != c.QueryParam("state") {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoLogin, "Invalid login state").HTTP()
+	}
+	c.SetCookie(&http.Cookie{Name: cookieEstadoOIDC, Path: "/auth/oidc", MaxAge: -1})
+
+	d, err := s.OIDC.configuracao(ctx)
+	if err != nil {
+		log.Errorf("Unable to read the OIDC discovery document: %v", err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Unable to reach the identity provider")
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {c.QueryParam("code")},
+		"redirect_uri":  {s.OIDC.RedirectURL},
+		"client_id":     {s.OIDC.ClientID},
+		"code_verifier": {estado.Verificador},
+	}
+	if s.OIDC.ClientSecret != "" {
+		form.Set("client_secret", s.OIDC.ClientSecret)
+	}
+	req, err := http.NewRequest(http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to complete the login")
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	res, err := s.OIDC.cliente().Do(req.WithContext(ctx))
+	if err != nil {
+		log.Errorf("Unable to exchange the authorization code: %v", err)
+		return echo.NewHTTPError(http.StatusBadGateway, "Unable to reach the identity provider")
+	}
+	defer res.Body.Close()
+	var resposta struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resposta); err != nil || res.StatusCode != http.StatusOK || resposta.IDToken == "" {
+		log.Errorf("Token endpoint answered %s: %v", res.Status, err)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoLogin, "Unable to exchange the authorization code").HTTP()
+	}
+
+	claims, err := s.OIDC.validar(ctx, resposta.IDToken, s.OIDC.ClientID)
+	if err != nil || claims["nonce"] != estado.Nonce {
+		log.Infof("Rejected ID token: %v", err)
+		return problema.Novo(http.StatusUnauthorized, problema.CodigoLogin, "Invalid ID token").HTTP()
+	}
+	identidade, err := s.OIDC.identidade(ctx, claims)
+	if err != nil {
+		log.Infof("OIDC login without a role: %v", err)
+		return echo.NewHTTPError(http.StatusForbidden, "The account has no role in this API")
+	}
+	tokens, err := s.Emitir(identidade)
+	if err != nil {
+		log.Errorf("Unable to sign the tokens: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to issue the tokens")
+	}
+	return c.JSON(http.StatusOK, tokens)
+}
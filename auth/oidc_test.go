@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+)
+
+// autorizacao é o que o provedor falso guarda de cada code emitido.
+type autorizacao struct {
+	desafio string //code_challenge
+	nonce   string
+}
+
+// provedorFalso é um provedor OIDC mínimo: descoberta, JWKS e token endpoint com PKCE S256.
+type provedorFalso struct {
+	t        *testing.T
+	chave    *rsa.PrivateKey
+	servidor *httptest.Server
+	mu       sync.Mutex
+	codigos  map[string]autorizacao
+	claims   jwt.MapClaims //claims do ID token, além de iss, aud, exp e nonce
+	nonce    string        //se preenchido, substitui o nonce recebido na autorização
+}
+
+func novoProvedor(t *testing.T) *provedorFalso {
+	chave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &provedorFalso{t: t, chave: chave, codigos: make(map[string]autorizacao),
+		claims: jwt.MapClaims{"sub": "idp-123", "email": "ana@escola.br", "email_verified": true, "roles": []string{"docente"}}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(descobertaOIDC{Issuer: p.servidor.URL, AuthorizationEndpoint: p.servidor.URL + "/authorize",
+			TokenEndpoint: p.servidor.URL + "/token", JWKSURI: p.servidor.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		e := big.NewInt(int64(chave.E)).Bytes()
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": {{Kid: "idp1", Kty: "RSA", Use: "sig",
+			N: base64.RawURLEncoding.EncodeToString(chave.N.Bytes()), E: base64.RawURLEncoding.EncodeToString(e)}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		a, ok := p.codigos[r.FormValue("code")]
+		delete(p.codigos, r.FormValue("code"))
+		p.mu.Unlock()
+		verificador := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+		if !ok || r.FormValue("grant_type") != "authorization_code" || r.FormValue("client_id") != "api" ||
+			base64.RawURLEncoding.EncodeToString(verificador[:]) != a.desafio {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		nonce := a.nonce
+		if p.nonce != "" {
+			nonce = p.nonce
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": p.assinar(jwt.MapClaims{"nonce": nonce}), "token_type": "Bearer"})
+	})
+	p.servidor = httptest.NewServer(mux)
+	t.Cleanup(p.servidor.Close)
+	return p
+}
+
+// assinar emite um token do provedor com os claims padrão mais os extras.
+func (p *provedorFalso) assinar(extras jwt.MapClaims) string {
+	claims := jwt.MapClaims{"iss": p.servidor.URL, "aud": "api", "exp": time.Now().Add(time.Minute).Unix()}
+	for k, v := range p.claims {
+		claims[k] = v
+	}
+	for k, v := range extras {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp1"
+	assinado, err := token.SignedString(p.chave)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return assinado
+}
+
+// vinculadorFalso vincula a conta do provedor por sub e guarda a última consulta.
+type vinculadorFalso struct {
+	contas map[string]Identidade
+	ultima ContaExterna
+}
+
+func (v *vinculadorFalso) BuscarIdentidade(ctx context.Context, conta ContaExterna) (Identidade, bool) {
+	v.ultima = conta
+	identidade, ok := v.contas[conta.Sub]
+	return identidade, ok
+}
+
+func servicoOIDC(p *provedorFalso, v Vinculador) *Servico {
+	s := novoServico()
+	s.OIDC = &OIDC{Emissor: p.servidor.URL, ClientID: "api", RedirectURL: "http://api/auth/oidc/callback",
+		Escopos: []string{"openid", "email"}, ClaimPapeis: "roles", MapaPapeis: map[string]string{"docente": PapelProfessor},
+		Vinculador: v, Cliente: p.servidor.Client()}
+	return s
+}
+
+// iniciarLogin chama GET /auth/oidc/login e autoriza no provedor falso: devolve o cookie de estado e os
+// parâmetros da autorização.
+func iniciarLogin(t *testing.T, s *Servico, p *provedorFalso, code string) (*http.Cookie, url.Values) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := s.LoginOIDC(e.NewContext(httptest.NewRequest(http.MethodGet, "/auth/oidc/login", nil), rec)); err != nil {
+		t.Fatal(err)
+	}
+	destino, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+	if err != nil || destino.Path != "/authorize" {
+		t.Fatalf("unexpected redirect %q", rec.Header().Get(echo.HeaderLocation))
+	}
+	q := destino.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("nonce") == "" || q.Get("state") == "" {
+		t.Fatalf("the authorization request lacks PKCE, nonce or state: %v", q)
+	}
+	p.mu.Lock()
+	p.codigos[code] = autorizacao{desafio: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	p.mu.Unlock()
+	return rec.Result().Cookies()[0], q
+}
+
+// concluirLogin chama GET /auth/oidc/callback com o cookie e a query dados; devolve o status e os tokens.
+func concluirLogin(s *Servico, cookie *http.Cookie, query url.Values) (int, Tokens) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/callback?"+query.Encode(), nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	if err := s.CallbackOIDC(e.NewContext(req, rec)); err != nil {
+		return err.(*echo.HTTPError).Code, Tokens{}
+	}
+	var tokens Tokens
+	json.Unmarshal(rec.Body.Bytes(), &tokens)
+	return rec.Code, tokens
+}
+
+func TestOIDCLoginCompleto(t *testing.T) {
+	p := novoProvedor(t)
+	v := &vinculadorFalso{contas: map[string]Identidade{"idp-123": {Usuario: "ana", Papel: PapelSecretaria}}}
+	s := servicoOIDC(p, v)
+	cookie, q := iniciarLogin(t, s, p, "codigo-1")
+
+	status, tokens := concluirLogin(s, cookie, url.Values{"code": {"codigo-1"}, "state": {q.Get("state")}})
+	if status != http.StatusOK {
+		t.Fatalf("got %d, want 200", status)
+	}
+	claims, err := s.Validar(context.Background(), tokens.AccessToken, TokenAcesso)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//o papel do provedor prevalece; o usuário é o da conta local vinculada
+	if claims.Subject != "ana" || claims.Papel != PapelProfessor {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if v.ultima != (ContaExterna{Emissor: p.servidor.URL, Sub: "idp-123", Email: "ana@escola.br", EmailVerificado: true}) {
+		t.Errorf("unexpected external account %+v", v.ultima)
+	}
+	//o code só vale uma vez
+	if status, _ := concluirLogin(s, cookie, url.Values{"code": {"codigo-1"}, "state": {q.Get("state")}}); status != http.StatusUnauthorized {
+		t.Errorf("reusing the code: got %d, want 401", status)
+	}
+}
+
+func TestOIDCSemContaVinculada(t *testing.T) {
+	p := novoProvedor(t)
+	p.claims["preferred_username"] = "ana"
+	p.claims["email_verified"] = false
+	v := &vinculadorFalso{}
+	s := servicoOIDC(p, v)
+	cookie, q := iniciarLogin(t, s, p, "codigo-1")
+	status, tokens := concluirLogin(s, cookie, url.Values{"code": {"codigo-1"}, "state": {q.Get("state")}})
+	if status != http.StatusOK {
+		t.Fatalf("got %d, want 200", status)
+	}
+	claims, _ := s.Validar(context.Background(), tokens.AccessToken, TokenAcesso)
+	if claims.Subject != "oidc:ana" || v.ultima.EmailVerificado {
+		t.Errorf("an unlinked account must not look like the local login: %+v, %+v", claims, v.ultima)
+	}
+}
+
+func TestOIDCRecusaCallbacksInvalidos(t *testing.T) {
+	p := novoProvedor(t)
+	s := servicoOIDC(p, &vinculadorFalso{})
+
+	//nonce diferente do enviado na autorização
+	cookie, q := iniciarLogin(t, s, p, "codigo-1")
+	p.nonce = "outro"
+	if status, _ := concluirLogin(s, cookie, url.Values{"code": {"codigo-1"}, "state": {q.Get("state")}}); status != http.StatusUnauthorized {
+		t.Errorf("wrong nonce: got %d, want 401", status)
+	}
+	p.nonce = ""
+
+	//o code foi emitido para outro code_challenge: o provedor recusa o code_verifier do cookie
+	cookie, q = iniciarLogin(t, s, p, "codigo-2")
+	outro, _ := iniciarLogin(t, s, p, "codigo-3")
+	if status, _ := concluirLogin(s, outro, url.Values{"code": {"codigo-2"}, "state": {q.Get("state")}}); status != http.StatusBadRequest {
+		t.Errorf("state of another login: got %d, want 400", status)
+	}
+	p.codigos["codigo-2"] = p.codigos["codigo-3"]
+	if status, _ := concluirLogin(s, cookie, url.Values{"code": {"codigo-2"}, "state": {q.Get("state")}}); status != http.StatusUnauthorized {
+		t.Errorf("wrong PKCE verifier: got %d, want 401", status)
+	}
+
+	//cookie de estado assinado com um kid que a API não tem
+	forjado := jwt.NewWithClaims(jwt.SigningMethodHS256, estadoOIDC{Estado: "e", Verificador: "v", Nonce: "n"})
+	forjado.Header["kid"] = "desconhecido"
+	assinado, _ := forjado.SignedString([]byte{})
+	cookie = &http.Cookie{Name: cookieEstadoOIDC, Value: assinado}
+	if status, _ := concluirLogin(s, cookie, url.Values{"code": {"x"}, "state": {"e"}}); status != http.StatusBadRequest {
+		t.Errorf("state signed with an unknown kid: got %d, want 400", status)
+	}
+}
+
+func TestMiddlewareAceitaAccessTokenDoProvedor(t *testing.T) {
+	p := novoProvedor(t)
+	s := servicoOIDC(p, &vinculadorFalso{})
+	api, _ := s.Emitir(Identidade{Usuario: "ana", Papel: PapelSecretaria})
+	e := echo.New()
+	e.Use(s.Middleware())
+	e.GET("/alunos", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get(ChaveAtor).(string))
+	})
+	casos := []struct {
+		token  string
+		status int
+		ator   string
+	}{
+		{api.AccessToken, http.StatusOK, "ana"},
+		{p.assinar(nil), http.StatusOK, "oidc:idp-123"},
+		{p.assinar(jwt.MapClaims{"aud": "outra-api"}), http.StatusUnauthorized, ""},
+		{p.assinar(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized, ""},
+		{"abc", http.StatusUnauthorized, ""},
+	}
+	for i, caso := range casos {
+		req := httptest.NewRequest(http.MethodGet, "/alunos", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+caso.token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != caso.status || (caso.ator != "" && rec.Body.String() != caso.ator) {
+			t.Errorf("case %d: got %d %q, want %d %q", i, rec.Code, rec.Body.String(), caso.status, caso.ator)
+		}
+	}
+}
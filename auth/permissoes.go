@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	PapelSecretaria  = "secretaria"
+	PapelProfessor   = "professor"
+	PapelAluno       = "aluno"
+	PapelResponsavel = "responsavel"
+)
+
+// Instancia decide se o portador do token pode acessar o documento específico da requisição.
+type Instancia func(c echo.Context, claims *Claims) bool
+
+// Regra libera uma rota para os papéis listados. Com Instancia definida, a liberação vale apenas quando a
+// verificação passa; caso contrário a resposta é 403 com o Motivo.
+type Regra struct {
+	Papeis    []string
+	Instancia Instancia
+	Motivo    string
+}
+
+// Politica associa cada rota, no formato "MÉTODO /caminho" como registrado no Echo, às regras que a
+// liberam. Rotas fora da política são negadas a todos.
+type Politica map[string][]Regra
+
+func Permitir(papeis ...string) Regra {
+	return Regra{Papeis: papeis}
+}
+
+// Se restringe a regra a um documento específico.
+func (r Regra) Se(instancia Instancia, motivo string) Regra {
+	r.Instancia = instancia
+	r.Motivo = motivo
+	return r
+}
+
+// ProprioAluno confere se o parâmetro da rota é o aluno vinculado ao token.
+func ProprioAluno(param string) Instancia {
+	return func(c echo.Context, claims *Claims) bool {
+		return claims.AlunoID != "" && claims.AlunoID == c.Param(param)
+	}
+}
+
+// AlunoVinculado confere se o parâmetro da rota é um dos alunos pelos quais o responsável responde.
+func AlunoVinculado(param string) Instancia {
+	return func(c echo.Context, claims *Claims) bool {
+		for _, id := range claims.Vinculados {
+			if id == c.Param(param) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func contem(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware aplica a política depois da autenticação. Rotas públicas e requisições sem rota (404) passam.
+// Chaves de API acessam as rotas da política cobertas pelos seus escopos, independentemente dos papéis.
+func (p Politica) Middleware(publicas ...string) echo.MiddlewareFunc {
+	livres := make(map[string]bool)
+	for _, rota := range publicas {
+		livres[rota] = true
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Path() == "" || livres[c.Path()] {
+				return next(c)
+			}
+			claims, ok := c.Get(ChaveClaims).(*Claims)
+			if !ok {
+				return problema.Novo(http.StatusUnauthorized, problema.CodigoTokenAusente, "Missing bearer token").HTTP()
+			}
+			rota := c.Request().Method + " " + c.Path()
+			if claims.Escopos != nil { //chave de API: vale o escopo, não o papel
+				if _, registrada := p[rota]; registrada && contem(claims.Escopos, EscopoDaRota(c.Request().Method, c.Path())) {
+					return next(c)
+				}
+				return problema.Novo(http.StatusForbidden, problema.CodigoEscopoAusente, fmt.Sprintf("The API key lacks the scope %s", EscopoDaRota(c.Request().Method, c.Path()))).HTTP()
+			}
+			motivo := fmt.Sprintf("Role %q is not allowed to %s", claims.Papel, rota)
+			for _, regra := range p[rota] {
+				if !contem(regra.Papeis, claims.Papel) {
+					continue
+				}
+				if regra.Instancia == nil || regra.Instancia(c, claims) {
+					return next(c)
+				}
+				motivo = regra.Motivo
+			}
+			return echo.NewHTTPError(http.StatusForbidden, motivo)
+		}
+	}
+}
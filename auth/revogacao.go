@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// prefixoUsuario distingue as revogações por usuário dos jti, que são hexadecimais.
+const prefixoUsuario = "usuario:"
+
+// TokensRevogados guarda o jti dos tokens revogados até a expiração original deles; depois disso o token
+// já seria recusado de qualquer forma e o índice TTL da coleção remove a entrada. As entradas por usuário
+// revogam todos os tokens dele emitidos até EmitidosAte.
+type TokensRevogados struct {
+	ID          string     `json:"_id" bson:"_id"` //jti, ou prefixoUsuario seguido do login
+	ExpiraEm    time.Time  `json:"expiraEm" bson:"expiraEm"`
+	EmitidosAte *time.Time `json:"emitidosAte,omitempty" bson:"emitidosAte,omitempty"`
+}
+
+type Revogacoes struct {
+	Col      dbiface.Collection
+	Validade time.Duration //maior duração de um token emitido, por quanto tempo vale a revogação por usuário
+}
+
+// Revogar registra o jti como revogado. A inclusão é atômica, pela chave única do _id: devolve false quando
+// o token já estava revogado, e assim só uma de duas renovações simultâneas com o mesmo refresh token passa.
+func (r *Revogacoes) Revogar(ctx context.Context, jti string, expiraEm time.Time) (bool, error) {
+	_, err := r.Col.InsertOne(ctx, TokensRevogados{ID: jti, ExpiraEm: expiraEm})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevogarUsuario revoga todos os tokens do usuário emitidos até agora, como depois de uma troca de senha.
+func (r *Revogacoes) RevogarUsuario(ctx context.Context, usuario string) error {
+	agora := time.Now()
+	update := bson.M{"$set": bson.M{"emitidosAte": agora, "expiraEm": agora.Add(r.Validade)}}
+	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": prefixoUsuario + usuario}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// Revogado diz se o próprio token foi revogado ou se ele foi emitido antes de uma revogação do usuário.
+func (r *Revogacoes) Revogado(ctx context.Context, claims *Claims) (bool, error) {
+	var emitido time.Time
+	if claims.IssuedAt != nil {
+		emitido = claims.IssuedAt.Time
+	}
+	filter := bson.M{"$or": []bson.M{
+		{"_id": claims.ID},
+		{"_id": prefixoUsuario + claims.Subject, "emitidosAte": bson.M{"$gte": emitido}},
+	}}
+	var revogado TokensRevogados
+	err := r.Col.FindOne(ctx, filter).Decode(&revogado)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
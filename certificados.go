@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// certificadoRecarregavel lê o certificado e a chave do disco e os relê quando os arquivos mudam, para que
+// a renovação (por exemplo pelo certbot) não exija reiniciar a API. A verificação acontece no handshake, no
+// máximo uma vez por intervalo.
+type certificadoRecarregavel struct {
+	certificado string
+	chave       string
+	intervalo   time.Duration
+
+	mu         sync.Mutex
+	atual      *tls.Certificate
+	modificado time.Time
+	verificado time.Time
+}
+
+func novoCertificadoRecarregavel(certificado, chave string, intervalo time.Duration) (*certificadoRecarregavel, error) {
+	cr := &certificadoRecarregavel{certificado: certificado, chave: chave, intervalo: intervalo}
+	if err := cr.carregar(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (cr *certificadoRecarregavel) ultimaModificacao() (time.Time, error) {
+	var ultima time.Time
+	for _, arquivo := range []string{cr.certificado, cr.chave} {
+		info, err := os.Stat(arquivo)
+		if err != nil {
+			return ultima, err
+		}
+		if info.ModTime().After(ultima) {
+			ultima = info.ModTime()
+		}
+	}
+	return ultima, nil
+}
+
+func (cr *certificadoRecarregavel) carregar() error {
+	modificado, err := cr.ultimaModificacao()
+	if err != nil {
+		return err
+	}
+	par, err := tls.LoadX509KeyPair(cr.certificado, cr.chave)
+	if err != nil {
+		return err
+	}
+	cr.atual, cr.modificado = &par, modificado
+	return nil
+}
+
+// GetCertificate atende tls.Config. Se a releitura falhar (arquivos pela metade durante a renovação), o
+// certificado anterior continua em uso.
+func (cr *certificadoRecarregavel) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if time.Since(cr.verificado) >= cr.intervalo {
+		cr.verificado = time.Now()
+		if modificado, err := cr.ultimaModificacao(); err == nil && modificado.After(cr.modificado) {
+			if err := cr.carregar(); err != nil {
+				log.Errorf("Unable to reload the TLS certificate: %v", err)
+			} else {
+				log.Infof("Reloaded the TLS certificate from %s", cr.certificado)
+			}
+		}
+	}
+	return cr.atual, nil
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operações comuns aos recursos do cadastro escolar. Os métodos tipados abaixo só escolhem o recurso e o
+// tipo do documento.
+
+func (c *Cliente) listar(ctx context.Context, recurso string, f Filtro, lixeira bool, destino interface{}) error {
+	caminho := "/" + recurso
+	if lixeira {
+		caminho += "/lixeira"
+	}
+	return c.chamar(ctx, http.MethodGet, caminho, f.consulta(), nil, destino)
+}
+
+func (c *Cliente) buscar(ctx context.Context, recurso string, id primitive.ObjectID, em time.Time, destino interface{}) error {
+	var q url.Values
+	if !em.IsZero() {
+		q = url.Values{"asOf": {em.Format(time.RFC3339)}}
+	}
+	return c.chamar(ctx, http.MethodGet, caminhoItem(recurso, id), q, nil, destino)
+}
+
+func (c *Cliente) inserir(ctx context.Context, recurso string, docs interface{}) ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	err := c.chamar(ctx, http.MethodPost, "/"+recurso, nil, docs, &ids)
+	return ids, err
+}
+
+func (c *Cliente) atualizar(ctx context.Context, recurso string, id primitive.ObjectID, doc, destino interface{}) error {
+	return c.chamar(ctx, http.MethodPut, caminhoItem(recurso, id), nil, doc, destino)
+}
+
+// deletar move o documento para a lixeira e devolve quantos documentos foram alterados.
+func (c *Cliente) deletar(ctx context.Context, recurso string, id primitive.ObjectID) (int64, error) {
+	var n int64
+	err := c.chamar(ctx, http.MethodDelete, caminhoItem(recurso, id), nil, nil, &n)
+	return n, err
+}
+
+func (c *Cliente) restaurar(ctx context.Context, recurso string, id primitive.ObjectID) (int64, error) {
+	var n int64
+	err := c.chamar(ctx, http.MethodPost, caminhoItem(recurso, id, "restaurar"), nil, nil, &n)
+	return n, err
+}
+
+func (c *Cliente) simularExclusao(ctx context.Context, recurso string, id primitive.ObjectID) (modelos.RelatorioExclusao, error) {
+	var relatorio modelos.RelatorioExclusao
+	q := url.Values{"dryRun": {"true"}}
+	err := c.chamar(ctx, http.MethodDelete, caminhoItem(recurso, id), q, nil, &relatorio)
+	return relatorio, err
+}
+
+// HistoricoAlteracoes lista as entradas de auditoria de um documento de qualquer recurso ("alunos",
+// "usuarios", "chaves-api"...).
+func (c *Cliente) HistoricoAlteracoes(ctx context.Context, recurso string, id primitive.ObjectID) ([]modelos.Auditoria, error) {
+	var entradas []modelos.Auditoria
+	err := c.chamar(ctx, http.MethodGet, caminhoItem(recurso, id, "historico-alteracoes"), nil, nil, &entradas)
+	return entradas, err
+}
+
+// Versoes lista as versões completas de um documento do cadastro escolar.
+func (c *Cliente) Versoes(ctx context.Context, recurso string, id primitive.ObjectID) ([]modelos.Versoes, error) {
+	var versoes []modelos.Versoes
+	err := c.chamar(ctx, http.MethodGet, caminhoItem(recurso, id, "versoes"), nil, nil, &versoes)
+	return versoes, err
+}
+
+// RestaurarVersao volta o documento para a versão v.
+func (c *Cliente) RestaurarVersao(ctx context.Context, recurso string, id primitive.ObjectID, v int) error {
+	return c.chamar(ctx, http.MethodPost, caminhoItem(recurso, id, "versoes", strconv.Itoa(v), "restaurar"), nil, nil, nil)
+}
+
+//alunos
+
+func (c *Cliente) BuscarAlunos(ctx context.Context, f Filtro) ([]modelos.Alunos, error) {
+	var alunos []modelos.Alunos
+	err := c.listar(ctx, "alunos", f, false, &alunos)
+	return alunos, err
+}
+
+func (c *Cliente) BuscarLixeiraAlunos(ctx context.Context, f Filtro) ([]modelos.Alunos, error) {
+	var alunos []modelos.Alunos
+	err := c.listar(ctx, "alunos", f, true, &alunos)
+	return alunos, err
+}
+
+// BuscarAluno devolve o aluno ativo; com em diferente de zero, o estado dele naquele instante.
+func (c *Cliente) BuscarAluno(ctx context.Context, id primitive.ObjectID, em time.Time) (modelos.Alunos, error) {
+	var aluno modelos.Alunos
+	err := c.buscar(ctx, "alunos", id, em, &aluno)
+	return aluno, err
+}
+
+func (c *Cliente) InserirAlunos(ctx context.Context, alunos []modelos.Alunos) ([]primitive.ObjectID, error) {
+	return c.inserir(ctx, "alunos", alunos)
+}
+
+func (c *Cliente) AtualizarAluno(ctx context.Context, id primitive.ObjectID, aluno modelos.Alunos) (modelos.Alunos, error) {
+	var atualizado modelos.Alunos
+	err := c.atualizar(ctx, "alunos", id, aluno, &atualizado)
+	return atualizado, err
+}
+
+func (c *Cliente) DeletarAluno(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.deletar(ctx, "alunos", id)
+}
+
+func (c *Cliente) RestaurarAluno(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.restaurar(ctx, "alunos", id)
+}
+
+//professores
+
+func (c *Cliente) BuscarProfessores(ctx context.Context, f Filtro) ([]modelos.Professores, error) {
+	var professores []modelos.Professores
+	err := c.listar(ctx, "professores", f, false, &professores)
+	return professores, err
+}
+
+func (c *Cliente) BuscarLixeiraProfessores(ctx context.Context, f Filtro) ([]modelos.Professores, error) {
+	var professores []modelos.Professores
+	err := c.listar(ctx, "professores", f, true, &professores)
+	return professores, err
+}
+
+// BuscarProfessor devolve o professor ativo; com em diferente de zero, o estado dele naquele instante.
+func (c *Cliente) BuscarProfessor(ctx context.Context, id primitive.ObjectID, em time.Time) (modelos.Professores, error) {
+	var professor modelos.Professores
+	err := c.buscar(ctx, "professores", id, em, &professor)
+	return professor, err
+}
+
+func (c *Cliente) InserirProfessores(ctx context.Context, professores []modelos.Professores) ([]primitive.ObjectID, error) {
+	return c.inserir(ctx, "professores", professores)
+}
+
+func (c *Cliente) AtualizarProfessor(ctx context.Context, id primitive.ObjectID, professor modelos.Professores) (modelos.Professores, error) {
+	var atualizado modelos.Professores
+	err := c.atualizar(ctx, "professores", id, professor, &atualizado)
+	return atualizado, err
+}
+
+func (c *Cliente) DeletarProfessor(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.deletar(ctx, "professores", id)
+}
+
+func (c *Cliente) RestaurarProfessor(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.restaurar(ctx, "professores", id)
+}
+
+//cursos
+
+func (c *Cliente) BuscarCursos(ctx context.Context, f Filtro) ([]modelos.Cursos, error) {
+	var cursos []modelos.Cursos
+	err := c.listar(ctx, "cursos", f, false, &cursos)
+	return cursos, err
+}
+
+func (c *Cliente) BuscarLixeiraCursos(ctx context.Context, f Filtro) ([]modelos.Cursos, error) {
+	var cursos []modelos.Cursos
+	err := c.listar(ctx, "cursos", f, true, &cursos)
+	return cursos, err
+}
+
+// BuscarCurso devolve o curso ativo; com em diferente de zero, o estado dele naquele instante.
+func (c *Cliente) BuscarCurso(ctx context.Context, id primitive.ObjectID, em time.Time) (modelos.Cursos, error) {
+	var curso modelos.Cursos
+	err := c.buscar(ctx, "cursos", id, em, &curso)
+	return curso, err
+}
+
+func (c *Cliente) InserirCursos(ctx context.Context, cursos []modelos.Cursos) ([]primitive.ObjectID, error) {
+	return c.inserir(ctx, "cursos", cursos)
+}
+
+func (c *Cliente) AtualizarCurso(ctx context.Context, id primitive.ObjectID, curso modelos.Cursos) (modelos.Cursos, error) {
+	var atualizado modelos.Cursos
+	err := c.atualizar(ctx, "cursos", id, curso, &atualizado)
+	return atualizado, err
+}
+
+// DeletarCurso aplica a política de integridade aos alunos e disciplinas do curso; SimularExclusaoCurso
+// mostra antes quais dependentes seriam afetados.
+func (c *Cliente) DeletarCurso(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.deletar(ctx, "cursos", id)
+}
+
+func (c *Cliente) SimularExclusaoCurso(ctx context.Context, id primitive.ObjectID) (modelos.RelatorioExclusao, error) {
+	return c.simularExclusao(ctx, "cursos", id)
+}
+
+func (c *Cliente) RestaurarCurso(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.restaurar(ctx, "cursos", id)
+}
+
+//disciplinas
+
+func (c *Cliente) BuscarDisciplinas(ctx context.Context, f Filtro) ([]modelos.Disciplinas, error) {
+	var disciplinas []modelos.Disciplinas
+	err := c.listar(ctx, "disciplinas", f, false, &disciplinas)
+	return disciplinas, err
+}
+
+func (c *Cliente) BuscarLixeiraDisciplinas(ctx context.Context, f Filtro) ([]modelos.Disciplinas, error) {
+	var disciplinas []modelos.Disciplinas
+	err := c.listar(ctx, "disciplinas", f, true, &disciplinas)
+	return disciplinas, err
+}
+
+// BuscarDisciplina devolve a disciplina ativa; com em diferente de zero, o estado dela naquele instante.
+func (c *Cliente) BuscarDisciplina(ctx context.Context, id primitive.ObjectID, em time.Time) (modelos.Disciplinas, error) {
+	var disciplina modelos.Disciplinas
+	err := c.buscar(ctx, "disciplinas", id, em, &disciplina)
+	return disciplina, err
+}
+
+func (c *Cliente) InserirDisciplinas(ctx context.Context, disciplinas []modelos.Disciplinas) ([]primitive.ObjectID, error) {
+	return c.inserir(ctx, "disciplinas", disciplinas)
+}
+
+func (c *Cliente) AtualizarDisciplina(ctx context.Context, id primitive.ObjectID, disciplina modelos.Disciplinas) (modelos.Disciplinas, error) {
+	var atualizada modelos.Disciplinas
+	err := c.atualizar(ctx, "disciplinas", id, disciplina, &atualizada)
+	return atualizada, err
+}
+
+// DeletarDisciplina aplica a política de integridade aos professores da disciplina.
+func (c *Cliente) DeletarDisciplina(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.deletar(ctx, "disciplinas", id)
+}
+
+func (c *Cliente) SimularExclusaoDisciplina(ctx context.Context, id primitive.ObjectID) (modelos.RelatorioExclusao, error) {
+	return c.simularExclusao(ctx, "disciplinas", id)
+}
+
+func (c *Cliente) RestaurarDisciplina(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return c.restaurar(ctx, "disciplinas", id)
+}
@@ -0,0 +1,223 @@
+// Package client chama a API com métodos tipados, para os serviços em Go que hoje montam as requisições à
+// mão. Os tipos dos documentos são os do pacote modelos, que não depende do servidor, e os erros chegam
+// como *modelos.Problema, com o Codigo estável de cada falha. As rotas usadas aqui são conferidas com as
+// registradas no servidor pelo teste em main_test.go.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cliente guarda o endereço da API e as credenciais. Campos zerados recebem os valores padrão: três
+// tentativas, espera inicial de 200ms e http.DefaultClient. Token é preenchido por Entrar; ChaveAPI é usada
+// quando não há token.
+type Cliente struct {
+	URL        string //por exemplo https://api.smartschool.local
+	Token      string
+	ChaveAPI   string
+	Idioma     string //Accept-Language das mensagens de erro
+	Tentativas int    //total de tentativas por chamada, inclusive a primeira
+	Espera     time.Duration
+	HTTP       *http.Client
+
+	mu sync.RWMutex //protege Token, trocado por Entrar e Renovar enquanto outras chamadas acontecem
+}
+
+// Filtro seleciona os documentos de uma listagem. Campos são comparados por igualdade, como na query
+// string da API; Limite zero devolve todos os documentos.
+type Filtro struct {
+	Campos map[string]string
+	Limite int
+	Pagina int //a partir de 1
+}
+
+func (f Filtro) consulta() url.Values {
+	q := url.Values{}
+	for campo, valor := range f.Campos {
+		q.Set(campo, valor)
+	}
+	if f.Limite > 0 {
+		q.Set("limite", strconv.Itoa(f.Limite))
+		if f.Pagina > 0 {
+			q.Set("pagina", strconv.Itoa(f.Pagina))
+		}
+	}
+	return q
+}
+
+// Entrar faz login com usuário e senha e passa a usar o access token nas chamadas seguintes.
+func (c *Cliente) Entrar(ctx context.Context, usuario, senha string) (modelos.Tokens, error) {
+	var tokens modelos.Tokens
+	err := c.chamar(ctx, http.MethodPost, "/auth/login", nil, modelos.RequisicaoLogin{Usuario: usuario, Senha: senha}, &tokens)
+	if err == nil {
+		c.definirToken(tokens.AccessToken)
+	}
+	return tokens, err
+}
+
+// Renovar troca o refresh token por um novo par de tokens e passa a usar o novo access token.
+func (c *Cliente) Renovar(ctx context.Context, refreshToken string) (modelos.Tokens, error) {
+	var tokens modelos.Tokens
+	err := c.chamar(ctx, http.MethodPost, "/auth/refresh", nil, modelos.RequisicaoRenovacao{RefreshToken: refreshToken}, &tokens)
+	if err == nil {
+		c.definirToken(tokens.AccessToken)
+	}
+	return tokens, err
+}
+
+// Sair revoga o access token atual e, se informado, o refresh token.
+func (c *Cliente) Sair(ctx context.Context, refreshToken string) error {
+	err := c.chamar(ctx, http.MethodPost, "/auth/logout", nil, modelos.RequisicaoRenovacao{RefreshToken: refreshToken}, nil)
+	if err == nil {
+		c.definirToken("")
+	}
+	return err
+}
+
+func (c *Cliente) definirToken(token string) {
+	c.mu.Lock()
+	c.Token = token
+	c.mu.Unlock()
+}
+
+// chamar envia a requisição com corpo JSON e decodifica a resposta em resposta, quando não é nil. Erros de
+// rede e respostas 429, 502, 503 e 504 são repetidos com espera exponencial, respeitando o Retry-After. Um
+// POST só é repetido quando o servidor certamente não o processou (429 e 503), para não duplicar inclusões.
+func (c *Cliente) chamar(ctx context.Context, metodo, caminho string, consulta url.Values, corpo, resposta interface{}) error {
+	var conteudo []byte
+	if corpo != nil {
+		var err error
+		if conteudo, err = json.Marshal(corpo); err != nil {
+			return err
+		}
+	}
+	endereco := strings.TrimRight(c.URL, "/") + caminho
+	if len(consulta) > 0 {
+		endereco += "?" + consulta.Encode()
+	}
+	tentativas, espera := c.Tentativas, c.Espera
+	if tentativas <= 0 {
+		tentativas = 3
+	}
+	if espera <= 0 {
+		espera = 200 * time.Millisecond
+	}
+	for tentativa := 1; ; tentativa++ {
+		res, err := c.enviar(ctx, metodo, endereco, conteudo)
+		if err == nil && res.StatusCode < http.StatusBadRequest {
+			defer res.Body.Close()
+			if resposta == nil || res.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			return json.NewDecoder(res.Body).Decode(resposta)
+		}
+		repetir := ctx.Err() == nil && tentativa < tentativas
+		if err != nil {
+			repetir = repetir && metodo != http.MethodPost
+		} else {
+			repetir = repetir && repetivel(metodo, res.StatusCode)
+			if repetir {
+				espera = esperaPedida(res, espera)
+				res.Body.Close()
+			} else {
+				defer res.Body.Close()
+				return decodificarProblema(res)
+			}
+		}
+		if !repetir {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(espera):
+		}
+		espera *= 2
+	}
+}
+
+func (c *Cliente) enviar(ctx context.Context, metodo, endereco string, conteudo []byte) (*http.Response, error) {
+	var corpo io.Reader
+	if conteudo != nil {
+		corpo = bytes.NewReader(conteudo)
+	}
+	req, err := http.NewRequestWithContext(ctx, metodo, endereco, corpo)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if conteudo != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Idioma != "" {
+		req.Header.Set("Accept-Language", c.Idioma)
+	}
+	c.mu.RLock()
+	token := c.Token
+	c.mu.RUnlock()
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if c.ChaveAPI != "" {
+		req.Header.Set("X-API-Key", c.ChaveAPI)
+	}
+	cliente := c.HTTP
+	if cliente == nil {
+		cliente = http.DefaultClient
+	}
+	return cliente.Do(req)
+}
+
+func repetivel(metodo string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return metodo != http.MethodPost
+	}
+	return false
+}
+
+// esperaPedida usa o Retry-After da resposta (em segundos) quando ele é maior que a espera calculada.
+func esperaPedida(res *http.Response, espera time.Duration) time.Duration {
+	segundos, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	if err == nil && time.Duration(segundos)*time.Second > espera {
+		return time.Duration(segundos) * time.Second
+	}
+	return espera
+}
+
+// decodificarProblema lê o corpo problem+json de uma resposta de erro. Respostas sem esse corpo, por exemplo
+// de um proxy, viram um problema com o status e o texto padrão.
+func decodificarProblema(res *http.Response) error {
+	conteudo, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1<<20))
+	p := &modelos.Problema{}
+	if err := json.Unmarshal(conteudo, p); err != nil || p.Status == 0 {
+		p = &modelos.Problema{Status: res.StatusCode, Titulo: http.StatusText(res.StatusCode),
+			Detalhe: strings.TrimSpace(string(conteudo))}
+	}
+	return p
+}
+
+// caminhoItem monta /recurso/id, seguido das partes de sufixo.
+func caminhoItem(recurso string, id primitive.ObjectID, sufixo ...string) string {
+	return strings.Join(append([]string{"", recurso, id.Hex()}, sufixo...), "/")
+}
+
+// Erro devolve o problema de um erro do cliente, ou nil quando o erro não veio da API (rede, contexto).
+func Erro(err error) *modelos.Problema {
+	p, _ := err.(*modelos.Problema)
+	return p
+}
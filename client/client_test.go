@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// servidorRoteirizado responde cada requisição com o próximo passo do roteiro, repetindo o último, e conta
+// as requisições recebidas.
+func servidorRoteirizado(t *testing.T, roteiro ...func(w http.ResponseWriter)) (*Cliente, *int) {
+	t.Helper()
+	recebidas := 0
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passo := roteiro[len(roteiro)-1]
+		if recebidas < len(roteiro) {
+			passo = roteiro[recebidas]
+		}
+		recebidas++
+		passo(w)
+	}))
+	t.Cleanup(servidor.Close)
+	return &Cliente{URL: servidor.URL, Espera: time.Millisecond}, &recebidas
+}
+
+func status(codigo int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) { w.WriteHeader(codigo) }
+}
+
+func corpo(codigo int, tipo, conteudo string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", tipo)
+		w.WriteHeader(codigo)
+		w.Write([]byte(conteudo))
+	}
+}
+
+func TestRepeticao(t *testing.T) {
+	ctx := context.Background()
+	ok := corpo(http.StatusOK, "application/json", `[{"nome":"Ana"}]`)
+
+	c, recebidas := servidorRoteirizado(t, status(http.StatusServiceUnavailable), status(http.StatusBadGateway), ok)
+	if alunos, err := c.BuscarAlunos(ctx, Filtro{}); err != nil || len(alunos) != 1 || *recebidas != 3 {
+		t.Errorf("GET after 503 and 502: got %v, %v in %d requests; want the list in 3", alunos, err, *recebidas)
+	}
+
+	c, recebidas = servidorRoteirizado(t, status(http.StatusServiceUnavailable))
+	if _, err := c.BuscarAlunos(ctx, Filtro{}); Erro(err) == nil || Erro(err).Status != http.StatusServiceUnavailable || *recebidas != 3 {
+		t.Errorf("GET always 503: got %v in %d requests; want the problem after 3 attempts", err, *recebidas)
+	}
+
+	c, recebidas = servidorRoteirizado(t, status(http.StatusBadGateway), ok)
+	if _, err := c.InserirAlunos(ctx, nil); Erro(err) == nil || *recebidas != 1 {
+		t.Errorf("POST after 502: got %v in %d requests; the server may have processed it, so it must not be repeated", err, *recebidas)
+	}
+
+	c, recebidas = servidorRoteirizado(t, status(http.StatusTooManyRequests), corpo(http.StatusCreated, "application/json", `["5f1b2c3d4e5f6a7b8c9d0e1f"]`))
+	if ids, err := c.InserirAlunos(ctx, nil); err != nil || len(ids) != 1 || *recebidas != 2 {
+		t.Errorf("POST after 429: got %v, %v in %d requests; want the ids in 2", ids, err, *recebidas)
+	}
+
+	c, recebidas = servidorRoteirizado(t, status(http.StatusNotFound), ok)
+	if _, err := c.BuscarAluno(ctx, primitive.NewObjectID(), time.Time{}); Erro(err) == nil || *recebidas != 1 {
+		t.Errorf("404 should not be repeated: got %v in %d requests", err, *recebidas)
+	}
+
+	c, recebidas = servidorRoteirizado(t, status(http.StatusServiceUnavailable))
+	c.Tentativas = 1
+	if _, err := c.BuscarAlunos(ctx, Filtro{}); err == nil || *recebidas != 1 {
+		t.Errorf("with one attempt: got %v in %d requests", err, *recebidas)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	casos := map[string]time.Duration{
+		"2":                             2 * time.Second,
+		"0":                             time.Second, //menor que a espera calculada
+		"":                              time.Second,
+		"abc":                           time.Second,
+		"Wed, 21 Oct 2015 07:28:00 GMT": time.Second, //datas não são usadas
+	}
+	for cabecalho, esperada := range casos {
+		res := &http.Response{Header: http.Header{"Retry-After": {cabecalho}}}
+		if obtida := esperaPedida(res, time.Second); obtida != esperada {
+			t.Errorf("Retry-After %q: waited %v, want %v", cabecalho, obtida, esperada)
+		}
+	}
+
+	c, recebidas := servidorRoteirizado(t, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, status(http.StatusNoContent))
+	inicio := time.Now()
+	if err := c.RestaurarVersao(context.Background(), "alunos", primitive.NewObjectID(), 1); err != nil || *recebidas != 2 {
+		t.Fatalf("got %v in %d requests", err, *recebidas)
+	}
+	if decorrido := time.Since(inicio); decorrido < time.Second {
+		t.Errorf("the retry came %v after a Retry-After of 1 second", decorrido)
+	}
+
+	//o contexto cancelado interrompe a espera
+	c, _ = servidorRoteirizado(t, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	ctx, cancelar := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelar()
+	if _, err := c.BuscarAlunos(ctx, Filtro{}); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want the context error", err)
+	}
+}
+
+func TestProblema(t *testing.T) {
+	ctx := context.Background()
+	c, _ := servidorRoteirizado(t, corpo(http.StatusConflict, "application/problem+json", `{"type":"about:blank",
+		"title":"Conflito","status":409,"code":"has_dependents","detail":"O curso tem dependentes",
+		"instance":"/cursos/1","requestId":"abc","dependentes":[{"recurso":"alunos","politica":"restrict"}],
+		"errors":[{"campo":"codigo","regra":"curso-existente","mensagem":"não existe"}]}`))
+	_, err := c.DeletarCurso(ctx, primitive.NewObjectID())
+	p := Erro(err)
+	if p == nil {
+		t.Fatalf("got %v, want a problem", err)
+	}
+	if p.Status != http.StatusConflict || p.Codigo != "has_dependents" || p.Titulo != "Conflito" ||
+		p.Detalhe != "O curso tem dependentes" || p.Instancia != "/cursos/1" || p.RequestID != "abc" {
+		t.Errorf("problem members were not decoded: %+v", p)
+	}
+	if len(p.Erros) != 1 || p.Erros[0].Campo != "codigo" || p.Erros[0].Regra != "curso-existente" {
+		t.Errorf("errors = %+v", p.Erros)
+	}
+	if dependentes, ok := p.Extensoes["dependentes"].([]interface{}); !ok || len(dependentes) != 1 {
+		t.Errorf("unknown members should become extensions: %+v", p.Extensoes)
+	}
+
+	//respostas sem problem+json, como as de um proxy, mantêm o status e o texto
+	c, _ = servidorRoteirizado(t, corpo(http.StatusRequestEntityTooLarge, "text/plain", "too large\n"))
+	_, err = c.InserirCursos(ctx, nil)
+	if p := Erro(err); p == nil || p.Status != http.StatusRequestEntityTooLarge || p.Titulo != "Request Entity Too Large" ||
+		p.Detalhe != "too large" || p.Codigo != "" {
+		t.Errorf("got %+v", p)
+	}
+
+	if Erro(context.Canceled) != nil {
+		t.Error("errors that did not come from the API should not be problems")
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
+import "time"
+
 type PropriedadesDB struct {
 	Port                  string `env:"MY_APP_PORT" env-default:"5001"`
 	Host                  string `env:"HOST" env-default:"localhost"`
+	GRPCPort              string `env:"GRPC_PORT"` //serviço gRPC com o mesmo cadastro, desativado até que a porta seja definida
 	DBHost                string `env:"DB_HOST" env-default:"localhost"`
 	DBPort                string `env:"DB_PORT" env-default:"27017"`
 	DBName                string `env:"DB_NAME" env-default:"desafio"`
@@ -10,4 +13,91 @@ type PropriedadesDB struct {
 	ProfessoresCollection string `env:"COLLECTION_NAME" env-default:"professores"` //nome da coleção
 	CursosCollection      string `env:"COLLECTION_NAME" env-default:"cursos"`
 	DisciplinasCollection string `env:"COLLECTION_NAME" env-default:"disciplinas"`
+	SequenciasCollection  string `env:"SEQUENCIAS_COLLECTION" env-default:"sequencias"` //contadores atômicos
+	AuditoriaCollection   string `env:"AUDITORIA_COLLECTION" env-default:"auditoria"`   //somente inserção
+	VersoesCollection     string `env:"VERSOES_COLLECTION" env-default:"versoes"`       //documento completo após cada escrita
+	RevogadosCollection   string `env:"REVOGADOS_COLLECTION" env-default:"tokens_revogados"`
+	UsuariosCollection    string `env:"USUARIOS_COLLECTION" env-default:"usuarios"`
+	ChavesAPICollection   string `env:"CHAVES_API_COLLECTION" env-default:"chaves_api"`
+	ImportacoesCollection string `env:"IMPORTACOES_COLLECTION" env-default:"importacoes"` //relatórios das importações de planilhas
+	TravasCollection      string `env:"TRAVAS_COLLECTION" env-default:"travas"`           //escritas conferidas por regras entre documentos
+	/*padrões de geração automática: {ano}, {ano:2}, {curso:N}, {seq:N} e {dv} (dígito verificador módulo 11),
+	sendo N a quantidade de dígitos preenchidos com zeros à esquerda*/
+	PadraoMatricula string `env:"PADRAO_MATRICULA" env-default:"{ano}{curso:3}{seq:4}{dv}"`
+	PadraoRegistro  string `env:"PADRAO_REGISTRO" env-default:"{ano}{seq:5}{dv}"`
+	//importações com até esse número de linhas respondem com o relatório; as maiores seguem em segundo plano
+	ImportacaoLimiteSincrono int `env:"IMPORTACAO_LIMITE_SINCRONO" env-default:"500"`
+	//documentos na lixeira há mais tempo que a retenção são removidos definitivamente pela purga periódica
+	RetencaoLixeira time.Duration `env:"LIXEIRA_RETENCAO" env-default:"2160h"`
+	IntervaloPurga  time.Duration `env:"LIXEIRA_INTERVALO_PURGA" env-default:"24h"`
+	//políticas de exclusão por relação: restrict (409 listando os dependentes), cascade ou nullify
+	PoliticaCursoAlunos           string `env:"POLITICA_CURSO_ALUNOS" env-default:"restrict"`
+	PoliticaCursoDisciplinas      string `env:"POLITICA_CURSO_DISCIPLINAS" env-default:"restrict"`
+	PoliticaDisciplinaProfessores string `env:"POLITICA_DISCIPLINA_PROFESSORES" env-default:"nullify"`
+	//idioma das mensagens de erro quando o Accept-Language não pede "en" nem "pt-BR"
+	IdiomaPadrao string `env:"IDIOMA_PADRAO" env-default:"en"`
+	//regras de negócio conferidas nas escritas (JSON com a lista de handlers.Regra); sem o arquivo, nenhuma
+	RegrasArquivo string `env:"REGRAS_ARQUIVO" env-default:"regras.json"`
+	/*chaves de assinatura dos tokens no formato "kid1:segredo1,kid2:segredo2"; os tokens são assinados com
+	JWT_CHAVE_ATIVA e as demais chaves continuam aceitas, permitindo a rotação*/
+	JWTChaves           map[string]string `env:"JWT_CHAVES"`
+	JWTChaveAtiva       string            `env:"JWT_CHAVE_ATIVA"`
+	JWTEmissor          string            `env:"JWT_EMISSOR" env-default:"smartschool"`
+	JWTDuracaoAcesso    time.Duration     `env:"JWT_DURACAO_ACESSO" env-default:"15m"`
+	JWTDuracaoRenovacao time.Duration     `env:"JWT_DURACAO_RENOVACAO" env-default:"168h"`
+	AdminUsuario        string            `env:"ADMIN_USUARIO" env-default:"admin"` //acesso inicial, desativado sem ADMIN_SENHA
+	AdminSenha          string            `env:"ADMIN_SENHA"`
+	//DDD dado na migração aos telefones antigos gravados sem DDD (8 ou 9 dígitos); vazio os deixa pendentes
+	TelefoneDDDPadrao string `env:"TELEFONE_DDD_PADRAO"`
+	//contas de usuário
+	SenhaTamanhoMinimo int           `env:"SENHA_TAMANHO_MINIMO" env-default:"10"`
+	LoginMaxTentativas int           `env:"LOGIN_MAX_TENTATIVAS" env-default:"5"`
+	LoginBloqueio      time.Duration `env:"LOGIN_BLOQUEIO" env-default:"15m"`
+	ResetValidade      time.Duration `env:"RESET_VALIDADE" env-default:"1h"`
+	ResetURL           string        `env:"RESET_URL" env-default:"http://localhost:5001/redefinir-senha"`
+	//envio de e-mail: "arquivo" grava .eml em MAILER_DIRETORIO, "smtp" envia por SMTP_ENDERECO
+	Mailer               string `env:"MAILER" env-default:"arquivo"`
+	MailerDiretorio      string `env:"MAILER_DIRETORIO" env-default:"emails"`
+	MailerRemetente      string `env:"MAILER_REMETENTE" env-default:"smartschool@localhost"`
+	SMTPEndereco         string `env:"SMTP_ENDERECO" env-default:"localhost:1025"`
+	SMTPUsuario          string `env:"SMTP_USUARIO"`
+	SMTPSenha            string `env:"SMTP_SENHA"`
+	ChaveAPILimitePadrao int    `env:"CHAVE_API_LIMITE_PADRAO" env-default:"600"` //requisições por minuto por chave
+	//limites de requisições (balde de fichas) por grupo de rotas, em requisições por LIMITE_PERIODO;
+	//"padrao" vale para as rotas fora dos grupos listados
+	LimiteIP      map[string]int `env:"LIMITE_IP" env-default:"padrao:600,/auth:20,/usuarios/senha:5"`
+	LimiteUsuario map[string]int `env:"LIMITE_USUARIO" env-default:"padrao:1200,/lgpd:10"`
+	LimitePeriodo time.Duration  `env:"LIMITE_PERIODO" env-default:"1m"`
+	//"memoria" limita cada instância sozinha; "redis" compartilha os baldes em REDIS_ENDERECO
+	LimiteStore   string `env:"LIMITE_STORE" env-default:"memoria"`
+	RedisEndereco string `env:"REDIS_ENDERECO" env-default:"localhost:6379"`
+	RedisSenha    string `env:"REDIS_SENHA"`
+	RedisDB       int    `env:"REDIS_DB"`
+	RedisPool     int    `env:"REDIS_POOL" env-default:"10"` //conexões por instância
+	//faixas (CIDR) dos proxies reversos cujo X-Forwarded-For é aceito; vazio usa o endereço da conexão
+	ProxiesConfiaveis []string `env:"PROXIES_CONFIAVEIS"`
+	//CORS: origens, métodos e cabeçalhos aceitos; credenciais exigem origens explícitas
+	CORSOrigens     []string `env:"CORS_ORIGENS" env-default:"*"`
+	CORSMetodos     []string `env:"CORS_METODOS" env-default:"GET,HEAD,PUT,PATCH,POST,DELETE"`
+	CORSCabecalhos  []string `env:"CORS_CABECALHOS" env-default:"Authorization,Content-Type,X-API-Key,X-Request-ID"`
+	CORSCredenciais bool     `env:"CORS_CREDENCIAIS" env-default:"false"`
+	CORSMaxAge      int      `env:"CORS_MAX_AGE" env-default:"600"` //segundos de cache do preflight
+	//cabeçalhos de segurança; HSTS só é enviado em conexões HTTPS
+	HSTSMaxAge int    `env:"HSTS_MAX_AGE" env-default:"31536000"`
+	CSP        string `env:"CSP" env-default:"default-src 'none'; frame-ancestors 'none'"`
+	//swagger-ui-dist instalado pelo npm; sem ele, /docs carrega o Swagger UI do CDN
+	SwaggerUIDir string `env:"SWAGGER_UI_DIR" env-default:"node_modules/swagger-ui-dist"`
+	//HTTPS direto: com TLS_CERTIFICADO e TLS_CHAVE a API serve TLS e relê os arquivos quando mudam
+	TLSCertificado string        `env:"TLS_CERTIFICADO"`
+	TLSChave       string        `env:"TLS_CHAVE"`
+	TLSRecarga     time.Duration `env:"TLS_RECARGA" env-default:"1m"`
+	//login pelo provedor de identidade (OpenID Connect), desativado sem OIDC_EMISSOR
+	OIDCEmissor      string            `env:"OIDC_EMISSOR"`
+	OIDCClientID     string            `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string            `env:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL  string            `env:"OIDC_REDIRECT_URL" env-default:"http://localhost:5001/auth/oidc/callback"`
+	OIDCEscopos      []string          `env:"OIDC_ESCOPOS" env-default:"openid,profile,email"`
+	OIDCAudiencia    string            `env:"OIDC_AUDIENCIA"`
+	OIDCClaimPapeis  string            `env:"OIDC_CLAIM_PAPEIS" env-default:"roles"`
+	OIDCPapeis       map[string]string `env:"OIDC_PAPEIS"` //valor no provedor:papel da API, ex. "staff:secretaria,teachers:professor"
 }
@@ -0,0 +1,475 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: smartschool/v1/alunos.proto
+
+package escolapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Aluno struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Gerada na inserção quando não informada.
+	Matricula int64  `protobuf:"varint,2,opt,name=matricula,proto3" json:"matricula,omitempty"`
+	Nome      string `protobuf:"bytes,3,opt,name=nome,proto3" json:"nome,omitempty"`
+	Sobrenome string `protobuf:"bytes,4,opt,name=sobrenome,proto3" json:"sobrenome,omitempty"`
+	Telefone  string `protobuf:"bytes,5,opt,name=telefone,proto3" json:"telefone,omitempty"`
+	Email     string `protobuf:"bytes,6,opt,name=email,proto3" json:"email,omitempty"`
+	Cpf       string `protobuf:"bytes,7,opt,name=cpf,proto3" json:"cpf,omitempty"`
+	Rg        string `protobuf:"bytes,8,opt,name=rg,proto3" json:"rg,omitempty"`
+	Cep       string `protobuf:"bytes,9,opt,name=cep,proto3" json:"cep,omitempty"`
+	// Código do curso principal.
+	Curso int64 `protobuf:"varint,10,opt,name=curso,proto3" json:"curso,omitempty"`
+	// Outros cursos em andamento, além do principal.
+	Cursos []int64 `protobuf:"varint,11,rep,packed,name=cursos,proto3" json:"cursos,omitempty"`
+	// Preenchido quando o aluno está na lixeira.
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+}
+
+func (x *Aluno) Reset() {
+	*x = Aluno{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_alunos_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Aluno) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Aluno) ProtoMessage() {}
+
+func (x *Aluno) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_alunos_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Aluno.ProtoReflect.Descriptor instead.
+func (*Aluno) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_alunos_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Aluno) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Aluno) GetMatricula() int64 {
+	if x != nil {
+		return x.Matricula
+	}
+	return 0
+}
+
+func (x *Aluno) GetNome() string {
+	if x != nil {
+		return x.Nome
+	}
+	return ""
+}
+
+func (x *Aluno) GetSobrenome() string {
+	if x != nil {
+		return x.Sobrenome
+	}
+	return ""
+}
+
+func (x *Aluno) GetTelefone() string {
+	if x != nil {
+		return x.Telefone
+	}
+	return ""
+}
+
+func (x *Aluno) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Aluno) GetCpf() string {
+	if x != nil {
+		return x.Cpf
+	}
+	return ""
+}
+
+func (x *Aluno) GetRg() string {
+	if x != nil {
+		return x.Rg
+	}
+	return ""
+}
+
+func (x *Aluno) GetCep() string {
+	if x != nil {
+		return x.Cep
+	}
+	return ""
+}
+
+func (x *Aluno) GetCurso() int64 {
+	if x != nil {
+		return x.Curso
+	}
+	return 0
+}
+
+func (x *Aluno) GetCursos() []int64 {
+	if x != nil {
+		return x.Cursos
+	}
+	return nil
+}
+
+func (x *Aluno) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+type ListaAlunos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Alunos []*Aluno `protobuf:"bytes,1,rep,name=alunos,proto3" json:"alunos,omitempty"`
+}
+
+func (x *ListaAlunos) Reset() {
+	*x = ListaAlunos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_alunos_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListaAlunos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListaAlunos) ProtoMessage() {}
+
+func (x *ListaAlunos) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_alunos_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListaAlunos.ProtoReflect.Descriptor instead.
+func (*ListaAlunos) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_alunos_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListaAlunos) GetAlunos() []*Aluno {
+	if x != nil {
+		return x.Alunos
+	}
+	return nil
+}
+
+// AtualizacaoAluno altera os campos listados em campos; sem a máscara, apenas os campos preenchidos.
+type AtualizacaoAluno struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Aluno  *Aluno                 `protobuf:"bytes,2,opt,name=aluno,proto3" json:"aluno,omitempty"`
+	Campos *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=campos,proto3" json:"campos,omitempty"`
+}
+
+func (x *AtualizacaoAluno) Reset() {
+	*x = AtualizacaoAluno{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_alunos_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AtualizacaoAluno) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AtualizacaoAluno) ProtoMessage() {}
+
+func (x *AtualizacaoAluno) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_alunos_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AtualizacaoAluno.ProtoReflect.Descriptor instead.
+func (*AtualizacaoAluno) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_alunos_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AtualizacaoAluno) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AtualizacaoAluno) GetAluno() *Aluno {
+	if x != nil {
+		return x.Aluno
+	}
+	return nil
+}
+
+func (x *AtualizacaoAluno) GetCampos() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Campos
+	}
+	return nil
+}
+
+var File_smartschool_v1_alunos_proto protoreflect.FileDescriptor
+
+var file_smartschool_v1_alunos_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x61, 0x6c, 0x75, 0x6e, 0x6f, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73,
+	0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x1a, 0x20, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1a, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x6f, 0x6d, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb6, 0x02, 0x0a,
+	0x05, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x61, 0x74, 0x72, 0x69, 0x63,
+	0x75, 0x6c, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6d, 0x61, 0x74, 0x72, 0x69,
+	0x63, 0x75, 0x6c, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x6f, 0x62, 0x72,
+	0x65, 0x6e, 0x6f, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x6f, 0x62,
+	0x72, 0x65, 0x6e, 0x6f, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6c, 0x65, 0x66, 0x6f,
+	0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6c, 0x65, 0x66, 0x6f,
+	0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x70, 0x66, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x70, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x72, 0x67,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x72, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x65,
+	0x70, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x65, 0x70, 0x12, 0x14, 0x0a, 0x05,
+	0x63, 0x75, 0x72, 0x73, 0x6f, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x18, 0x0b, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x3c, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x41, 0x6c,
+	0x75, 0x6e, 0x6f, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x61, 0x6c, 0x75, 0x6e, 0x6f, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f,
+	0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x52, 0x06, 0x61, 0x6c, 0x75,
+	0x6e, 0x6f, 0x73, 0x22, 0x83, 0x01, 0x0a, 0x10, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61,
+	0x63, 0x61, 0x6f, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2b, 0x0a, 0x05, 0x61, 0x6c, 0x75, 0x6e,
+	0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73,
+	0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x52, 0x05,
+	0x61, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x32, 0x0a, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73,
+	0x6b, 0x52, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x32, 0xc8, 0x04, 0x0a, 0x06, 0x41, 0x6c,
+	0x75, 0x6e, 0x6f, 0x73, 0x12, 0x4c, 0x0a, 0x0c, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x69, 0x72, 0x41,
+	0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x1b, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f,
+	0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x41, 0x6c, 0x75, 0x6e, 0x6f,
+	0x73, 0x1a, 0x1f, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x76, 0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72,
+	0x65, 0x73, 0x12, 0x43, 0x0a, 0x0c, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e,
+	0x6f, 0x73, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x1a, 0x1b, 0x2e, 0x73, 0x6d, 0x61,
+	0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x61, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x73, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72,
+	0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72,
+	0x6f, 0x1a, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0d, 0x42, 0x75,
+	0x73, 0x63, 0x61, 0x72, 0x4c, 0x69, 0x78, 0x65, 0x69, 0x72, 0x61, 0x12, 0x16, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c,
+	0x74, 0x72, 0x6f, 0x1a, 0x1b, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x73,
+	0x12, 0x43, 0x0a, 0x0b, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12,
+	0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x15,
+	0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x49, 0x0a, 0x0e, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x20, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73,
+	0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a,
+	0x61, 0x63, 0x61, 0x6f, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x1a, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72,
+	0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x75, 0x6e, 0x6f,
+	0x12, 0x47, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e, 0x6f,
+	0x12, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76,
+	0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a,
+	0x18, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x6d, 0x12, 0x49, 0x0a, 0x0e, 0x52, 0x65, 0x73,
+	0x74, 0x61, 0x75, 0x72, 0x61, 0x72, 0x41, 0x6c, 0x75, 0x6e, 0x6f, 0x12, 0x1d, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x18, 0x2e, 0x73, 0x6d, 0x61,
+	0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74,
+	0x61, 0x67, 0x65, 0x6d, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x6b, 0x72, 0x75, 0x6e, 0x61, 0x6c, 0x34, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2f,
+	0x74, 0x72, 0x6f, 0x6e, 0x69, 0x63, 0x73, 0x63, 0x6f, 0x72, 0x70, 0x2f, 0x65, 0x73, 0x63, 0x6f,
+	0x6c, 0x61, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_smartschool_v1_alunos_proto_rawDescOnce sync.Once
+	file_smartschool_v1_alunos_proto_rawDescData = file_smartschool_v1_alunos_proto_rawDesc
+)
+
+func file_smartschool_v1_alunos_proto_rawDescGZIP() []byte {
+	file_smartschool_v1_alunos_proto_rawDescOnce.Do(func() {
+		file_smartschool_v1_alunos_proto_rawDescData = protoimpl.X.CompressGZIP(file_smartschool_v1_alunos_proto_rawDescData)
+	})
+	return file_smartschool_v1_alunos_proto_rawDescData
+}
+
+var file_smartschool_v1_alunos_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_smartschool_v1_alunos_proto_goTypes = []interface{}{
+	(*Aluno)(nil),                 // 0: smartschool.v1.Aluno
+	(*ListaAlunos)(nil),           // 1: smartschool.v1.ListaAlunos
+	(*AtualizacaoAluno)(nil),      // 2: smartschool.v1.AtualizacaoAluno
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 4: google.protobuf.FieldMask
+	(*Filtro)(nil),                // 5: smartschool.v1.Filtro
+	(*Identificador)(nil),         // 6: smartschool.v1.Identificador
+	(*Identificadores)(nil),       // 7: smartschool.v1.Identificadores
+	(*Contagem)(nil),              // 8: smartschool.v1.Contagem
+}
+var file_smartschool_v1_alunos_proto_depIdxs = []int32{
+	3,  // 0: smartschool.v1.Aluno.deleted_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: smartschool.v1.ListaAlunos.alunos:type_name -> smartschool.v1.Aluno
+	0,  // 2: smartschool.v1.AtualizacaoAluno.aluno:type_name -> smartschool.v1.Aluno
+	4,  // 3: smartschool.v1.AtualizacaoAluno.campos:type_name -> google.protobuf.FieldMask
+	1,  // 4: smartschool.v1.Alunos.InserirAluno:input_type -> smartschool.v1.ListaAlunos
+	5,  // 5: smartschool.v1.Alunos.BuscarAlunos:input_type -> smartschool.v1.Filtro
+	5,  // 6: smartschool.v1.Alunos.ExportarAlunos:input_type -> smartschool.v1.Filtro
+	5,  // 7: smartschool.v1.Alunos.BuscarLixeira:input_type -> smartschool.v1.Filtro
+	6,  // 8: smartschool.v1.Alunos.BuscarAluno:input_type -> smartschool.v1.Identificador
+	2,  // 9: smartschool.v1.Alunos.AtualizarAluno:input_type -> smartschool.v1.AtualizacaoAluno
+	6,  // 10: smartschool.v1.Alunos.DeletarAluno:input_type -> smartschool.v1.Identificador
+	6,  // 11: smartschool.v1.Alunos.RestaurarAluno:input_type -> smartschool.v1.Identificador
+	7,  // 12: smartschool.v1.Alunos.InserirAluno:output_type -> smartschool.v1.Identificadores
+	1,  // 13: smartschool.v1.Alunos.BuscarAlunos:output_type -> smartschool.v1.ListaAlunos
+	0,  // 14: smartschool.v1.Alunos.ExportarAlunos:output_type -> smartschool.v1.Aluno
+	1,  // 15: smartschool.v1.Alunos.BuscarLixeira:output_type -> smartschool.v1.ListaAlunos
+	0,  // 16: smartschool.v1.Alunos.BuscarAluno:output_type -> smartschool.v1.Aluno
+	0,  // 17: smartschool.v1.Alunos.AtualizarAluno:output_type -> smartschool.v1.Aluno
+	8,  // 18: smartschool.v1.Alunos.DeletarAluno:output_type -> smartschool.v1.Contagem
+	8,  // 19: smartschool.v1.Alunos.RestaurarAluno:output_type -> smartschool.v1.Contagem
+	12, // [12:20] is the sub-list for method output_type
+	4,  // [4:12] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_smartschool_v1_alunos_proto_init() }
+func file_smartschool_v1_alunos_proto_init() {
+	if File_smartschool_v1_alunos_proto != nil {
+		return
+	}
+	file_smartschool_v1_comum_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_smartschool_v1_alunos_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Aluno); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_alunos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListaAlunos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_alunos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AtualizacaoAluno); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_smartschool_v1_alunos_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_smartschool_v1_alunos_proto_goTypes,
+		DependencyIndexes: file_smartschool_v1_alunos_proto_depIdxs,
+		MessageInfos:      file_smartschool_v1_alunos_proto_msgTypes,
+	}.Build()
+	File_smartschool_v1_alunos_proto = out.File
+	file_smartschool_v1_alunos_proto_rawDesc = nil
+	file_smartschool_v1_alunos_proto_goTypes = nil
+	file_smartschool_v1_alunos_proto_depIdxs = nil
+}
@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: smartschool/v1/alunos.proto
+
+package escolapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Alunos_InserirAluno_FullMethodName   = "/smartschool.v1.Alunos/InserirAluno"
+	Alunos_BuscarAlunos_FullMethodName   = "/smartschool.v1.Alunos/BuscarAlunos"
+	Alunos_ExportarAlunos_FullMethodName = "/smartschool.v1.Alunos/ExportarAlunos"
+	Alunos_BuscarLixeira_FullMethodName  = "/smartschool.v1.Alunos/BuscarLixeira"
+	Alunos_BuscarAluno_FullMethodName    = "/smartschool.v1.Alunos/BuscarAluno"
+	Alunos_AtualizarAluno_FullMethodName = "/smartschool.v1.Alunos/AtualizarAluno"
+	Alunos_DeletarAluno_FullMethodName   = "/smartschool.v1.Alunos/DeletarAluno"
+	Alunos_RestaurarAluno_FullMethodName = "/smartschool.v1.Alunos/RestaurarAluno"
+)
+
+// AlunosClient is the client API for Alunos service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AlunosClient interface {
+	InserirAluno(ctx context.Context, in *ListaAlunos, opts ...grpc.CallOption) (*Identificadores, error)
+	BuscarAlunos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaAlunos, error)
+	// ExportarAlunos envia os alunos um a um, sem carregar a listagem inteira na memória.
+	ExportarAlunos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Alunos_ExportarAlunosClient, error)
+	BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaAlunos, error)
+	BuscarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Aluno, error)
+	AtualizarAluno(ctx context.Context, in *AtualizacaoAluno, opts ...grpc.CallOption) (*Aluno, error)
+	// DeletarAluno move o aluno para a lixeira.
+	DeletarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+	RestaurarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+}
+
+type alunosClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAlunosClient(cc grpc.ClientConnInterface) AlunosClient {
+	return &alunosClient{cc}
+}
+
+func (c *alunosClient) InserirAluno(ctx context.Context, in *ListaAlunos, opts ...grpc.CallOption) (*Identificadores, error) {
+	out := new(Identificadores)
+	err := c.cc.Invoke(ctx, Alunos_InserirAluno_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) BuscarAlunos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaAlunos, error) {
+	out := new(ListaAlunos)
+	err := c.cc.Invoke(ctx, Alunos_BuscarAlunos_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) ExportarAlunos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Alunos_ExportarAlunosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Alunos_ServiceDesc.Streams[0], Alunos_ExportarAlunos_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &alunosExportarAlunosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Alunos_ExportarAlunosClient interface {
+	Recv() (*Aluno, error)
+	grpc.ClientStream
+}
+
+type alunosExportarAlunosClient struct {
+	grpc.ClientStream
+}
+
+func (x *alunosExportarAlunosClient) Recv() (*Aluno, error) {
+	m := new(Aluno)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *alunosClient) BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaAlunos, error) {
+	out := new(ListaAlunos)
+	err := c.cc.Invoke(ctx, Alunos_BuscarLixeira_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) BuscarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Aluno, error) {
+	out := new(Aluno)
+	err := c.cc.Invoke(ctx, Alunos_BuscarAluno_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) AtualizarAluno(ctx context.Context, in *AtualizacaoAluno, opts ...grpc.CallOption) (*Aluno, error) {
+	out := new(Aluno)
+	err := c.cc.Invoke(ctx, Alunos_AtualizarAluno_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) DeletarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Alunos_DeletarAluno_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alunosClient) RestaurarAluno(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Alunos_RestaurarAluno_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AlunosServer is the server API for Alunos service.
+// All implementations must embed UnimplementedAlunosServer
+// for forward compatibility
+type AlunosServer interface {
+	InserirAluno(context.Context, *ListaAlunos) (*Identificadores, error)
+	BuscarAlunos(context.Context, *Filtro) (*ListaAlunos, error)
+	// ExportarAlunos envia os alunos um a um, sem carregar a listagem inteira na memória.
+	ExportarAlunos(*Filtro, Alunos_ExportarAlunosServer) error
+	BuscarLixeira(context.Context, *Filtro) (*ListaAlunos, error)
+	BuscarAluno(context.Context, *Identificador) (*Aluno, error)
+	AtualizarAluno(context.Context, *AtualizacaoAluno) (*Aluno, error)
+	// DeletarAluno move o aluno para a lixeira.
+	DeletarAluno(context.Context, *Identificador) (*Contagem, error)
+	RestaurarAluno(context.Context, *Identificador) (*Contagem, error)
+	mustEmbedUnimplementedAlunosServer()
+}
+
+// UnimplementedAlunosServer must be embedded to have forward compatible implementations.
+type UnimplementedAlunosServer struct {
+}
+
+func (UnimplementedAlunosServer) InserirAluno(context.Context, *ListaAlunos) (*Identificadores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InserirAluno not implemented")
+}
+func (UnimplementedAlunosServer) BuscarAlunos(context.Context, *Filtro) (*ListaAlunos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarAlunos not implemented")
+}
+func (UnimplementedAlunosServer) ExportarAlunos(*Filtro, Alunos_ExportarAlunosServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportarAlunos not implemented")
+}
+func (UnimplementedAlunosServer) BuscarLixeira(context.Context, *Filtro) (*ListaAlunos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarLixeira not implemented")
+}
+func (UnimplementedAlunosServer) BuscarAluno(context.Context, *Identificador) (*Aluno, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarAluno not implemented")
+}
+func (UnimplementedAlunosServer) AtualizarAluno(context.Context, *AtualizacaoAluno) (*Aluno, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AtualizarAluno not implemented")
+}
+func (UnimplementedAlunosServer) DeletarAluno(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletarAluno not implemented")
+}
+func (UnimplementedAlunosServer) RestaurarAluno(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestaurarAluno not implemented")
+}
+func (UnimplementedAlunosServer) mustEmbedUnimplementedAlunosServer() {}
+
+// UnsafeAlunosServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AlunosServer will
+// result in compilation errors.
+type UnsafeAlunosServer interface {
+	mustEmbedUnimplementedAlunosServer()
+}
+
+func RegisterAlunosServer(s grpc.ServiceRegistrar, srv AlunosServer) {
+	s.RegisterService(&Alunos_ServiceDesc, srv)
+}
+
+func _Alunos_InserirAluno_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListaAlunos)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).InserirAluno(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_InserirAluno_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).InserirAluno(ctx, req.(*ListaAlunos))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_BuscarAlunos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).BuscarAlunos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_BuscarAlunos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).BuscarAlunos(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_ExportarAlunos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Filtro)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AlunosServer).ExportarAlunos(m, &alunosExportarAlunosServer{stream})
+}
+
+type Alunos_ExportarAlunosServer interface {
+	Send(*Aluno) error
+	grpc.ServerStream
+}
+
+type alunosExportarAlunosServer struct {
+	grpc.ServerStream
+}
+
+func (x *alunosExportarAlunosServer) Send(m *Aluno) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Alunos_BuscarLixeira_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).BuscarLixeira(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_BuscarLixeira_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).BuscarLixeira(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_BuscarAluno_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).BuscarAluno(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_BuscarAluno_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).BuscarAluno(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_AtualizarAluno_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AtualizacaoAluno)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).AtualizarAluno(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_AtualizarAluno_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).AtualizarAluno(ctx, req.(*AtualizacaoAluno))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_DeletarAluno_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).DeletarAluno(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_DeletarAluno_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).DeletarAluno(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Alunos_RestaurarAluno_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlunosServer).RestaurarAluno(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Alunos_RestaurarAluno_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlunosServer).RestaurarAluno(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Alunos_ServiceDesc is the grpc.ServiceDesc for Alunos service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Alunos_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartschool.v1.Alunos",
+	HandlerType: (*AlunosServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InserirAluno",
+			Handler:    _Alunos_InserirAluno_Handler,
+		},
+		{
+			MethodName: "BuscarAlunos",
+			Handler:    _Alunos_BuscarAlunos_Handler,
+		},
+		{
+			MethodName: "BuscarLixeira",
+			Handler:    _Alunos_BuscarLixeira_Handler,
+		},
+		{
+			MethodName: "BuscarAluno",
+			Handler:    _Alunos_BuscarAluno_Handler,
+		},
+		{
+			MethodName: "AtualizarAluno",
+			Handler:    _Alunos_AtualizarAluno_Handler,
+		},
+		{
+			MethodName: "DeletarAluno",
+			Handler:    _Alunos_DeletarAluno_Handler,
+		},
+		{
+			MethodName: "RestaurarAluno",
+			Handler:    _Alunos_RestaurarAluno_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportarAlunos",
+			Handler:       _Alunos_ExportarAlunos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smartschool/v1/alunos.proto",
+}
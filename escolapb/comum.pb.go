@@ -0,0 +1,364 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: smartschool/v1/comum.proto
+
+package escolapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Filtro seleciona os documentos das listagens, como os parâmetros de consulta das rotas GET do REST.
+type Filtro struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Igualdade campo a campo, com os nomes dos campos do JSON da API REST (por exemplo "nome" ou "curso").
+	Campos map[string]string `protobuf:"bytes,1,rep,name=campos,proto3" json:"campos,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Quantidade por página; 0 devolve todos os documentos.
+	Limite int64 `protobuf:"varint,2,opt,name=limite,proto3" json:"limite,omitempty"`
+	// Página a partir de 1; só vale com limite.
+	Pagina int64 `protobuf:"varint,3,opt,name=pagina,proto3" json:"pagina,omitempty"`
+}
+
+func (x *Filtro) Reset() {
+	*x = Filtro{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_comum_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Filtro) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filtro) ProtoMessage() {}
+
+func (x *Filtro) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_comum_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filtro.ProtoReflect.Descriptor instead.
+func (*Filtro) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_comum_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Filtro) GetCampos() map[string]string {
+	if x != nil {
+		return x.Campos
+	}
+	return nil
+}
+
+func (x *Filtro) GetLimite() int64 {
+	if x != nil {
+		return x.Limite
+	}
+	return 0
+}
+
+func (x *Filtro) GetPagina() int64 {
+	if x != nil {
+		return x.Pagina
+	}
+	return 0
+}
+
+type Identificador struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *Identificador) Reset() {
+	*x = Identificador{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_comum_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Identificador) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Identificador) ProtoMessage() {}
+
+func (x *Identificador) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_comum_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Identificador.ProtoReflect.Descriptor instead.
+func (*Identificador) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_comum_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Identificador) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Identificadores são os ids gerados na inserção, na ordem dos documentos enviados.
+type Identificadores struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+}
+
+func (x *Identificadores) Reset() {
+	*x = Identificadores{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_comum_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Identificadores) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Identificadores) ProtoMessage() {}
+
+func (x *Identificadores) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_comum_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Identificadores.ProtoReflect.Descriptor instead.
+func (*Identificadores) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_comum_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Identificadores) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// Contagem é a quantidade de documentos afetados por uma exclusão ou restauração.
+type Contagem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Total int64 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+}
+
+func (x *Contagem) Reset() {
+	*x = Contagem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_comum_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Contagem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Contagem) ProtoMessage() {}
+
+func (x *Contagem) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_comum_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Contagem.ProtoReflect.Descriptor instead.
+func (*Contagem) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_comum_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Contagem) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+var File_smartschool_v1_comum_proto protoreflect.FileDescriptor
+
+var file_smartschool_v1_comum_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x6f, 0x6d, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x22, 0xaf, 0x01, 0x0a,
+	0x06, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x12, 0x3a, 0x0a, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73,
+	0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x2e,
+	0x43, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x63, 0x61, 0x6d,
+	0x70, 0x6f, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x61, 0x67, 0x69, 0x6e, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x61, 0x67,
+	0x69, 0x6e, 0x61, 0x1a, 0x39, 0x0a, 0x0b, 0x43, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x1f,
+	0x0a, 0x0d, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22,
+	0x23, 0x0a, 0x0f, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72,
+	0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x03, 0x69, 0x64, 0x73, 0x22, 0x20, 0x0a, 0x08, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x6d,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x72, 0x75, 0x6e, 0x61, 0x6c, 0x34, 0x61, 0x6d, 0x69, 0x74,
+	0x79, 0x2f, 0x74, 0x72, 0x6f, 0x6e, 0x69, 0x63, 0x73, 0x63, 0x6f, 0x72, 0x70, 0x2f, 0x65, 0x73,
+	0x63, 0x6f, 0x6c, 0x61, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_smartschool_v1_comum_proto_rawDescOnce sync.Once
+	file_smartschool_v1_comum_proto_rawDescData = file_smartschool_v1_comum_proto_rawDesc
+)
+
+func file_smartschool_v1_comum_proto_rawDescGZIP() []byte {
+	file_smartschool_v1_comum_proto_rawDescOnce.Do(func() {
+		file_smartschool_v1_comum_proto_rawDescData = protoimpl.X.CompressGZIP(file_smartschool_v1_comum_proto_rawDescData)
+	})
+	return file_smartschool_v1_comum_proto_rawDescData
+}
+
+var file_smartschool_v1_comum_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_smartschool_v1_comum_proto_goTypes = []interface{}{
+	(*Filtro)(nil),          // 0: smartschool.v1.Filtro
+	(*Identificador)(nil),   // 1: smartschool.v1.Identificador
+	(*Identificadores)(nil), // 2: smartschool.v1.Identificadores
+	(*Contagem)(nil),        // 3: smartschool.v1.Contagem
+	nil,                     // 4: smartschool.v1.Filtro.CamposEntry
+}
+var file_smartschool_v1_comum_proto_depIdxs = []int32{
+	4, // 0: smartschool.v1.Filtro.campos:type_name -> smartschool.v1.Filtro.CamposEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_smartschool_v1_comum_proto_init() }
+func file_smartschool_v1_comum_proto_init() {
+	if File_smartschool_v1_comum_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_smartschool_v1_comum_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Filtro); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_comum_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Identificador); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_comum_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Identificadores); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_comum_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Contagem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_smartschool_v1_comum_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_smartschool_v1_comum_proto_goTypes,
+		DependencyIndexes: file_smartschool_v1_comum_proto_depIdxs,
+		MessageInfos:      file_smartschool_v1_comum_proto_msgTypes,
+	}.Build()
+	File_smartschool_v1_comum_proto = out.File
+	file_smartschool_v1_comum_proto_rawDesc = nil
+	file_smartschool_v1_comum_proto_goTypes = nil
+	file_smartschool_v1_comum_proto_depIdxs = nil
+}
@@ -0,0 +1,408 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: smartschool/v1/cursos.proto
+
+package escolapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Curso struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Compõe a matrícula dos alunos do curso.
+	Codigo int64  `protobuf:"varint,2,opt,name=codigo,proto3" json:"codigo,omitempty"`
+	Nome   string `protobuf:"bytes,3,opt,name=nome,proto3" json:"nome,omitempty"`
+	// Por exemplo "tecnico" ou "graduacao".
+	Nivel string `protobuf:"bytes,4,opt,name=nivel,proto3" json:"nivel,omitempty"`
+	// Preenchido quando o curso está na lixeira.
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+}
+
+func (x *Curso) Reset() {
+	*x = Curso{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_cursos_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Curso) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Curso) ProtoMessage() {}
+
+func (x *Curso) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_cursos_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Curso.ProtoReflect.Descriptor instead.
+func (*Curso) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_cursos_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Curso) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Curso) GetCodigo() int64 {
+	if x != nil {
+		return x.Codigo
+	}
+	return 0
+}
+
+func (x *Curso) GetNome() string {
+	if x != nil {
+		return x.Nome
+	}
+	return ""
+}
+
+func (x *Curso) GetNivel() string {
+	if x != nil {
+		return x.Nivel
+	}
+	return ""
+}
+
+func (x *Curso) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+type ListaCursos struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cursos []*Curso `protobuf:"bytes,1,rep,name=cursos,proto3" json:"cursos,omitempty"`
+}
+
+func (x *ListaCursos) Reset() {
+	*x = ListaCursos{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_cursos_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListaCursos) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListaCursos) ProtoMessage() {}
+
+func (x *ListaCursos) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_cursos_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListaCursos.ProtoReflect.Descriptor instead.
+func (*ListaCursos) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_cursos_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListaCursos) GetCursos() []*Curso {
+	if x != nil {
+		return x.Cursos
+	}
+	return nil
+}
+
+// AtualizacaoCurso altera os campos listados em campos; sem a máscara, apenas os campos preenchidos.
+type AtualizacaoCurso struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Curso  *Curso                 `protobuf:"bytes,2,opt,name=curso,proto3" json:"curso,omitempty"`
+	Campos *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=campos,proto3" json:"campos,omitempty"`
+}
+
+func (x *AtualizacaoCurso) Reset() {
+	*x = AtualizacaoCurso{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_cursos_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AtualizacaoCurso) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AtualizacaoCurso) ProtoMessage() {}
+
+func (x *AtualizacaoCurso) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_cursos_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AtualizacaoCurso.ProtoReflect.Descriptor instead.
+func (*AtualizacaoCurso) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_cursos_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AtualizacaoCurso) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AtualizacaoCurso) GetCurso() *Curso {
+	if x != nil {
+		return x.Curso
+	}
+	return nil
+}
+
+func (x *AtualizacaoCurso) GetCampos() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Campos
+	}
+	return nil
+}
+
+var File_smartschool_v1_cursos_proto protoreflect.FileDescriptor
+
+var file_smartschool_v1_cursos_proto_rawDesc = []byte{
+	0x0a, 0x1b, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73,
+	0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x1a, 0x20, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1a, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x6f, 0x6d, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x94, 0x01, 0x0a,
+	0x05, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x64, 0x69, 0x67, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x63, 0x6f, 0x64, 0x69, 0x67, 0x6f, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x6f, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x6f,
+	0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x69, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6e, 0x69, 0x76, 0x65, 0x6c, 0x12, 0x39, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x3c, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x43, 0x75, 0x72, 0x73,
+	0x6f, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f,
+	0x73, 0x22, 0x83, 0x01, 0x0a, 0x10, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x61,
+	0x6f, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2b, 0x0a, 0x05, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x52, 0x05, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x12, 0x32, 0x0a, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x52,
+	0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x32, 0xc8, 0x04, 0x0a, 0x06, 0x43, 0x75, 0x72, 0x73,
+	0x6f, 0x73, 0x12, 0x4c, 0x0a, 0x0c, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x69, 0x72, 0x43, 0x75, 0x72,
+	0x73, 0x6f, 0x12, 0x1b, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x1a,
+	0x1f, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x65, 0x73,
+	0x12, 0x43, 0x0a, 0x0c, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x73,
+	0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76,
+	0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x1a, 0x1b, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74,
+	0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x43,
+	0x75, 0x72, 0x73, 0x6f, 0x73, 0x12, 0x41, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x61,
+	0x72, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73,
+	0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x1a,
+	0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0d, 0x42, 0x75, 0x73, 0x63,
+	0x61, 0x72, 0x4c, 0x69, 0x78, 0x65, 0x69, 0x72, 0x61, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72,
+	0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72,
+	0x6f, 0x1a, 0x1b, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x73, 0x12, 0x43,
+	0x0a, 0x0b, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x1d, 0x2e,
+	0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x15, 0x2e, 0x73,
+	0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75,
+	0x72, 0x73, 0x6f, 0x12, 0x49, 0x0a, 0x0e, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x72,
+	0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x20, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68,
+	0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63,
+	0x61, 0x6f, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x1a, 0x15, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73,
+	0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x47,
+	0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x61, 0x72, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x1d,
+	0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x18, 0x2e,
+	0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x6f, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x6d, 0x12, 0x49, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x74, 0x61,
+	0x75, 0x72, 0x61, 0x72, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x12, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72,
+	0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x18, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74,
+	0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x67,
+	0x65, 0x6d, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x6b, 0x72, 0x75, 0x6e, 0x61, 0x6c, 0x34, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2f, 0x74, 0x72,
+	0x6f, 0x6e, 0x69, 0x63, 0x73, 0x63, 0x6f, 0x72, 0x70, 0x2f, 0x65, 0x73, 0x63, 0x6f, 0x6c, 0x61,
+	0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_smartschool_v1_cursos_proto_rawDescOnce sync.Once
+	file_smartschool_v1_cursos_proto_rawDescData = file_smartschool_v1_cursos_proto_rawDesc
+)
+
+func file_smartschool_v1_cursos_proto_rawDescGZIP() []byte {
+	file_smartschool_v1_cursos_proto_rawDescOnce.Do(func() {
+		file_smartschool_v1_cursos_proto_rawDescData = protoimpl.X.CompressGZIP(file_smartschool_v1_cursos_proto_rawDescData)
+	})
+	return file_smartschool_v1_cursos_proto_rawDescData
+}
+
+var file_smartschool_v1_cursos_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_smartschool_v1_cursos_proto_goTypes = []interface{}{
+	(*Curso)(nil),                 // 0: smartschool.v1.Curso
+	(*ListaCursos)(nil),           // 1: smartschool.v1.ListaCursos
+	(*AtualizacaoCurso)(nil),      // 2: smartschool.v1.AtualizacaoCurso
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 4: google.protobuf.FieldMask
+	(*Filtro)(nil),                // 5: smartschool.v1.Filtro
+	(*Identificador)(nil),         // 6: smartschool.v1.Identificador
+	(*Identificadores)(nil),       // 7: smartschool.v1.Identificadores
+	(*Contagem)(nil),              // 8: smartschool.v1.Contagem
+}
+var file_smartschool_v1_cursos_proto_depIdxs = []int32{
+	3,  // 0: smartschool.v1.Curso.deleted_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: smartschool.v1.ListaCursos.cursos:type_name -> smartschool.v1.Curso
+	0,  // 2: smartschool.v1.AtualizacaoCurso.curso:type_name -> smartschool.v1.Curso
+	4,  // 3: smartschool.v1.AtualizacaoCurso.campos:type_name -> google.protobuf.FieldMask
+	1,  // 4: smartschool.v1.Cursos.InserirCurso:input_type -> smartschool.v1.ListaCursos
+	5,  // 5: smartschool.v1.Cursos.BuscarCursos:input_type -> smartschool.v1.Filtro
+	5,  // 6: smartschool.v1.Cursos.ExportarCursos:input_type -> smartschool.v1.Filtro
+	5,  // 7: smartschool.v1.Cursos.BuscarLixeira:input_type -> smartschool.v1.Filtro
+	6,  // 8: smartschool.v1.Cursos.BuscarCurso:input_type -> smartschool.v1.Identificador
+	2,  // 9: smartschool.v1.Cursos.AtualizarCurso:input_type -> smartschool.v1.AtualizacaoCurso
+	6,  // 10: smartschool.v1.Cursos.DeletarCurso:input_type -> smartschool.v1.Identificador
+	6,  // 11: smartschool.v1.Cursos.RestaurarCurso:input_type -> smartschool.v1.Identificador
+	7,  // 12: smartschool.v1.Cursos.InserirCurso:output_type -> smartschool.v1.Identificadores
+	1,  // 13: smartschool.v1.Cursos.BuscarCursos:output_type -> smartschool.v1.ListaCursos
+	0,  // 14: smartschool.v1.Cursos.ExportarCursos:output_type -> smartschool.v1.Curso
+	1,  // 15: smartschool.v1.Cursos.BuscarLixeira:output_type -> smartschool.v1.ListaCursos
+	0,  // 16: smartschool.v1.Cursos.BuscarCurso:output_type -> smartschool.v1.Curso
+	0,  // 17: smartschool.v1.Cursos.AtualizarCurso:output_type -> smartschool.v1.Curso
+	8,  // 18: smartschool.v1.Cursos.DeletarCurso:output_type -> smartschool.v1.Contagem
+	8,  // 19: smartschool.v1.Cursos.RestaurarCurso:output_type -> smartschool.v1.Contagem
+	12, // [12:20] is the sub-list for method output_type
+	4,  // [4:12] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_smartschool_v1_cursos_proto_init() }
+func file_smartschool_v1_cursos_proto_init() {
+	if File_smartschool_v1_cursos_proto != nil {
+		return
+	}
+	file_smartschool_v1_comum_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_smartschool_v1_cursos_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Curso); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_cursos_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListaCursos); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_cursos_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AtualizacaoCurso); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_smartschool_v1_cursos_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_smartschool_v1_cursos_proto_goTypes,
+		DependencyIndexes: file_smartschool_v1_cursos_proto_depIdxs,
+		MessageInfos:      file_smartschool_v1_cursos_proto_msgTypes,
+	}.Build()
+	File_smartschool_v1_cursos_proto = out.File
+	file_smartschool_v1_cursos_proto_rawDesc = nil
+	file_smartschool_v1_cursos_proto_goTypes = nil
+	file_smartschool_v1_cursos_proto_depIdxs = nil
+}
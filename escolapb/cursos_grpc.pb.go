@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: smartschool/v1/cursos.proto
+
+package escolapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Cursos_InserirCurso_FullMethodName   = "/smartschool.v1.Cursos/InserirCurso"
+	Cursos_BuscarCursos_FullMethodName   = "/smartschool.v1.Cursos/BuscarCursos"
+	Cursos_ExportarCursos_FullMethodName = "/smartschool.v1.Cursos/ExportarCursos"
+	Cursos_BuscarLixeira_FullMethodName  = "/smartschool.v1.Cursos/BuscarLixeira"
+	Cursos_BuscarCurso_FullMethodName    = "/smartschool.v1.Cursos/BuscarCurso"
+	Cursos_AtualizarCurso_FullMethodName = "/smartschool.v1.Cursos/AtualizarCurso"
+	Cursos_DeletarCurso_FullMethodName   = "/smartschool.v1.Cursos/DeletarCurso"
+	Cursos_RestaurarCurso_FullMethodName = "/smartschool.v1.Cursos/RestaurarCurso"
+)
+
+// CursosClient is the client API for Cursos service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CursosClient interface {
+	InserirCurso(ctx context.Context, in *ListaCursos, opts ...grpc.CallOption) (*Identificadores, error)
+	BuscarCursos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaCursos, error)
+	// ExportarCursos envia os cursos um a um, sem carregar a listagem inteira na memória.
+	ExportarCursos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Cursos_ExportarCursosClient, error)
+	BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaCursos, error)
+	BuscarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Curso, error)
+	AtualizarCurso(ctx context.Context, in *AtualizacaoCurso, opts ...grpc.CallOption) (*Curso, error)
+	// DeletarCurso move o curso para a lixeira, aplicando as políticas de exclusão dos alunos e disciplinas.
+	DeletarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+	RestaurarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+}
+
+type cursosClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCursosClient(cc grpc.ClientConnInterface) CursosClient {
+	return &cursosClient{cc}
+}
+
+func (c *cursosClient) InserirCurso(ctx context.Context, in *ListaCursos, opts ...grpc.CallOption) (*Identificadores, error) {
+	out := new(Identificadores)
+	err := c.cc.Invoke(ctx, Cursos_InserirCurso_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) BuscarCursos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaCursos, error) {
+	out := new(ListaCursos)
+	err := c.cc.Invoke(ctx, Cursos_BuscarCursos_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) ExportarCursos(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Cursos_ExportarCursosClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Cursos_ServiceDesc.Streams[0], Cursos_ExportarCursos_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cursosExportarCursosClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Cursos_ExportarCursosClient interface {
+	Recv() (*Curso, error)
+	grpc.ClientStream
+}
+
+type cursosExportarCursosClient struct {
+	grpc.ClientStream
+}
+
+func (x *cursosExportarCursosClient) Recv() (*Curso, error) {
+	m := new(Curso)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cursosClient) BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaCursos, error) {
+	out := new(ListaCursos)
+	err := c.cc.Invoke(ctx, Cursos_BuscarLixeira_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) BuscarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Curso, error) {
+	out := new(Curso)
+	err := c.cc.Invoke(ctx, Cursos_BuscarCurso_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) AtualizarCurso(ctx context.Context, in *AtualizacaoCurso, opts ...grpc.CallOption) (*Curso, error) {
+	out := new(Curso)
+	err := c.cc.Invoke(ctx, Cursos_AtualizarCurso_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) DeletarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Cursos_DeletarCurso_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cursosClient) RestaurarCurso(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Cursos_RestaurarCurso_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CursosServer is the server API for Cursos service.
+// All implementations must embed UnimplementedCursosServer
+// for forward compatibility
+type CursosServer interface {
+	InserirCurso(context.Context, *ListaCursos) (*Identificadores, error)
+	BuscarCursos(context.Context, *Filtro) (*ListaCursos, error)
+	// ExportarCursos envia os cursos um a um, sem carregar a listagem inteira na memória.
+	ExportarCursos(*Filtro, Cursos_ExportarCursosServer) error
+	BuscarLixeira(context.Context, *Filtro) (*ListaCursos, error)
+	BuscarCurso(context.Context, *Identificador) (*Curso, error)
+	AtualizarCurso(context.Context, *AtualizacaoCurso) (*Curso, error)
+	// DeletarCurso move o curso para a lixeira, aplicando as políticas de exclusão dos alunos e disciplinas.
+	DeletarCurso(context.Context, *Identificador) (*Contagem, error)
+	RestaurarCurso(context.Context, *Identificador) (*Contagem, error)
+	mustEmbedUnimplementedCursosServer()
+}
+
+// UnimplementedCursosServer must be embedded to have forward compatible implementations.
+type UnimplementedCursosServer struct {
+}
+
+func (UnimplementedCursosServer) InserirCurso(context.Context, *ListaCursos) (*Identificadores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InserirCurso not implemented")
+}
+func (UnimplementedCursosServer) BuscarCursos(context.Context, *Filtro) (*ListaCursos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarCursos not implemented")
+}
+func (UnimplementedCursosServer) ExportarCursos(*Filtro, Cursos_ExportarCursosServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportarCursos not implemented")
+}
+func (UnimplementedCursosServer) BuscarLixeira(context.Context, *Filtro) (*ListaCursos, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarLixeira not implemented")
+}
+func (UnimplementedCursosServer) BuscarCurso(context.Context, *Identificador) (*Curso, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarCurso not implemented")
+}
+func (UnimplementedCursosServer) AtualizarCurso(context.Context, *AtualizacaoCurso) (*Curso, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AtualizarCurso not implemented")
+}
+func (UnimplementedCursosServer) DeletarCurso(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletarCurso not implemented")
+}
+func (UnimplementedCursosServer) RestaurarCurso(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestaurarCurso not implemented")
+}
+func (UnimplementedCursosServer) mustEmbedUnimplementedCursosServer() {}
+
+// UnsafeCursosServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CursosServer will
+// result in compilation errors.
+type UnsafeCursosServer interface {
+	mustEmbedUnimplementedCursosServer()
+}
+
+func RegisterCursosServer(s grpc.ServiceRegistrar, srv CursosServer) {
+	s.RegisterService(&Cursos_ServiceDesc, srv)
+}
+
+func _Cursos_InserirCurso_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListaCursos)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).InserirCurso(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_InserirCurso_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).InserirCurso(ctx, req.(*ListaCursos))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_BuscarCursos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).BuscarCursos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_BuscarCursos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).BuscarCursos(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_ExportarCursos_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Filtro)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CursosServer).ExportarCursos(m, &cursosExportarCursosServer{stream})
+}
+
+type Cursos_ExportarCursosServer interface {
+	Send(*Curso) error
+	grpc.ServerStream
+}
+
+type cursosExportarCursosServer struct {
+	grpc.ServerStream
+}
+
+func (x *cursosExportarCursosServer) Send(m *Curso) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Cursos_BuscarLixeira_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).BuscarLixeira(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_BuscarLixeira_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).BuscarLixeira(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_BuscarCurso_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).BuscarCurso(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_BuscarCurso_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).BuscarCurso(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_AtualizarCurso_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AtualizacaoCurso)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).AtualizarCurso(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_AtualizarCurso_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).AtualizarCurso(ctx, req.(*AtualizacaoCurso))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_DeletarCurso_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).DeletarCurso(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_DeletarCurso_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).DeletarCurso(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cursos_RestaurarCurso_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CursosServer).RestaurarCurso(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cursos_RestaurarCurso_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CursosServer).RestaurarCurso(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Cursos_ServiceDesc is the grpc.ServiceDesc for Cursos service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cursos_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartschool.v1.Cursos",
+	HandlerType: (*CursosServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InserirCurso",
+			Handler:    _Cursos_InserirCurso_Handler,
+		},
+		{
+			MethodName: "BuscarCursos",
+			Handler:    _Cursos_BuscarCursos_Handler,
+		},
+		{
+			MethodName: "BuscarLixeira",
+			Handler:    _Cursos_BuscarLixeira_Handler,
+		},
+		{
+			MethodName: "BuscarCurso",
+			Handler:    _Cursos_BuscarCurso_Handler,
+		},
+		{
+			MethodName: "AtualizarCurso",
+			Handler:    _Cursos_AtualizarCurso_Handler,
+		},
+		{
+			MethodName: "DeletarCurso",
+			Handler:    _Cursos_DeletarCurso_Handler,
+		},
+		{
+			MethodName: "RestaurarCurso",
+			Handler:    _Cursos_RestaurarCurso_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportarCursos",
+			Handler:       _Cursos_ExportarCursos_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smartschool/v1/cursos.proto",
+}
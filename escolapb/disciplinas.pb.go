@@ -0,0 +1,416 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: smartschool/v1/disciplinas.proto
+
+package escolapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Disciplina struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id           string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Nome         string `protobuf:"bytes,2,opt,name=nome,proto3" json:"nome,omitempty"`
+	CargaHoraria int64  `protobuf:"varint,3,opt,name=carga_horaria,json=cargaHoraria,proto3" json:"carga_horaria,omitempty"`
+	// Código do curso.
+	Curso int64 `protobuf:"varint,4,opt,name=curso,proto3" json:"curso,omitempty"`
+	// Preenchido quando a disciplina está na lixeira.
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+}
+
+func (x *Disciplina) Reset() {
+	*x = Disciplina{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_disciplinas_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Disciplina) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Disciplina) ProtoMessage() {}
+
+func (x *Disciplina) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_disciplinas_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Disciplina.ProtoReflect.Descriptor instead.
+func (*Disciplina) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_disciplinas_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Disciplina) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Disciplina) GetNome() string {
+	if x != nil {
+		return x.Nome
+	}
+	return ""
+}
+
+func (x *Disciplina) GetCargaHoraria() int64 {
+	if x != nil {
+		return x.CargaHoraria
+	}
+	return 0
+}
+
+func (x *Disciplina) GetCurso() int64 {
+	if x != nil {
+		return x.Curso
+	}
+	return 0
+}
+
+func (x *Disciplina) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+type ListaDisciplinas struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Disciplinas []*Disciplina `protobuf:"bytes,1,rep,name=disciplinas,proto3" json:"disciplinas,omitempty"`
+}
+
+func (x *ListaDisciplinas) Reset() {
+	*x = ListaDisciplinas{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_disciplinas_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListaDisciplinas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListaDisciplinas) ProtoMessage() {}
+
+func (x *ListaDisciplinas) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_disciplinas_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListaDisciplinas.ProtoReflect.Descriptor instead.
+func (*ListaDisciplinas) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_disciplinas_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListaDisciplinas) GetDisciplinas() []*Disciplina {
+	if x != nil {
+		return x.Disciplinas
+	}
+	return nil
+}
+
+// AtualizacaoDisciplina altera os campos listados em campos; sem a máscara, apenas os campos preenchidos.
+type AtualizacaoDisciplina struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Disciplina *Disciplina            `protobuf:"bytes,2,opt,name=disciplina,proto3" json:"disciplina,omitempty"`
+	Campos     *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=campos,proto3" json:"campos,omitempty"`
+}
+
+func (x *AtualizacaoDisciplina) Reset() {
+	*x = AtualizacaoDisciplina{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_smartschool_v1_disciplinas_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AtualizacaoDisciplina) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AtualizacaoDisciplina) ProtoMessage() {}
+
+func (x *AtualizacaoDisciplina) ProtoReflect() protoreflect.Message {
+	mi := &file_smartschool_v1_disciplinas_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AtualizacaoDisciplina.ProtoReflect.Descriptor instead.
+func (*AtualizacaoDisciplina) Descriptor() ([]byte, []int) {
+	return file_smartschool_v1_disciplinas_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AtualizacaoDisciplina) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AtualizacaoDisciplina) GetDisciplina() *Disciplina {
+	if x != nil {
+		return x.Disciplina
+	}
+	return nil
+}
+
+func (x *AtualizacaoDisciplina) GetCampos() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.Campos
+	}
+	return nil
+}
+
+var File_smartschool_v1_disciplinas_proto protoreflect.FileDescriptor
+
+var file_smartschool_v1_disciplinas_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x64, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e,
+	0x76, 0x31, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f,
+	0x6f, 0x6c, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x6f, 0x6d, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0xa6, 0x01, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x6f, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x72, 0x67, 0x61, 0x5f, 0x68, 0x6f,
+	0x72, 0x61, 0x72, 0x69, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x63, 0x61, 0x72,
+	0x67, 0x61, 0x48, 0x6f, 0x72, 0x61, 0x72, 0x69, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x75, 0x72,
+	0x73, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x12,
+	0x39, 0x0a, 0x0a, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x50, 0x0a, 0x10, 0x4c, 0x69,
+	0x73, 0x74, 0x61, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x12, 0x3c,
+	0x0a, 0x0b, 0x64, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x52,
+	0x0b, 0x64, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x22, 0x97, 0x01, 0x0a,
+	0x15, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x61, 0x6f, 0x44, 0x69, 0x73, 0x63,
+	0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x3a, 0x0a, 0x0a, 0x64, 0x69, 0x73, 0x63, 0x69, 0x70,
+	0x6c, 0x69, 0x6e, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x6d, 0x61,
+	0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63,
+	0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x52, 0x0a, 0x64, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69,
+	0x6e, 0x61, 0x12, 0x32, 0x0a, 0x06, 0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x4d, 0x61, 0x73, 0x6b, 0x52, 0x06,
+	0x63, 0x61, 0x6d, 0x70, 0x6f, 0x73, 0x32, 0x93, 0x05, 0x0a, 0x0b, 0x44, 0x69, 0x73, 0x63, 0x69,
+	0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x12, 0x56, 0x0a, 0x11, 0x49, 0x6e, 0x73, 0x65, 0x72, 0x69,
+	0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x12, 0x20, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x61, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x1a, 0x1f, 0x2e,
+	0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x4d,
+	0x0a, 0x11, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69,
+	0x6e, 0x61, 0x73, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x1a, 0x20, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x61, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x73, 0x12, 0x4b, 0x0a,
+	0x13, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c,
+	0x69, 0x6e, 0x61, 0x73, 0x12, 0x16, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f,
+	0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x72, 0x6f, 0x1a, 0x1a, 0x2e, 0x73,
+	0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69,
+	0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x30, 0x01, 0x12, 0x49, 0x0a, 0x0d, 0x42, 0x75,
+	0x73, 0x63, 0x61, 0x72, 0x4c, 0x69, 0x78, 0x65, 0x69, 0x72, 0x61, 0x12, 0x16, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c,
+	0x74, 0x72, 0x6f, 0x1a, 0x20, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x61, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70,
+	0x6c, 0x69, 0x6e, 0x61, 0x73, 0x12, 0x4d, 0x0a, 0x10, 0x42, 0x75, 0x73, 0x63, 0x61, 0x72, 0x44,
+	0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x12, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72,
+	0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x64, 0x6f, 0x72, 0x1a, 0x1a, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74,
+	0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70,
+	0x6c, 0x69, 0x6e, 0x61, 0x12, 0x58, 0x0a, 0x13, 0x41, 0x74, 0x75, 0x61, 0x6c, 0x69, 0x7a, 0x61,
+	0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x12, 0x25, 0x2e, 0x73, 0x6d,
+	0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x75,
+	0x61, 0x6c, 0x69, 0x7a, 0x61, 0x63, 0x61, 0x6f, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69,
+	0x6e, 0x61, 0x1a, 0x1a, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c, 0x69, 0x6e, 0x61, 0x12, 0x4c,
+	0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x61, 0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c,
+	0x69, 0x6e, 0x61, 0x12, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x72, 0x1a, 0x18, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x6d, 0x12, 0x4e, 0x0a, 0x13,
+	0x52, 0x65, 0x73, 0x74, 0x61, 0x75, 0x72, 0x61, 0x72, 0x44, 0x69, 0x73, 0x63, 0x69, 0x70, 0x6c,
+	0x69, 0x6e, 0x61, 0x12, 0x1d, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x64,
+	0x6f, 0x72, 0x1a, 0x18, 0x2e, 0x73, 0x6d, 0x61, 0x72, 0x74, 0x73, 0x63, 0x68, 0x6f, 0x6f, 0x6c,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x6d, 0x42, 0x2e, 0x5a, 0x2c,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x72, 0x75, 0x6e, 0x61,
+	0x6c, 0x34, 0x61, 0x6d, 0x69, 0x74, 0x79, 0x2f, 0x74, 0x72, 0x6f, 0x6e, 0x69, 0x63, 0x73, 0x63,
+	0x6f, 0x72, 0x70, 0x2f, 0x65, 0x73, 0x63, 0x6f, 0x6c, 0x61, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_smartschool_v1_disciplinas_proto_rawDescOnce sync.Once
+	file_smartschool_v1_disciplinas_proto_rawDescData = file_smartschool_v1_disciplinas_proto_rawDesc
+)
+
+func file_smartschool_v1_disciplinas_proto_rawDescGZIP() []byte {
+	file_smartschool_v1_disciplinas_proto_rawDescOnce.Do(func() {
+		file_smartschool_v1_disciplinas_proto_rawDescData = protoimpl.X.CompressGZIP(file_smartschool_v1_disciplinas_proto_rawDescData)
+	})
+	return file_smartschool_v1_disciplinas_proto_rawDescData
+}
+
+var file_smartschool_v1_disciplinas_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_smartschool_v1_disciplinas_proto_goTypes = []interface{}{
+	(*Disciplina)(nil),            // 0: smartschool.v1.Disciplina
+	(*ListaDisciplinas)(nil),      // 1: smartschool.v1.ListaDisciplinas
+	(*AtualizacaoDisciplina)(nil), // 2: smartschool.v1.AtualizacaoDisciplina
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 4: google.protobuf.FieldMask
+	(*Filtro)(nil),                // 5: smartschool.v1.Filtro
+	(*Identificador)(nil),         // 6: smartschool.v1.Identificador
+	(*Identificadores)(nil),       // 7: smartschool.v1.Identificadores
+	(*Contagem)(nil),              // 8: smartschool.v1.Contagem
+}
+var file_smartschool_v1_disciplinas_proto_depIdxs = []int32{
+	3,  // 0: smartschool.v1.Disciplina.deleted_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: smartschool.v1.ListaDisciplinas.disciplinas:type_name -> smartschool.v1.Disciplina
+	0,  // 2: smartschool.v1.AtualizacaoDisciplina.disciplina:type_name -> smartschool.v1.Disciplina
+	4,  // 3: smartschool.v1.AtualizacaoDisciplina.campos:type_name -> google.protobuf.FieldMask
+	1,  // 4: smartschool.v1.Disciplinas.InserirDisciplina:input_type -> smartschool.v1.ListaDisciplinas
+	5,  // 5: smartschool.v1.Disciplinas.BuscarDisciplinas:input_type -> smartschool.v1.Filtro
+	5,  // 6: smartschool.v1.Disciplinas.ExportarDisciplinas:input_type -> smartschool.v1.Filtro
+	5,  // 7: smartschool.v1.Disciplinas.BuscarLixeira:input_type -> smartschool.v1.Filtro
+	6,  // 8: smartschool.v1.Disciplinas.BuscarDisciplina:input_type -> smartschool.v1.Identificador
+	2,  // 9: smartschool.v1.Disciplinas.AtualizarDisciplina:input_type -> smartschool.v1.AtualizacaoDisciplina
+	6,  // 10: smartschool.v1.Disciplinas.DeletarDisciplina:input_type -> smartschool.v1.Identificador
+	6,  // 11: smartschool.v1.Disciplinas.RestaurarDisciplina:input_type -> smartschool.v1.Identificador
+	7,  // 12: smartschool.v1.Disciplinas.InserirDisciplina:output_type -> smartschool.v1.Identificadores
+	1,  // 13: smartschool.v1.Disciplinas.BuscarDisciplinas:output_type -> smartschool.v1.ListaDisciplinas
+	0,  // 14: smartschool.v1.Disciplinas.ExportarDisciplinas:output_type -> smartschool.v1.Disciplina
+	1,  // 15: smartschool.v1.Disciplinas.BuscarLixeira:output_type -> smartschool.v1.ListaDisciplinas
+	0,  // 16: smartschool.v1.Disciplinas.BuscarDisciplina:output_type -> smartschool.v1.Disciplina
+	0,  // 17: smartschool.v1.Disciplinas.AtualizarDisciplina:output_type -> smartschool.v1.Disciplina
+	8,  // 18: smartschool.v1.Disciplinas.DeletarDisciplina:output_type -> smartschool.v1.Contagem
+	8,  // 19: smartschool.v1.Disciplinas.RestaurarDisciplina:output_type -> smartschool.v1.Contagem
+	12, // [12:20] is the sub-list for method output_type
+	4,  // [4:12] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_smartschool_v1_disciplinas_proto_init() }
+func file_smartschool_v1_disciplinas_proto_init() {
+	if File_smartschool_v1_disciplinas_proto != nil {
+		return
+	}
+	file_smartschool_v1_comum_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_smartschool_v1_disciplinas_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Disciplina); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_disciplinas_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListaDisciplinas); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_smartschool_v1_disciplinas_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AtualizacaoDisciplina); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_smartschool_v1_disciplinas_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_smartschool_v1_disciplinas_proto_goTypes,
+		DependencyIndexes: file_smartschool_v1_disciplinas_proto_depIdxs,
+		MessageInfos:      file_smartschool_v1_disciplinas_proto_msgTypes,
+	}.Build()
+	File_smartschool_v1_disciplinas_proto = out.File
+	file_smartschool_v1_disciplinas_proto_rawDesc = nil
+	file_smartschool_v1_disciplinas_proto_goTypes = nil
+	file_smartschool_v1_disciplinas_proto_depIdxs = nil
+}
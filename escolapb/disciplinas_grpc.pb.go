@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: smartschool/v1/disciplinas.proto
+
+package escolapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Disciplinas_InserirDisciplina_FullMethodName   = "/smartschool.v1.Disciplinas/InserirDisciplina"
+	Disciplinas_BuscarDisciplinas_FullMethodName   = "/smartschool.v1.Disciplinas/BuscarDisciplinas"
+	Disciplinas_ExportarDisciplinas_FullMethodName = "/smartschool.v1.Disciplinas/ExportarDisciplinas"
+	Disciplinas_BuscarLixeira_FullMethodName       = "/smartschool.v1.Disciplinas/BuscarLixeira"
+	Disciplinas_BuscarDisciplina_FullMethodName    = "/smartschool.v1.Disciplinas/BuscarDisciplina"
+	Disciplinas_AtualizarDisciplina_FullMethodName = "/smartschool.v1.Disciplinas/AtualizarDisciplina"
+	Disciplinas_DeletarDisciplina_FullMethodName   = "/smartschool.v1.Disciplinas/DeletarDisciplina"
+	Disciplinas_RestaurarDisciplina_FullMethodName = "/smartschool.v1.Disciplinas/RestaurarDisciplina"
+)
+
+// DisciplinasClient is the client API for Disciplinas service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DisciplinasClient interface {
+	InserirDisciplina(ctx context.Context, in *ListaDisciplinas, opts ...grpc.CallOption) (*Identificadores, error)
+	BuscarDisciplinas(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaDisciplinas, error)
+	// ExportarDisciplinas envia as disciplinas uma a uma, sem carregar a listagem inteira na memória.
+	ExportarDisciplinas(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Disciplinas_ExportarDisciplinasClient, error)
+	BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaDisciplinas, error)
+	BuscarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Disciplina, error)
+	AtualizarDisciplina(ctx context.Context, in *AtualizacaoDisciplina, opts ...grpc.CallOption) (*Disciplina, error)
+	// DeletarDisciplina move a disciplina para a lixeira, aplicando a política de exclusão dos professores.
+	DeletarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+	RestaurarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+}
+
+type disciplinasClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDisciplinasClient(cc grpc.ClientConnInterface) DisciplinasClient {
+	return &disciplinasClient{cc}
+}
+
+func (c *disciplinasClient) InserirDisciplina(ctx context.Context, in *ListaDisciplinas, opts ...grpc.CallOption) (*Identificadores, error) {
+	out := new(Identificadores)
+	err := c.cc.Invoke(ctx, Disciplinas_InserirDisciplina_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) BuscarDisciplinas(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaDisciplinas, error) {
+	out := new(ListaDisciplinas)
+	err := c.cc.Invoke(ctx, Disciplinas_BuscarDisciplinas_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) ExportarDisciplinas(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Disciplinas_ExportarDisciplinasClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Disciplinas_ServiceDesc.Streams[0], Disciplinas_ExportarDisciplinas_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &disciplinasExportarDisciplinasClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Disciplinas_ExportarDisciplinasClient interface {
+	Recv() (*Disciplina, error)
+	grpc.ClientStream
+}
+
+type disciplinasExportarDisciplinasClient struct {
+	grpc.ClientStream
+}
+
+func (x *disciplinasExportarDisciplinasClient) Recv() (*Disciplina, error) {
+	m := new(Disciplina)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *disciplinasClient) BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaDisciplinas, error) {
+	out := new(ListaDisciplinas)
+	err := c.cc.Invoke(ctx, Disciplinas_BuscarLixeira_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) BuscarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Disciplina, error) {
+	out := new(Disciplina)
+	err := c.cc.Invoke(ctx, Disciplinas_BuscarDisciplina_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) AtualizarDisciplina(ctx context.Context, in *AtualizacaoDisciplina, opts ...grpc.CallOption) (*Disciplina, error) {
+	out := new(Disciplina)
+	err := c.cc.Invoke(ctx, Disciplinas_AtualizarDisciplina_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) DeletarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Disciplinas_DeletarDisciplina_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *disciplinasClient) RestaurarDisciplina(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Disciplinas_RestaurarDisciplina_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DisciplinasServer is the server API for Disciplinas service.
+// All implementations must embed UnimplementedDisciplinasServer
+// for forward compatibility
+type DisciplinasServer interface {
+	InserirDisciplina(context.Context, *ListaDisciplinas) (*Identificadores, error)
+	BuscarDisciplinas(context.Context, *Filtro) (*ListaDisciplinas, error)
+	// ExportarDisciplinas envia as disciplinas uma a uma, sem carregar a listagem inteira na memória.
+	ExportarDisciplinas(*Filtro, Disciplinas_ExportarDisciplinasServer) error
+	BuscarLixeira(context.Context, *Filtro) (*ListaDisciplinas, error)
+	BuscarDisciplina(context.Context, *Identificador) (*Disciplina, error)
+	AtualizarDisciplina(context.Context, *AtualizacaoDisciplina) (*Disciplina, error)
+	// DeletarDisciplina move a disciplina para a lixeira, aplicando a política de exclusão dos professores.
+	DeletarDisciplina(context.Context, *Identificador) (*Contagem, error)
+	RestaurarDisciplina(context.Context, *Identificador) (*Contagem, error)
+	mustEmbedUnimplementedDisciplinasServer()
+}
+
+// UnimplementedDisciplinasServer must be embedded to have forward compatible implementations.
+type UnimplementedDisciplinasServer struct {
+}
+
+func (UnimplementedDisciplinasServer) InserirDisciplina(context.Context, *ListaDisciplinas) (*Identificadores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InserirDisciplina not implemented")
+}
+func (UnimplementedDisciplinasServer) BuscarDisciplinas(context.Context, *Filtro) (*ListaDisciplinas, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarDisciplinas not implemented")
+}
+func (UnimplementedDisciplinasServer) ExportarDisciplinas(*Filtro, Disciplinas_ExportarDisciplinasServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportarDisciplinas not implemented")
+}
+func (UnimplementedDisciplinasServer) BuscarLixeira(context.Context, *Filtro) (*ListaDisciplinas, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarLixeira not implemented")
+}
+func (UnimplementedDisciplinasServer) BuscarDisciplina(context.Context, *Identificador) (*Disciplina, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarDisciplina not implemented")
+}
+func (UnimplementedDisciplinasServer) AtualizarDisciplina(context.Context, *AtualizacaoDisciplina) (*Disciplina, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AtualizarDisciplina not implemented")
+}
+func (UnimplementedDisciplinasServer) DeletarDisciplina(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletarDisciplina not implemented")
+}
+func (UnimplementedDisciplinasServer) RestaurarDisciplina(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestaurarDisciplina not implemented")
+}
+func (UnimplementedDisciplinasServer) mustEmbedUnimplementedDisciplinasServer() {}
+
+// UnsafeDisciplinasServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DisciplinasServer will
+// result in compilation errors.
+type UnsafeDisciplinasServer interface {
+	mustEmbedUnimplementedDisciplinasServer()
+}
+
+func RegisterDisciplinasServer(s grpc.ServiceRegistrar, srv DisciplinasServer) {
+	s.RegisterService(&Disciplinas_ServiceDesc, srv)
+}
+
+func _Disciplinas_InserirDisciplina_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListaDisciplinas)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).InserirDisciplina(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_InserirDisciplina_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).InserirDisciplina(ctx, req.(*ListaDisciplinas))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_BuscarDisciplinas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).BuscarDisciplinas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_BuscarDisciplinas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).BuscarDisciplinas(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_ExportarDisciplinas_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Filtro)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DisciplinasServer).ExportarDisciplinas(m, &disciplinasExportarDisciplinasServer{stream})
+}
+
+type Disciplinas_ExportarDisciplinasServer interface {
+	Send(*Disciplina) error
+	grpc.ServerStream
+}
+
+type disciplinasExportarDisciplinasServer struct {
+	grpc.ServerStream
+}
+
+func (x *disciplinasExportarDisciplinasServer) Send(m *Disciplina) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Disciplinas_BuscarLixeira_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).BuscarLixeira(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_BuscarLixeira_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).BuscarLixeira(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_BuscarDisciplina_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).BuscarDisciplina(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_BuscarDisciplina_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).BuscarDisciplina(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_AtualizarDisciplina_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AtualizacaoDisciplina)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).AtualizarDisciplina(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_AtualizarDisciplina_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).AtualizarDisciplina(ctx, req.(*AtualizacaoDisciplina))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_DeletarDisciplina_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).DeletarDisciplina(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_DeletarDisciplina_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).DeletarDisciplina(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Disciplinas_RestaurarDisciplina_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DisciplinasServer).RestaurarDisciplina(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Disciplinas_RestaurarDisciplina_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DisciplinasServer).RestaurarDisciplina(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Disciplinas_ServiceDesc is the grpc.ServiceDesc for Disciplinas service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Disciplinas_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartschool.v1.Disciplinas",
+	HandlerType: (*DisciplinasServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InserirDisciplina",
+			Handler:    _Disciplinas_InserirDisciplina_Handler,
+		},
+		{
+			MethodName: "BuscarDisciplinas",
+			Handler:    _Disciplinas_BuscarDisciplinas_Handler,
+		},
+		{
+			MethodName: "BuscarLixeira",
+			Handler:    _Disciplinas_BuscarLixeira_Handler,
+		},
+		{
+			MethodName: "BuscarDisciplina",
+			Handler:    _Disciplinas_BuscarDisciplina_Handler,
+		},
+		{
+			MethodName: "AtualizarDisciplina",
+			Handler:    _Disciplinas_AtualizarDisciplina_Handler,
+		},
+		{
+			MethodName: "DeletarDisciplina",
+			Handler:    _Disciplinas_DeletarDisciplina_Handler,
+		},
+		{
+			MethodName: "RestaurarDisciplina",
+			Handler:    _Disciplinas_RestaurarDisciplina_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportarDisciplinas",
+			Handler:       _Disciplinas_ExportarDisciplinas_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smartschool/v1/disciplinas.proto",
+}
@@ -0,0 +1,5 @@
+// Package escolapb contém o código gerado a partir das definições protobuf em proto/smartschool/v1, com as
+// mensagens e os serviços gRPC do cadastro escolar. Os serviços são implementados em handlers.
+package escolapb
+
+//go:generate protoc -I ../proto --go_out=.. --go_opt=module=github.com/krunal4amity/tronicscorp --go-grpc_out=.. --go-grpc_opt=module=github.com/krunal4amity/tronicscorp smartschool/v1/comum.proto smartschool/v1/alunos.proto smartschool/v1/professores.proto smartschool/v1/cursos.proto smartschool/v1/disciplinas.proto
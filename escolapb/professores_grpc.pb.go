@@ -0,0 +1,400 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: smartschool/v1/professores.proto
+
+package escolapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Professores_InserirProfessor_FullMethodName    = "/smartschool.v1.Professores/InserirProfessor"
+	Professores_BuscarProfessores_FullMethodName   = "/smartschool.v1.Professores/BuscarProfessores"
+	Professores_ExportarProfessores_FullMethodName = "/smartschool.v1.Professores/ExportarProfessores"
+	Professores_BuscarLixeira_FullMethodName       = "/smartschool.v1.Professores/BuscarLixeira"
+	Professores_BuscarProfessor_FullMethodName     = "/smartschool.v1.Professores/BuscarProfessor"
+	Professores_AtualizarProfessor_FullMethodName  = "/smartschool.v1.Professores/AtualizarProfessor"
+	Professores_DeletarProfessor_FullMethodName    = "/smartschool.v1.Professores/DeletarProfessor"
+	Professores_RestaurarProfessor_FullMethodName  = "/smartschool.v1.Professores/RestaurarProfessor"
+)
+
+// ProfessoresClient is the client API for Professores service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProfessoresClient interface {
+	InserirProfessor(ctx context.Context, in *ListaProfessores, opts ...grpc.CallOption) (*Identificadores, error)
+	BuscarProfessores(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaProfessores, error)
+	// ExportarProfessores envia os professores um a um, sem carregar a listagem inteira na memória.
+	ExportarProfessores(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Professores_ExportarProfessoresClient, error)
+	BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaProfessores, error)
+	BuscarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Professor, error)
+	AtualizarProfessor(ctx context.Context, in *AtualizacaoProfessor, opts ...grpc.CallOption) (*Professor, error)
+	// DeletarProfessor move o professor para a lixeira.
+	DeletarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+	RestaurarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error)
+}
+
+type professoresClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProfessoresClient(cc grpc.ClientConnInterface) ProfessoresClient {
+	return &professoresClient{cc}
+}
+
+func (c *professoresClient) InserirProfessor(ctx context.Context, in *ListaProfessores, opts ...grpc.CallOption) (*Identificadores, error) {
+	out := new(Identificadores)
+	err := c.cc.Invoke(ctx, Professores_InserirProfessor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) BuscarProfessores(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaProfessores, error) {
+	out := new(ListaProfessores)
+	err := c.cc.Invoke(ctx, Professores_BuscarProfessores_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) ExportarProfessores(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (Professores_ExportarProfessoresClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Professores_ServiceDesc.Streams[0], Professores_ExportarProfessores_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &professoresExportarProfessoresClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Professores_ExportarProfessoresClient interface {
+	Recv() (*Professor, error)
+	grpc.ClientStream
+}
+
+type professoresExportarProfessoresClient struct {
+	grpc.ClientStream
+}
+
+func (x *professoresExportarProfessoresClient) Recv() (*Professor, error) {
+	m := new(Professor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *professoresClient) BuscarLixeira(ctx context.Context, in *Filtro, opts ...grpc.CallOption) (*ListaProfessores, error) {
+	out := new(ListaProfessores)
+	err := c.cc.Invoke(ctx, Professores_BuscarLixeira_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) BuscarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Professor, error) {
+	out := new(Professor)
+	err := c.cc.Invoke(ctx, Professores_BuscarProfessor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) AtualizarProfessor(ctx context.Context, in *AtualizacaoProfessor, opts ...grpc.CallOption) (*Professor, error) {
+	out := new(Professor)
+	err := c.cc.Invoke(ctx, Professores_AtualizarProfessor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) DeletarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Professores_DeletarProfessor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *professoresClient) RestaurarProfessor(ctx context.Context, in *Identificador, opts ...grpc.CallOption) (*Contagem, error) {
+	out := new(Contagem)
+	err := c.cc.Invoke(ctx, Professores_RestaurarProfessor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProfessoresServer is the server API for Professores service.
+// All implementations must embed UnimplementedProfessoresServer
+// for forward compatibility
+type ProfessoresServer interface {
+	InserirProfessor(context.Context, *ListaProfessores) (*Identificadores, error)
+	BuscarProfessores(context.Context, *Filtro) (*ListaProfessores, error)
+	// ExportarProfessores envia os professores um a um, sem carregar a listagem inteira na memória.
+	ExportarProfessores(*Filtro, Professores_ExportarProfessoresServer) error
+	BuscarLixeira(context.Context, *Filtro) (*ListaProfessores, error)
+	BuscarProfessor(context.Context, *Identificador) (*Professor, error)
+	AtualizarProfessor(context.Context, *AtualizacaoProfessor) (*Professor, error)
+	// DeletarProfessor move o professor para a lixeira.
+	DeletarProfessor(context.Context, *Identificador) (*Contagem, error)
+	RestaurarProfessor(context.Context, *Identificador) (*Contagem, error)
+	mustEmbedUnimplementedProfessoresServer()
+}
+
+// UnimplementedProfessoresServer must be embedded to have forward compatible implementations.
+type UnimplementedProfessoresServer struct {
+}
+
+func (UnimplementedProfessoresServer) InserirProfessor(context.Context, *ListaProfessores) (*Identificadores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InserirProfessor not implemented")
+}
+func (UnimplementedProfessoresServer) BuscarProfessores(context.Context, *Filtro) (*ListaProfessores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarProfessores not implemented")
+}
+func (UnimplementedProfessoresServer) ExportarProfessores(*Filtro, Professores_ExportarProfessoresServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportarProfessores not implemented")
+}
+func (UnimplementedProfessoresServer) BuscarLixeira(context.Context, *Filtro) (*ListaProfessores, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarLixeira not implemented")
+}
+func (UnimplementedProfessoresServer) BuscarProfessor(context.Context, *Identificador) (*Professor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuscarProfessor not implemented")
+}
+func (UnimplementedProfessoresServer) AtualizarProfessor(context.Context, *AtualizacaoProfessor) (*Professor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AtualizarProfessor not implemented")
+}
+func (UnimplementedProfessoresServer) DeletarProfessor(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletarProfessor not implemented")
+}
+func (UnimplementedProfessoresServer) RestaurarProfessor(context.Context, *Identificador) (*Contagem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestaurarProfessor not implemented")
+}
+func (UnimplementedProfessoresServer) mustEmbedUnimplementedProfessoresServer() {}
+
+// UnsafeProfessoresServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProfessoresServer will
+// result in compilation errors.
+type UnsafeProfessoresServer interface {
+	mustEmbedUnimplementedProfessoresServer()
+}
+
+func RegisterProfessoresServer(s grpc.ServiceRegistrar, srv ProfessoresServer) {
+	s.RegisterService(&Professores_ServiceDesc, srv)
+}
+
+func _Professores_InserirProfessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListaProfessores)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).InserirProfessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_InserirProfessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).InserirProfessor(ctx, req.(*ListaProfessores))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_BuscarProfessores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).BuscarProfessores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_BuscarProfessores_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).BuscarProfessores(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_ExportarProfessores_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Filtro)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProfessoresServer).ExportarProfessores(m, &professoresExportarProfessoresServer{stream})
+}
+
+type Professores_ExportarProfessoresServer interface {
+	Send(*Professor) error
+	grpc.ServerStream
+}
+
+type professoresExportarProfessoresServer struct {
+	grpc.ServerStream
+}
+
+func (x *professoresExportarProfessoresServer) Send(m *Professor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Professores_BuscarLixeira_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Filtro)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).BuscarLixeira(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_BuscarLixeira_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).BuscarLixeira(ctx, req.(*Filtro))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_BuscarProfessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).BuscarProfessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_BuscarProfessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).BuscarProfessor(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_AtualizarProfessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AtualizacaoProfessor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).AtualizarProfessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_AtualizarProfessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).AtualizarProfessor(ctx, req.(*AtualizacaoProfessor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_DeletarProfessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).DeletarProfessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_DeletarProfessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).DeletarProfessor(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Professores_RestaurarProfessor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Identificador)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfessoresServer).RestaurarProfessor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Professores_RestaurarProfessor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfessoresServer).RestaurarProfessor(ctx, req.(*Identificador))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Professores_ServiceDesc is the grpc.ServiceDesc for Professores service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Professores_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "smartschool.v1.Professores",
+	HandlerType: (*ProfessoresServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InserirProfessor",
+			Handler:    _Professores_InserirProfessor_Handler,
+		},
+		{
+			MethodName: "BuscarProfessores",
+			Handler:    _Professores_BuscarProfessores_Handler,
+		},
+		{
+			MethodName: "BuscarLixeira",
+			Handler:    _Professores_BuscarLixeira_Handler,
+		},
+		{
+			MethodName: "BuscarProfessor",
+			Handler:    _Professores_BuscarProfessor_Handler,
+		},
+		{
+			MethodName: "AtualizarProfessor",
+			Handler:    _Professores_AtualizarProfessor_Handler,
+		},
+		{
+			MethodName: "DeletarProfessor",
+			Handler:    _Professores_DeletarProfessor_Handler,
+		},
+		{
+			MethodName: "RestaurarProfessor",
+			Handler:    _Professores_RestaurarProfessor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportarProfessores",
+			Handler:       _Professores_ExportarProfessores_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "smartschool/v1/professores.proto",
+}
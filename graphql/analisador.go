@@ -0,0 +1,509 @@
+// Package graphql é uma implementação mínima de GraphQL, sem depender de uma biblioteca: analisa consultas
+// (operações query, fragmentos, variáveis e as diretivas @include e @skip) e as executa sobre um Esquema
+// cujos resolvedores recebem todos os objetos pais de um nível de uma vez, o que evita o problema N+1.
+// A introspecção (__schema, __type e __typename) segue a especificação, para o GraphiQL e os geradores de
+// código; mutations e subscriptions não são suportadas. O esquema também é publicado em SDL.
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Documento é uma consulta analisada.
+type Documento struct {
+	Operacoes  []*Operacao
+	Fragmentos map[string]*Fragmento
+}
+
+type Operacao struct {
+	Tipo      string //"query"; mutation e subscription são recusadas na execução
+	Nome      string
+	Variaveis []DefinicaoVariavel
+	Selecoes  []Selecao
+}
+
+type DefinicaoVariavel struct {
+	Nome   string
+	Tipo   string //como escrito na consulta, por exemplo "[ID!]!"
+	Padrao interface{}
+}
+
+type Fragmento struct {
+	Nome     string
+	Condicao string //tipo do "on"
+	Selecoes []Selecao
+}
+
+// Selecao é um campo, um fragmento nomeado (Fragmento) ou um fragmento inline (Inline).
+type Selecao struct {
+	Apelido    string
+	Nome       string
+	Argumentos map[string]interface{}
+	Diretivas  []Diretiva
+	Selecoes   []Selecao
+	Fragmento  string
+	Inline     bool
+	Condicao   string
+}
+
+// Chave é o nome do campo na resposta.
+func (s Selecao) Chave() string {
+	if s.Apelido != "" {
+		return s.Apelido
+	}
+	return s.Nome
+}
+
+type Diretiva struct {
+	Nome       string
+	Argumentos map[string]interface{}
+}
+
+// Os valores literais são convertidos para int64, float64, string, bool, nil, Enum, []interface{} e
+// map[string]interface{}; variáveis ficam como Variavel até a execução.
+type (
+	Enum     string
+	Variavel string
+)
+
+// Analisar lê o texto de uma consulta.
+func Analisar(texto string) (doc *Documento, err error) {
+	a := &analisador{lexico: lexico{texto: texto}}
+	defer func() { //os erros de sintaxe interrompem a análise com panic(erroSintaxe)
+		if r := recover(); r != nil {
+			e, ok := r.(erroSintaxe)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, e
+		}
+	}()
+	a.avancar()
+	doc = &Documento{Fragmentos: make(map[string]*Fragmento)}
+	for a.atual.tipo != fimTexto {
+		switch {
+		case a.atual.tipo == pontuacao && a.atual.valor == "{": //forma abreviada de uma query anônima
+			doc.Operacoes = append(doc.Operacoes, &Operacao{Tipo: "query", Selecoes: a.selecoes()})
+		case a.atual.tipo == nome && a.atual.valor == "fragment":
+			a.avancar()
+			f := &Fragmento{Nome: a.nome()}
+			a.palavra("on")
+			f.Condicao = a.nome()
+			f.Selecoes = a.selecoes()
+			if _, repetido := doc.Fragmentos[f.Nome]; repetido {
+				a.falhar("fragment %q is defined more than once", f.Nome)
+			}
+			doc.Fragmentos[f.Nome] = f
+		case a.atual.tipo == nome:
+			op := &Operacao{Tipo: a.nome()}
+			if a.atual.tipo == nome {
+				op.Nome = a.nome()
+			}
+			if a.pontuacao("(") {
+				op.Variaveis = a.definicoesVariaveis()
+			}
+			a.diretivas()
+			op.Selecoes = a.selecoes()
+			doc.Operacoes = append(doc.Operacoes, op)
+		default:
+			a.falhar("unexpected %s", a.atual)
+		}
+	}
+	if len(doc.Operacoes) == 0 {
+		a.falhar("the document has no operation")
+	}
+	return doc, nil
+}
+
+type erroSintaxe struct {
+	mensagem string
+	posicao  int
+}
+
+func (e erroSintaxe) Error() string {
+	return fmt.Sprintf("Syntax error at position %d: %s", e.posicao, e.mensagem)
+}
+
+type analisador struct {
+	lexico
+	atual token
+}
+
+func (a *analisador) falhar(formato string, args ...interface{}) {
+	panic(erroSintaxe{mensagem: fmt.Sprintf(formato, args...), posicao: a.atual.posicao})
+}
+
+func (a *analisador) avancar() {
+	t, err := a.proximo()
+	if err != nil {
+		panic(err)
+	}
+	a.atual = t
+}
+
+// pontuacao consome a pontuação p, se for o token atual.
+func (a *analisador) pontuacao(p string) bool {
+	if a.atual.tipo == pontuacao && a.atual.valor == p {
+		a.avancar()
+		return true
+	}
+	return false
+}
+
+func (a *analisador) esperar(p string) {
+	if !a.pontuacao(p) {
+		a.falhar("expected %q, found %s", p, a.atual)
+	}
+}
+
+func (a *analisador) palavra(p string) {
+	if a.atual.tipo != nome || a.atual.valor != p {
+		a.falhar("expected %q, found %s", p, a.atual)
+	}
+	a.avancar()
+}
+
+func (a *analisador) nome() string {
+	if a.atual.tipo != nome {
+		a.falhar("expected a name, found %s", a.atual)
+	}
+	n := a.atual.valor
+	a.avancar()
+	return n
+}
+
+func (a *analisador) selecoes() []Selecao {
+	a.esperar("{")
+	var selecoes []Selecao
+	for !a.pontuacao("}") {
+		selecoes = append(selecoes, a.selecao())
+	}
+	if len(selecoes) == 0 {
+		a.falhar("empty selection set")
+	}
+	return selecoes
+}
+
+func (a *analisador) selecao() Selecao {
+	if a.pontuacao("...") {
+		s := Selecao{}
+		switch {
+		case a.atual.tipo == nome && a.atual.valor == "on":
+			a.avancar()
+			s.Inline, s.Condicao = true, a.nome()
+		case a.atual.tipo == nome:
+			s.Fragmento = a.nome()
+			s.Diretivas = a.diretivas()
+			return s
+		default:
+			s.Inline = true
+		}
+		s.Diretivas = a.diretivas()
+		s.Selecoes = a.selecoes()
+		return s
+	}
+	s := Selecao{Nome: a.nome()}
+	if a.pontuacao(":") {
+		s.Apelido, s.Nome = s.Nome, a.nome()
+	}
+	if a.pontuacao("(") {
+		s.Argumentos = a.argumentos()
+	}
+	s.Diretivas = a.diretivas()
+	if a.atual.tipo == pontuacao && a.atual.valor == "{" {
+		s.Selecoes = a.selecoes()
+	}
+	return s
+}
+
+// argumentos lê a lista depois do "(" já consumido.
+func (a *analisador) argumentos() map[string]interface{} {
+	args := make(map[string]interface{})
+	for !a.pontuacao(")") {
+		n := a.nome()
+		a.esperar(":")
+		if _, repetido := args[n]; repetido {
+			a.falhar("argument %q is repeated", n)
+		}
+		args[n] = a.valor(false)
+	}
+	return args
+}
+
+func (a *analisador) diretivas() []Diretiva {
+	var diretivas []Diretiva
+	for a.pontuacao("@") {
+		d := Diretiva{Nome: a.nome()}
+		if a.pontuacao("(") {
+			d.Argumentos = a.argumentos()
+		}
+		diretivas = append(diretivas, d)
+	}
+	return diretivas
+}
+
+func (a *analisador) definicoesVariaveis() []DefinicaoVariavel {
+	var defs []DefinicaoVariavel
+	for !a.pontuacao(")") {
+		a.esperar("$")
+		d := DefinicaoVariavel{Nome: a.nome()}
+		a.esperar(":")
+		d.Tipo = a.tipo()
+		if a.pontuacao("=") {
+			d.Padrao = a.valor(true)
+		}
+		defs = append(defs, d)
+	}
+	return defs
+}
+
+func (a *analisador) tipo() string {
+	var t string
+	if a.pontuacao("[") {
+		t = "[" + a.tipo() + "]"
+		a.esperar("]")
+	} else {
+		t = a.nome()
+	}
+	if a.pontuacao("!") {
+		t += "!"
+	}
+	return t
+}
+
+// valor lê um valor literal; constante proíbe variáveis, como nos valores padrão.
+func (a *analisador) valor(constante bool) interface{} {
+	t := a.atual
+	switch t.tipo {
+	case inteiro:
+		a.avancar()
+		n, err := strconv.ParseInt(t.valor, 10, 64)
+		if err != nil {
+			a.falhar("invalid integer %s", t.valor)
+		}
+		return n
+	case decimal:
+		a.avancar()
+		n, err := strconv.ParseFloat(t.valor, 64)
+		if err != nil {
+			a.falhar("invalid float %s", t.valor)
+		}
+		return n
+	case texto:
+		a.avancar()
+		return t.valor
+	case nome:
+		a.avancar()
+		switch t.valor {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nil
+		}
+		return Enum(t.valor)
+	}
+	switch {
+	case a.pontuacao("$"):
+		if constante {
+			a.falhar("variables are not allowed here")
+		}
+		return Variavel(a.nome())
+	case a.pontuacao("["):
+		lista := []interface{}{}
+		for !a.pontuacao("]") {
+			lista = append(lista, a.valor(constante))
+		}
+		return lista
+	case a.pontuacao("{"):
+		objeto := make(map[string]interface{})
+		for !a.pontuacao("}") {
+			n := a.nome()
+			a.esperar(":")
+			objeto[n] = a.valor(constante)
+		}
+		return objeto
+	}
+	a.falhar("expected a value, found %s", t)
+	return nil
+}
+
+//análise léxica
+
+const (
+	fimTexto = iota
+	pontuacao
+	nome
+	inteiro
+	decimal
+	texto
+)
+
+type token struct {
+	tipo    int
+	valor   string
+	posicao int
+}
+
+func (t token) String() string {
+	if t.tipo == fimTexto {
+		return "end of document"
+	}
+	return strconv.Quote(t.valor)
+}
+
+type lexico struct {
+	texto string
+	pos   int
+}
+
+func (l *lexico) proximo() (token, error) {
+	l.ignorar()
+	inicio := l.pos
+	if l.pos >= len(l.texto) {
+		return token{tipo: fimTexto, posicao: inicio}, nil
+	}
+	c := l.texto[l.pos]
+	switch {
+	case strings.HasPrefix(l.texto[l.pos:], "..."):
+		l.pos += 3
+		return token{tipo: pontuacao, valor: "...", posicao: inicio}, nil
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{tipo: pontuacao, valor: string(c), posicao: inicio}, nil
+	case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		for l.pos < len(l.texto) && ehNome(l.texto[l.pos]) {
+			l.pos++
+		}
+		return token{tipo: nome, valor: l.texto[inicio:l.pos], posicao: inicio}, nil
+	case c == '-' || c >= '0' && c <= '9':
+		return l.numero()
+	case c == '"':
+		return l.cadeia()
+	}
+	return token{}, erroSintaxe{mensagem: fmt.Sprintf("unexpected character %q", c), posicao: inicio}
+}
+
+// ignorar pula espaços, vírgulas e comentários.
+func (l *lexico) ignorar() {
+	for l.pos < len(l.texto) {
+		switch l.texto[l.pos] {
+		case ' ', '\t', '\n', '\r', ',':
+			l.pos++
+		case '#':
+			for l.pos < len(l.texto) && l.texto[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.texto[l.pos:], "\ufeff") { //BOM
+				l.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func ehNome(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (l *lexico) numero() (token, error) {
+	inicio, tipo := l.pos, inteiro
+	if l.texto[l.pos] == '-' {
+		l.pos++
+	}
+	digitos := func() int {
+		n := 0
+		for l.pos < len(l.texto) && l.texto[l.pos] >= '0' && l.texto[l.pos] <= '9' {
+			l.pos++
+			n++
+		}
+		return n
+	}
+	if digitos() == 0 {
+		return token{}, erroSintaxe{mensagem: "invalid number", posicao: inicio}
+	}
+	if l.pos < len(l.texto) && l.texto[l.pos] == '.' {
+		l.pos++
+		tipo = decimal
+		if digitos() == 0 {
+			return token{}, erroSintaxe{mensagem: "invalid number", posicao: inicio}
+		}
+	}
+	if l.pos < len(l.texto) && (l.texto[l.pos] == 'e' || l.texto[l.pos] == 'E') {
+		l.pos++
+		tipo = decimal
+		if l.pos < len(l.texto) && (l.texto[l.pos] == '+' || l.texto[l.pos] == '-') {
+			l.pos++
+		}
+		if digitos() == 0 {
+			return token{}, erroSintaxe{mensagem: "invalid number", posicao: inicio}
+		}
+	}
+	return token{tipo: tipo, valor: l.texto[inicio:l.pos], posicao: inicio}, nil
+}
+
+// cadeia lê uma string, simples ou em bloco ("""), com os escapes do GraphQL.
+func (l *lexico) cadeia() (token, error) {
+	inicio := l.pos
+	if strings.HasPrefix(l.texto[l.pos:], `"""`) {
+		fim := strings.Index(l.texto[l.pos+3:], `"""`)
+		if fim < 0 {
+			return token{}, erroSintaxe{mensagem: "unterminated string", posicao: inicio}
+		}
+		valor := l.texto[l.pos+3 : l.pos+3+fim]
+		l.pos += 6 + fim
+		return token{tipo: texto, valor: strings.TrimSpace(valor), posicao: inicio}, nil
+	}
+	l.pos++
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.texto) || l.texto[l.pos] == '\n' {
+			return token{}, erroSintaxe{mensagem: "unterminated string", posicao: inicio}
+		}
+		c := l.texto[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{tipo: texto, valor: b.String(), posicao: inicio}, nil
+		case c == '\\' && l.pos+1 < len(l.texto):
+			escape := l.texto[l.pos+1]
+			l.pos += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.texto) {
+					return token{}, erroSintaxe{mensagem: "invalid unicode escape", posicao: l.pos}
+				}
+				n, err := strconv.ParseUint(l.texto[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, erroSintaxe{mensagem: "invalid unicode escape", posicao: l.pos}
+				}
+				b.WriteRune(rune(n))
+				l.pos += 4
+			default:
+				return token{}, erroSintaxe{mensagem: fmt.Sprintf("invalid escape \\%c", escape), posicao: l.pos - 2}
+			}
+		default:
+			r, tamanho := utf8.DecodeRuneInString(l.texto[l.pos:])
+			b.WriteRune(r)
+			l.pos += tamanho
+		}
+	}
+}
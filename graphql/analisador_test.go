@@ -0,0 +1,95 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalisar(t *testing.T) {
+	doc, err := Analisar(`
+		# comentário
+		query Turma($curso: Int = 3, $ids: [ID!]!, $completo: Boolean!) {
+			cursos(codigo: $curso, nivel: TECNICO, limite: 10) @include(if: $completo) {
+				id
+				nome: titulo
+				...Disciplinas
+				... on Curso { codigo }
+				... @skip(if: true) { nivel }
+			}
+			texto(a: "linha\n\"aspas\" é", b: """ bloco """, c: -1.5e2, d: [1, 2], e: {x: null, y: false})
+		}
+		fragment Disciplinas on Curso { disciplinas { nome } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operacoes) != 1 || len(doc.Fragmentos) != 1 {
+		t.Fatalf("got %d operations and %d fragments", len(doc.Operacoes), len(doc.Fragmentos))
+	}
+	op := doc.Operacoes[0]
+	if op.Tipo != "query" || op.Nome != "Turma" {
+		t.Errorf("operation = %s %s", op.Tipo, op.Nome)
+	}
+	variaveis := []DefinicaoVariavel{{Nome: "curso", Tipo: "Int", Padrao: int64(3)}, {Nome: "ids", Tipo: "[ID!]!"},
+		{Nome: "completo", Tipo: "Boolean!"}}
+	if !reflect.DeepEqual(op.Variaveis, variaveis) {
+		t.Errorf("variables = %+v", op.Variaveis)
+	}
+
+	cursos := op.Selecoes[0]
+	args := map[string]interface{}{"codigo": Variavel("curso"), "nivel": Enum("TECNICO"), "limite": int64(10)}
+	if cursos.Nome != "cursos" || !reflect.DeepEqual(cursos.Argumentos, args) {
+		t.Errorf("cursos = %+v", cursos)
+	}
+	if len(cursos.Diretivas) != 1 || cursos.Diretivas[0].Nome != "include" || cursos.Diretivas[0].Argumentos["if"] != Variavel("completo") {
+		t.Errorf("directives = %+v", cursos.Diretivas)
+	}
+	sub := cursos.Selecoes
+	if len(sub) != 5 || sub[1].Apelido != "nome" || sub[1].Nome != "titulo" || sub[1].Chave() != "nome" {
+		t.Fatalf("subselections = %+v", sub)
+	}
+	if sub[2].Fragmento != "Disciplinas" || !sub[3].Inline || sub[3].Condicao != "Curso" || !sub[4].Inline || sub[4].Condicao != "" {
+		t.Errorf("fragments = %+v", sub[2:])
+	}
+
+	literais := map[string]interface{}{
+		"a": "linha\n\"aspas\" é",
+		"b": "bloco",
+		"c": -150.0,
+		"d": []interface{}{int64(1), int64(2)},
+		"e": map[string]interface{}{"x": nil, "y": false},
+	}
+	if texto := op.Selecoes[1]; !reflect.DeepEqual(texto.Argumentos, literais) {
+		t.Errorf("literals = %#v", texto.Argumentos)
+	}
+	if f := doc.Fragmentos["Disciplinas"]; f.Condicao != "Curso" || f.Selecoes[0].Selecoes[0].Nome != "nome" {
+		t.Errorf("fragment = %+v", f)
+	}
+
+	//a forma abreviada é uma query anônima
+	if doc, err := Analisar(`{ __typename }`); err != nil || doc.Operacoes[0].Tipo != "query" || doc.Operacoes[0].Selecoes[0].Nome != "__typename" {
+		t.Errorf("shorthand query: %+v, %v", doc, err)
+	}
+}
+
+func TestAnalisarErros(t *testing.T) {
+	casos := map[string]string{
+		``:                          "the document has no operation",
+		`{ }`:                       "empty selection set",
+		`{ a`:                       "end of document",
+		`{ a(x: 1, x: 2) }`:         `argument "x" is repeated`,
+		`query($a: Int = $b) { a }`: "variables are not allowed here",
+		`{ a(x: "sem fim) }`:        "unterminated string",
+		`{ a(x: "\q") }`:            `invalid escape \q`,
+		`{ a(x: 1.) }`:              "invalid number",
+		`{ a ; }`:                   `unexpected character ';'`,
+		`{ a } fragment F on T { a } fragment F on T { b }`: `fragment "F" is defined more than once`,
+	}
+	for consulta, esperado := range casos {
+		_, err := Analisar(consulta)
+		if err == nil || !strings.Contains(err.Error(), esperado) || !strings.HasPrefix(err.Error(), "Syntax error at position") {
+			t.Errorf("Analisar(%q) = %v, want an error with %q", consulta, err, esperado)
+		}
+	}
+}
@@ -0,0 +1,576 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Esquema descreve os tipos de objeto e os campos que podem ser consultados. Tipos que não estão em Tipos
+// são escalares (String, Int, Float, Boolean, ID) e saem como o JSON do valor resolvido.
+type Esquema struct {
+	Consulta           string //tipo raiz das operações query
+	Tipos              map[string]*Tipo
+	ProfundidadeMaxima int //níveis de objetos aninhados; 10 quando zero
+
+	once     sync.Once
+	completo *Esquema //com a introspecção, veja executavel
+}
+
+type Tipo struct {
+	Descricao string
+	Campos    map[string]*Campo
+}
+
+// Campo de um tipo. Tipo segue a notação do GraphQL ("Curso", "[Curso]", "ID!"); Argumentos mapeia o nome
+// de cada argumento aceito para o tipo dele.
+type Campo struct {
+	Tipo       string
+	Descricao  string
+	Argumentos map[string]string
+	Resolver   Resolver //nil: o valor é o campo de mesmo nome JSON do objeto pai
+}
+
+// Resolver recebe todos os objetos pais de um nível da consulta e devolve o valor do campo para cada um, na
+// mesma ordem. Campos de lista devolvem um slice por pai. Como um resolvedor é chamado uma única vez por
+// nível, buscar os dados de todos os pais em uma consulta ao banco evita o problema N+1.
+type Resolver func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+// Resposta é o corpo da resposta do GraphQL. Dados fica ausente quando a consulta nem chega a ser executada.
+type Resposta struct {
+	Dados *Objeto `json:"data,omitempty"`
+	Erros []Erro  `json:"errors,omitempty"`
+}
+
+type Erro struct {
+	Mensagem string        `json:"message"`
+	Caminho  []interface{} `json:"path,omitempty"`
+}
+
+// Objeto é um objeto do resultado, que mantém os campos na ordem da consulta.
+type Objeto struct {
+	chaves  []string
+	valores map[string]interface{}
+}
+
+func (o *Objeto) definir(chave string, valor interface{}) {
+	if o.valores == nil {
+		o.valores = make(map[string]interface{})
+	}
+	if _, existe := o.valores[chave]; !existe {
+		o.chaves = append(o.chaves, chave)
+	}
+	o.valores[chave] = valor
+}
+
+// Valor devolve o valor de um campo do resultado.
+func (o *Objeto) Valor(chave string) interface{} {
+	return o.valores[chave]
+}
+
+func (o *Objeto) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, chave := range o.chaves {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		nome, _ := json.Marshal(chave)
+		valor, err := json.Marshal(o.valores[chave])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(nome)
+		b.WriteByte(':')
+		b.Write(valor)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// Executar analisa e executa uma consulta. Erros de sintaxe e de variáveis impedem a execução; os demais,
+// inclusive os de validação, que são verificados durante a execução, deixam o campo nulo e são listados em
+// Erros.
+func (e *Esquema) Executar(ctx context.Context, consulta, nomeOperacao string, variaveis map[string]interface{}) *Resposta {
+	doc, err := Analisar(consulta)
+	if err != nil {
+		return &Resposta{Erros: []Erro{{Mensagem: err.Error()}}}
+	}
+	op, err := doc.operacao(nomeOperacao)
+	if err != nil {
+		return &Resposta{Erros: []Erro{{Mensagem: err.Error()}}}
+	}
+	x := &execucao{esquema: e.executavel(), doc: doc}
+	if x.variaveis, err = coagirVariaveis(op.Variaveis, variaveis); err != nil {
+		return &Resposta{Erros: []Erro{{Mensagem: err.Error()}}}
+	}
+	objetos := x.selecionar(ctx, e.Consulta, []interface{}{nil}, op.Selecoes, nil, 0)
+	return &Resposta{Dados: objetos[0], Erros: x.erros}
+}
+
+func (d *Documento) operacao(nome string) (*Operacao, error) {
+	var op *Operacao
+	switch {
+	case nome != "":
+		for _, o := range d.Operacoes {
+			if o.Nome == nome {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("Unknown operation %q", nome)
+		}
+	case len(d.Operacoes) == 1:
+		op = d.Operacoes[0]
+	default:
+		return nil, fmt.Errorf("An operation name is required when the query has more than one operation")
+	}
+	if op.Tipo != "query" {
+		return nil, fmt.Errorf("Only query operations are supported, not %s", op.Tipo)
+	}
+	return op, nil
+}
+
+type execucao struct {
+	esquema   *Esquema
+	doc       *Documento
+	variaveis map[string]interface{}
+	erros     []Erro
+}
+
+func (x *execucao) falhar(caminho []interface{}, formato string, args ...interface{}) {
+	x.erros = append(x.erros, Erro{Mensagem: fmt.Sprintf(formato, args...), Caminho: caminho})
+}
+
+// selecionar resolve as seleções para todos os pais de um mesmo tipo e devolve um objeto por pai. Os
+// caminhos dos erros não têm os índices das listas, porque cada campo é resolvido para todos os pais juntos.
+func (x *execucao) selecionar(ctx context.Context, nomeTipo string, pais []interface{}, selecoes []Selecao,
+	caminho []interface{}, profundidade int) []*Objeto {
+	tipo := x.esquema.Tipos[nomeTipo]
+	objetos := make([]*Objeto, len(pais))
+	for i := range objetos {
+		objetos[i] = &Objeto{}
+	}
+	for _, s := range x.coletar(nomeTipo, selecoes, caminho, map[string]bool{}) {
+		chave := s.Chave()
+		caminhoCampo := append(caminho[:len(caminho):len(caminho)], chave)
+		if s.Nome == "__typename" {
+			for _, o := range objetos {
+				o.definir(chave, nomeTipo)
+			}
+			continue
+		}
+		valores := x.resolver(ctx, tipo, nomeTipo, pais, s, caminhoCampo, profundidade)
+		for i, o := range objetos {
+			var valor interface{}
+			if valores != nil {
+				valor = valores[i]
+			}
+			o.definir(chave, valor)
+		}
+	}
+	return objetos
+}
+
+// resolver devolve o valor do campo s para cada pai, ou nil quando o campo falhou.
+func (x *execucao) resolver(ctx context.Context, tipo *Tipo, nomeTipo string, pais []interface{}, s Selecao,
+	caminho []interface{}, profundidade int) []interface{} {
+	def := tipo.Campos[s.Nome]
+	if def == nil {
+		x.falhar(caminho, "Cannot query field %q on type %q", s.Nome, nomeTipo)
+		return nil
+	}
+	args, err := x.argumentos(s, def)
+	if err != nil {
+		x.falhar(caminho, "%v", err)
+		return nil
+	}
+	base, lista := tipoBase(def.Tipo)
+	_, ehObjeto := x.esquema.Tipos[base]
+	switch {
+	case ehObjeto && len(s.Selecoes) == 0:
+		x.falhar(caminho, "Field %q of type %q must have a selection of subfields", s.Nome, def.Tipo)
+		return nil
+	case !ehObjeto && len(s.Selecoes) > 0:
+		x.falhar(caminho, "Field %q must not have a selection since type %q has no subfields", s.Nome, def.Tipo)
+		return nil
+	case ehObjeto && profundidade >= x.profundidadeMaxima() && !strings.HasPrefix(base, "__"): //a introspecção é finita
+		x.falhar(caminho, "The query exceeds the maximum depth of %d", x.profundidadeMaxima())
+		return nil
+	}
+
+	var valores []interface{}
+	if def.Resolver == nil {
+		valores = make([]interface{}, len(pais))
+		for i, pai := range pais {
+			valores[i] = campoPorNome(pai, s.Nome)
+		}
+	} else if valores, err = def.Resolver(ctx, pais, args); err != nil {
+		x.falhar(caminho, "%v", err)
+		return nil
+	} else if len(valores) != len(pais) {
+		x.falhar(caminho, "Internal error resolving %q", s.Nome)
+		return nil
+	}
+	if !ehObjeto {
+		return valores
+	}
+
+	//os filhos de todos os pais são resolvidos juntos no nível seguinte
+	var filhos []interface{}
+	for _, valor := range valores {
+		if lista {
+			filhos = append(filhos, elementos(valor)...)
+		} else if !nulo(valor) {
+			filhos = append(filhos, valor)
+		}
+	}
+	resultados := x.selecionar(ctx, base, filhos, s.Selecoes, caminho, profundidade+1)
+	for i, valor := range valores {
+		switch {
+		case lista && !nulo(valor):
+			n := len(elementos(valor))
+			objetos := make([]*Objeto, n)
+			copy(objetos, resultados[:n])
+			resultados = resultados[n:]
+			valores[i] = objetos
+		case lista || nulo(valor):
+			valores[i] = nil
+		default:
+			valores[i] = resultados[0]
+			resultados = resultados[1:]
+		}
+	}
+	return valores
+}
+
+func (x *execucao) profundidadeMaxima() int {
+	if x.esquema.ProfundidadeMaxima > 0 {
+		return x.esquema.ProfundidadeMaxima
+	}
+	return 10
+}
+
+// coletar expande os fragmentos e aplica @skip e @include, juntando as seleções repetidas de um campo.
+func (x *execucao) coletar(nomeTipo string, selecoes []Selecao, caminho []interface{}, visitados map[string]bool) []Selecao {
+	var campos []Selecao
+	posicoes := make(map[string]int)
+	juntar := func(s Selecao) {
+		if i, ok := posicoes[s.Chave()]; ok {
+			campos[i].Selecoes = append(campos[i].Selecoes[:len(campos[i].Selecoes):len(campos[i].Selecoes)], s.Selecoes...)
+			return
+		}
+		posicoes[s.Chave()] = len(campos)
+		campos = append(campos, s)
+	}
+	for _, s := range selecoes {
+		incluir, err := x.incluir(s.Diretivas)
+		if err != nil {
+			x.falhar(caminho, "%v", err)
+			continue
+		}
+		if !incluir {
+			continue
+		}
+		switch {
+		case s.Fragmento != "":
+			f, ok := x.doc.Fragmentos[s.Fragmento]
+			if !ok {
+				x.falhar(caminho, "Unknown fragment %q", s.Fragmento)
+				continue
+			}
+			if visitados[f.Nome] || f.Condicao != nomeTipo {
+				continue
+			}
+			visitados[f.Nome] = true
+			for _, c := range x.coletar(nomeTipo, f.Selecoes, caminho, visitados) {
+				juntar(c)
+			}
+		case s.Inline:
+			if s.Condicao != "" && s.Condicao != nomeTipo {
+				continue
+			}
+			for _, c := range x.coletar(nomeTipo, s.Selecoes, caminho, visitados) {
+				juntar(c)
+			}
+		default:
+			juntar(s)
+		}
+	}
+	return campos
+}
+
+func (x *execucao) incluir(diretivas []Diretiva) (bool, error) {
+	for _, d := range diretivas {
+		if d.Nome != "skip" && d.Nome != "include" {
+			return false, fmt.Errorf("Unknown directive @%s", d.Nome)
+		}
+		condicao, ok := x.substituir(d.Argumentos["if"]).(bool)
+		if !ok {
+			return false, fmt.Errorf("The directive @%s requires a boolean argument if", d.Nome)
+		}
+		if condicao == (d.Nome == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// argumentos confere os argumentos do campo com a definição e substitui as variáveis.
+func (x *execucao) argumentos(s Selecao, def *Campo) (map[string]interface{}, error) {
+	args := make(map[string]interface{}, len(s.Argumentos))
+	for nome, valor := range s.Argumentos {
+		tipo, ok := def.Argumentos[nome]
+		if !ok {
+			return nil, fmt.Errorf("Unknown argument %q on field %q", nome, s.Nome)
+		}
+		v, err := coagir(tipo, x.substituir(valor))
+		if err != nil {
+			return nil, fmt.Errorf("Argument %q: %v", nome, err)
+		}
+		if v != nil {
+			args[nome] = v
+		}
+	}
+	for nome, tipo := range def.Argumentos {
+		if _, ok := args[nome]; !ok && strings.HasSuffix(tipo, "!") {
+			return nil, fmt.Errorf("Argument %q of field %q is required", nome, s.Nome)
+		}
+	}
+	return args, nil
+}
+
+func (x *execucao) substituir(valor interface{}) interface{} {
+	switch v := valor.(type) {
+	case Variavel:
+		return x.variaveis[string(v)]
+	case []interface{}:
+		lista := make([]interface{}, len(v))
+		for i, item := range v {
+			lista[i] = x.substituir(item)
+		}
+		return lista
+	case map[string]interface{}:
+		objeto := make(map[string]interface{}, len(v))
+		for nome, item := range v {
+			objeto[nome] = x.substituir(item)
+		}
+		return objeto
+	}
+	return valor
+}
+
+func coagirVariaveis(defs []DefinicaoVariavel, valores map[string]interface{}) (map[string]interface{}, error) {
+	variaveis := make(map[string]interface{}, len(defs))
+	for _, d := range defs {
+		valor, ok := valores[d.Nome]
+		if !ok {
+			valor = d.Padrao
+		}
+		v, err := coagir(d.Tipo, valor)
+		if err != nil {
+			return nil, fmt.Errorf("Variable $%s: %v", d.Nome, err)
+		}
+		variaveis[d.Nome] = v
+	}
+	return variaveis, nil
+}
+
+// coagir converte um valor literal ou vindo do JSON das variáveis para o tipo: números do JSON viram
+// int64 em Int, e Enum vira string.
+func coagir(tipo string, valor interface{}) (interface{}, error) {
+	obrigatorio := strings.HasSuffix(tipo, "!")
+	tipo = strings.TrimSuffix(tipo, "!")
+	if valor == nil {
+		if obrigatorio {
+			return nil, fmt.Errorf("a value of type %s! is required", tipo)
+		}
+		return nil, nil
+	}
+	if strings.HasPrefix(tipo, "[") {
+		item := tipo[1 : len(tipo)-1]
+		lista, ok := valor.([]interface{})
+		if !ok {
+			lista = []interface{}{valor} //um valor único vale como lista de um item
+		}
+		convertida := make([]interface{}, len(lista))
+		for i, v := range lista {
+			c, err := coagir(item, v)
+			if err != nil {
+				return nil, err
+			}
+			convertida[i] = c
+		}
+		return convertida, nil
+	}
+	if e, ok := valor.(Enum); ok {
+		valor = string(e)
+	}
+	switch tipo {
+	case "Int":
+		switch n := valor.(type) {
+		case int64:
+			return n, nil
+		case float64:
+			if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+				return int64(n), nil
+			}
+		}
+		return nil, fmt.Errorf("%v is not an Int", valor)
+	case "Float":
+		switch n := valor.(type) {
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+		return nil, fmt.Errorf("%v is not a Float", valor)
+	case "Boolean":
+		if _, ok := valor.(bool); !ok {
+			return nil, fmt.Errorf("%v is not a Boolean", valor)
+		}
+	case "ID":
+		switch n := valor.(type) {
+		case string:
+			return n, nil
+		case int64:
+			return fmt.Sprint(n), nil
+		}
+		return nil, fmt.Errorf("%v is not an ID", valor)
+	case "String":
+		if _, ok := valor.(string); !ok {
+			return nil, fmt.Errorf("%v is not a String", valor)
+		}
+	}
+	return valor, nil
+}
+
+// tipoBase devolve o nome do tipo sem as marcas de lista e de obrigatório.
+func tipoBase(tipo string) (string, bool) {
+	tipo = strings.TrimSuffix(tipo, "!")
+	if strings.HasPrefix(tipo, "[") {
+		base, _ := tipoBase(tipo[1 : len(tipo)-1])
+		return base, true
+	}
+	return tipo, false
+}
+
+func nulo(valor interface{}) bool {
+	if valor == nil {
+		return true
+	}
+	v := reflect.ValueOf(valor)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// elementos devolve os itens não nulos de um slice de qualquer tipo.
+func elementos(valor interface{}) []interface{} {
+	if nulo(valor) {
+		return nil
+	}
+	v := reflect.ValueOf(valor)
+	if v.Kind() != reflect.Slice {
+		return []interface{}{valor}
+	}
+	itens := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if item := v.Index(i).Interface(); !nulo(item) {
+			itens = append(itens, item)
+		}
+	}
+	return itens
+}
+
+var camposJSON sync.Map //reflect.Type -> map[string]int
+
+// campoPorNome lê de uma struct o campo com o nome JSON pedido, ou a chave de um mapa.
+func campoPorNome(pai interface{}, nome string) interface{} {
+	v := reflect.ValueOf(pai)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if item := v.MapIndex(reflect.ValueOf(nome)); item.IsValid() {
+			return item.Interface()
+		}
+	case reflect.Struct:
+		indices, ok := camposJSON.Load(v.Type())
+		if !ok {
+			m := make(map[string]int)
+			for i := 0; i < v.NumField(); i++ {
+				f := v.Type().Field(i)
+				n := strings.Split(f.Tag.Get("json"), ",")[0]
+				if n == "" {
+					n = f.Name
+				}
+				m[n] = i
+			}
+			camposJSON.Store(v.Type(), m)
+			indices = m
+		}
+		if i, ok := indices.(map[string]int)[nome]; ok {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
+
+// SDL descreve o esquema na linguagem de definição do GraphQL.
+func (e *Esquema) SDL() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema {\n  query: %s\n}\n", e.Consulta)
+	for _, nome := range ordenadas(e.Tipos) {
+		t := e.Tipos[nome]
+		b.WriteString("\n")
+		if t.Descricao != "" {
+			fmt.Fprintf(&b, "\"\"\"%s\"\"\"\n", t.Descricao)
+		}
+		fmt.Fprintf(&b, "type %s {\n", nome)
+		campos := make([]string, 0, len(t.Campos))
+		for n := range t.Campos {
+			campos = append(campos, n)
+		}
+		sort.Strings(campos)
+		for _, n := range campos {
+			c := t.Campos[n]
+			if c.Descricao != "" {
+				fmt.Fprintf(&b, "  \"%s\"\n", c.Descricao)
+			}
+			b.WriteString("  " + n)
+			if len(c.Argumentos) > 0 {
+				args := make([]string, 0, len(c.Argumentos))
+				for a, tipo := range c.Argumentos {
+					args = append(args, a+": "+tipo)
+				}
+				sort.Strings(args)
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + c.Tipo + "\n")
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func ordenadas(tipos map[string]*Tipo) []string {
+	nomes := make([]string, 0, len(tipos))
+	for n := range tipos {
+		nomes = append(nomes, n)
+	}
+	sort.Strings(nomes)
+	return nomes
+}
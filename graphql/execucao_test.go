@@ -0,0 +1,266 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type cursoTeste struct {
+	Codigo int64  `json:"codigo"`
+	Nome   string `json:"nome"`
+}
+
+type disciplinaTeste struct {
+	Nome  string `json:"nome"`
+	Curso int64  `json:"-"`
+}
+
+// esquemaTeste tem cursos e as disciplinas de cada curso; chamadas conta as execuções de cada resolvedor.
+func esquemaTeste(chamadas map[string]int) *Esquema {
+	cursos := []interface{}{cursoTeste{1, "Redes"}, cursoTeste{2, "Computação"}, cursoTeste{3, "Letras"}}
+	disciplinas := []disciplinaTeste{{"Protocolos", 1}, {"Cálculo", 2}, {"Física", 2}}
+	return &Esquema{
+		Consulta:           "Consulta",
+		ProfundidadeMaxima: 3,
+		Tipos: map[string]*Tipo{
+			"Consulta": {Campos: map[string]*Campo{
+				"cursos": {Tipo: "[Curso]", Argumentos: map[string]string{"codigo": "Int", "limite": "Int"},
+					Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+						chamadas["cursos"]++
+						if codigo, ok := args["codigo"].(int64); ok {
+							return []interface{}{[]interface{}{cursos[codigo-1]}}, nil
+						}
+						return []interface{}{cursos}, nil
+					}},
+				"curso": {Tipo: "Curso", Argumentos: map[string]string{"codigo": "Int!"},
+					Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+						codigo := args["codigo"].(int64)
+						if codigo < 1 || codigo > int64(len(cursos)) {
+							return []interface{}{nil}, nil
+						}
+						return []interface{}{cursos[codigo-1]}, nil
+					}},
+				"falha": {Tipo: "String", Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+					return nil, errors.New("Unable to find the document")
+				}},
+			}},
+			"Curso": {Descricao: "Curso oferecido", Campos: map[string]*Campo{
+				"codigo": {Tipo: "Int"},
+				"nome":   {Tipo: "String"},
+				"disciplinas": {Tipo: "[Disciplina]", Descricao: "Disciplinas do curso",
+					Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+						chamadas["disciplinas"]++
+						valores := make([]interface{}, len(pais))
+						for i, pai := range pais {
+							var lista []interface{}
+							for _, d := range disciplinas {
+								if d.Curso == pai.(cursoTeste).Codigo {
+									lista = append(lista, d)
+								}
+							}
+							valores[i] = lista
+						}
+						return valores, nil
+					}},
+				"curso": {Tipo: "Curso", Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+					return pais, nil //o próprio curso, para testar a profundidade
+				}},
+			}},
+			"Disciplina": {Campos: map[string]*Campo{"nome": {Tipo: "String"}}},
+		},
+	}
+}
+
+func executar(t *testing.T, e *Esquema, consulta string, variaveis map[string]interface{}) (string, []Erro) {
+	t.Helper()
+	resposta := e.Executar(context.Background(), consulta, "", variaveis)
+	if resposta.Dados == nil {
+		return "", resposta.Erros
+	}
+	dados, err := json.Marshal(resposta.Dados)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(dados), resposta.Erros
+}
+
+func TestExecutar(t *testing.T) {
+	chamadas := make(map[string]int)
+	e := esquemaTeste(chamadas)
+	dados, erros := executar(t, e, `query($sem: Boolean!) {
+		__typename
+		todos: cursos { titulo: nome ...Grade }
+		um: cursos(codigo: 2) { codigo nome @skip(if: $sem) }
+		curso(codigo: 9) { nome }
+	}
+	fragment Grade on Curso { disciplinas { nome __typename } }`, map[string]interface{}{"sem": true})
+	if len(erros) > 0 {
+		t.Fatalf("errors: %+v", erros)
+	}
+	esperado := `{"__typename":"Consulta",` +
+		`"todos":[{"titulo":"Redes","disciplinas":[{"nome":"Protocolos","__typename":"Disciplina"}]},` +
+		`{"titulo":"Computação","disciplinas":[{"nome":"Cálculo","__typename":"Disciplina"},{"nome":"Física","__typename":"Disciplina"}]},` +
+		`{"titulo":"Letras","disciplinas":null}]` +
+		`,"um":[{"codigo":2}],"curso":null}`
+	if dados != esperado {
+		t.Errorf("got\n%s\nwant\n%s", dados, esperado)
+	}
+	if chamadas["disciplinas"] != 1 {
+		t.Errorf("the disciplinas resolver ran %d times for three courses; it should run once per level", chamadas["disciplinas"])
+	}
+}
+
+func TestExecutarErros(t *testing.T) {
+	e := esquemaTeste(make(map[string]int))
+	casos := []struct {
+		consulta  string
+		variaveis map[string]interface{}
+		erro      string
+		semDados  bool
+	}{
+		{consulta: `{ cursos { nome idade } }`, erro: `Cannot query field "idade" on type "Curso"`},
+		{consulta: `{ curso { nome } }`, erro: `Argument "codigo" of field "curso" is required`},
+		{consulta: `{ cursos(serie: 1) { nome } }`, erro: `Unknown argument "serie" on field "cursos"`},
+		{consulta: `{ cursos(codigo: "um") { nome } }`, erro: `Argument "codigo": um is not an Int`},
+		{consulta: `{ cursos }`, erro: `Field "cursos" of type "[Curso]" must have a selection of subfields`},
+		{consulta: `{ cursos { nome { x } } }`, erro: `Field "nome" must not have a selection since type "String" has no subfields`},
+		{consulta: `{ cursos { curso { curso { curso { nome } } } } }`, erro: "The query exceeds the maximum depth of 3"},
+		{consulta: `{ falha }`, erro: "Unable to find the document"},
+		{consulta: `{ cursos { nome @defer } }`, erro: "Unknown directive @defer"},
+		{consulta: `{ cursos { ...Nada } }`, erro: `Unknown fragment "Nada"`},
+		{consulta: `mutation { cursos { nome } }`, erro: "Only query operations are supported, not mutation", semDados: true},
+		{consulta: `query A { falha } query B { falha }`, erro: "An operation name is required", semDados: true},
+		{consulta: `query($c: Int!) { curso(codigo: $c) { nome } }`, erro: "Variable $c: a value of type Int! is required", semDados: true},
+		{consulta: `query($c: Int!) { curso(codigo: $c) { nome } }`, variaveis: map[string]interface{}{"c": 1.5},
+			erro: "Variable $c: 1.5 is not an Int", semDados: true},
+		{consulta: `{ cursos { nome `, erro: "Syntax error", semDados: true},
+	}
+	for _, caso := range casos {
+		dados, erros := executar(t, e, caso.consulta, caso.variaveis)
+		if len(erros) != 1 || !strings.Contains(erros[0].Mensagem, caso.erro) {
+			t.Errorf("%s: errors = %+v, want %q", caso.consulta, erros, caso.erro)
+		}
+		if (dados == "") != caso.semDados {
+			t.Errorf("%s: data = %q", caso.consulta, dados)
+		}
+	}
+
+	//o campo que falhou fica nulo e o erro aponta o caminho; os demais campos são resolvidos
+	dados, erros := executar(t, e, `{ falha um: curso(codigo: 1) { nome } }`, nil)
+	if dados != `{"falha":null,"um":{"nome":"Redes"}}` || len(erros) != 1 || len(erros[0].Caminho) != 1 || erros[0].Caminho[0] != "falha" {
+		t.Errorf("got %s, %+v", dados, erros)
+	}
+}
+
+// consultaIntrospeccao é a consulta do GraphiQL, com os tipos aninhados em nove níveis de ofType.
+const consultaIntrospeccao = `query IntrospectionQuery {
+	__schema {
+		queryType { name }
+		mutationType { name }
+		subscriptionType { name }
+		types { ...FullType }
+		directives { name description locations args { ...InputValue } }
+	}
+}
+fragment FullType on __Type {
+	kind name description
+	fields(includeDeprecated: true) {
+		name description args { ...InputValue } type { ...TypeRef } isDeprecated deprecationReason
+	}
+	inputFields { ...InputValue }
+	interfaces { ...TypeRef }
+	enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }
+	possibleTypes { ...TypeRef }
+}
+fragment InputValue on __InputValue { name description type { ...TypeRef } defaultValue }
+fragment TypeRef on __Type {
+	kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name
+	ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } } } } } }
+}`
+
+func TestIntrospeccao(t *testing.T) {
+	e := esquemaTeste(make(map[string]int))
+	dados, erros := executar(t, e, consultaIntrospeccao, nil)
+	if len(erros) > 0 {
+		t.Fatalf("errors: %+v", erros)
+	}
+	var resposta struct {
+		Schema struct {
+			QueryType    struct{ Name string }
+			MutationType *struct{ Name string }
+			Types        []struct {
+				Kind, Name  string
+				Description *string
+				Fields      []struct {
+					Name string
+					Args []struct{ Name string }
+					Type struct {
+						Kind   string
+						Name   *string
+						OfType *struct{ Kind, Name string }
+					}
+				}
+				EnumValues []struct{ Name string }
+			}
+			Directives []struct {
+				Name      string
+				Locations []string
+			}
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal([]byte(dados), &resposta); err != nil {
+		t.Fatal(err)
+	}
+	s := resposta.Schema
+	if s.QueryType.Name != "Consulta" || s.MutationType != nil {
+		t.Errorf("query type = %+v, mutation type = %+v", s.QueryType, s.MutationType)
+	}
+	tipos := make(map[string]string)
+	for _, tipo := range s.Types {
+		tipos[tipo.Name] = tipo.Kind
+		switch tipo.Name {
+		case "Consulta":
+			for _, c := range tipo.Fields {
+				if strings.HasPrefix(c.Name, "__") {
+					t.Errorf("the root type should not list the introspection field %s", c.Name)
+				}
+			}
+		case "Curso":
+			if tipo.Description == nil || *tipo.Description != "Curso oferecido" || len(tipo.Fields) != 4 {
+				t.Errorf("Curso = %+v", tipo)
+			}
+			for _, c := range tipo.Fields {
+				if c.Name == "disciplinas" && (c.Type.Kind != "LIST" || c.Type.OfType == nil || c.Type.OfType.Name != "Disciplina") {
+					t.Errorf("disciplinas has type %+v", c.Type)
+				}
+			}
+		case "__TypeKind":
+			if len(tipo.EnumValues) != 8 {
+				t.Errorf("__TypeKind values = %+v", tipo.EnumValues)
+			}
+		}
+	}
+	for nome, tipo := range map[string]string{"Consulta": "OBJECT", "Curso": "OBJECT", "Disciplina": "OBJECT", "Int": "SCALAR",
+		"String": "SCALAR", "Boolean": "SCALAR", "__Type": "OBJECT", "__TypeKind": "ENUM"} {
+		if tipos[nome] != tipo {
+			t.Errorf("type %s is %q, want %s", nome, tipos[nome], tipo)
+		}
+	}
+	if len(s.Directives) != 2 || s.Directives[0].Name != "include" || s.Directives[1].Name != "skip" {
+		t.Errorf("directives = %+v", s.Directives)
+	}
+
+	dados, erros = executar(t, e, `{ curso: __type(name: "Curso") { name kind fields { name type { kind ofType { name } } } }
+		nada: __type(name: "Nada") { name } }`, nil)
+	if len(erros) > 0 || !strings.Contains(dados, `{"name":"curso","type":{"kind":"OBJECT","ofType":null}}`) ||
+		!strings.HasSuffix(dados, `"nada":null}`) {
+		t.Errorf("got %s, %+v", dados, erros)
+	}
+
+	if sdl := e.SDL(); strings.Contains(sdl, "__") {
+		t.Errorf("the SDL should describe only the declared types:\n%s", sdl)
+	}
+}
@@ -0,0 +1,215 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// tiposIntrospeccao são os tipos que descrevem o próprio esquema, como na especificação. Os valores são
+// mapas montados uma vez por esquema (veja descrever), lidos pelos campos sem resolvedor.
+var tiposIntrospeccao = map[string]*Tipo{
+	"__Schema": {Campos: map[string]*Campo{
+		"description":      {Tipo: "String"},
+		"types":            {Tipo: "[__Type!]!"},
+		"queryType":        {Tipo: "__Type!"},
+		"mutationType":     {Tipo: "__Type"},
+		"subscriptionType": {Tipo: "__Type"},
+		"directives":       {Tipo: "[__Directive!]!"},
+	}},
+	"__Type": {Campos: map[string]*Campo{
+		"kind":           {Tipo: "__TypeKind!"},
+		"name":           {Tipo: "String"},
+		"description":    {Tipo: "String"},
+		"specifiedByURL": {Tipo: "String"},
+		"fields":         {Tipo: "[__Field!]", Argumentos: map[string]string{"includeDeprecated": "Boolean"}},
+		"interfaces":     {Tipo: "[__Type!]"},
+		"possibleTypes":  {Tipo: "[__Type!]"},
+		"enumValues":     {Tipo: "[__EnumValue!]", Argumentos: map[string]string{"includeDeprecated": "Boolean"}},
+		"inputFields":    {Tipo: "[__InputValue!]", Argumentos: map[string]string{"includeDeprecated": "Boolean"}},
+		"ofType":         {Tipo: "__Type"},
+		"isOneOf":        {Tipo: "Boolean"},
+	}},
+	"__Field": {Campos: map[string]*Campo{
+		"name":              {Tipo: "String!"},
+		"description":       {Tipo: "String"},
+		"args":              {Tipo: "[__InputValue!]!", Argumentos: map[string]string{"includeDeprecated": "Boolean"}},
+		"type":              {Tipo: "__Type!"},
+		"isDeprecated":      {Tipo: "Boolean!"},
+		"deprecationReason": {Tipo: "String"},
+	}},
+	"__InputValue": {Campos: map[string]*Campo{
+		"name":              {Tipo: "String!"},
+		"description":       {Tipo: "String"},
+		"type":              {Tipo: "__Type!"},
+		"defaultValue":      {Tipo: "String"},
+		"isDeprecated":      {Tipo: "Boolean!"},
+		"deprecationReason": {Tipo: "String"},
+	}},
+	"__EnumValue": {Campos: map[string]*Campo{
+		"name":              {Tipo: "String!"},
+		"description":       {Tipo: "String"},
+		"isDeprecated":      {Tipo: "Boolean!"},
+		"deprecationReason": {Tipo: "String"},
+	}},
+	"__Directive": {Campos: map[string]*Campo{
+		"name":         {Tipo: "String!"},
+		"description":  {Tipo: "String"},
+		"locations":    {Tipo: "[__DirectiveLocation!]!"},
+		"args":         {Tipo: "[__InputValue!]!", Argumentos: map[string]string{"includeDeprecated": "Boolean"}},
+		"isRepeatable": {Tipo: "Boolean!"},
+	}},
+}
+
+var enumsIntrospeccao = map[string][]string{
+	"__TypeKind": {"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"},
+	"__DirectiveLocation": {"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD",
+		"INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION",
+		"ARGUMENT_DEFINITION", "INTERFACE", "UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION"},
+}
+
+// executavel devolve o esquema usado na execução: o próprio esquema com os tipos de introspecção e os
+// campos __schema e __type no tipo raiz. O SDL continua descrevendo só os tipos declarados.
+func (e *Esquema) executavel() *Esquema {
+	e.once.Do(func() {
+		tipos := make(map[string]*Tipo, len(e.Tipos)+len(tiposIntrospeccao))
+		for nome, t := range e.Tipos {
+			tipos[nome] = t
+		}
+		for nome, t := range tiposIntrospeccao {
+			tipos[nome] = t
+		}
+		esquema, nomeados := e.descrever()
+		raiz := &Tipo{Descricao: e.Tipos[e.Consulta].Descricao, Campos: map[string]*Campo{
+			"__schema": {Tipo: "__Schema!", Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+				return repetir(esquema, len(pais)), nil
+			}},
+			"__type": {Tipo: "__Type", Argumentos: map[string]string{"name": "String!"},
+				Resolver: func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+					return repetir(nomeados[args["name"].(string)], len(pais)), nil
+				}},
+		}}
+		for nome, c := range e.Tipos[e.Consulta].Campos {
+			raiz.Campos[nome] = c
+		}
+		tipos[e.Consulta] = raiz
+		e.completo = &Esquema{Consulta: e.Consulta, Tipos: tipos, ProfundidadeMaxima: e.ProfundidadeMaxima}
+	})
+	return e.completo
+}
+
+func repetir(valor map[string]interface{}, n int) []interface{} {
+	valores := make([]interface{}, n)
+	for i := range valores {
+		valores[i] = valor
+	}
+	return valores
+}
+
+// descrever monta o __Schema e a descrição de cada tipo nomeado no formato de __Type. Os tipos se
+// referenciam pelos mesmos mapas, então a descrição é montada uma única vez.
+func (e *Esquema) descrever() (map[string]interface{}, map[string]map[string]interface{}) {
+	objetos := make(map[string]*Tipo, len(e.Tipos)+len(tiposIntrospeccao))
+	for nome, t := range e.Tipos {
+		objetos[nome] = t
+	}
+	for nome, t := range tiposIntrospeccao {
+		objetos[nome] = t
+	}
+	nomeados := make(map[string]map[string]interface{})
+	escalares := map[string]bool{"String": true, "Boolean": true} //usados pela introspecção e pelas diretivas
+	for _, t := range objetos {
+		for _, c := range t.Campos {
+			escalares[strings.Trim(c.Tipo, "[]!")] = true
+			for _, tipo := range c.Argumentos {
+				escalares[strings.Trim(tipo, "[]!")] = true
+			}
+		}
+	}
+	for nome := range escalares {
+		if _, objeto := objetos[nome]; !objeto && enumsIntrospeccao[nome] == nil {
+			nomeados[nome] = map[string]interface{}{"kind": "SCALAR", "name": nome}
+		}
+	}
+	for nome, valores := range enumsIntrospeccao {
+		enum := make([]interface{}, len(valores))
+		for i, v := range valores {
+			enum[i] = map[string]interface{}{"name": v, "isDeprecated": false}
+		}
+		nomeados[nome] = map[string]interface{}{"kind": "ENUM", "name": nome, "enumValues": enum}
+	}
+	for nome, t := range objetos {
+		nomeados[nome] = map[string]interface{}{"kind": "OBJECT", "name": nome, "description": descricao(t.Descricao),
+			"interfaces": []interface{}{}}
+	}
+
+	//com todos os tipos criados, os campos já podem apontar para eles
+	referencia := func(tipo string) map[string]interface{} { return referenciaTipo(nomeados, tipo) }
+	argumentos := func(args map[string]string) []interface{} {
+		lista := []interface{}{}
+		for _, nome := range argumentosOrdenados(args) {
+			lista = append(lista, map[string]interface{}{"name": nome, "type": referencia(args[nome]), "isDeprecated": false})
+		}
+		return lista
+	}
+	for nome, t := range objetos {
+		campos := make([]string, 0, len(t.Campos))
+		for n := range t.Campos {
+			campos = append(campos, n)
+		}
+		sort.Strings(campos)
+		lista := []interface{}{}
+		for _, n := range campos {
+			c := t.Campos[n]
+			lista = append(lista, map[string]interface{}{"name": n, "description": descricao(c.Descricao),
+				"args": argumentos(c.Argumentos), "type": referencia(c.Tipo), "isDeprecated": false})
+		}
+		nomeados[nome]["fields"] = lista
+	}
+
+	tipos := make([]string, 0, len(nomeados))
+	for nome := range nomeados {
+		tipos = append(tipos, nome)
+	}
+	sort.Strings(tipos)
+	lista := make([]interface{}, len(tipos))
+	for i, nome := range tipos {
+		lista[i] = nomeados[nome]
+	}
+	var diretivas []interface{}
+	for _, nome := range []string{"include", "skip"} {
+		diretivas = append(diretivas, map[string]interface{}{"name": nome, "isRepeatable": false,
+			"locations": []interface{}{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+			"args":      argumentos(map[string]string{"if": "Boolean!"})})
+	}
+	esquema := map[string]interface{}{"types": lista, "queryType": nomeados[e.Consulta], "directives": diretivas}
+	return esquema, nomeados
+}
+
+// referenciaTipo descreve um tipo escrito na notação do GraphQL, com LIST e NON_NULL em volta do tipo
+// nomeado.
+func referenciaTipo(nomeados map[string]map[string]interface{}, tipo string) map[string]interface{} {
+	switch {
+	case strings.HasSuffix(tipo, "!"):
+		return map[string]interface{}{"kind": "NON_NULL", "ofType": referenciaTipo(nomeados, strings.TrimSuffix(tipo, "!"))}
+	case strings.HasPrefix(tipo, "["):
+		return map[string]interface{}{"kind": "LIST", "ofType": referenciaTipo(nomeados, tipo[1:len(tipo)-1])}
+	}
+	return nomeados[tipo]
+}
+
+func descricao(texto string) interface{} {
+	if texto == "" {
+		return nil
+	}
+	return texto
+}
+
+func argumentosOrdenados(m map[string]string) []string {
+	chaves := make([]string, 0, len(m))
+	for k := range m {
+		chaves = append(chaves, k)
+	}
+	sort.Strings(chaves)
+	return chaves
+}
@@ -6,41 +6,41 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 
+	"github.com/krunal4amity/tronicscorp/auth"
 	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/problema"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type Alunos struct {
-	ID primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"` /*onitempty serve para caso o
-	campo não tenha sido preenchido, não haverá nenhum valor padrão, será ignorado*/
-	Matricula int    `json:"matricula" bson:"matricula" validate:"required"`
-	Nome      string `json:"nome" bson:"nome" validate:"required,max=20"`
-	Sobrenome string `json:"sobrenome" bson:"sobrenome" validate:"required,max=20"`
-	Telefone  int    `json:"telefone" bson:"telefone" validate:"required,max=10"`
-}
+type Alunos = modelos.Alunos
 
 type AlunosHandler struct {
-	Col dbiface.Collection
+	Col         dbiface.Collection
+	Matriculas  *GeradorNumero
+	Regras      *Regras
+	Auditoria   *Auditor
+	Versoes     *Versionador
+	Importacoes *Importador
+}
+
+func (h *AlunosHandler) historico() historico {
+	return historico{recurso: "alunos", auditoria: h.Auditoria, versoes: h.Versoes}
 }
 
-func buscarAlunos(ctx context.Context, q url.Values, collection dbiface.Collection) ([]Alunos, *echo.HTTPError) {
+func buscarAlunos(ctx context.Context, q url.Values, collection dbiface.Collection, lixeira bool, claims *auth.Claims) ([]Alunos, *echo.HTTPError) {
 	var alunos []Alunos
-	filter := make(map[string]interface{})
-	for k, v := range q {
-		filter[k] = v[0]
-	}
-	if filter["_id"] != nil { //convertendo o id, que no filter é um string, para um primitiveObjectID
-		docID, err := primitive.ObjectIDFromHex(filter["_id"].(string))
-		if err != nil {
-			return alunos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
-		}
-		filter["_id"] = docID
+	filter, opts, herr := consultaListagem(q, lixeira, Alunos{}, claims)
+	if herr != nil {
+		return alunos, herr
 	}
-	cursor, err := collection.Find(ctx, bson.M(filter)) /*filter, que corresponde ao q url.Values, está sendo
+	cursor, err := collection.Find(ctx, filter, opts) /*filter, que corresponde ao q url.Values, está sendo
 	convertido para bson. Filter é argumento do método Find e está sendo definido logo acima*/
 	if err != nil {
 		log.Errorf("Unable to find the student: %v", err)
@@ -49,29 +49,34 @@ func buscarAlunos(ctx context.Context, q url.Values, collection dbiface.Collecti
 	err = cursor.All(ctx, &alunos)
 	if err != nil {
 		log.Errorf("Unable to read the cursor: %v", err)
-		return alunos, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return alunos, problema.CorpoInvalido().HTTP()
 	}
 	return alunos, nil
 }
 
 func (h *AlunosHandler) BuscarAlunos(c echo.Context) error {
-	alunos, err := buscarAlunos(context.Background(), c.QueryParams(), h.Col) /*c.QueryParams() para especificar
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "alunos", h.Col, Alunos{}, false)
+	}
+	alunos, err := buscarAlunos(context.Background(), c.QueryParams(), h.Col, false, claimsDaRequisicao(c)) /*c.QueryParams() para especificar
 	consultas, por exemplo, caso não queira fazer um GET de todos os produtos, mas apenas de um produto com um
 	determinado nome*/
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, alunos)
+	return c.JSON(http.StatusOK, projetar(c, mascarar(c, alunos)))
 }
 
 func buscarAluno(ctx context.Context, id string, collection dbiface.Collection) (Alunos, *echo.HTTPError) {
 	var alunos Alunos
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return alunos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return alunos, problema.IDInvalido().HTTP()
 	}
-	filter := bson.M{"_id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	err = res.Decode(&alunos)
 	if err != nil {
@@ -81,53 +86,103 @@ func buscarAluno(ctx context.Context, id string, collection dbiface.Collection)
 }
 
 func (h *AlunosHandler) BuscarAluno(c echo.Context) error {
+	if asOf := c.QueryParam("asOf"); asOf != "" { //estado do documento em uma data passada
+		var alunos Alunos
+		if err := h.Versoes.versaoEm(context.Background(), "alunos", c.Param("id"), asOf, &alunos); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, mascarar(c, alunos))
+	}
 	alunos, err := buscarAluno(context.Background(), c.Param("id"), h.Col)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, alunos)
+	return c.JSON(http.StatusOK, mascarar(c, alunos))
 }
 
-func inserirAluno(ctx context.Context, alunos []Alunos, collection dbiface.Collection) ([]interface{}, *echo.HTTPError) {
+func inserirAluno(ctx context.Context, ev Evento, alunos []Alunos, collection dbiface.Collection, matriculas *GeradorNumero, regras *Regras, hist historico) ([]interface{}, *echo.HTTPError) {
 	var insertedIds []interface{}
-	for _, aluno := range alunos {
+	for i := range alunos {
+		alunos[i].AnonimizadoEm = nil //só a anonimização marca o aluno
+	}
+	if err := validarLista(alunos); err != nil { //nenhum aluno é gravado se algum for inválido
+		return insertedIds, err
+	}
+	liberar, herr := regras.travar(ctx, "alunos", alunos)
+	if herr != nil {
+		return insertedIds, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificarLista(ctx, "alunos", alunos); err != nil {
+		return insertedIds, err
+	}
+	for i, aluno := range alunos {
 		aluno.ID = primitive.NewObjectID()
+		if aluno.Matricula == 0 && matriculas != nil { //matrícula explícita é mantida (importação de legado)
+			matricula, err := matriculas.Gerar(ctx, aluno.Curso)
+			if err != nil {
+				log.Errorf("Unable to generate the matricula: %v", err)
+				return insertedIds, echo.NewHTTPError(http.StatusInternalServerError, "Unable to generate the matricula")
+			}
+			aluno.Matricula = matricula
+		}
 		insertID, err := collection.InsertOne(ctx, aluno)
 		if err != nil {
 			log.Errorf("Unable to insert :%v", err)
-			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
+			return insertedIds, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
 		}
 		insertedIds = append(insertedIds, insertID.InsertedID)
+		alunos[i] = aluno //ID e números gerados ficam disponíveis para quem chamou
+		hist.registrar(ctx, ev, OperacaoInsercao, aluno.ID, nil, aluno)
 	}
 	return insertedIds, nil
 }
 
 func (h *AlunosHandler) InserirAluno(c echo.Context) error {
 	var alunos []Alunos
-	c.Echo().Validator = &AlunosValidator{validator: v}
 	if err := c.Bind(&alunos); err != nil {
 		log.Errorf("Unable to bind: %v", err)
-		return c.JSON(http.StatusBadRequest, "Unable to parse request payload")
+		return problema.CorpoInvalido().HTTP()
 	}
-	IDs, err := inserirAluno(context.Background(), alunos, h.Col)
+	IDs, err := inserirAluno(context.Background(), eventoDaRequisicao(c), alunos, h.Col, h.Matriculas, h.Regras, h.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusCreated, IDs)
 }
 
-func alterarAluno(ctx context.Context, id string, reqBody io.ReadCloser, collection dbiface.Collection) (Alunos, *echo.HTTPError) {
+// ImportarAlunos inclui os alunos de uma planilha CSV ou XLSX pelo mesmo caminho do POST /alunos, com
+// a matrícula gerada quando a coluna não vier preenchida.
+func (h *AlunosHandler) ImportarAlunos(c echo.Context) error {
+	return h.Importacoes.importar(c, importavel{recurso: "alunos", tipo: reflect.TypeOf(Alunos{}),
+		regras: h.Regras,
+		inserir: func(ctx context.Context, ev Evento, doc interface{}) (interface{}, *echo.HTTPError) {
+			alunos := []Alunos{doc.(Alunos)}
+			_, err := inserirAluno(ctx, ev, alunos, h.Col, h.Matriculas, h.Regras, h.historico())
+			return alunos[0], err
+		}})
+}
+
+func (h *AlunosHandler) BuscarImportacao(c echo.Context) error {
+	return h.Importacoes.buscar(c, "alunos")
+}
+
+func alterarAluno(ctx context.Context, ev Evento, id string, reqBody io.ReadCloser, collection dbiface.Collection, regras *Regras, hist historico) (Alunos, *echo.HTTPError) {
 	var alunos Alunos
+	antes, herr := buscarAluno(ctx, id, collection) //cópia própria, já que o corpo é decodificado sobre alunos
+	if herr != nil {
+		return alunos, herr
+	}
 
 	//convertendo o id, que é um string, para primitive.ObjectID
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		log.Errorf("Cannot convert to ObjectID: %v", err)
-		return alunos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return alunos, problema.IDInvalido().HTTP()
 	}
 
 	//procurar se o aluno existe, caso contrário erro 404 (Not Found)
-	filter := bson.M{"_id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	if err := res.Decode(&alunos); err != nil {
 		log.Errorf("Unable to decode to student: %v", err)
@@ -135,12 +190,29 @@ func alterarAluno(ctx context.Context, id string, reqBody io.ReadCloser, collect
 	} /*todos os valores, obtidos do método FindOne e atribuídos para res, são decodificados para alunos,
 	ou seja, alunos está sendo populado*/
 
+	anonimizado := alunos.AnonimizadoEm
+
 	//JSON decodificação do reqBody
 	if err := json.NewDecoder(reqBody).Decode(&alunos); err != nil {
 		log.Errorf("Unable to decode using reqBody: %v", err)
-		return alunos, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return alunos, problema.CorpoInvalido().HTTP()
 	} /*ler o reqBody e usar as informações inseridas para popular os campos de alunos, que "representa" a
 	struct Alunos*/
+	alunos.DeletadoEm = nil            //a lixeira só é alterada pelas rotas de DELETE e restaurar
+	alunos.AnonimizadoEm = anonimizado //e a marca da anonimização, só pela LGPD
+
+	//validação da requisição
+	if err := validar(alunos); err != nil {
+		return alunos, err
+	}
+	liberar, herr := regras.travar(ctx, "alunos", alunos)
+	if herr != nil {
+		return alunos, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificar(ctx, "alunos", alunos); err != nil {
+		return alunos, err
+	}
 
 	//atualização do aluno
 	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": alunos}) /* _, err, pois UpdateOne possui 2
@@ -149,35 +221,71 @@ func alterarAluno(ctx context.Context, id string, reqBody io.ReadCloser, collect
 		log.Errorf("Unable to update the student: %v", err)
 		return alunos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the student")
 	}
+	hist.registrar(ctx, ev, OperacaoAlteracao, antes.ID, antes, alunos)
 	return alunos, nil
 }
 
 func (h *AlunosHandler) AtualizarAluno(c echo.Context) error {
-	alunos, err := alterarAluno(context.Background(), c.Param("id"), c.Request().Body, h.Col) /*criando o
-	método alterarAluno*/
+	if err := negociacao.CorpoJSON(c, Alunos{}); err != nil { //corpos em XML ou MessagePack seguem como JSON
+		return err
+	}
+	alunos, err := alterarAluno(context.Background(), eventoDaRequisicao(c), c.Param("id"), c.Request().Body, h.Col,
+		h.Regras, h.historico()) /*criando o método alterarAluno*/
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, alunos)
+	return c.JSON(http.StatusOK, mascarar(c, alunos))
 }
 
-func deletarAluno(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
-	docID, err := primitive.ObjectIDFromHex(id)
+func deletarAluno(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	antes, err := buscarAluno(ctx, id, collection)
 	if err != nil {
-		log.Errorf("Unable to delete the student: %v", err)
-		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return 0, err
 	}
-	res, err := collection.DeleteOne(ctx, bson.M{"_id": docID})
+	total, err := moverParaLixeira(ctx, id, collection) //o aluno vai para a lixeira em vez de ser removido
 	if err != nil {
-		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to delete the student")
+		return 0, err
 	}
-	return res.DeletedCount, nil
+	hist.registrar(ctx, ev, OperacaoExclusao, antes.ID, antes, nil)
+	return total, nil
 }
 
 func (h *AlunosHandler) DeletarAluno(c echo.Context) error {
-	delCount, err := deletarAluno(context.Background(), c.Param("id"), h.Col)
+	delCount, err := deletarAluno(context.Background(), eventoDaRequisicao(c), c.Param("id"), h.Col, h.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, delCount)
 }
+
+func (h *AlunosHandler) BuscarLixeira(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "alunos", h.Col, Alunos{}, true)
+	}
+	alunos, err := buscarAlunos(context.Background(), c.QueryParams(), h.Col, true, claimsDaRequisicao(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projetar(c, mascarar(c, alunos)))
+}
+
+func restaurarAluno(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	total, err := restaurarDaLixeira(ctx, id, collection)
+	if err != nil {
+		return 0, err
+	}
+	if depois, err := buscarAluno(ctx, id, collection); err == nil {
+		hist.registrar(ctx, ev, OperacaoRestauracao, depois.ID, nil, depois)
+	}
+	return total, nil
+}
+
+func (h *AlunosHandler) RestaurarAluno(c echo.Context) error {
+	count, err := restaurarAluno(context.Background(), eventoDaRequisicao(c), c.Param("id"), h.Col, h.historico())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, count)
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	OperacaoInsercao    = "insert"
+	OperacaoAlteracao   = "update"
+	OperacaoExclusao    = "delete"
+	OperacaoRestauracao = "restore"
+	OperacaoPurga       = "purge" //remoção definitiva da lixeira, feita pelo servidor
+)
+
+// Auditoria é uma entrada do histórico de escritas. A coleção é somente de inserção: nenhuma rota altera ou
+// remove entradas.
+type Auditoria = modelos.Auditoria
+
+type Alteracao = modelos.Alteracao
+
+// Evento identifica quem fez a escrita e em qual requisição.
+type Evento struct {
+	Ator      string
+	IP        string
+	RequestID string
+}
+
+// EventoSistema identifica as escritas feitas pelo próprio servidor, fora de uma requisição.
+var EventoSistema = Evento{Ator: "sistema"}
+
+func eventoDaRequisicao(c echo.Context) Evento {
+	ator, _ := c.Get(auth.ChaveAtor).(string)
+	if ator == "" {
+		ator = "anonimo"
+	}
+	return Evento{
+		Ator:      ator,
+		IP:        c.RealIP(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+}
+
+type Auditor struct {
+	Col dbiface.Collection
+}
+
+// Registrar grava uma entrada de auditoria. Um Auditor nil não registra nada, e uma falha na gravação é
+// apenas logada, já que a escrita auditada já foi concluída.
+func (a *Auditor) Registrar(ctx context.Context, ev Evento, recurso, operacao string, id primitive.ObjectID, antes, depois interface{}) {
+	if a == nil {
+		return
+	}
+	entrada := Auditoria{
+		ID:          primitive.NewObjectID(),
+		Recurso:     recurso,
+		DocumentoID: id,
+		Operacao:    operacao,
+		Ator:        ev.Ator,
+		IP:          ev.IP,
+		RequestID:   ev.RequestID,
+		Data:        time.Now(),
+		Antes:       paraDocumento(antes),
+		Depois:      paraDocumento(depois),
+	}
+	entrada.Alteracoes = diferencas(entrada.Antes, entrada.Depois)
+	if _, err := a.Col.InsertOne(ctx, entrada); err != nil {
+		log.Errorf("Unable to record the audit entry for %s %s %+v: %v", recurso, id.Hex(), ParaLog(depois), err)
+	}
+}
+
+// historico registra as escritas de um recurso do cadastro na auditoria e nas versões. As funções de
+// escrita compartilhadas pelo REST e pelo gRPC o recebem, para que os dois registrem igual.
+type historico struct {
+	recurso   string
+	auditoria *Auditor
+	versoes   *Versionador
+}
+
+// registrar grava a entrada de auditoria e a versão resultante; na exclusão, a versão guarda o documento
+// excluído.
+func (h historico) registrar(ctx context.Context, ev Evento, operacao string, id primitive.ObjectID, antes, depois interface{}) {
+	h.auditoria.Registrar(ctx, ev, h.recurso, operacao, id, antes, depois)
+	if operacao == OperacaoExclusao {
+		depois = antes
+	}
+	h.versoes.Registrar(ctx, ev, h.recurso, operacao, id, depois)
+}
+
+// paraDocumento converte a struct do recurso para bson.M usando as mesmas tags gravadas no banco.
+func paraDocumento(v interface{}) bson.M {
+	if v == nil {
+		return nil
+	}
+	data, err := bson.Marshal(v)
+	if err != nil {
+		log.Errorf("Unable to marshal the audited document: %v", err)
+		return nil
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		log.Errorf("Unable to unmarshal the audited document: %v", err)
+		return nil
+	}
+	return doc
+}
+
+func diferencas(antes, depois bson.M) []Alteracao {
+	campos := make(map[string]bool)
+	for k := range antes {
+		campos[k] = true
+	}
+	for k := range depois {
+		campos[k] = true
+	}
+	var nomes []string
+	for k := range campos {
+		if k != "_id" {
+			nomes = append(nomes, k)
+		}
+	}
+	sort.Strings(nomes)
+
+	var alteracoes []Alteracao
+	for _, k := range nomes {
+		if !reflect.DeepEqual(antes[k], depois[k]) {
+			alteracoes = append(alteracoes, Alteracao{Campo: k, Antes: antes[k], Depois: depois[k]})
+		}
+	}
+	return alteracoes
+}
+
+// opcoesAuditoria ordena da escrita mais recente para a mais antiga; limite zero devolve todas.
+func opcoesAuditoria(limite int64) *options.FindOptions {
+	return options.Find().SetSort(bson.M{"data": -1}).SetLimit(limite)
+}
+
+// exportar exporta as entradas do filtro mascaradas como na resposta JSON.
+func (a *Auditor) exportar(c echo.Context, formato, nome string, filter bson.M, limite int64) error {
+	claims := claimsDaRequisicao(c)
+	return exportarListagem(c, formato, exportacao{nome: nome, col: a.Col, filter: filter, opts: opcoesAuditoria(limite),
+		modelo: Auditoria{}, tratar: func(doc interface{}) interface{} {
+			entradas := []Auditoria{*doc.(*Auditoria)}
+			mascararAuditoria(entradas, claims)
+			return entradas[0]
+		}})
+}
+
+func buscarAuditoria(ctx context.Context, filter bson.M, limite int64, collection dbiface.Collection) ([]Auditoria, *echo.HTTPError) {
+	var entradas []Auditoria
+	cursor, err := collection.Find(ctx, filter, opcoesAuditoria(limite))
+	if err != nil {
+		log.Errorf("Unable to find the audit entries: %v", err)
+		return entradas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to find the audit entries")
+	}
+	if err := cursor.All(ctx, &entradas); err != nil {
+		log.Errorf("Unable to read the cursor: %v", err)
+		return entradas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to read the audit entries")
+	}
+	return entradas, nil
+}
+
+// HistoricoAlteracoes atende GET /<recurso>/:id/historico-alteracoes.
+func (a *Auditor) HistoricoAlteracoes(recurso string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		docID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			return problema.IDInvalido().HTTP()
+		}
+		filter := bson.M{"recurso": recurso, "documentoId": docID}
+		if formato, herr := formatoExportacao(c); herr != nil {
+			return herr
+		} else if formato != "" {
+			return a.exportar(c, formato, recurso+"-"+docID.Hex()+"-historico-alteracoes", filter, 0)
+		}
+		entradas, herr := buscarAuditoria(context.Background(), filter, 0, a.Col)
+		if herr != nil {
+			return herr
+		}
+		mascararAuditoria(entradas, claimsDaRequisicao(c))
+		return c.JSON(http.StatusOK, entradas)
+	}
+}
+
+// BuscarAuditoria atende a consulta global: GET /auditoria?recurso=&documentoId=&ator=&operacao=&requestId=&de=&ate=&limite=
+func (a *Auditor) BuscarAuditoria(c echo.Context) error {
+	formato, herr := formatoExportacao(c)
+	if herr != nil {
+		return herr
+	}
+	filter := bson.M{}
+	for _, campo := range []string{"recurso", "ator", "operacao", "requestId"} {
+		if valor := c.QueryParam(campo); valor != "" {
+			filter[campo] = valor
+		}
+	}
+	if id := c.QueryParam("documentoId"); id != "" {
+		docID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return problema.IDInvalido().HTTP()
+		}
+		filter["documentoId"] = docID
+	}
+	periodo := bson.M{}
+	for param, operador := range map[string]string{"de": "$gte", "ate": "$lte"} {
+		if valor := c.QueryParam(param); valor != "" {
+			data, err := time.Parse(time.RFC3339, valor)
+			if err != nil {
+				return problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "Dates must use the RFC 3339 format").HTTP()
+			}
+			periodo[operador] = data
+		}
+	}
+	if len(periodo) > 0 {
+		filter["data"] = periodo
+	}
+	limite := int64(100)
+	if valor := c.QueryParam("limite"); valor != "" {
+		n, err := strconv.ParseInt(valor, 10, 64)
+		if err != nil || n <= 0 {
+			return problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "limite must be a positive number").HTTP()
+		}
+		limite = n
+	}
+	if formato != "" {
+		return a.exportar(c, formato, "auditoria", filter, limite)
+	}
+	entradas, err := buscarAuditoria(context.Background(), filter, limite, a.Col)
+	if err != nil {
+		return err
+	}
+	mascararAuditoria(entradas, claimsDaRequisicao(c))
+	return c.JSON(http.StatusOK, entradas)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecursosComEscopo são os recursos que uma chave de API pode receber, como "read:alunos" ou "write:cursos".
+// Contas, chaves, auditoria (inclusive o histórico e as versões de cada documento) e lixeira ficam de fora:
+// só são acessíveis com login. "read:graphql" libera o /graphql, que ainda exige o escopo de leitura de cada
+// recurso consultado.
+var RecursosComEscopo = []string{"alunos", "professores", "cursos", "disciplinas", "graphql"}
+
+// ChavesAPI guarda apenas o sha256 da chave; o valor completo é mostrado uma única vez, na criação.
+type ChavesAPI struct {
+	ID              primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Nome            string             `json:"nome" bson:"nome" validate:"required,max=100"`
+	Prefixo         string             `json:"prefixo" bson:"prefixo"` //início da chave, para identificá-la nas listagens
+	Hash            string             `json:"-" bson:"hash"`
+	Escopos         []string           `json:"escopos" bson:"escopos" validate:"required,min=1"`
+	LimitePorMinuto int                `json:"limitePorMinuto" bson:"limitePorMinuto" validate:"min=0"`
+	ExpiraEm        *time.Time         `json:"expiraEm,omitempty" bson:"expiraEm,omitempty"`
+	CriadaPor       string             `json:"criadaPor" bson:"criadaPor"`
+	CriadaEm        time.Time          `json:"criadaEm" bson:"criadaEm"`
+	UltimoUso       *time.Time         `json:"ultimoUso,omitempty" bson:"ultimoUso,omitempty"`
+	DeletadoEm      *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+// semHash remove o hash antes de gravar a chave na auditoria.
+func (k ChavesAPI) semHash() ChavesAPI {
+	k.Hash = ""
+	return k
+}
+
+type ChavesAPIHandler struct {
+	Col          dbiface.Collection
+	Auditoria    *Auditor
+	LimitePadrao int //requisições por minuto de chaves criadas sem limitePorMinuto
+}
+
+func hashChave(chave string) string {
+	soma := sha256.Sum256([]byte(chave))
+	return hex.EncodeToString(soma[:])
+}
+
+func validarEscopos(escopos []string) error {
+	for _, escopo := range escopos {
+		valido := false
+		for _, recurso := range RecursosComEscopo {
+			if escopo == "read:"+recurso || escopo == "write:"+recurso {
+				valido = true
+			}
+		}
+		if !valido {
+			return fmt.Errorf("unknown scope %q", escopo)
+		}
+	}
+	return nil
+}
+
+// VerificarChave implementa auth.VerificadorChaves. O último uso é gravado no máximo uma vez por minuto.
+func (kh *ChavesAPIHandler) VerificarChave(ctx context.Context, valor string) (auth.ChaveAPI, error) {
+	var chave ChavesAPI
+	if err := kh.Col.FindOne(ctx, filtroAtivos(bson.M{"hash": hashChave(valor)})).Decode(&chave); err != nil {
+		return auth.ChaveAPI{}, auth.ErrChaveInvalida
+	}
+	agora := time.Now()
+	if chave.ExpiraEm != nil && chave.ExpiraEm.Before(agora) {
+		return auth.ChaveAPI{}, auth.ErrChaveInvalida
+	}
+	if chave.UltimoUso == nil || agora.Sub(*chave.UltimoUso) > time.Minute {
+		if _, err := kh.Col.UpdateOne(ctx, bson.M{"_id": chave.ID}, bson.M{"$set": bson.M{"ultimoUso": agora}}); err != nil {
+			log.Errorf("Unable to record the API key usage: %v", err)
+		}
+	}
+	return auth.ChaveAPI{
+		ID:              chave.ID.Hex(),
+		Nome:            chave.Nome,
+		Escopos:         chave.Escopos,
+		LimitePorMinuto: chave.LimitePorMinuto,
+	}, nil
+}
+
+// ChaveCriada é a resposta de POST /chaves-api, a única que traz a chave em claro.
+type ChaveCriada struct {
+	ChavesAPI
+	Chave string `json:"chave"`
+}
+
+// InserirChave atende POST /chaves-api e devolve a chave em claro, que não pode ser consultada depois.
+func (kh *ChavesAPIHandler) InserirChave(c echo.Context) error {
+	var chave ChavesAPI
+	if err := c.Bind(&chave); err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	if chave.LimitePorMinuto == 0 {
+		chave.LimitePorMinuto = kh.LimitePadrao
+	}
+	if err := v.Struct(chave); err != nil {
+		log.Errorf("Unable to validate the struct: %v", err)
+		return problema.Validacao(err).HTTP()
+	}
+	if err := validarEscopos(chave.Escopos); err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoEscopoDesconhecido, err.Error()).HTTP()
+	}
+	segredo := make([]byte, 24)
+	if _, err := rand.Read(segredo); err != nil {
+		log.Errorf("Unable to generate the API key: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to create the API key")
+	}
+	valor := "ss_" + hex.EncodeToString(segredo)
+	ev := eventoDaRequisicao(c)
+	chave.ID = primitive.NewObjectID()
+	chave.Prefixo = valor[:11]
+	chave.Hash = hashChave(valor)
+	chave.CriadaPor = ev.Ator
+	chave.CriadaEm = time.Now()
+	chave.UltimoUso, chave.DeletadoEm = nil, nil
+	if _, err := kh.Col.InsertOne(context.Background(), chave); err != nil {
+		log.Errorf("Unable to insert: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
+	}
+	kh.Auditoria.Registrar(context.Background(), ev, "chaves-api", OperacaoInsercao, chave.ID, nil, chave.semHash())
+	return c.JSON(http.StatusCreated, ChaveCriada{ChavesAPI: chave, Chave: valor})
+}
+
+func (kh *ChavesAPIHandler) BuscarChaves(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarListagem(c, formato, exportacao{nome: "chaves-api", col: kh.Col, filter: filtroAtivos(bson.M{}),
+			modelo: ChavesAPI{}})
+	}
+	var chaves []ChavesAPI
+	cursor, err := kh.Col.Find(context.Background(), filtroAtivos(bson.M{}))
+	if err != nil {
+		log.Errorf("Unable to find the API keys: %v", err)
+		return echo.NewHTTPError(http.StatusNotFound, "Unable to find the API keys")
+	}
+	if err := cursor.All(context.Background(), &chaves); err != nil {
+		log.Errorf("Unable to read the cursor: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	return c.JSON(http.StatusOK, chaves)
+}
+
+func buscarChave(ctx context.Context, id string, collection dbiface.Collection) (ChavesAPI, *echo.HTTPError) {
+	var chave ChavesAPI
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return chave, problema.IDInvalido().HTTP()
+	}
+	if err := collection.FindOne(ctx, filtroAtivos(bson.M{"_id": docID})).Decode(&chave); err != nil {
+		return chave, echo.NewHTTPError(http.StatusNotFound, "Unable to find the API key")
+	}
+	return chave, nil
+}
+
+func (kh *ChavesAPIHandler) BuscarChave(c echo.Context) error {
+	chave, err := buscarChave(context.Background(), c.Param("id"), kh.Col)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, chave)
+}
+
+// AtualizarChave altera nome, escopos, limite e expiração. O valor da chave não muda.
+func (kh *ChavesAPIHandler) AtualizarChave(c echo.Context) error {
+	ctx := context.Background()
+	antes, err := buscarChave(ctx, c.Param("id"), kh.Col)
+	if err != nil {
+		return err
+	}
+	depois := antes
+	if err := c.Bind(&depois); err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	depois.ID, depois.Prefixo, depois.Hash = antes.ID, antes.Prefixo, antes.Hash
+	depois.CriadaPor, depois.CriadaEm, depois.UltimoUso = antes.CriadaPor, antes.CriadaEm, antes.UltimoUso
+	if err := v.Struct(depois); err != nil {
+		log.Errorf("Unable to validate the struct: %v", err)
+		return problema.Validacao(err).HTTP()
+	}
+	if err := validarEscopos(depois.Escopos); err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoEscopoDesconhecido, err.Error()).HTTP()
+	}
+	update := bson.M{"$set": bson.M{
+		"nome":            depois.Nome,
+		"escopos":         depois.Escopos,
+		"limitePorMinuto": depois.LimitePorMinuto,
+		"expiraEm":        depois.ExpiraEm,
+	}}
+	if _, err := kh.Col.UpdateOne(ctx, bson.M{"_id": antes.ID}, update); err != nil {
+		log.Errorf("Unable to update the API key: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the API key")
+	}
+	kh.Auditoria.Registrar(ctx, eventoDaRequisicao(c), "chaves-api", OperacaoAlteracao, antes.ID, antes.semHash(), depois.semHash())
+	return c.JSON(http.StatusOK, depois)
+}
+
+// DeletarChave revoga a chave; ela deixa de autenticar imediatamente.
+func (kh *ChavesAPIHandler) DeletarChave(c echo.Context) error {
+	antes, err := buscarChave(context.Background(), c.Param("id"), kh.Col)
+	if err != nil {
+		return err
+	}
+	del, err := moverParaLixeira(context.Background(), c.Param("id"), kh.Col)
+	if err != nil {
+		return err
+	}
+	kh.Auditoria.Registrar(context.Background(), eventoDaRequisicao(c), "chaves-api", OperacaoExclusao, antes.ID, antes.semHash(), nil)
+	return c.JSON(http.StatusOK, del)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestVerificarChave(t *testing.T) {
+	ontem := time.Now().Add(-24 * time.Hour)
+	ativa := ChavesAPI{ID: primitive.NewObjectID(), Nome: "portal", Hash: hashChave("ss_ativa"), Escopos: []string{"read:alunos"}, LimitePorMinuto: 60}
+	expirada := ChavesAPI{ID: primitive.NewObjectID(), Nome: "antiga", Hash: hashChave("ss_expirada"), Escopos: []string{"read:alunos"}, ExpiraEm: &ontem}
+	revogada := ChavesAPI{ID: primitive.NewObjectID(), Nome: "revogada", Hash: hashChave("ss_revogada"), Escopos: []string{"read:alunos"}, DeletadoEm: &ontem}
+	col := novaColecao(ativa, expirada, revogada)
+	kh := &ChavesAPIHandler{Col: col}
+	ctx := context.Background()
+
+	chave, err := kh.VerificarChave(ctx, "ss_ativa")
+	if err != nil || chave.ID != ativa.ID.Hex() || chave.LimitePorMinuto != 60 {
+		t.Fatalf("got %+v, %v", chave, err)
+	}
+	if col.buscarID(ativa.ID)["ultimoUso"] == nil {
+		t.Error("the last use should be recorded")
+	}
+	//dentro do mesmo minuto o uso não é gravado de novo
+	escritas := col.escritas
+	kh.VerificarChave(ctx, "ss_ativa")
+	if col.escritas != escritas {
+		t.Error("the last use was written twice in the same minute")
+	}
+	for _, valor := range []string{"ss_expirada", "ss_revogada", "ss_inexistente"} {
+		if _, err := kh.VerificarChave(ctx, valor); err != auth.ErrChaveInvalida {
+			t.Errorf("%s: got %v, want ErrChaveInvalida", valor, err)
+		}
+	}
+}
+
+func TestValidarEscopos(t *testing.T) {
+	if err := validarEscopos([]string{"read:alunos", "write:cursos", "read:graphql"}); err != nil {
+		t.Error(err)
+	}
+	for _, escopo := range []string{"read:notas", "write:frequencia", "admin:alunos", "read:usuarios"} {
+		if err := validarEscopos([]string{escopo}); err == nil {
+			t.Errorf("%s should be rejected", escopo)
+		}
+	}
+}
@@ -3,36 +3,62 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 
 	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/problema"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type Cursos struct {
-	ID   primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty`
-	Nome string             `json:"nome" bson:"nome"`
-}
+type Cursos = modelos.Cursos
 
 type CursosHandler struct {
-	Col dbiface.Collection
+	Col       dbiface.Collection
+	Relacoes  []Relacao //alunos e disciplinas que referenciam o código do curso
+	Regras    *Regras
+	Auditoria *Auditor
+	Versoes   *Versionador
+}
+
+func (ah *CursosHandler) historico() historico {
+	return historico{recurso: "cursos", auditoria: ah.Auditoria, versoes: ah.Versoes}
 }
 
-func inserirCurso(ctx context.Context, cursos []Cursos, collection dbiface.Collection) ([]interface{}, *echo.HTTPError) {
+func inserirCurso(ctx context.Context, ev Evento, cursos []Cursos, collection dbiface.Collection, regras *Regras, hist historico) ([]interface{}, *echo.HTTPError) {
 	var insertedIds []interface{}
-	for _, curso := range cursos {
+	if err := validarLista(cursos); err != nil { //nenhum curso é gravado se algum for inválido
+		return insertedIds, err
+	}
+	liberar, herr := regras.travar(ctx, "cursos", cursos)
+	if herr != nil {
+		return insertedIds, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificarLista(ctx, "cursos", cursos); err != nil {
+		return insertedIds, err
+	}
+	for i, curso := range cursos {
 		curso.ID = primitive.NewObjectID()
 		insertID, err := collection.InsertOne(ctx, curso)
+		if mongo.IsDuplicateKeyError(err) { //o código é único entre os cursos ativos
+			return insertedIds, problema.Novo(http.StatusConflict, problema.CodigoValorDuplicado, "Another active document has the same unique value").HTTP()
+		}
 		if err != nil {
 			log.Errorf("Unable to insert: %v", err)
-			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
+			return insertedIds, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
 		}
 		insertedIds = append(insertedIds, insertID.InsertedID)
+		cursos[i] = curso //ID e números gerados ficam disponíveis para quem chamou
+		hist.registrar(ctx, ev, OperacaoInsercao, curso.ID, nil, curso)
 	}
 	return insertedIds, nil
 }
@@ -42,30 +68,23 @@ func (ah *CursosHandler) InserirCurso(c echo.Context) error {
 
 	if err := c.Bind(&cursos); err != nil {
 		log.Errorf("Unable to bind: %v", err)
-		return echo.NewHTTPError(http.StatusBadRequest, "Unable to parse the request payload")
+		return problema.CorpoInvalido().HTTP()
 	}
 
-	IDs, err := inserirCurso(context.Background(), cursos, ah.Col)
+	IDs, err := inserirCurso(context.Background(), eventoDaRequisicao(c), cursos, ah.Col, ah.Regras, ah.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusCreated, IDs)
 }
 
-func buscarCursos(ctx context.Context, q url.Values, collection dbiface.Collection) ([]Cursos, *echo.HTTPError) {
+func buscarCursos(ctx context.Context, q url.Values, collection dbiface.Collection, lixeira bool) ([]Cursos, *echo.HTTPError) {
 	var cursos []Cursos
-	filter := make(map[string]interface{})
-	for k, v := range q {
-		filter[k] = v[0]
+	filter, opts, herr := consultaListagem(q, lixeira, Cursos{}, nil) //sem campos sensíveis
+	if herr != nil {
+		return cursos, herr
 	}
-	if filter["_id"] != nil { //convertendo o id, que no filter é um string, para um primitiveObjectID
-		docID, err := primitive.ObjectIDFromHex(filter["_id"].(string))
-		if err != nil {
-			return cursos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
-		}
-		filter["_id"] = docID
-	}
-	cursor, err := collection.Find(ctx, bson.M(filter)) /*filter, que corresponde ao q url.Values, está sendo
+	cursor, err := collection.Find(ctx, filter, opts) /*filter, que corresponde ao q url.Values, está sendo
 	convertido para bson. Filter é argumento do método Find e está sendo definido logo acima*/
 	if err != nil {
 		log.Errorf("Unable to find the course: %v", err)
@@ -74,26 +93,31 @@ func buscarCursos(ctx context.Context, q url.Values, collection dbiface.Collecti
 	err = cursor.All(ctx, &cursos)
 	if err != nil {
 		log.Errorf("Unable to read the cursor: %v", err)
-		return cursos, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return cursos, problema.CorpoInvalido().HTTP()
 	}
 	return cursos, nil
 }
 
 func (ah *CursosHandler) BuscarCursos(c echo.Context) error {
-	cursos, err := buscarCursos(context.Background(), c.QueryParams(), ah.Col)
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "cursos", ah.Col, Cursos{}, false)
+	}
+	cursos, err := buscarCursos(context.Background(), c.QueryParams(), ah.Col, false)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusCreated, cursos)
+	return c.JSON(http.StatusCreated, projetar(c, cursos))
 }
 
 func buscarCurso(ctx context.Context, id string, collection dbiface.Collection) (Cursos, *echo.HTTPError) {
 	var cursos Cursos
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return cursos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return cursos, problema.IDInvalido().HTTP()
 	}
-	filter := bson.M{"id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	err = res.Decode(&cursos)
 	if err != nil {
@@ -103,6 +127,13 @@ func buscarCurso(ctx context.Context, id string, collection dbiface.Collection)
 }
 
 func (h *CursosHandler) BuscarCurso(c echo.Context) error {
+	if asOf := c.QueryParam("asOf"); asOf != "" { //estado do documento em uma data passada
+		var cursos Cursos
+		if err := h.Versoes.versaoEm(context.Background(), "cursos", c.Param("id"), asOf, &cursos); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, cursos)
+	}
 	cursos, err := buscarCurso(context.Background(), c.Param("id"), h.Col)
 	if err != nil {
 		return err
@@ -110,18 +141,22 @@ func (h *CursosHandler) BuscarCurso(c echo.Context) error {
 	return c.JSON(http.StatusOK, cursos)
 }
 
-func atualizarCurso(ctx context.Context, id string, reqBody io.ReadCloser, collection dbiface.Collection) (Cursos, *echo.HTTPError) {
+func atualizarCurso(ctx context.Context, ev Evento, id string, reqBody io.ReadCloser, collection dbiface.Collection, regras *Regras, hist historico) (Cursos, *echo.HTTPError) {
 	var cursos Cursos
+	antes, herr := buscarCurso(ctx, id, collection) //cópia própria, já que o corpo é decodificado sobre cursos
+	if herr != nil {
+		return cursos, herr
+	}
 
 	//convertendo o id, que é um string, para primitive.ObjectID
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		log.Errorf("Cannot convert to ObjectID: %v", err)
-		return cursos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return cursos, problema.IDInvalido().HTTP()
 	}
 
 	//procurar se o curso existe, caso contrário erro 404 (Not Found)
-	filter := bson.M{"id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	if err := res.Decode(&cursos); err != nil {
 		log.Errorf("Unable to decode to course: %v", err)
@@ -132,15 +167,27 @@ func atualizarCurso(ctx context.Context, id string, reqBody io.ReadCloser, colle
 	//JSON decodificação do reqBody
 	if err := json.NewDecoder(reqBody).Decode(&cursos); err != nil {
 		log.Errorf("Unable to decode using reqBody: %v", err)
-		return cursos, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return cursos, problema.CorpoInvalido().HTTP()
 	} /*ler o reqBody e usar as informações inseridas para popular os campos de cursos, que "representa" a
 	struct cursos*/
+	cursos.DeletadoEm = nil //a lixeira só é alterada pelas rotas de DELETE e restaurar
+	//alunos, disciplinas e professores referenciam o curso pelo código, que por isso não muda
+	if cursos.Codigo != antes.Codigo {
+		return cursos, problema.Novo(http.StatusBadRequest, problema.CodigoCampoImutavel, fmt.Sprintf("The field %q cannot be updated", "codigo")).HTTP()
+	}
 
 	//validação da requisição
-	if err := v.Struct(cursos); err != nil {
-		log.Errorf("Unable to validate the struct: %v", err)
-		return cursos, echo.NewHTTPError(http.StatusBadRequest, "Unable to validate request payload")
+	if err := validar(cursos); err != nil {
+		return cursos, err
 	} //verificar se o produto, agora atualizado, é válido ou não
+	liberar, herr := regras.travar(ctx, "cursos", cursos)
+	if herr != nil {
+		return cursos, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificar(ctx, "cursos", cursos); err != nil {
+		return cursos, err
+	}
 
 	//atualização do curso
 	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": cursos}) /* _, err, pois UpdateOne possui 2
@@ -149,34 +196,82 @@ func atualizarCurso(ctx context.Context, id string, reqBody io.ReadCloser, colle
 		log.Errorf("Unable to update the course: %v", err)
 		return cursos, echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the course")
 	}
+	hist.registrar(ctx, ev, OperacaoAlteracao, antes.ID, antes, cursos)
 	return cursos, nil
 }
 
 func (ah *CursosHandler) AtualizarCurso(c echo.Context) error {
-	cursos, err := atualizarCurso(context.Background(), c.Param("id"), c.Request().Body, ah.Col)
+	if err := negociacao.CorpoJSON(c, Cursos{}); err != nil { //corpos em XML ou MessagePack seguem como JSON
+		return err
+	}
+	cursos, err := atualizarCurso(context.Background(), eventoDaRequisicao(c), c.Param("id"), c.Request().Body, ah.Col,
+		ah.Regras, ah.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusCreated, cursos)
 }
 
-func deletarCurso(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
-	docID, err := primitive.ObjectIDFromHex(id)
+func deletarCurso(ctx context.Context, ev Evento, id string, collection dbiface.Collection, relacoes []Relacao, hist historico) (int64, *echo.HTTPError) {
+	curso, err := buscarCurso(ctx, id, collection)
 	if err != nil {
-		log.Errorf("Unable to delete the course: %v", err)
-		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return 0, err
 	}
-	res, err := collection.DeleteOne(ctx, bson.M{"id": docID})
+	total, err := excluirComIntegridade(ctx, ev, id, curso.Codigo, collection, relacoes) //o curso vai para a lixeira
 	if err != nil {
-		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to delete the course")
+		return 0, err
 	}
-	return res.DeletedCount, nil
+	hist.registrar(ctx, ev, OperacaoExclusao, curso.ID, curso, nil)
+	return total, nil
 }
 
 func (h *CursosHandler) DeletarCurso(c echo.Context) error {
-	delCount, err := deletarCurso(context.Background(), c.Param("id"), h.Col)
+	if c.QueryParam("dryRun") == "true" { //apenas mostra os dependentes afetados
+		curso, err := buscarCurso(context.Background(), c.Param("id"), h.Col)
+		if err != nil {
+			return err
+		}
+		relatorio, err := simularExclusao(context.Background(), h.Relacoes, curso.Codigo)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, relatorio)
+	}
+	delCount, err := deletarCurso(context.Background(), eventoDaRequisicao(c), c.Param("id"), h.Col, h.Relacoes, h.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, delCount)
 }
+
+func (ah *CursosHandler) BuscarLixeira(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "cursos", ah.Col, Cursos{}, true)
+	}
+	cursos, err := buscarCursos(context.Background(), c.QueryParams(), ah.Col, true)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projetar(c, cursos))
+}
+
+func restaurarCurso(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	total, err := restaurarDaLixeira(ctx, id, collection)
+	if err != nil {
+		return 0, err
+	}
+	if depois, err := buscarCurso(ctx, id, collection); err == nil {
+		hist.registrar(ctx, ev, OperacaoRestauracao, depois.ID, nil, depois)
+	}
+	return total, nil
+}
+
+func (ah *CursosHandler) RestaurarCurso(c echo.Context) error {
+	count, err := restaurarCurso(context.Background(), eventoDaRequisicao(c), c.Param("id"), ah.Col, ah.historico())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, count)
+}
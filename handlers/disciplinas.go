@@ -6,35 +6,57 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 
 	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/problema"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type Disciplinas struct {
-	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Nome         string             `json:"nome" bson:"nome"`
-	CargaHoraria int                `json:"cargaHoraria" bson:"cargaHoraria"`
-}
+type Disciplinas = modelos.Disciplinas
 
 type DisciplinasHandler struct {
-	Col dbiface.Collection
+	Col         dbiface.Collection
+	Relacoes    []Relacao //professores que lecionam a disciplina
+	Regras      *Regras
+	Auditoria   *Auditor
+	Versoes     *Versionador
+	Importacoes *Importador
+}
+
+func (oh *DisciplinasHandler) historico() historico {
+	return historico{recurso: "disciplinas", auditoria: oh.Auditoria, versoes: oh.Versoes}
 }
 
-func inserirDisciplina(ctx context.Context, disciplinas []Disciplinas, collection dbiface.Collection) ([]interface{}, *echo.HTTPError) {
+func inserirDisciplina(ctx context.Context, ev Evento, disciplinas []Disciplinas, collection dbiface.Collection, regras *Regras, hist historico) ([]interface{}, *echo.HTTPError) {
 	var insertedIDs []interface{}
+	if err := validarLista(disciplinas); err != nil { //nenhuma disciplina é gravada se alguma for inválida
+		return insertedIDs, err
+	}
+	liberar, herr := regras.travar(ctx, "disciplinas", disciplinas)
+	if herr != nil {
+		return insertedIDs, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificarLista(ctx, "disciplinas", disciplinas); err != nil {
+		return insertedIDs, err
+	}
 
-	for _, disciplina := range disciplinas {
+	for i, disciplina := range disciplinas {
 		disciplina.ID = primitive.NewObjectID()
 		insertID, err := collection.InsertOne(ctx, disciplina)
 		if err != nil {
 			log.Errorf("Unable to insert: %v", err)
-			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
+			return insertedIDs, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
 		}
 		insertedIDs = append(insertedIDs, insertID.InsertedID)
+		disciplinas[i] = disciplina //ID e números gerados ficam disponíveis para quem chamou
+		hist.registrar(ctx, ev, OperacaoInsercao, disciplina.ID, nil, disciplina)
 	}
 	return insertedIDs, nil
 }
@@ -44,29 +66,37 @@ func (oh *DisciplinasHandler) InserirDisciplina(c echo.Context) error {
 
 	if err := c.Bind(&disciplinas); err != nil {
 		log.Errorf("Unable to bind: %v", err)
-		return c.JSON(http.StatusBadRequest, "Unable to parse request payload")
+		return problema.CorpoInvalido().HTTP()
 	}
-	IDs, err := inserirDisciplina(context.Background(), disciplinas, oh.Col)
+	IDs, err := inserirDisciplina(context.Background(), eventoDaRequisicao(c), disciplinas, oh.Col, oh.Regras, oh.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusCreated, IDs)
 }
 
-func buscarDisciplinas(ctx context.Context, q url.Values, collection dbiface.Collection) ([]Disciplinas, *echo.HTTPError) {
+// ImportarDisciplinas inclui as disciplinas de uma planilha CSV ou XLSX pelo mesmo caminho do POST /disciplinas.
+func (oh *DisciplinasHandler) ImportarDisciplinas(c echo.Context) error {
+	return oh.Importacoes.importar(c, importavel{recurso: "disciplinas", tipo: reflect.TypeOf(Disciplinas{}),
+		regras: oh.Regras,
+		inserir: func(ctx context.Context, ev Evento, doc interface{}) (interface{}, *echo.HTTPError) {
+			disciplinas := []Disciplinas{doc.(Disciplinas)}
+			_, err := inserirDisciplina(ctx, ev, disciplinas, oh.Col, oh.Regras, oh.historico())
+			return disciplinas[0], err
+		}})
+}
+
+func (oh *DisciplinasHandler) BuscarImportacao(c echo.Context) error {
+	return oh.Importacoes.buscar(c, "disciplinas")
+}
+
+func buscarDisciplinas(ctx context.Context, q url.Values, collection dbiface.Collection, lixeira bool) ([]Disciplinas, *echo.HTTPError) {
 	var disciplinas []Disciplinas
-	filter := make(map[string]interface{})
-	for k, v := range q {
-		filter[k] = v[0]
-	}
-	if filter["_id"] != nil { //convertendo o id, que no filter é um string, para um primitiveObjectID
-		docID, err := primitive.ObjectIDFromHex(filter["_id"].(string))
-		if err != nil {
-			return disciplinas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
-		}
-		filter["_id"] = docID
+	filter, opts, herr := consultaListagem(q, lixeira, Disciplinas{}, nil) //sem campos sensíveis
+	if herr != nil {
+		return disciplinas, herr
 	}
-	cursor, err := collection.Find(ctx, bson.M(filter)) /*filter, que corresponde ao q url.Values, está sendo
+	cursor, err := collection.Find(ctx, filter, opts) /*filter, que corresponde ao q url.Values, está sendo
 	convertido para bson. Filter é argumento do método Find e está sendo definido logo acima*/
 	if err != nil {
 		log.Errorf("Unable to find the discipline: %v", err)
@@ -75,26 +105,31 @@ func buscarDisciplinas(ctx context.Context, q url.Values, collection dbiface.Col
 	err = cursor.All(ctx, &disciplinas)
 	if err != nil {
 		log.Errorf("Unable to read the cursor: %v", err)
-		return disciplinas, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return disciplinas, problema.CorpoInvalido().HTTP()
 	}
 	return disciplinas, nil
 }
 
 func (oh *DisciplinasHandler) BuscarDisciplinas(c echo.Context) error {
-	disciplinas, err := buscarDisciplinas(context.Background(), c.QueryParams(), oh.Col)
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "disciplinas", oh.Col, Disciplinas{}, false)
+	}
+	disciplinas, err := buscarDisciplinas(context.Background(), c.QueryParams(), oh.Col, false)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusCreated, disciplinas)
+	return c.JSON(http.StatusCreated, projetar(c, disciplinas))
 }
 
 func buscarDisciplina(ctx context.Context, id string, collection dbiface.Collection) (Disciplinas, *echo.HTTPError) {
 	var disciplinas Disciplinas
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return disciplinas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return disciplinas, problema.IDInvalido().HTTP()
 	}
-	filter := bson.M{"_id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	err = res.Decode(&disciplinas)
 	if err != nil {
@@ -104,6 +139,13 @@ func buscarDisciplina(ctx context.Context, id string, collection dbiface.Collect
 }
 
 func (oh *DisciplinasHandler) BuscarDisciplina(c echo.Context) error {
+	if asOf := c.QueryParam("asOf"); asOf != "" { //estado do documento em uma data passada
+		var disciplinas Disciplinas
+		if err := oh.Versoes.versaoEm(context.Background(), "disciplinas", c.Param("id"), asOf, &disciplinas); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, disciplinas)
+	}
 	disciplinas, err := buscarDisciplina(context.Background(), c.Param("id"), oh.Col)
 	if err != nil {
 		return err
@@ -111,18 +153,22 @@ func (oh *DisciplinasHandler) BuscarDisciplina(c echo.Context) error {
 	return c.JSON(http.StatusOK, disciplinas)
 }
 
-func atualizarDisciplina(ctx context.Context, id string, reqBody io.ReadCloser, collection dbiface.Collection) (Disciplinas, *echo.HTTPError) {
+func atualizarDisciplina(ctx context.Context, ev Evento, id string, reqBody io.ReadCloser, collection dbiface.Collection, regras *Regras, hist historico) (Disciplinas, *echo.HTTPError) {
 	var disciplinas Disciplinas
+	antes, herr := buscarDisciplina(ctx, id, collection) //cópia própria, já que o corpo é decodificado sobre disciplinas
+	if herr != nil {
+		return disciplinas, herr
+	}
 
 	//convertendo o id, que é um string, para primitive.ObjectID
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		log.Errorf("Cannot convert to ObjectID: %v", err)
-		return disciplinas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return disciplinas, problema.IDInvalido().HTTP()
 	}
 
 	//procurar se o disciplina existe, caso contrário erro 404 (Not Found)
-	filter := bson.M{"_id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	if err := res.Decode(&disciplinas); err != nil {
 		log.Errorf("Unable to decode to discipine: %v", err)
@@ -133,15 +179,23 @@ func atualizarDisciplina(ctx context.Context, id string, reqBody io.ReadCloser,
 	//JSON decodificação do reqBody
 	if err := json.NewDecoder(reqBody).Decode(&disciplinas); err != nil {
 		log.Errorf("Unable to decode using reqBody: %v", err)
-		return disciplinas, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return disciplinas, problema.CorpoInvalido().HTTP()
 	} /*ler o reqBody e usar as informações inseridas para popular os campos de disciplinas, que "representa" a
 	struct disciplinas*/
+	disciplinas.DeletadoEm = nil //a lixeira só é alterada pelas rotas de DELETE e restaurar
 
 	//validação da requisição
-	if err := v.Struct(disciplinas); err != nil {
-		log.Errorf("Unable to validate the struct: %v", err)
-		return disciplinas, echo.NewHTTPError(http.StatusBadRequest, "Unable to validate request payload")
+	if err := validar(disciplinas); err != nil {
+		return disciplinas, err
 	} //verificar se o produto, agora atualizado, é válido ou não
+	liberar, herr := regras.travar(ctx, "disciplinas", disciplinas)
+	if herr != nil {
+		return disciplinas, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificar(ctx, "disciplinas", disciplinas); err != nil {
+		return disciplinas, err
+	}
 
 	//atualização do disciplina
 	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": disciplinas}) /* _, err, pois UpdateOne possui 2
@@ -150,35 +204,83 @@ func atualizarDisciplina(ctx context.Context, id string, reqBody io.ReadCloser,
 		log.Errorf("Unable to update the discipline: %v", err)
 		return disciplinas, echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the discipline")
 	}
+	hist.registrar(ctx, ev, OperacaoAlteracao, antes.ID, antes, disciplinas)
 	return disciplinas, nil
 }
 
 func (oh *DisciplinasHandler) AtualizarDisciplina(c echo.Context) error {
-	disciplinas, err := atualizarDisciplina(context.Background(), c.Param("id"), c.Request().Body, oh.Col)
+	if err := negociacao.CorpoJSON(c, Disciplinas{}); err != nil { //corpos em XML ou MessagePack seguem como JSON
+		return err
+	}
+	disciplinas, err := atualizarDisciplina(context.Background(), eventoDaRequisicao(c), c.Param("id"), c.Request().Body, oh.Col,
+		oh.Regras, oh.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, disciplinas)
 }
 
-func deletarDisciplina(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
-	docID, err := primitive.ObjectIDFromHex(id)
+func deletarDisciplina(ctx context.Context, ev Evento, id string, collection dbiface.Collection, relacoes []Relacao, hist historico) (int64, *echo.HTTPError) {
+	//a disciplina deve existir e estar ativa antes que as políticas alterem os dependentes
+	disciplina, err := buscarDisciplina(ctx, id, collection)
 	if err != nil {
-		log.Errorf("Unable to delete the discipline: %v", err)
-		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return 0, err
 	}
-	filter := bson.M{"_id": docID}
-	res, err := collection.DeleteOne(ctx, filter)
+	total, err := excluirComIntegridade(ctx, ev, id, disciplina.ID, collection, relacoes) //a disciplina vai para a lixeira
 	if err != nil {
-		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to delete the discipline")
+		return 0, err
 	}
-	return res.DeletedCount, nil
+	hist.registrar(ctx, ev, OperacaoExclusao, disciplina.ID, disciplina, nil)
+	return total, nil
 }
 
 func (oh *DisciplinasHandler) DeletarDisciplina(c echo.Context) error {
-	del, err := deletarDisciplina(context.Background(), c.Param("id"), oh.Col)
+	if c.QueryParam("dryRun") == "true" { //apenas mostra os dependentes afetados
+		disciplina, err := buscarDisciplina(context.Background(), c.Param("id"), oh.Col)
+		if err != nil {
+			return err
+		}
+		relatorio, err := simularExclusao(context.Background(), oh.Relacoes, disciplina.ID)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, relatorio)
+	}
+	del, err := deletarDisciplina(context.Background(), eventoDaRequisicao(c), c.Param("id"), oh.Col, oh.Relacoes, oh.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, del)
 }
+
+func (oh *DisciplinasHandler) BuscarLixeira(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "disciplinas", oh.Col, Disciplinas{}, true)
+	}
+	disciplinas, err := buscarDisciplinas(context.Background(), c.QueryParams(), oh.Col, true)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projetar(c, disciplinas))
+}
+
+func restaurarDisciplina(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	total, err := restaurarDaLixeira(ctx, id, collection)
+	if err != nil {
+		return 0, err
+	}
+	if depois, err := buscarDisciplina(ctx, id, collection); err == nil {
+		hist.registrar(ctx, ev, OperacaoRestauracao, depois.ID, nil, depois)
+	}
+	return total, nil
+}
+
+func (oh *DisciplinasHandler) RestaurarDisciplina(c echo.Context) error {
+	count, err := restaurarDisciplina(context.Background(), eventoDaRequisicao(c), c.Param("id"), oh.Col, oh.historico())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, count)
+}
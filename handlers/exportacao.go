@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/planilha"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const FormatoNDJSON = "ndjson"
+
+// TiposExportacao associa os formatos aceitos em ?format= aos tipos de conteúdo equivalentes no Accept.
+var TiposExportacao = map[string]string{
+	planilha.FormatoCSV:  "text/csv",
+	planilha.FormatoXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatoNDJSON:        "application/x-ndjson",
+}
+
+// formatoExportacao escolhe o formato da listagem por ?format= ou, na falta dele, pelo primeiro tipo do
+// Accept que seja de exportação. "" é a resposta JSON de sempre.
+func formatoExportacao(c echo.Context) (string, *echo.HTTPError) {
+	if formato := c.QueryParam("format"); formato != "" {
+		if formato == "json" {
+			return "", nil
+		}
+		if _, ok := TiposExportacao[formato]; !ok {
+			return "", problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "format must be one of json, csv, xlsx or ndjson").HTTP()
+		}
+		return formato, nil
+	}
+	for _, tipo := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		tipo = strings.TrimSpace(strings.SplitN(tipo, ";", 2)[0])
+		for formato, t := range TiposExportacao {
+			if strings.EqualFold(tipo, t) {
+				return formato, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// exportacao descreve a consulta de uma listagem exportada e o tratamento de cada documento antes da
+// escrita, em geral o mascaramento dos campos sensíveis.
+type exportacao struct {
+	nome   string //nome do arquivo, sem a extensão
+	col    dbiface.Collection
+	filter bson.M
+	opts   *options.FindOptions              //opcional
+	modelo interface{}                       //valor da struct em que cada documento é decodificado
+	campos []string                          //colunas pedidas em ?campos=; sem elas, todos os campos JSON do modelo
+	tratar func(doc interface{}) interface{} //opcional
+}
+
+// exportarListagem escreve a listagem no formato pedido lendo o cursor um documento por vez, em vez de
+// carregar a coleção inteira com cursor.All. Depois que a resposta começa, um erro só pode ir para o log: o
+// cliente recebe o arquivo truncado.
+func exportarListagem(c echo.Context, formato string, e exportacao) error {
+	ctx := c.Request().Context()
+	opts := e.opts
+	if opts == nil {
+		opts = options.Find()
+	}
+	cursor, err := e.col.Find(ctx, e.filter, opts)
+	if err != nil {
+		log.Errorf("Unable to find the documents to export: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to export the documents")
+	}
+	defer cursor.Close(ctx)
+	tipo := reflect.TypeOf(e.modelo)
+	colunas := e.campos
+	if len(colunas) == 0 {
+		colunas = camposJSON(tipo)
+	}
+	r := c.Response()
+	conteudo := TiposExportacao[formato]
+	if formato != planilha.FormatoXLSX {
+		conteudo += "; charset=utf-8"
+	}
+	r.Header().Set(echo.HeaderContentType, conteudo)
+	r.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", e.nome+"."+formato))
+	r.WriteHeader(http.StatusOK)
+
+	var escritor planilha.Escritor
+	if formato != FormatoNDJSON {
+		if escritor, err = planilha.NovoEscritor(r, formato, e.nome); err != nil {
+			log.Errorf("Unable to start the export: %v", err)
+			return err
+		}
+		cabecalho := make([]interface{}, len(colunas))
+		for i, coluna := range colunas {
+			cabecalho[i] = coluna
+		}
+		if err := escritor.Escrever(cabecalho); err != nil {
+			log.Errorf("Unable to write the export: %v", err)
+			return err
+		}
+	}
+	ndjson := json.NewEncoder(r)
+	for cursor.Next(ctx) {
+		doc := reflect.New(tipo).Interface()
+		if err := cursor.Decode(doc); err != nil {
+			log.Errorf("Unable to decode the document to export: %v", err)
+			return err
+		}
+		saida := doc
+		if e.tratar != nil {
+			saida = e.tratar(doc)
+		}
+		if escritor != nil || len(e.campos) > 0 {
+			valores, err := documentoJSON(saida)
+			if err != nil {
+				log.Errorf("Unable to encode the document to export: %v", err)
+				return err
+			}
+			if escritor != nil {
+				err = escritor.Escrever(linhaExportada(valores, colunas))
+			} else {
+				err = ndjson.Encode(selecionarCampos(valores, e.campos))
+			}
+			if err != nil {
+				log.Errorf("Unable to write the export: %v", err)
+				return err
+			}
+			continue
+		}
+		if err := ndjson.Encode(saida); err != nil {
+			log.Errorf("Unable to write the export: %v", err)
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Errorf("Unable to read the cursor: %v", err)
+		return err
+	}
+	if escritor != nil {
+		if err := escritor.Fechar(); err != nil {
+			log.Errorf("Unable to write the export: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// camposJSON lista os campos do tipo como o encoding/json os nomeia, na ordem da struct.
+func camposJSON(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var campos []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		nome := strings.Split(f.Tag.Get("json"), ",")[0]
+		if nome == "-" || f.PkgPath != "" {
+			continue
+		}
+		if nome == "" {
+			nome = f.Name
+		}
+		campos = append(campos, nome)
+	}
+	return campos
+}
+
+// documentoJSON converte o documento no mapa que o encoding/json produziria, com os números preservados.
+func documentoJSON(doc interface{}) (map[string]interface{}, error) {
+	dados, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(dados))
+	d.UseNumber()
+	var valores map[string]interface{}
+	err = d.Decode(&valores)
+	return valores, err
+}
+
+// linhaExportada devolve as células das colunas, aceitando caminhos como "endereco.cidade". Listas de
+// valores simples viram um texto separado por "; ", que a importação lê de volta; objetos vão como JSON.
+func linhaExportada(valores map[string]interface{}, colunas []string) []interface{} {
+	linha := make([]interface{}, len(colunas))
+	for i, coluna := range colunas {
+		var valor interface{} = valores
+		for _, parte := range strings.Split(coluna, ".") {
+			m, ok := valor.(map[string]interface{})
+			if !ok {
+				valor = nil
+				break
+			}
+			valor = m[parte]
+		}
+		linha[i] = celulaExportada(valor)
+	}
+	return linha
+}
+
+func celulaExportada(valor interface{}) interface{} {
+	switch v := valor.(type) {
+	case nil, string, bool, json.Number:
+		return v
+	case []interface{}:
+		partes := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case string, bool, json.Number:
+				partes = append(partes, fmt.Sprint(item))
+			default:
+				dados, _ := json.Marshal(v)
+				return string(dados)
+			}
+		}
+		return strings.Join(partes, "; ")
+	}
+	dados, _ := json.Marshal(valor)
+	return string(dados)
+}
+
+// exportarCadastro exporta as listagens de alunos, professores, cursos e disciplinas, ativos ou na lixeira,
+// com os mesmos filtros, ordenação e projeção da resposta JSON.
+func exportarCadastro(c echo.Context, formato, recurso string, col dbiface.Collection, modelo interface{}, lixeira bool) error {
+	claims := claimsDaRequisicao(c)
+	filter, opts, herr := consultaListagem(c.QueryParams(), lixeira, modelo, claims)
+	if herr != nil {
+		return herr
+	}
+	campos, _ := camposProjetados(c.QueryParams())
+	nome := recurso
+	if lixeira {
+		nome += "-lixeira"
+	}
+	return exportarListagem(c, formato, exportacao{nome: nome, col: col, filter: filter, opts: opts, modelo: modelo,
+		campos: campos, tratar: func(doc interface{}) interface{} { return Mascarar(doc, claims) }})
+}
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/graphql"
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GraphQLHandler atende POST /graphql com consultas sobre o cadastro escolar e suas relações: o curso e
+// as matrículas dos alunos, as disciplinas de cada curso e os professores de cada disciplina. Notas e
+// frequência ainda não existem no cadastro e por isso não fazem parte do esquema. Os dados pessoais saem
+// mascarados como nas rotas REST, e chaves de API precisam do escopo de leitura de cada recurso consultado.
+// A rota é só da secretaria e dos professores: não há controle por titular, que alunos e responsáveis têm
+// nas rotas REST.
+type GraphQLHandler struct {
+	Alunos      dbiface.Collection
+	Professores dbiface.Collection
+	Cursos      dbiface.Collection
+	Disciplinas dbiface.Collection
+
+	once    sync.Once
+	esquema *graphql.Esquema
+}
+
+// requisicaoGraphQL guarda o que vale para uma consulta: as claims e os carregadores, que agrupam as
+// buscas de um nível em uma consulta ao banco e guardam os documentos já lidos.
+type requisicaoGraphQL struct {
+	claims       *auth.Claims
+	mu           sync.Mutex
+	carregadores map[string]*carregador
+}
+
+type chaveGraphQL struct{}
+
+func requisicaoDoContexto(ctx context.Context) *requisicaoGraphQL {
+	r, _ := ctx.Value(chaveGraphQL{}).(*requisicaoGraphQL)
+	return r
+}
+
+// Consultar executa uma consulta no formato {"query", "operationName", "variables"}. Erros da consulta
+// seguem a especificação do GraphQL (status 200 e a lista errors), traduzidos pelo Accept-Language.
+func (gh *GraphQLHandler) Consultar(c echo.Context) error {
+	var corpo struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := c.Bind(&corpo); err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	if corpo.Query == "" {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoConsultaAusente, "The query is required").HTTP()
+	}
+	ctx := context.WithValue(c.Request().Context(), chaveGraphQL{},
+		&requisicaoGraphQL{claims: claimsDaRequisicao(c), carregadores: make(map[string]*carregador)})
+	resposta := gh.esquemaGraphQL().Executar(ctx, corpo.Query, corpo.OperationName, corpo.Variables)
+	idioma := i18n.Negociar(c.Request().Header.Get("Accept-Language"))
+	for i := range resposta.Erros {
+		resposta.Erros[i].Mensagem = i18n.Traduzir(idioma, resposta.Erros[i].Mensagem)
+	}
+	c.Response().Header().Set("Content-Language", idioma)
+	return c.JSON(http.StatusOK, resposta)
+}
+
+// Esquema atende GET /graphql com o esquema em SDL, para as ferramentas do front-end.
+func (gh *GraphQLHandler) Esquema(c echo.Context) error {
+	return c.String(http.StatusOK, gh.esquemaGraphQL().SDL())
+}
+
+func (gh *GraphQLHandler) esquemaGraphQL() *graphql.Esquema {
+	gh.once.Do(func() { gh.esquema = gh.montarEsquema() })
+	return gh.esquema
+}
+
+var (
+	tipoAluno      = reflect.TypeOf(Alunos{})
+	tipoProfessor  = reflect.TypeOf(Professores{})
+	tipoCurso      = reflect.TypeOf(Cursos{})
+	tipoDisciplina = reflect.TypeOf(Disciplinas{})
+)
+
+// paginado acrescenta limite e pagina aos argumentos de um campo de lista.
+func paginado(args map[string]string) map[string]string {
+	args["limite"], args["pagina"] = "Int", "Int"
+	return args
+}
+
+func (gh *GraphQLHandler) montarEsquema() *graphql.Esquema {
+	texto := &graphql.Campo{Tipo: "String"}
+	numero := &graphql.Campo{Tipo: "Int"}
+	id := &graphql.Campo{Tipo: "ID", Resolver: resolverID}
+	filtrosAluno := map[string]string{"id": "ID", "nome": "String", "sobrenome": "String", "matricula": "Int", "curso": "Int"}
+
+	return &graphql.Esquema{
+		Consulta: "Consulta",
+		Tipos: map[string]*graphql.Tipo{
+			"Consulta": {Campos: map[string]*graphql.Campo{
+				"alunos": {Tipo: "[Aluno]", Descricao: "Alunos ativos; os argumentos filtram por igualdade",
+					Argumentos: paginado(filtrosAluno),
+					Resolver:   gh.listar("alunos", gh.Alunos, tipoAluno, map[string]string{"id": "_id"})},
+				"aluno": {Tipo: "Aluno", Argumentos: map[string]string{"id": "ID!"},
+					Resolver: gh.porID("alunos", gh.Alunos, tipoAluno)},
+				"professores": {Tipo: "[Professor]", Descricao: "Professores ativos; disciplina filtra pelo id de uma disciplina",
+					Argumentos: paginado(map[string]string{"id": "ID", "nome": "String", "sobrenome": "String", "registro": "Int", "disciplina": "ID"}),
+					Resolver:   gh.listar("professores", gh.Professores, tipoProfessor, map[string]string{"id": "_id", "disciplina": "disciplinas"})},
+				"professor": {Tipo: "Professor", Argumentos: map[string]string{"id": "ID!"},
+					Resolver: gh.porID("professores", gh.Professores, tipoProfessor)},
+				"cursos": {Tipo: "[Curso]", Descricao: "Cursos ativos",
+					Argumentos: paginado(map[string]string{"id": "ID", "codigo": "Int", "nome": "String", "nivel": "String"}),
+					Resolver:   gh.listar("cursos", gh.Cursos, tipoCurso, map[string]string{"id": "_id"})},
+				"curso": {Tipo: "Curso", Argumentos: map[string]string{"id": "ID!"},
+					Resolver: gh.porID("cursos", gh.Cursos, tipoCurso)},
+				"disciplinas": {Tipo: "[Disciplina]", Descricao: "Disciplinas ativas",
+					Argumentos: paginado(map[string]string{"id": "ID", "nome": "String", "cargaHoraria": "Int", "curso": "Int"}),
+					Resolver:   gh.listar("disciplinas", gh.Disciplinas, tipoDisciplina, map[string]string{"id": "_id"})},
+				"disciplina": {Tipo: "Disciplina", Argumentos: map[string]string{"id": "ID!"},
+					Resolver: gh.porID("disciplinas", gh.Disciplinas, tipoDisciplina)},
+			}},
+			"Aluno": {Campos: map[string]*graphql.Campo{
+				"id": id, "matricula": numero, "nome": texto, "sobrenome": texto, "telefone": texto, "email": texto,
+				"cpf": texto, "rg": texto, "cep": texto,
+				"curso": {Tipo: "Curso", Descricao: "Curso principal",
+					Resolver: gh.relacao("cursos", "cursos.codigo", gh.cursosPorCodigo, func(pai interface{}) []interface{} {
+						return []interface{}{pai.(Alunos).Curso}
+					}, false)},
+				"cursos": {Tipo: "[Curso]", Descricao: "Outros cursos em andamento, além do principal",
+					Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("cursos", "cursos.codigo", gh.cursosPorCodigo, func(pai interface{}) []interface{} {
+						return inteiros(pai.(Alunos).Cursos)
+					}, true)},
+				"matriculas": {Tipo: "[Curso]", Descricao: "Todos os cursos do aluno: o principal e os demais",
+					Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("cursos", "cursos.codigo", gh.cursosPorCodigo, func(pai interface{}) []interface{} {
+						a := pai.(Alunos)
+						return append([]interface{}{a.Curso}, inteiros(a.Cursos)...)
+					}, true)},
+			}},
+			"Professor": {Campos: map[string]*graphql.Campo{
+				"id": id, "registro": numero, "nome": texto, "sobrenome": texto, "telefone": texto, "email": texto,
+				"cpf": texto, "rg": texto, "cep": texto,
+				"disciplinas": {Tipo: "[Disciplina]", Descricao: "Disciplinas que o professor leciona",
+					Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("disciplinas", "disciplinas._id", gh.disciplinasPorID, func(pai interface{}) []interface{} {
+						var ids []interface{}
+						for _, id := range pai.(Professores).Disciplinas {
+							ids = append(ids, id)
+						}
+						return ids
+					}, true)},
+			}},
+			"Curso": {Campos: map[string]*graphql.Campo{
+				"id": id, "codigo": numero, "nome": texto, "nivel": texto,
+				"alunos": {Tipo: "[Aluno]", Descricao: "Alunos matriculados, no curso principal ou como outro curso",
+					Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("alunos", "alunos.curso", gh.alunosPorCurso, func(pai interface{}) []interface{} {
+						return []interface{}{pai.(Cursos).Codigo}
+					}, true)},
+				"disciplinas": {Tipo: "[Disciplina]", Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("disciplinas", "disciplinas.curso", gh.disciplinasPorCurso, func(pai interface{}) []interface{} {
+						return []interface{}{pai.(Cursos).Codigo}
+					}, true)},
+			}},
+			"Disciplina": {Campos: map[string]*graphql.Campo{
+				"id": id, "nome": texto, "cargaHoraria": numero,
+				"curso": {Tipo: "Curso",
+					Resolver: gh.relacao("cursos", "cursos.codigo", gh.cursosPorCodigo, func(pai interface{}) []interface{} {
+						return []interface{}{pai.(Disciplinas).Curso}
+					}, false)},
+				"professores": {Tipo: "[Professor]", Descricao: "Professores que lecionam a disciplina",
+					Argumentos: paginado(map[string]string{}),
+					Resolver: gh.relacao("professores", "professores.disciplinas", gh.professoresPorDisciplina, func(pai interface{}) []interface{} {
+						return []interface{}{pai.(Disciplinas).ID}
+					}, true)},
+			}},
+		},
+	}
+}
+
+func resolverID(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	ids := make([]interface{}, len(pais))
+	for i, pai := range pais {
+		ids[i] = reflect.ValueOf(pai).FieldByName("ID").Interface()
+	}
+	return ids, nil
+}
+
+func inteiros(lista []int) []interface{} {
+	valores := make([]interface{}, len(lista))
+	for i, n := range lista {
+		valores[i] = n
+	}
+	return valores
+}
+
+// escopo confere se a chave de API da consulta pode ler o recurso. Tokens de usuários já passaram pela
+// política da rota.
+func escopo(ctx context.Context, recurso string) error {
+	r := requisicaoDoContexto(ctx)
+	if r == nil || r.claims == nil || r.claims.Escopos == nil || contem(r.claims.Escopos, "read:"+recurso) {
+		return nil
+	}
+	return fmt.Errorf("The API key lacks the scope %s", "read:"+recurso)
+}
+
+// listar resolve as listas da raiz com os argumentos como filtro de igualdade; campos renomeia os
+// argumentos que não têm o nome do campo no BSON.
+func (gh *GraphQLHandler) listar(recurso string, col dbiface.Collection, tipo reflect.Type, campos map[string]string) graphql.Resolver {
+	return func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		if err := escopo(ctx, recurso); err != nil {
+			return nil, err
+		}
+		filtro := bson.M{}
+		for nome, valor := range args {
+			if parametrosPaginacao[nome] {
+				continue
+			}
+			if campo, ok := campos[nome]; ok {
+				nome = campo
+			}
+			if texto, ok := valor.(string); ok && (nome == "_id" || nome == "disciplinas") {
+				oid, err := primitive.ObjectIDFromHex(texto)
+				if err != nil {
+					return nil, errors.New("Unable to convert to ObjectID")
+				}
+				valor = oid
+			}
+			filtro[nome] = valor
+		}
+		opts := options.Find()
+		if limite, ok := args["limite"].(int64); ok {
+			pagina, ok := args["pagina"].(int64)
+			if !ok {
+				pagina = 1
+			}
+			switch {
+			case limite <= 0:
+				return nil, errors.New("limite must be a positive number")
+			case pagina <= 0:
+				return nil, errors.New("pagina must be a positive number")
+			}
+			opts = opcoesPagina(limite, pagina)
+		}
+		docs, err := buscarTipados(ctx, col, filtroAtivos(filtro), tipo, opts)
+		if err != nil {
+			log.Errorf("Unable to find the %s: %v", recurso, err)
+			return nil, errors.New("Unable to find the document")
+		}
+		return []interface{}{mascararTodos(ctx, docs)}, nil
+	}
+}
+
+func (gh *GraphQLHandler) porID(recurso string, col dbiface.Collection, tipo reflect.Type) graphql.Resolver {
+	return func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		if err := escopo(ctx, recurso); err != nil {
+			return nil, err
+		}
+		oid, err := primitive.ObjectIDFromHex(args["id"].(string))
+		if err != nil {
+			return nil, errors.New("Unable to convert to ObjectID")
+		}
+		docs, err := buscarTipados(ctx, col, filtroAtivos(bson.M{"_id": oid}), tipo, options.Find())
+		if err != nil {
+			log.Errorf("Unable to find the %s: %v", recurso, err)
+			return nil, errors.New("Unable to find the document")
+		}
+		if len(docs) == 0 {
+			return []interface{}{nil}, nil //não encontrado fica null, como no GraphQL
+		}
+		return []interface{}{mascararTodos(ctx, docs)[0]}, nil
+	}
+}
+
+// relacao resolve um campo de relação para todos os pais com uma única busca: referencias devolve as
+// chaves que o pai aponta e o carregador devolve os documentos de cada chave.
+func (gh *GraphQLHandler) relacao(recurso, nome string, novo func() *carregador, referencias func(interface{}) []interface{},
+	lista bool) graphql.Resolver {
+	return func(ctx context.Context, pais []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		if err := escopo(ctx, recurso); err != nil {
+			return nil, err
+		}
+		porPai := make([][]interface{}, len(pais))
+		var todas []interface{}
+		for i, pai := range pais {
+			porPai[i] = referencias(pai)
+			todas = append(todas, porPai[i]...)
+		}
+		l := requisicaoDoContexto(ctx).carregador(nome, novo)
+		encontrados, err := l.carregar(ctx, todas)
+		if err != nil {
+			log.Errorf("Unable to find the %s: %v", recurso, err)
+			return nil, errors.New("Unable to find the document")
+		}
+		valores := make([]interface{}, len(pais))
+		for i, chaves := range porPai {
+			var docs []interface{}
+			vistos := make(map[interface{}]bool)
+			for _, chave := range chaves {
+				for _, doc := range encontrados[normalizar(chave)] {
+					id := reflect.ValueOf(doc).FieldByName("ID").Interface()
+					if !vistos[id] {
+						vistos[id] = true
+						docs = append(docs, doc)
+					}
+				}
+			}
+			switch {
+			case !lista && len(docs) > 0:
+				valores[i] = docs[0]
+			case lista:
+				valores[i] = paginarLista(docs, args)
+			}
+		}
+		return valores, nil
+	}
+}
+
+// paginarLista aplica limite e pagina à lista de um único pai.
+func paginarLista(docs []interface{}, args map[string]interface{}) []interface{} {
+	limite, ok := args["limite"].(int64)
+	if !ok || limite <= 0 {
+		return docs
+	}
+	pagina, _ := args["pagina"].(int64)
+	if pagina <= 0 {
+		pagina = 1
+	}
+	inicio := (pagina - 1) * limite
+	if inicio >= int64(len(docs)) {
+		return []interface{}{}
+	}
+	fim := inicio + limite
+	if fim > int64(len(docs)) {
+		fim = int64(len(docs))
+	}
+	return docs[inicio:fim]
+}
+
+func (r *requisicaoGraphQL) carregador(nome string, novo func() *carregador) *carregador {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.carregadores[nome]
+	if !ok {
+		l = novo()
+		l.cache = make(map[interface{}][]interface{})
+		r.carregadores[nome] = l
+	}
+	return l
+}
+
+// carregador busca de uma vez os documentos de várias chaves e os guarda durante a consulta, no estilo do
+// DataLoader. filtro monta a busca das chaves que faltam e chaves indica a quais chaves um documento
+// responde (um aluno responde pelo curso principal e pelos demais).
+type carregador struct {
+	col    dbiface.Collection
+	tipo   reflect.Type
+	filtro func(chaves []interface{}) bson.M
+	chaves func(doc interface{}) []interface{}
+
+	mu    sync.Mutex
+	cache map[interface{}][]interface{}
+}
+
+func (l *carregador) carregar(ctx context.Context, chaves []interface{}) (map[interface{}][]interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var faltando []interface{}
+	pedidas := make(map[interface{}]bool)
+	for _, chave := range chaves {
+		chave = normalizar(chave)
+		if _, ok := l.cache[chave]; !ok && !pedidas[chave] && !nuloOuZero(chave) {
+			pedidas[chave] = true
+			faltando = append(faltando, chave)
+		}
+	}
+	if len(faltando) > 0 {
+		docs, err := buscarTipados(ctx, l.col, filtroAtivos(l.filtro(faltando)), l.tipo, options.Find())
+		if err != nil {
+			return nil, err
+		}
+		for _, chave := range faltando {
+			l.cache[chave] = nil
+		}
+		for _, doc := range mascararTodos(ctx, docs) {
+			for _, chave := range l.chaves(doc) {
+				if chave = normalizar(chave); pedidas[chave] {
+					l.cache[chave] = append(l.cache[chave], doc)
+				}
+			}
+		}
+	}
+	return l.cache, nil
+}
+
+// normalizar iguala os inteiros do cadastro (int) aos dos argumentos e do banco (int32, int64).
+func normalizar(chave interface{}) interface{} {
+	switch n := chave.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	}
+	return chave
+}
+
+func nuloOuZero(chave interface{}) bool {
+	return chave == nil || chave == int64(0) || chave == primitive.NilObjectID
+}
+
+func porCampo(campo string) func([]interface{}) bson.M {
+	return func(chaves []interface{}) bson.M { return bson.M{campo: bson.M{"$in": chaves}} }
+}
+
+func (gh *GraphQLHandler) cursosPorCodigo() *carregador {
+	return &carregador{col: gh.Cursos, tipo: tipoCurso, filtro: porCampo("codigo"),
+		chaves: func(doc interface{}) []interface{} { return []interface{}{doc.(Cursos).Codigo} }}
+}
+
+func (gh *GraphQLHandler) disciplinasPorID() *carregador {
+	return &carregador{col: gh.Disciplinas, tipo: tipoDisciplina, filtro: porCampo("_id"),
+		chaves: func(doc interface{}) []interface{} { return []interface{}{doc.(Disciplinas).ID} }}
+}
+
+func (gh *GraphQLHandler) disciplinasPorCurso() *carregador {
+	return &carregador{col: gh.Disciplinas, tipo: tipoDisciplina, filtro: porCampo("curso"),
+		chaves: func(doc interface{}) []interface{} { return []interface{}{doc.(Disciplinas).Curso} }}
+}
+
+func (gh *GraphQLHandler) alunosPorCurso() *carregador {
+	return &carregador{col: gh.Alunos, tipo: tipoAluno,
+		filtro: func(chaves []interface{}) bson.M {
+			return bson.M{"$or": bson.A{bson.M{"curso": bson.M{"$in": chaves}}, bson.M{"cursos": bson.M{"$in": chaves}}}}
+		},
+		chaves: func(doc interface{}) []interface{} {
+			a := doc.(Alunos)
+			return append([]interface{}{a.Curso}, inteiros(a.Cursos)...)
+		}}
+}
+
+func (gh *GraphQLHandler) professoresPorDisciplina() *carregador {
+	return &carregador{col: gh.Professores, tipo: tipoProfessor, filtro: porCampo("disciplinas"),
+		chaves: func(doc interface{}) []interface{} {
+			var ids []interface{}
+			for _, id := range doc.(Professores).Disciplinas {
+				ids = append(ids, id)
+			}
+			return ids
+		}}
+}
+
+// buscarTipados decodifica os documentos na struct do recurso e devolve cada um como interface{}.
+func buscarTipados(ctx context.Context, col dbiface.Collection, filtro bson.M, tipo reflect.Type, opts *options.FindOptions) ([]interface{}, error) {
+	cursor, err := col.Find(ctx, filtro, opts)
+	if err != nil {
+		return nil, err
+	}
+	lista := reflect.New(reflect.SliceOf(tipo))
+	if err := cursor.All(ctx, lista.Interface()); err != nil {
+		return nil, err
+	}
+	docs := make([]interface{}, lista.Elem().Len())
+	for i := range docs {
+		docs[i] = lista.Elem().Index(i).Interface()
+	}
+	return docs, nil
+}
+
+// mascararTodos aplica o mascaramento dos dados pessoais com as claims da consulta.
+func mascararTodos(ctx context.Context, docs []interface{}) []interface{} {
+	var claims *auth.Claims
+	if r := requisicaoDoContexto(ctx); r != nil {
+		claims = r.claims
+	}
+	for i, doc := range docs {
+		docs[i] = Mascarar(doc, claims)
+	}
+	return docs
+}
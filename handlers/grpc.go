@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/escolapb"
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"github.com/labstack/gommon/random"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// metadadosGRPC são os metadados das chamadas gRPC repassados como cabeçalhos aos middlewares. X-Forwarded-For e
+// X-Real-IP ficam de fora: o IP é sempre o do par da conexão, que o cliente não escolhe.
+var metadadosGRPC = []string{echo.HeaderAuthorization, "X-API-Key", "Accept-Language"}
+
+// PonteGRPC faz as chamadas gRPC passarem pelos mesmos middlewares das rotas REST (autenticação, política e
+// limites): cada método é tratado como uma requisição à rota equivalente em Rotas, por exemplo
+// "/smartschool.v1.Alunos/BuscarAluno": "GET /alunos/:id", com o id da mensagem no parâmetro da rota e os
+// metadados no lugar dos cabeçalhos. Métodos ausentes de Rotas são negados. Os serviços gRPC deste pacote
+// dependem dos interceptadores da ponte.
+type PonteGRPC struct {
+	Echo           *echo.Echo
+	Rotas          map[string]string
+	Intermediarios []echo.MiddlewareFunc
+}
+
+type chaveContextoEcho struct{}
+
+// contextoEcho devolve o echo.Context montado pela ponte para a chamada em andamento, com as claims e o ator
+// definidos pelos middlewares.
+func contextoEcho(ctx context.Context) echo.Context {
+	c, _ := ctx.Value(chaveContextoEcho{}).(echo.Context)
+	return c
+}
+
+// respostaGRPC recebe o que os middlewares escreveriam na resposta HTTP; na ponte só os cabeçalhos importam.
+type respostaGRPC struct {
+	cabecalhos http.Header
+}
+
+func (r *respostaGRPC) Header() http.Header         { return r.cabecalhos }
+func (r *respostaGRPC) Write(b []byte) (int, error) { return len(b), nil }
+func (r *respostaGRPC) WriteHeader(int)             {}
+
+// Unario é o interceptador das chamadas unárias.
+func (p *PonteGRPC) Unario(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	var id string
+	if m, ok := req.(interface{ GetId() string }); ok {
+		id = m.GetId()
+	}
+	var resposta interface{}
+	err := p.executar(ctx, info.FullMethod, id, func(ctx context.Context) error {
+		var err error
+		resposta, err = handler(ctx, req)
+		return err
+	})
+	return resposta, err
+}
+
+// fluxoGRPC troca o contexto do stream pelo que carrega o echo.Context.
+type fluxoGRPC struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fluxoGRPC) Context() context.Context {
+	return f.ctx
+}
+
+// Fluxo é o interceptador das chamadas com stream; as rotas equivalentes são listagens, sem id.
+func (p *PonteGRPC) Fluxo(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return p.executar(ss.Context(), info.FullMethod, "", func(ctx context.Context) error {
+		return handler(srv, &fluxoGRPC{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// executar monta a requisição da rota equivalente ao método, passa pelos middlewares e converte o erro,
+// do middleware ou do serviço, em um status gRPC no idioma do metadado accept-language.
+func (p *PonteGRPC) executar(ctx context.Context, metodo, id string, chamada func(context.Context) error) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	primeiro := func(chave string) string {
+		if valores := md.Get(chave); len(valores) > 0 {
+			return valores[0]
+		}
+		return ""
+	}
+	idioma := i18n.Negociar(primeiro("accept-language"))
+	requestID := primeiro("x-request-id")
+	if requestID == "" {
+		requestID = random.String(32)
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	rota, ok := p.Rotas[metodo]
+	if !ok {
+		err := echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("The method %s is not allowed", metodo))
+		return problema.StatusGRPC(err, idioma).Err()
+	}
+	partes := strings.SplitN(rota, " ", 2)
+	req, err := http.NewRequestWithContext(ctx, partes[0], strings.Replace(partes[1], ":id", url.PathEscape(id), 1), nil)
+	if err != nil {
+		return problema.StatusGRPC(err, idioma).Err()
+	}
+	for _, cabecalho := range metadadosGRPC {
+		if valor := primeiro(strings.ToLower(cabecalho)); valor != "" {
+			req.Header.Set(cabecalho, valor)
+		}
+	}
+	if par, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = par.Addr.String()
+	}
+	c := p.Echo.NewContext(req, &respostaGRPC{cabecalhos: make(http.Header)})
+	c.SetPath(partes[1])
+	if strings.Contains(partes[1], ":id") {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+	h := func(c echo.Context) error {
+		return chamada(context.WithValue(ctx, chaveContextoEcho{}, c))
+	}
+	for i := len(p.Intermediarios) - 1; i >= 0; i-- {
+		h = p.Intermediarios[i](h)
+	}
+	err = h(c)
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok { //já é um status gRPC, por exemplo do envio no stream
+		return err
+	}
+	return problema.StatusGRPC(err, idioma).Err()
+}
+
+// consultaGRPC converte o filtro nos parâmetros de consulta da listagem REST equivalente.
+func consultaGRPC(f *escolapb.Filtro) url.Values {
+	q := make(url.Values)
+	for campo, valor := range f.GetCampos() {
+		q.Set(campo, valor)
+	}
+	if f.GetLimite() != 0 {
+		q.Set("limite", strconv.FormatInt(f.GetLimite(), 10))
+	}
+	if f.GetPagina() != 0 {
+		q.Set("pagina", strconv.FormatInt(f.GetPagina(), 10))
+	}
+	return q
+}
+
+// exportar percorre a listagem de modelo pelo cursor, entregando a enviar um documento por vez.
+func exportar(ctx context.Context, collection dbiface.Collection, f *escolapb.Filtro, modelo interface{}, enviar func(*mongo.Cursor) error) error {
+	filter, opts, herr := consultaListagem(consultaGRPC(f), false, modelo, claimsDaRequisicao(contextoEcho(ctx)))
+	if herr != nil {
+		return herr
+	}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		log.Errorf("Unable to find the documents to export: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to export the documents")
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		if err := enviar(cursor); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Errorf("Unable to read the cursor: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to export the documents")
+	}
+	return nil
+}
+
+// identificadores converte os ids devolvidos pelas inserções.
+func identificadores(IDs []interface{}) *escolapb.Identificadores {
+	res := &escolapb.Identificadores{}
+	for _, id := range IDs {
+		if oid, ok := id.(primitive.ObjectID); ok {
+			res.Ids = append(res.Ids, oid.Hex())
+		}
+	}
+	return res
+}
+
+// paraMensagem preenche a mensagem com o documento pelos nomes dos campos no JSON, que são os mesmos nas
+// duas representações, exceto o "_id", que nas mensagens é "id".
+func paraMensagem(doc interface{}, m proto.Message) error {
+	dados, err := json.Marshal(doc)
+	if err == nil {
+		var campos map[string]interface{}
+		if err = json.Unmarshal(dados, &campos); err == nil {
+			if id, ok := campos["_id"]; ok {
+				delete(campos, "_id")
+				campos["id"] = id
+			}
+			if dados, err = json.Marshal(campos); err == nil {
+				err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(dados, m)
+			}
+		}
+	}
+	if err != nil {
+		log.Errorf("Unable to convert %T to %T: %v", doc, m, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to encode the response")
+	}
+	return nil
+}
+
+// paraMensagens acrescenta à lista de mensagens (um *[]*escolapb.X) uma mensagem por documento de docs.
+func paraMensagens(docs interface{}, lista interface{}) error {
+	v := reflect.ValueOf(docs)
+	destino := reflect.ValueOf(lista).Elem()
+	for i := 0; i < v.Len(); i++ {
+		m := reflect.New(destino.Type().Elem().Elem())
+		if err := paraMensagem(v.Index(i).Interface(), m.Interface().(proto.Message)); err != nil {
+			return err
+		}
+		destino.Set(reflect.Append(destino, m))
+	}
+	return nil
+}
+
+// camposDaMensagem é o inverso de paraMensagem para as escritas: com a máscara, os campos listados, mesmo
+// vazios; sem ela, os campos preenchidos. O id e a data de exclusão não são alterados pelos clientes.
+func camposDaMensagem(m proto.Message, mascara *fieldmaskpb.FieldMask) (map[string]interface{}, *echo.HTTPError) {
+	r := m.ProtoReflect()
+	editavel := func(fd protoreflect.FieldDescriptor) bool {
+		return fd.Name() != "id" && fd.Kind() != protoreflect.MessageKind
+	}
+	campos := make(map[string]interface{})
+	incluir := func(fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+		if !fd.IsList() {
+			campos[fd.JSONName()] = v.Interface()
+			return
+		}
+		lista := make([]interface{}, v.List().Len())
+		for i := range lista {
+			lista[i] = v.List().Get(i).Interface()
+		}
+		campos[fd.JSONName()] = lista
+	}
+	if len(mascara.GetPaths()) == 0 {
+		r.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			if editavel(fd) {
+				incluir(fd, v)
+			}
+			return true
+		})
+		return campos, nil
+	}
+	for _, caminho := range mascara.GetPaths() {
+		fd := r.Descriptor().Fields().ByName(protoreflect.Name(caminho))
+		if fd == nil || !editavel(fd) {
+			return nil, problema.Novo(http.StatusBadRequest, problema.CodigoCampoImutavel, fmt.Sprintf("The field %q cannot be updated", caminho)).HTTP()
+		}
+		incluir(fd, r.Get(fd))
+	}
+	return campos, nil
+}
+
+// corpoAtualizacao é o corpo que a rota PUT equivalente receberia.
+func corpoAtualizacao(m proto.Message, mascara *fieldmaskpb.FieldMask) (io.ReadCloser, *echo.HTTPError) {
+	campos, herr := camposDaMensagem(m, mascara)
+	if herr != nil {
+		return nil, herr
+	}
+	dados, err := json.Marshal(campos)
+	if err != nil {
+		return nil, problema.CorpoInvalido().HTTP()
+	}
+	return ioutil.NopCloser(bytes.NewReader(dados)), nil
+}
+
+// daLista converte as mensagens de uma inserção (um []*escolapb.X) nos documentos (um *[]X).
+func daLista(mensagens interface{}, docs interface{}) *echo.HTTPError {
+	v := reflect.ValueOf(mensagens)
+	lista := make([]map[string]interface{}, v.Len())
+	for i := range lista {
+		campos, herr := camposDaMensagem(v.Index(i).Interface().(proto.Message), nil)
+		if herr != nil {
+			return herr
+		}
+		lista[i] = campos
+	}
+	dados, err := json.Marshal(lista)
+	if err == nil {
+		err = json.Unmarshal(dados, docs)
+	}
+	if err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	return nil
+}
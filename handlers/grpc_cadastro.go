@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/krunal4amity/tronicscorp/escolapb"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AlunosGRPC implementa o serviço smartschool.v1.Alunos com as mesmas funções das rotas /alunos.
+type AlunosGRPC struct {
+	escolapb.UnimplementedAlunosServer
+	H *AlunosHandler
+}
+
+func (s *AlunosGRPC) InserirAluno(ctx context.Context, req *escolapb.ListaAlunos) (*escolapb.Identificadores, error) {
+	var alunos []Alunos
+	if err := daLista(req.Alunos, &alunos); err != nil {
+		return nil, err
+	}
+	IDs, err := inserirAluno(ctx, eventoDaRequisicao(contextoEcho(ctx)), alunos, s.H.Col, s.H.Matriculas, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return identificadores(IDs), nil
+}
+
+func (s *AlunosGRPC) BuscarAlunos(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaAlunos, error) {
+	return s.listar(ctx, req, false)
+}
+
+func (s *AlunosGRPC) BuscarLixeira(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaAlunos, error) {
+	return s.listar(ctx, req, true)
+}
+
+func (s *AlunosGRPC) listar(ctx context.Context, req *escolapb.Filtro, lixeira bool) (*escolapb.ListaAlunos, error) {
+	alunos, err := buscarAlunos(ctx, consultaGRPC(req), s.H.Col, lixeira, claimsDaRequisicao(contextoEcho(ctx)))
+	if err != nil {
+		return nil, err
+	}
+	lista := &escolapb.ListaAlunos{}
+	if err := paraMensagens(mascarar(contextoEcho(ctx), alunos), &lista.Alunos); err != nil {
+		return nil, err
+	}
+	return lista, nil
+}
+
+func (s *AlunosGRPC) ExportarAlunos(req *escolapb.Filtro, stream escolapb.Alunos_ExportarAlunosServer) error {
+	c := contextoEcho(stream.Context())
+	return exportar(stream.Context(), s.H.Col, req, Alunos{}, func(cursor *mongo.Cursor) error {
+		var aluno Alunos
+		if err := cursor.Decode(&aluno); err != nil {
+			return err
+		}
+		m := &escolapb.Aluno{}
+		if err := paraMensagem(mascarar(c, aluno), m); err != nil {
+			return err
+		}
+		return stream.Send(m)
+	})
+}
+
+func (s *AlunosGRPC) BuscarAluno(ctx context.Context, req *escolapb.Identificador) (*escolapb.Aluno, error) {
+	aluno, err := buscarAluno(ctx, req.Id, s.H.Col)
+	if err != nil {
+		return nil, err
+	}
+	return s.mensagem(ctx, aluno)
+}
+
+func (s *AlunosGRPC) mensagem(ctx context.Context, aluno Alunos) (*escolapb.Aluno, error) {
+	m := &escolapb.Aluno{}
+	if err := paraMensagem(mascarar(contextoEcho(ctx), aluno), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *AlunosGRPC) AtualizarAluno(ctx context.Context, req *escolapb.AtualizacaoAluno) (*escolapb.Aluno, error) {
+	corpo, err := corpoAtualizacao(req.Aluno, req.Campos)
+	if err != nil {
+		return nil, err
+	}
+	aluno, err := alterarAluno(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, corpo, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return s.mensagem(ctx, aluno)
+}
+
+func (s *AlunosGRPC) DeletarAluno(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := deletarAluno(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+func (s *AlunosGRPC) RestaurarAluno(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := restaurarAluno(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+// ProfessoresGRPC implementa o serviço smartschool.v1.Professores com as mesmas funções das rotas /professores.
+type ProfessoresGRPC struct {
+	escolapb.UnimplementedProfessoresServer
+	H *ProfessoresHandler
+}
+
+func (s *ProfessoresGRPC) InserirProfessor(ctx context.Context, req *escolapb.ListaProfessores) (*escolapb.Identificadores, error) {
+	var professores []Professores
+	if err := daLista(req.Professores, &professores); err != nil {
+		return nil, err
+	}
+	IDs, err := inserirProfessor(ctx, eventoDaRequisicao(contextoEcho(ctx)), professores, s.H.Col, s.H.Registros, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return identificadores(IDs), nil
+}
+
+func (s *ProfessoresGRPC) BuscarProfessores(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaProfessores, error) {
+	return s.listar(ctx, req, false)
+}
+
+func (s *ProfessoresGRPC) BuscarLixeira(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaProfessores, error) {
+	return s.listar(ctx, req, true)
+}
+
+func (s *ProfessoresGRPC) listar(ctx context.Context, req *escolapb.Filtro, lixeira bool) (*escolapb.ListaProfessores, error) {
+	professores, err := buscarProfessores(ctx, consultaGRPC(req), s.H.Col, lixeira, claimsDaRequisicao(contextoEcho(ctx)))
+	if err != nil {
+		return nil, err
+	}
+	lista := &escolapb.ListaProfessores{}
+	if err := paraMensagens(mascarar(contextoEcho(ctx), professores), &lista.Professores); err != nil {
+		return nil, err
+	}
+	return lista, nil
+}
+
+func (s *ProfessoresGRPC) ExportarProfessores(req *escolapb.Filtro, stream escolapb.Professores_ExportarProfessoresServer) error {
+	c := contextoEcho(stream.Context())
+	return exportar(stream.Context(), s.H.Col, req, Professores{}, func(cursor *mongo.Cursor) error {
+		var professor Professores
+		if err := cursor.Decode(&professor); err != nil {
+			return err
+		}
+		m := &escolapb.Professor{}
+		if err := paraMensagem(mascarar(c, professor), m); err != nil {
+			return err
+		}
+		return stream.Send(m)
+	})
+}
+
+func (s *ProfessoresGRPC) BuscarProfessor(ctx context.Context, req *escolapb.Identificador) (*escolapb.Professor, error) {
+	professor, err := buscarProfessor(ctx, req.Id, s.H.Col)
+	if err != nil {
+		return nil, err
+	}
+	return s.mensagem(ctx, professor)
+}
+
+func (s *ProfessoresGRPC) mensagem(ctx context.Context, professor Professores) (*escolapb.Professor, error) {
+	m := &escolapb.Professor{}
+	if err := paraMensagem(mascarar(contextoEcho(ctx), professor), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *ProfessoresGRPC) AtualizarProfessor(ctx context.Context, req *escolapb.AtualizacaoProfessor) (*escolapb.Professor, error) {
+	corpo, err := corpoAtualizacao(req.Professor, req.Campos)
+	if err != nil {
+		return nil, err
+	}
+	professor, err := alterarProfessor(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, corpo, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return s.mensagem(ctx, professor)
+}
+
+func (s *ProfessoresGRPC) DeletarProfessor(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := deletarProfessor(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+func (s *ProfessoresGRPC) RestaurarProfessor(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := restaurarProfessor(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+// CursosGRPC implementa o serviço smartschool.v1.Cursos com as mesmas funções das rotas /cursos.
+type CursosGRPC struct {
+	escolapb.UnimplementedCursosServer
+	H *CursosHandler
+}
+
+func (s *CursosGRPC) InserirCurso(ctx context.Context, req *escolapb.ListaCursos) (*escolapb.Identificadores, error) {
+	var cursos []Cursos
+	if err := daLista(req.Cursos, &cursos); err != nil {
+		return nil, err
+	}
+	IDs, err := inserirCurso(ctx, eventoDaRequisicao(contextoEcho(ctx)), cursos, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return identificadores(IDs), nil
+}
+
+func (s *CursosGRPC) BuscarCursos(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaCursos, error) {
+	return s.listar(ctx, req, false)
+}
+
+func (s *CursosGRPC) BuscarLixeira(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaCursos, error) {
+	return s.listar(ctx, req, true)
+}
+
+func (s *CursosGRPC) listar(ctx context.Context, req *escolapb.Filtro, lixeira bool) (*escolapb.ListaCursos, error) {
+	cursos, err := buscarCursos(ctx, consultaGRPC(req), s.H.Col, lixeira)
+	if err != nil {
+		return nil, err
+	}
+	lista := &escolapb.ListaCursos{}
+	if err := paraMensagens(cursos, &lista.Cursos); err != nil {
+		return nil, err
+	}
+	return lista, nil
+}
+
+func (s *CursosGRPC) ExportarCursos(req *escolapb.Filtro, stream escolapb.Cursos_ExportarCursosServer) error {
+	return exportar(stream.Context(), s.H.Col, req, Cursos{}, func(cursor *mongo.Cursor) error {
+		var curso Cursos
+		if err := cursor.Decode(&curso); err != nil {
+			return err
+		}
+		m := &escolapb.Curso{}
+		if err := paraMensagem(curso, m); err != nil {
+			return err
+		}
+		return stream.Send(m)
+	})
+}
+
+func (s *CursosGRPC) BuscarCurso(ctx context.Context, req *escolapb.Identificador) (*escolapb.Curso, error) {
+	curso, err := buscarCurso(ctx, req.Id, s.H.Col)
+	if err != nil {
+		return nil, err
+	}
+	return mensagemCurso(curso)
+}
+
+func mensagemCurso(curso Cursos) (*escolapb.Curso, error) {
+	m := &escolapb.Curso{}
+	if err := paraMensagem(curso, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *CursosGRPC) AtualizarCurso(ctx context.Context, req *escolapb.AtualizacaoCurso) (*escolapb.Curso, error) {
+	corpo, err := corpoAtualizacao(req.Curso, req.Campos)
+	if err != nil {
+		return nil, err
+	}
+	curso, err := atualizarCurso(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, corpo, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return mensagemCurso(curso)
+}
+
+func (s *CursosGRPC) DeletarCurso(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := deletarCurso(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.Relacoes, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+func (s *CursosGRPC) RestaurarCurso(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := restaurarCurso(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+// DisciplinasGRPC implementa o serviço smartschool.v1.Disciplinas com as mesmas funções das rotas /disciplinas.
+type DisciplinasGRPC struct {
+	escolapb.UnimplementedDisciplinasServer
+	H *DisciplinasHandler
+}
+
+func (s *DisciplinasGRPC) InserirDisciplina(ctx context.Context, req *escolapb.ListaDisciplinas) (*escolapb.Identificadores, error) {
+	var disciplinas []Disciplinas
+	if err := daLista(req.Disciplinas, &disciplinas); err != nil {
+		return nil, err
+	}
+	IDs, err := inserirDisciplina(ctx, eventoDaRequisicao(contextoEcho(ctx)), disciplinas, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return identificadores(IDs), nil
+}
+
+func (s *DisciplinasGRPC) BuscarDisciplinas(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaDisciplinas, error) {
+	return s.listar(ctx, req, false)
+}
+
+func (s *DisciplinasGRPC) BuscarLixeira(ctx context.Context, req *escolapb.Filtro) (*escolapb.ListaDisciplinas, error) {
+	return s.listar(ctx, req, true)
+}
+
+func (s *DisciplinasGRPC) listar(ctx context.Context, req *escolapb.Filtro, lixeira bool) (*escolapb.ListaDisciplinas, error) {
+	disciplinas, err := buscarDisciplinas(ctx, consultaGRPC(req), s.H.Col, lixeira)
+	if err != nil {
+		return nil, err
+	}
+	lista := &escolapb.ListaDisciplinas{}
+	if err := paraMensagens(disciplinas, &lista.Disciplinas); err != nil {
+		return nil, err
+	}
+	return lista, nil
+}
+
+func (s *DisciplinasGRPC) ExportarDisciplinas(req *escolapb.Filtro, stream escolapb.Disciplinas_ExportarDisciplinasServer) error {
+	return exportar(stream.Context(), s.H.Col, req, Disciplinas{}, func(cursor *mongo.Cursor) error {
+		var disciplina Disciplinas
+		if err := cursor.Decode(&disciplina); err != nil {
+			return err
+		}
+		m := &escolapb.Disciplina{}
+		if err := paraMensagem(disciplina, m); err != nil {
+			return err
+		}
+		return stream.Send(m)
+	})
+}
+
+func (s *DisciplinasGRPC) BuscarDisciplina(ctx context.Context, req *escolapb.Identificador) (*escolapb.Disciplina, error) {
+	disciplina, err := buscarDisciplina(ctx, req.Id, s.H.Col)
+	if err != nil {
+		return nil, err
+	}
+	return mensagemDisciplina(disciplina)
+}
+
+func mensagemDisciplina(disciplina Disciplinas) (*escolapb.Disciplina, error) {
+	m := &escolapb.Disciplina{}
+	if err := paraMensagem(disciplina, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *DisciplinasGRPC) AtualizarDisciplina(ctx context.Context, req *escolapb.AtualizacaoDisciplina) (*escolapb.Disciplina, error) {
+	corpo, err := corpoAtualizacao(req.Disciplina, req.Campos)
+	if err != nil {
+		return nil, err
+	}
+	disciplina, err := atualizarDisciplina(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, corpo, s.H.Col, s.H.Regras, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return mensagemDisciplina(disciplina)
+}
+
+func (s *DisciplinasGRPC) DeletarDisciplina(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := deletarDisciplina(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.Relacoes, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
+
+func (s *DisciplinasGRPC) RestaurarDisciplina(ctx context.Context, req *escolapb.Identificador) (*escolapb.Contagem, error) {
+	total, err := restaurarDisciplina(ctx, eventoDaRequisicao(contextoEcho(ctx)), req.Id, s.H.Col, s.H.historico())
+	if err != nil {
+		return nil, err
+	}
+	return &escolapb.Contagem{Total: total}, nil
+}
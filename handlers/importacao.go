@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/planilha"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ImportacaoProcessando = "processing"
+	ImportacaoConcluida   = "completed"
+	ImportacaoFalhou      = "failed"
+)
+
+const (
+	maxErrosImportacao = 1000 //as demais linhas com erro só entram na contagem
+	tamanhoPrevia      = 20   //documentos mostrados na simulação
+	intervaloAndamento = 100  //linhas entre as gravações do andamento das importações assíncronas
+	//sem andamento gravado nesse prazo, a importação em segundo plano parou com a instância que a processava
+	prazoAndamento = 5 * time.Minute
+)
+
+// Importacao é o andamento e o relatório de uma importação de planilha. As importações grandes continuam em
+// segundo plano e são acompanhadas em GET /<recurso>/importar/:id até a situação deixar de ser "processing".
+type Importacao struct {
+	ID           primitive.ObjectID `json:"_id" bson:"_id"`
+	Recurso      string             `json:"recurso" bson:"recurso"`
+	Arquivo      string             `json:"arquivo" bson:"arquivo"`
+	Simulacao    bool               `json:"simulacao" bson:"simulacao"` //dryRun: valida sem gravar
+	Situacao     string             `json:"situacao" bson:"situacao"`
+	Colunas      map[string]string  `json:"colunas" bson:"colunas"` //coluna da planilha: campo
+	Ignoradas    []string           `json:"colunasIgnoradas,omitempty" bson:"colunasIgnoradas,omitempty"`
+	Linhas       int                `json:"linhas" bson:"linhas"` //linhas de dados, sem o cabeçalho e as vazias
+	Processadas  int                `json:"processadas" bson:"processadas"`
+	Importadas   int                `json:"importadas" bson:"importadas"` //na simulação, as que seriam importadas
+	ComErro      int                `json:"comErro" bson:"comErro"`
+	Erros        []ErroLinha        `json:"erros,omitempty" bson:"erros,omitempty"`
+	Previa       []bson.M           `json:"previa,omitempty" bson:"previa,omitempty"` //primeiros documentos da simulação
+	Ator         string             `json:"ator" bson:"ator"`
+	RequestID    string             `json:"requestId" bson:"requestId"`
+	CriadaEm     time.Time          `json:"criadaEm" bson:"criadaEm"`
+	AtualizadaEm time.Time          `json:"atualizadaEm" bson:"atualizadaEm"` //última gravação do andamento
+	ConcluidaEm  *time.Time         `json:"concluidaEm,omitempty" bson:"concluidaEm,omitempty"`
+}
+
+// ErroLinha é o motivo de uma linha não ter sido importada, já traduzido para o idioma de quem enviou a
+// planilha. Linha segue a numeração do Excel, com o cabeçalho na linha 1.
+type ErroLinha struct {
+	Linha    int              `json:"linha" bson:"linha"`
+	Codigo   string           `json:"codigo" bson:"codigo"`
+	Mensagem string           `json:"mensagem" bson:"mensagem"`
+	Campos   []CampoReprovado `json:"campos,omitempty" bson:"campos,omitempty"`
+}
+
+type CampoReprovado struct {
+	Campo    string `json:"campo" bson:"campo"`
+	Regra    string `json:"regra" bson:"regra"`
+	Mensagem string `json:"mensagem" bson:"mensagem"`
+}
+
+// FormularioImportacao descreve o multipart/form-data das rotas /importar. Sem mapeamento, as colunas cujo
+// cabeçalho é o nome de um campo (sem diferenciar maiúsculas) são importadas e as demais ignoradas.
+type FormularioImportacao struct {
+	Arquivo    []byte            `json:"arquivo" validate:"required"`
+	Formato    string            `json:"formato,omitempty"`    //csv ou xlsx; pela extensão do arquivo quando ausente
+	Mapeamento map[string]string `json:"mapeamento,omitempty"` //JSON com coluna da planilha: campo
+}
+
+// Importador recebe as planilhas das rotas /importar e guarda o relatório de cada importação em Col.
+type Importador struct {
+	Col dbiface.Collection
+	//planilhas com até LimiteSincrono linhas são processadas na requisição; as maiores, em segundo plano
+	LimiteSincrono int
+}
+
+// importavel liga o Importador ao caminho de inserção de um recurso: inserir grava um documento do tipo
+// pela mesma função do POST, que também registra a auditoria e a versão, e devolve o documento gravado.
+type importavel struct {
+	recurso string
+	tipo    reflect.Type
+	regras  *Regras
+	inserir func(ctx context.Context, ev Evento, doc interface{}) (interface{}, *echo.HTTPError)
+}
+
+// importar lê a planilha, mapeia as colunas e processa as linhas uma a uma, para que uma linha inválida não
+// impeça as demais; com ?dryRun=true só valida, devolvendo os erros e uma prévia dos documentos.
+func (im *Importador) importar(c echo.Context, imp importavel) error {
+	arquivo, err := c.FormFile("arquivo")
+	if err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoPlanilhaInvalida, "The spreadsheet is required in the arquivo field").HTTP()
+	}
+	formato := c.FormValue("formato")
+	if formato == "" {
+		formato = planilha.Formato(arquivo.Filename)
+	}
+	if formato != planilha.FormatoCSV && formato != planilha.FormatoXLSX {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Unsupported spreadsheet format, use csv or xlsx")
+	}
+	f, err := arquivo.Open()
+	if err != nil {
+		log.Errorf("Unable to open the uploaded file: %v", err)
+		return problema.Novo(http.StatusBadRequest, problema.CodigoPlanilhaInvalida, "Unable to read the spreadsheet").HTTP()
+	}
+	dados, err := ioutil.ReadAll(f)
+	f.Close()
+	if err != nil {
+		log.Errorf("Unable to read the uploaded file: %v", err)
+		return problema.Novo(http.StatusBadRequest, problema.CodigoPlanilhaInvalida, "Unable to read the spreadsheet").HTTP()
+	}
+	linhas, err := planilha.Ler(dados, formato)
+	if err != nil {
+		log.Errorf("Unable to read the spreadsheet %s: %v", arquivo.Filename, err)
+		return problema.Novo(http.StatusBadRequest, problema.CodigoPlanilhaInvalida, "Unable to read the spreadsheet").HTTP()
+	}
+	if len(linhas) == 0 {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoPlanilhaInvalida, "The spreadsheet is empty").HTTP()
+	}
+	colunas, ignoradas, herr := mapearColunas(linhas[0], c.FormValue("mapeamento"), camposImportaveis(imp.tipo))
+	if herr != nil {
+		return herr
+	}
+
+	ev := eventoDaRequisicao(c)
+	job := &Importacao{
+		ID:        primitive.NewObjectID(),
+		Recurso:   imp.recurso,
+		Arquivo:   arquivo.Filename,
+		Simulacao: c.QueryParam("dryRun") == "true",
+		Situacao:  ImportacaoProcessando,
+		Colunas:   make(map[string]string),
+		Ignoradas: ignoradas,
+		Ator:      ev.Ator,
+		RequestID: ev.RequestID,
+		CriadaEm:  time.Now(),
+	}
+	job.AtualizadaEm = job.CriadaEm
+	for i, campo := range colunas {
+		job.Colunas[strings.TrimSpace(linhas[0][i])] = campo
+	}
+	for _, linha := range linhas[1:] {
+		if !linhaVazia(linha) {
+			job.Linhas++
+		}
+	}
+	idioma := i18n.Negociar(c.Request().Header.Get("Accept-Language"))
+
+	if job.Linhas <= im.LimiteSincrono {
+		im.processar(job, imp, linhas, colunas, ev, idioma, false)
+		if _, err := im.Col.InsertOne(context.Background(), job); err != nil {
+			log.Errorf("Unable to save the import report: %v", err) //o relatório ainda vai na resposta
+		}
+		return c.JSON(http.StatusOK, mascararImportacao(c, *job))
+	}
+	if _, err := im.Col.InsertOne(context.Background(), job); err != nil {
+		log.Errorf("Unable to save the import: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to start the import")
+	}
+	resposta := *job //a cópia vai na resposta enquanto o job é alterado em segundo plano
+	go im.processar(job, imp, linhas, colunas, ev, idioma, true)
+	c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/%s/importar/%s", imp.recurso, job.ID.Hex()))
+	return c.JSON(http.StatusAccepted, resposta)
+}
+
+// processar importa (ou, na simulação, valida) as linhas de dados. Em segundo plano, grava o andamento a
+// cada intervaloAndamento linhas e o relatório final, inclusive quando a importação é interrompida.
+func (im *Importador) processar(job *Importacao, imp importavel, linhas [][]string, colunas map[int]string, ev Evento, idioma string, assincrona bool) {
+	ctx := context.Background()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("The import %s was interrupted: %v", job.ID.Hex(), r)
+			job.Situacao = ImportacaoFalhou
+		}
+		if job.Situacao == ImportacaoProcessando {
+			job.Situacao = ImportacaoConcluida
+		}
+		agora := time.Now()
+		job.ConcluidaEm = &agora
+		if assincrona {
+			im.salvar(ctx, job)
+		}
+	}()
+
+	campos := camposImportaveis(imp.tipo)
+	for i, linha := range linhas[1:] {
+		if linhaVazia(linha) {
+			continue
+		}
+		numero := i + 2
+		doc, err := converterLinha(linha, colunas, campos, imp.tipo)
+		if err == nil && job.Simulacao {
+			lista := reflect.Append(reflect.MakeSlice(reflect.SliceOf(imp.tipo), 0, 1), reflect.ValueOf(doc))
+			if err = validarLista(lista.Interface()); err == nil {
+				err = imp.regras.verificarLista(ctx, imp.recurso, lista.Interface())
+			}
+			if err == nil && len(job.Previa) < tamanhoPrevia {
+				job.Previa = append(job.Previa, paraDocumento(doc))
+			}
+		} else if err == nil {
+			_, err = imp.inserir(ctx, ev, doc)
+		}
+		if err != nil {
+			job.ComErro++
+			if len(job.Erros) < maxErrosImportacao {
+				job.Erros = append(job.Erros, erroLinha(numero, err, idioma))
+			}
+		} else {
+			job.Importadas++
+		}
+		job.Processadas++
+		if assincrona && job.Processadas%intervaloAndamento == 0 {
+			im.salvar(ctx, job)
+		}
+	}
+}
+
+func (im *Importador) salvar(ctx context.Context, job *Importacao) {
+	job.AtualizadaEm = time.Now()
+	if _, err := im.Col.ReplaceOne(ctx, bson.M{"_id": job.ID}, job); err != nil {
+		log.Errorf("Unable to save the progress of the import %s: %v", job.ID.Hex(), err)
+	}
+}
+
+// MarcarInterrompidas marca como falhas as importações em processamento sem andamento gravado há mais de
+// prazoAndamento, que pararam com a instância que as processava e nunca seriam concluídas. É chamada na
+// inicialização; as interrompidas há menos tempo são marcadas quando consultadas em buscar. As importações
+// das demais instâncias, que gravam o andamento, não são afetadas.
+func (im *Importador) MarcarInterrompidas(ctx context.Context) (int64, error) {
+	res, err := im.Col.UpdateMany(ctx, filtroInterrompidas(bson.M{}),
+		bson.M{"$set": bson.M{"situacao": ImportacaoFalhou, "concluidaEm": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func filtroInterrompidas(filter bson.M) bson.M {
+	filter["situacao"] = ImportacaoProcessando
+	filter["atualizadaEm"] = bson.M{"$lt": time.Now().Add(-prazoAndamento)}
+	return filter
+}
+
+// buscar atende GET /<recurso>/importar/:id.
+func (im *Importador) buscar(c echo.Context, recurso string) error {
+	docID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		log.Errorf("Cannot convert to ObjectID: %v", err)
+		return problema.IDInvalido().HTTP()
+	}
+	var job Importacao
+	if err := im.Col.FindOne(context.Background(), bson.M{"_id": docID, "recurso": recurso}).Decode(&job); err != nil {
+		log.Errorf("Unable to find the import: %v", err)
+		return echo.NewHTTPError(http.StatusNotFound, "Unable to find the import")
+	}
+	if job.Situacao == ImportacaoProcessando && time.Since(job.AtualizadaEm) > prazoAndamento {
+		agora := time.Now()
+		res, err := im.Col.UpdateOne(context.Background(), filtroInterrompidas(bson.M{"_id": docID}),
+			bson.M{"$set": bson.M{"situacao": ImportacaoFalhou, "concluidaEm": agora}})
+		if err == nil && res.ModifiedCount > 0 {
+			job.Situacao, job.ConcluidaEm = ImportacaoFalhou, &agora
+		}
+	}
+	return c.JSON(http.StatusOK, mascararImportacao(c, job))
+}
+
+// mascararImportacao aplica na prévia o mascaramento de quem consulta o relatório.
+func mascararImportacao(c echo.Context, job Importacao) Importacao {
+	previa := make([]bson.M, len(job.Previa))
+	for i, doc := range job.Previa {
+		copia := make(bson.M, len(doc))
+		for k, v := range doc {
+			copia[k] = v
+		}
+		mascararDocumento(job.Recurso, copia, claimsDaRequisicao(c))
+		previa[i] = copia
+	}
+	job.Previa = previa
+	return job
+}
+
+func erroLinha(numero int, err *echo.HTTPError, idioma string) ErroLinha {
+	p := problema.Traduzido(err, idioma)
+	erro := ErroLinha{Linha: numero, Codigo: p.Codigo, Mensagem: p.Detalhe}
+	if erro.Mensagem == "" {
+		erro.Mensagem = p.Titulo
+	}
+	for _, e := range p.Erros {
+		//as funções de inserção recebem uma lista de um documento e apontam os campos como "[0].nome"
+		erro.Campos = append(erro.Campos, CampoReprovado{Campo: strings.TrimPrefix(e.Campo, "[0]."), Regra: e.Regra, Mensagem: e.Mensagem})
+	}
+	return erro
+}
+
+func linhaVazia(linha []string) bool {
+	for _, celula := range linha {
+		if strings.TrimSpace(celula) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// camposImportaveis são os campos do JSON do tipo que uma planilha pode preencher: todos, exceto o id e a
+// data de exclusão.
+func camposImportaveis(t reflect.Type) map[string]reflect.Type {
+	campos := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		nome := strings.Split(f.Tag.Get("json"), ",")[0]
+		if nome == "" || nome == "-" || nome == "_id" || nome == "deletedAt" || f.PkgPath != "" {
+			continue
+		}
+		campos[nome] = f.Type
+	}
+	return campos
+}
+
+// mapearColunas associa o índice de cada coluna importada ao campo e lista as colunas ignoradas. O
+// mapeamento explícito é um objeto JSON com o cabeçalho da coluna e o campo, como {"Nome do aluno": "nome"}.
+func mapearColunas(cabecalho []string, mapeamento string, campos map[string]reflect.Type) (map[int]string, []string, *echo.HTTPError) {
+	porCabecalho := make(map[string]int)
+	for i, titulo := range cabecalho {
+		if titulo = strings.ToLower(strings.TrimSpace(titulo)); titulo != "" {
+			porCabecalho[titulo] = i
+		}
+	}
+	colunas := make(map[int]string)
+	if mapeamento != "" {
+		var explicito map[string]string
+		if err := json.Unmarshal([]byte(mapeamento), &explicito); err != nil {
+			return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoMapeamento, "Unable to parse the column mapping").HTTP()
+		}
+		for coluna, campo := range explicito {
+			if _, ok := campos[campo]; !ok {
+				return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoMapeamento, fmt.Sprintf("Unknown field %q in the column mapping", campo)).HTTP()
+			}
+			i, ok := porCabecalho[strings.ToLower(strings.TrimSpace(coluna))]
+			if !ok {
+				return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoMapeamento, fmt.Sprintf("Column %q not found in the spreadsheet", coluna)).HTTP()
+			}
+			colunas[i] = campo
+		}
+	} else {
+		for i, titulo := range cabecalho {
+			for campo := range campos {
+				if strings.EqualFold(strings.TrimSpace(titulo), campo) {
+					colunas[i] = campo
+				}
+			}
+		}
+	}
+	if len(colunas) == 0 {
+		return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoMapeamento, "No column of the spreadsheet matches a field").HTTP()
+	}
+	usados := make(map[string]bool)
+	for _, campo := range colunas {
+		if usados[campo] {
+			return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoMapeamento, fmt.Sprintf("The field %q is mapped to more than one column", campo)).HTTP()
+		}
+		usados[campo] = true
+	}
+	var ignoradas []string
+	for i, titulo := range cabecalho {
+		if _, ok := colunas[i]; !ok && strings.TrimSpace(titulo) != "" {
+			ignoradas = append(ignoradas, strings.TrimSpace(titulo))
+		}
+	}
+	return colunas, ignoradas, nil
+}
+
+// converterLinha monta o documento pelo mesmo JSON que o POST receberia, para valerem as mesmas conversões
+// (como a normalização do telefone). Células vazias deixam o campo sem valor.
+func converterLinha(linha []string, colunas map[int]string, campos map[string]reflect.Type, t reflect.Type) (interface{}, *echo.HTTPError) {
+	valores := make(map[string]interface{})
+	var reprovados []problema.ErroCampo
+	for i, campo := range colunas {
+		if i >= len(linha) {
+			continue
+		}
+		texto := strings.TrimSpace(linha[i])
+		if texto == "" {
+			continue
+		}
+		valor, ok := valorDaCelula(texto, campos[campo])
+		if !ok {
+			reprovados = append(reprovados, problema.ErroCampo{Campo: campo, Regra: "type", Mensagem: fmt.Sprintf("invalid value %q", texto)})
+			continue
+		}
+		valores[campo] = valor
+	}
+	if len(reprovados) > 0 {
+		p := problema.Novo(http.StatusBadRequest, problema.CodigoValidacao, "Unable to validate request payload")
+		p.Erros = reprovados
+		return nil, p.HTTP()
+	}
+	doc := reflect.New(t)
+	dados, err := json.Marshal(valores)
+	if err == nil {
+		err = json.Unmarshal(dados, doc.Interface())
+	}
+	if err != nil {
+		return nil, problema.CorpoInvalido().HTTP()
+	}
+	return doc.Elem().Interface(), nil
+}
+
+// valorDaCelula converte o texto da célula para o tipo do campo. Listas vêm separadas por vírgula, ponto e
+// vírgula, barra vertical ou espaço; números aceitam a vírgula decimal.
+func valorDaCelula(texto string, t reflect.Type) (interface{}, bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return valorDaCelula(texto, t.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(texto, 10, 64); err == nil {
+			return n, true
+		}
+		f, err := strconv.ParseFloat(strings.Replace(texto, ",", ".", 1), 64)
+		if err != nil || f != math.Trunc(f) {
+			return nil, false
+		}
+		return int64(f), true
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.Replace(texto, ",", ".", 1), 64)
+		return f, err == nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(texto)
+		return b, err == nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return texto, true
+		}
+		partes := strings.FieldsFunc(texto, func(r rune) bool {
+			return r == ',' || r == ';' || r == '|' || unicode.IsSpace(r)
+		})
+		lista := make([]interface{}, 0, len(partes))
+		for _, parte := range partes {
+			valor, ok := valorDaCelula(parte, t.Elem())
+			if !ok {
+				return nil, false
+			}
+			lista = append(lista, valor)
+		}
+		return lista, true
+	}
+	return texto, true
+}
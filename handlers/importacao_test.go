@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMapearColunas(t *testing.T) {
+	campos := camposImportaveis(reflect.TypeOf(Alunos{}))
+	if _, ok := campos["_id"]; ok {
+		t.Error("the id should not be importable")
+	}
+	if _, ok := campos["deletedAt"]; ok {
+		t.Error("the trash date should not be importable")
+	}
+
+	cabecalho := []string{" Nome ", "SOBRENOME", "Observações", "", "curso"}
+	colunas, ignoradas, err := mapearColunas(cabecalho, "", campos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(colunas, map[int]string{0: "nome", 1: "sobrenome", 4: "curso"}) {
+		t.Errorf("columns = %v", colunas)
+	}
+	if !reflect.DeepEqual(ignoradas, []string{"Observações"}) {
+		t.Errorf("ignored = %v", ignoradas)
+	}
+
+	colunas, ignoradas, err = mapearColunas([]string{"Nome do aluno", "Fone", "Nome"}, `{"nome do aluno": "nome", "Fone": "telefone"}`, campos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(colunas, map[int]string{0: "nome", 1: "telefone"}) || !reflect.DeepEqual(ignoradas, []string{"Nome"}) {
+		t.Errorf("explicit mapping: columns = %v, ignored = %v", colunas, ignoradas)
+	}
+
+	erros := []struct {
+		cabecalho  []string
+		mapeamento string
+		mensagem   string
+	}{
+		{[]string{"Nome"}, `{"Nome": `, "Unable to parse the column mapping"},
+		{[]string{"Nome"}, `{"Nome": "idade"}`, `Unknown field "idade" in the column mapping`},
+		{[]string{"Nome"}, `{"Aluno": "nome"}`, `Column "Aluno" not found in the spreadsheet`},
+		{[]string{"Aluno", "Fone"}, "", "No column of the spreadsheet matches a field"},
+		{[]string{"Nome", "nome"}, "", `The field "nome" is mapped to more than one column`},
+		{[]string{"Nome", "Aluno"}, `{"Nome": "nome", "Aluno": "nome"}`, `The field "nome" is mapped to more than one column`},
+	}
+	for _, caso := range erros {
+		_, _, err := mapearColunas(caso.cabecalho, caso.mapeamento, campos)
+		if err == nil {
+			t.Errorf("%v %s: no error", caso.cabecalho, caso.mapeamento)
+			continue
+		}
+		p := problema.Traduzido(err, i18n.Ingles)
+		if p.Status != http.StatusBadRequest || p.Codigo != problema.CodigoMapeamento || p.Detalhe != caso.mensagem {
+			t.Errorf("%v %s: got %+v, want %q", caso.cabecalho, caso.mapeamento, p, caso.mensagem)
+		}
+	}
+}
+
+func TestValorDaCelula(t *testing.T) {
+	var texto string
+	var numero int
+	var real float64
+	var logico bool
+	var ponteiro *int
+	casos := []struct {
+		texto string
+		tipo  reflect.Type
+		valor interface{}
+		ok    bool
+	}{
+		{"Ana", reflect.TypeOf(texto), "Ana", true},
+		{"42", reflect.TypeOf(numero), int64(42), true},
+		{"42,0", reflect.TypeOf(numero), int64(42), true}, //o Excel em português grava a vírgula decimal
+		{"1.5", reflect.TypeOf(numero), nil, false},
+		{"dez", reflect.TypeOf(numero), nil, false},
+		{"7", reflect.TypeOf(ponteiro), int64(7), true},
+		{"2,5", reflect.TypeOf(real), 2.5, true},
+		{"TRUE", reflect.TypeOf(logico), true, true},
+		{"sim", reflect.TypeOf(logico), nil, false},
+		{"1; 2 |3,4", reflect.TypeOf([]int{}), []interface{}{int64(1), int64(2), int64(3), int64(4)}, true},
+		{"1, dois", reflect.TypeOf([]int{}), nil, false},
+		{"abc", reflect.TypeOf([]byte{}), "abc", true},
+		{"5f1b2c3d4e5f6a7b8c9d0e1f", reflect.TypeOf(primitive.ObjectID{}), "5f1b2c3d4e5f6a7b8c9d0e1f", true}, //o JSON valida o id
+	}
+	for _, caso := range casos {
+		valor, ok := valorDaCelula(caso.texto, caso.tipo)
+		if ok != caso.ok || (ok && !reflect.DeepEqual(valor, caso.valor)) {
+			t.Errorf("valorDaCelula(%q, %v) = %#v, %v; want %#v, %v", caso.texto, caso.tipo, valor, ok, caso.valor, caso.ok)
+		}
+	}
+}
+
+func TestImportacaoInterrompida(t *testing.T) {
+	antiga := time.Now().Add(-2 * prazoAndamento)
+	parada := Importacao{ID: primitive.NewObjectID(), Recurso: "alunos", Situacao: ImportacaoProcessando, CriadaEm: antiga, AtualizadaEm: antiga}
+	ativa := Importacao{ID: primitive.NewObjectID(), Recurso: "alunos", Situacao: ImportacaoProcessando, CriadaEm: antiga, AtualizadaEm: time.Now()}
+	concluida := Importacao{ID: primitive.NewObjectID(), Recurso: "alunos", Situacao: ImportacaoConcluida, CriadaEm: antiga, AtualizadaEm: antiga}
+	im := &Importador{Col: novaColecao(parada, ativa, concluida)}
+	col := im.Col.(*colecaoMemoria)
+
+	n, err := im.MarcarInterrompidas(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("marked %d imports, %v; want only the one without progress", n, err)
+	}
+	if doc := col.buscarID(parada.ID); doc["situacao"] != ImportacaoFalhou || doc["concluidaEm"] == nil {
+		t.Errorf("stalled import = %v", doc)
+	}
+	if doc := col.buscarID(ativa.ID); doc["situacao"] != ImportacaoProcessando {
+		t.Errorf("an import still saving progress, maybe in another instance, was marked: %v", doc)
+	}
+
+	//a que parou depois da inicialização é marcada quando consultada
+	col.docs[1]["atualizadaEm"] = primitive.NewDateTimeFromTime(antiga)
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/alunos/importar/"+ativa.ID.Hex(), nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues(ativa.ID.Hex())
+	if err := im.buscar(c, "alunos"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rec.Body.String(), `"situacao":"failed"`) || col.buscarID(ativa.ID)["situacao"] != ImportacaoFalhou {
+		t.Errorf("got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Politica = modelos.Politica
+
+const (
+	Restringir = modelos.Restringir
+	Cascata    = modelos.Cascata
+	Anular     = modelos.Anular
+)
+
+// ConferirPolitica converte o texto da configuração em uma Politica, recusando valores desconhecidos, que
+// seriam tratados como restrict sem aviso.
+func ConferirPolitica(texto string) (Politica, error) {
+	switch p := Politica(texto); p {
+	case Restringir, Cascata, Anular:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown delete policy %q, expected restrict, cascade or nullify", texto)
+}
+
+// Relacao descreve uma coleção cujos documentos apontam para o recurso que está sendo deletado.
+type Relacao struct {
+	Recurso  string //nome exibido no relatório, por exemplo "alunos"
+	Col      dbiface.Collection
+	Campo    string //campo do dependente que guarda a referência
+	Lista    bool   //o campo é um array de referências
+	Politica Politica
+	//registram cada dependente alterado pelas políticas cascade e nullify
+	Auditoria *Auditor
+	Versoes   *Versionador
+}
+
+type Dependentes = modelos.Dependentes
+
+type RelatorioExclusao = modelos.RelatorioExclusao
+
+func buscarDependentes(ctx context.Context, relacoes []Relacao, chave interface{}) ([]Dependentes, *echo.HTTPError) {
+	var dependentes []Dependentes
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	for _, rel := range relacoes {
+		cursor, err := rel.Col.Find(ctx, filtroAtivos(bson.M{rel.Campo: chave}), opts) //itens na lixeira não contam
+		if err != nil {
+			log.Errorf("Unable to find the dependents in %s: %v", rel.Recurso, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to check the dependents")
+		}
+		var docs []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			log.Errorf("Unable to read the cursor: %v", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to check the dependents")
+		}
+		if len(docs) == 0 {
+			continue
+		}
+		dep := Dependentes{Recurso: rel.Recurso, Politica: rel.Politica}
+		for _, doc := range docs {
+			dep.IDs = append(dep.IDs, doc.ID)
+		}
+		dependentes = append(dependentes, dep)
+	}
+	return dependentes, nil
+}
+
+// simularExclusao informa o que aconteceria com os dependentes, sem alterar nada (?dryRun=true).
+func simularExclusao(ctx context.Context, relacoes []Relacao, chave interface{}) (RelatorioExclusao, *echo.HTTPError) {
+	dependentes, err := buscarDependentes(ctx, relacoes, chave)
+	if err != nil {
+		return RelatorioExclusao{}, err
+	}
+	relatorio := RelatorioExclusao{Permitido: true, Dependentes: dependentes}
+	for _, dep := range dependentes {
+		if dep.Politica != Cascata && dep.Politica != Anular {
+			relatorio.Permitido = false
+		}
+	}
+	return relatorio, nil
+}
+
+// excluirComIntegridade aplica as políticas de cada relação e só então move o documento para a lixeira.
+// Dependentes em cascata também vão para a lixeira, então uma restauração posterior continua consistente.
+func excluirComIntegridade(ctx context.Context, ev Evento, id string, chave interface{}, collection dbiface.Collection, relacoes []Relacao) (int64, *echo.HTTPError) {
+	relatorio, err := simularExclusao(ctx, relacoes, chave)
+	if err != nil {
+		return 0, err
+	}
+	if !relatorio.Permitido {
+		p := problema.Novo(http.StatusConflict, problema.CodigoDependentes, "Unable to delete: the document has dependents")
+		return 0, p.Com("dependentes", relatorio.Dependentes).HTTP()
+	}
+	for _, rel := range relacoes {
+		if rel.Politica != Cascata && rel.Politica != Anular {
+			continue
+		}
+		if err := aplicarPolitica(ctx, ev, rel, chave); err != nil {
+			return 0, err
+		}
+	}
+	return moverParaLixeira(ctx, id, collection)
+}
+
+// aplicarPolitica altera os dependentes ativos da relação e registra cada um na auditoria, com o documento
+// antes e depois da política. A atualização se limita aos documentos lidos, para que a auditoria cubra
+// exatamente o que foi alterado.
+func aplicarPolitica(ctx context.Context, ev Evento, rel Relacao, chave interface{}) *echo.HTTPError {
+	antes, err := buscarTodos(ctx, rel.Col, filtroAtivos(bson.M{rel.Campo: chave}))
+	if err != nil {
+		log.Errorf("Unable to find the dependents in %s: %v", rel.Recurso, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the dependents")
+	}
+	if len(antes) == 0 {
+		return nil
+	}
+	ids := make([]interface{}, len(antes))
+	for i, doc := range antes {
+		ids[i] = doc["_id"]
+	}
+	var update bson.M
+	switch {
+	case rel.Politica == Cascata:
+		update = bson.M{"$set": bson.M{"deletedAt": time.Now()}}
+	case rel.Lista:
+		update = bson.M{"$pull": bson.M{rel.Campo: chave}}
+	default:
+		update = bson.M{"$unset": bson.M{rel.Campo: ""}}
+	}
+	if _, err := rel.Col.UpdateMany(ctx, filtroAtivos(bson.M{"_id": bson.M{"$in": ids}}), update); err != nil {
+		log.Errorf("Unable to apply the %s policy to %s: %v", rel.Politica, rel.Recurso, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the dependents")
+	}
+
+	depois := make(map[interface{}]bson.M)
+	docs, err := buscarTodos(ctx, rel.Col, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil { //a política já foi aplicada; as alterações ficam fora da auditoria e das versões
+		log.Errorf("Unable to read the dependents updated in %s: %v", rel.Recurso, err)
+	}
+	for _, doc := range docs {
+		depois[doc["_id"]] = doc
+	}
+	for _, doc := range antes {
+		id, _ := doc["_id"].(primitive.ObjectID)
+		if rel.Politica == Cascata {
+			rel.Auditoria.Registrar(ctx, ev, rel.Recurso, OperacaoExclusao, id, doc, nil)
+			rel.Versoes.Registrar(ctx, ev, rel.Recurso, OperacaoExclusao, id, doc)
+		} else if alterado, ok := depois[id]; ok {
+			rel.Auditoria.Registrar(ctx, ev, rel.Recurso, OperacaoAlteracao, id, doc, alterado)
+			rel.Versoes.Registrar(ctx, ev, rel.Recurso, OperacaoAlteracao, id, alterado)
+		}
+	}
+	return nil
+}
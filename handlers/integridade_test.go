@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/problema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestConferirPolitica(t *testing.T) {
+	for _, texto := range []string{"restrict", "cascade", "nullify"} {
+		if p, err := ConferirPolitica(texto); err != nil || string(p) != texto {
+			t.Errorf("ConferirPolitica(%q) = %q, %v", texto, p, err)
+		}
+	}
+	for _, texto := range []string{"", "Cascade", "delete"} {
+		if _, err := ConferirPolitica(texto); err == nil {
+			t.Errorf("ConferirPolitica(%q) should fail", texto)
+		}
+	}
+}
+
+func TestDeletarDisciplinaInexistenteNaoAlteraDependentes(t *testing.T) {
+	agora := time.Now()
+	naLixeira := Disciplinas{ID: primitive.NewObjectID(), Nome: "Cálculo", DeletadoEm: &agora}
+	disciplinas := novaColecao(naLixeira)
+	professores := novaColecao(Professores{ID: primitive.NewObjectID(), Nome: "Ana", Disciplinas: []primitive.ObjectID{naLixeira.ID}})
+	relacoes := []Relacao{{Recurso: "professores", Col: professores, Campo: "disciplinas", Lista: true, Politica: Anular}}
+
+	for _, id := range []string{naLixeira.ID.Hex(), primitive.NewObjectID().Hex()} {
+		_, err := deletarDisciplina(context.Background(), Evento{}, id, disciplinas, relacoes, historico{})
+		if err == nil || err.Code != http.StatusNotFound {
+			t.Errorf("deleting %s: got %v, want 404", id, err)
+		}
+	}
+	if professores.escritas != 0 || disciplinas.escritas != 0 {
+		t.Errorf("a failed delete wrote %d professores and %d disciplinas", professores.escritas, disciplinas.escritas)
+	}
+}
+
+func TestExcluirComIntegridade(t *testing.T) {
+	cursoID := primitive.NewObjectID()
+	novosCursos := func() *colecaoMemoria { return novaColecao(Cursos{ID: cursoID, Codigo: 7, Nome: "Física"}) }
+	aluno := Alunos{ID: primitive.NewObjectID(), Nome: "Bia", Curso: 7, Cursos: []int{7, 8}}
+
+	//restrict: nada muda e a resposta lista os dependentes
+	cursos, alunos := novosCursos(), novaColecao(aluno)
+	relacoes := []Relacao{{Recurso: "alunos", Col: alunos, Campo: "curso", Politica: Restringir}}
+	if _, err := excluirComIntegridade(context.Background(), Evento{}, cursoID.Hex(), 7, cursos, relacoes); err == nil || err.Code != http.StatusConflict {
+		t.Errorf("restrict: got %v, want 409", err)
+	}
+	if cursos.escritas != 0 || alunos.escritas != 0 {
+		t.Error("restrict: the delete changed documents")
+	}
+
+	//cascade: o aluno vai para a lixeira junto com o curso, e a exclusão dele é auditada
+	ev := Evento{Ator: "secretaria", RequestID: "req-1"}
+	cursos, alunos = novosCursos(), novaColecao(aluno)
+	auditoria := novaColecao()
+	relacoes = []Relacao{{Recurso: "alunos", Col: alunos, Campo: "curso", Politica: Cascata, Auditoria: &Auditor{Col: auditoria}}}
+	if _, err := excluirComIntegridade(context.Background(), ev, cursoID.Hex(), 7, cursos, relacoes); err != nil {
+		t.Fatalf("cascade: %v", err)
+	}
+	if alunos.buscarID(aluno.ID)["deletedAt"] == nil || cursos.buscarID(cursoID)["deletedAt"] == nil {
+		t.Error("cascade: the course and the student should be in the trash")
+	}
+	conferirAuditoria(t, "cascade", auditoria, aluno.ID, OperacaoExclusao, ev)
+
+	//nullify: a referência sai da lista, o aluno continua ativo e a alteração é auditada
+	cursos, alunos = novosCursos(), novaColecao(aluno)
+	auditoria = novaColecao()
+	relacoes = []Relacao{{Recurso: "alunos", Col: alunos, Campo: "cursos", Lista: true, Politica: Anular, Auditoria: &Auditor{Col: auditoria}}}
+	if _, err := excluirComIntegridade(context.Background(), ev, cursoID.Hex(), 7, cursos, relacoes); err != nil {
+		t.Fatalf("nullify: %v", err)
+	}
+	doc := alunos.buscarID(aluno.ID)
+	if doc["deletedAt"] != nil {
+		t.Error("nullify: the student should stay active")
+	}
+	if restantes := doc["cursos"].(bson.A); len(restantes) != 1 || !iguais(restantes[0], int64(8)) {
+		t.Errorf("nullify: cursos = %v, want [8]", restantes)
+	}
+	entrada := conferirAuditoria(t, "nullify", auditoria, aluno.ID, OperacaoAlteracao, ev)
+	if len(entrada.Alteracoes) != 1 || entrada.Alteracoes[0].Campo != "cursos" {
+		t.Errorf("nullify: the audit entry should record the change to cursos, got %+v", entrada.Alteracoes)
+	}
+}
+
+// conferirAuditoria exige uma única entrada de auditoria, do documento e da operação esperados.
+func conferirAuditoria(t *testing.T, caso string, col *colecaoMemoria, id primitive.ObjectID, operacao string, ev Evento) Auditoria {
+	t.Helper()
+	entradas, err := buscarAuditoria(context.Background(), bson.M{}, 0, col)
+	if err != nil || len(entradas) != 1 {
+		t.Fatalf("%s: want one audit entry, got %d (%v)", caso, len(entradas), err)
+	}
+	e := entradas[0]
+	if e.DocumentoID != id || e.Operacao != operacao || e.Ator != ev.Ator || e.RequestID != ev.RequestID {
+		t.Errorf("%s: unexpected audit entry %+v", caso, e)
+	}
+	return e
+}
+
+func TestCodigoDoCursoImutavel(t *testing.T) {
+	curso := Cursos{ID: primitive.NewObjectID(), Codigo: 1, Nome: "Redes", Nivel: "tecnico"}
+	cursos := novaColecao(curso)
+	corpo := ioutil.NopCloser(strings.NewReader(`{"codigo":2,"nome":"Redes de Computadores"}`))
+	_, err := atualizarCurso(context.Background(), Evento{}, curso.ID.Hex(), corpo, cursos, nil, historico{})
+	if err == nil || problema.Converter(err).Codigo != problema.CodigoCampoImutavel {
+		t.Errorf("changing the code would orphan the students: got %v, want %s", err, problema.CodigoCampoImutavel)
+	}
+	if cursos.escritas != 0 {
+		t.Errorf("the refused change wrote %d times", cursos.escritas)
+	}
+	//repetir o código é aceito
+	corpo = ioutil.NopCloser(strings.NewReader(`{"codigo":1,"nome":"Redes de Computadores"}`))
+	if _, err := atualizarCurso(context.Background(), Evento{}, curso.ID.Hex(), corpo, cursos, nil, historico{}); err != nil {
+		t.Errorf("keeping the code: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	OperacaoExportacao   = "export"    //exportação dos dados do titular
+	OperacaoAnonimizacao = "anonymize" //anonimização dos dados do titular, registrada sem os valores
+)
+
+// ValorAnonimizado substitui os textos pessoais. Campos marcados como "unico" recebem também o id do
+// documento, para não violar índices únicos; campos de outros tipos e os marcados como "remover", cujo
+// formato o validador confere (telefone, CPF...), são removidos.
+const ValorAnonimizado = "anonimizado"
+
+// Os campos pessoais que a anonimização substitui são marcados com a tag lgpd:"anonimizar" (ou
+// "anonimizar,unico", "anonimizar,remover"). Os demais, como matrícula, curso e disciplinas, formam o registro acadêmico que a
+// escola deve manter e não são alterados.
+
+type campoPessoal struct {
+	nome    string //nome no BSON
+	texto   bool
+	unico   bool
+	remover bool
+}
+
+func camposPessoais(t reflect.Type) []campoPessoal {
+	var campos []campoPessoal
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		opcoes := strings.Split(f.Tag.Get("lgpd"), ",")
+		if opcoes[0] != "anonimizar" {
+			continue
+		}
+		campo := campoPessoal{nome: strings.Split(f.Tag.Get("bson"), ",")[0], texto: f.Type.Kind() == reflect.String}
+		for _, opcao := range opcoes[1:] {
+			campo.unico = campo.unico || opcao == "unico"
+			campo.remover = campo.remover || opcao == "remover"
+		}
+		campo.texto = campo.texto && !campo.remover
+		campos = append(campos, campo)
+	}
+	return campos
+}
+
+func (c campoPessoal) valor(id primitive.ObjectID) string {
+	if c.unico {
+		return ValorAnonimizado + "-" + id.Hex()
+	}
+	return ValorAnonimizado
+}
+
+// LGPDHandler atende as solicitações dos titulares: alunos, professores e usuários (inclusive responsáveis).
+type LGPDHandler struct {
+	Cols       map[string]dbiface.Collection //coleção de cada recurso com dados pessoais
+	Usuarios   dbiface.Collection
+	Auditoria  *Auditor
+	Versoes    *Versionador
+	Revogacoes *auth.Revogacoes //a anonimização revoga os tokens das contas do titular
+}
+
+func (lh *LGPDHandler) titular(c echo.Context) (string, primitive.ObjectID, dbiface.Collection, *echo.HTTPError) {
+	recurso := c.Param("recurso")
+	col, ok := lh.Cols[recurso]
+	if _, pessoal := recursosPessoais[recurso]; !ok || !pessoal {
+		return recurso, primitive.NilObjectID, nil, echo.NewHTTPError(http.StatusNotFound, "Unknown data subject type")
+	}
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return recurso, id, col, problema.IDInvalido().HTTP()
+	}
+	if err := col.FindOne(context.Background(), bson.M{"_id": id}).Err(); err != nil { //inclui a lixeira
+		return recurso, id, col, echo.NewHTTPError(http.StatusNotFound, "Unable to find the data subject")
+	}
+	return recurso, id, col, nil
+}
+
+// filtroContas seleciona as contas de usuário que pertencem ao titular.
+func filtroContas(recurso string, id primitive.ObjectID) bson.M {
+	switch recurso {
+	case "alunos":
+		return bson.M{"alunoId": id}
+	case "professores":
+		return bson.M{"professorId": id}
+	}
+	return bson.M{"_id": id}
+}
+
+func buscarTodos(ctx context.Context, col dbiface.Collection, filter bson.M, opts ...*options.FindOptions) ([]bson.M, error) {
+	var docs []bson.M
+	cursor, err := col.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+// semSegredosDaConta remove os hashes das contas de usuário exportadas.
+func semSegredosDaConta(conta bson.M) {
+	delete(conta, "senhaHash")
+	delete(conta, "resetHash")
+	delete(conta, "resetExpira")
+}
+
+// escreverJSON grava v como JSON indentado, como os demais arquivos do pacote de exportação.
+func escreverJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// escreverLista grava os documentos da busca como um array JSON, lendo-os do cursor um por vez.
+func escreverLista(ctx context.Context, w io.Writer, col dbiface.Collection, filter bson.M, opts *options.FindOptions, tratar func(bson.M)) error {
+	cursor, err := col.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	separador := "[\n"
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if tratar != nil {
+			tratar(doc)
+		}
+		dados, err := json.MarshalIndent(doc, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, separador+"  "); err != nil {
+			return err
+		}
+		if _, err := w.Write(dados); err != nil {
+			return err
+		}
+		separador = ",\n"
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if separador == "[\n" {
+		_, err = io.WriteString(w, "[]\n")
+	} else {
+		_, err = io.WriteString(w, "\n]\n")
+	}
+	return err
+}
+
+// Exportar atende GET /lgpd/:recurso/:id/exportacao com um zip de arquivos JSON: o cadastro, as contas de
+// usuário, o histórico de alterações e as versões do titular. O zip é enviado enquanto é montado, lendo o
+// histórico e as versões pelo cursor; uma falha no meio deixa o arquivo sem o índice final, o que o leitor
+// de zip acusa.
+func (lh *LGPDHandler) Exportar(c echo.Context) error {
+	ctx := context.Background()
+	recurso, id, col, herr := lh.titular(c)
+	if herr != nil {
+		return herr
+	}
+	var documento bson.M
+	if err := col.FindOne(ctx, bson.M{"_id": id}).Decode(&documento); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Unable to find the data subject")
+	}
+
+	porData := options.Find().SetSort(bson.M{"data": 1})
+	doTitular := bson.M{"recurso": recurso, "documentoId": id}
+	arquivos := []struct {
+		nome     string
+		escrever func(w io.Writer) error
+	}{
+		{"manifesto.json", func(w io.Writer) error {
+			return escreverJSON(w, bson.M{"recurso": recurso, "id": id, "geradoEm": time.Now()})
+		}},
+		{recurso + ".json", func(w io.Writer) error { return escreverJSON(w, documento) }},
+		{"contas.json", func(w io.Writer) error {
+			return escreverLista(ctx, w, lh.Usuarios, filtroContas(recurso, id), nil, semSegredosDaConta)
+		}},
+		{"historico-alteracoes.json", func(w io.Writer) error {
+			return escreverLista(ctx, w, lh.Auditoria.Col, doTitular, porData, nil)
+		}},
+		{"versoes.json", func(w io.Writer) error { return escreverLista(ctx, w, lh.Versoes.Col, doTitular, porData, nil) }},
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/zip")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "lgpd-"+recurso+"-"+id.Hex()+".zip"))
+	res.WriteHeader(http.StatusOK)
+	zw := zip.NewWriter(res)
+	for _, arquivo := range arquivos {
+		w, err := zw.Create(arquivo.nome)
+		if err == nil {
+			err = arquivo.escrever(w)
+		}
+		if err != nil {
+			log.Errorf("Unable to write %s to the archive of %s %s: %v", arquivo.nome, recurso, id.Hex(), err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to export the data")
+		}
+		res.Flush()
+	}
+	if err := zw.Close(); err != nil {
+		log.Errorf("Unable to close the archive: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to export the data")
+	}
+	lh.Auditoria.Registrar(ctx, eventoDaRequisicao(c), recurso, OperacaoExportacao, id, nil, nil)
+	return nil
+}
+
+// anonimizar substitui os campos pessoais do documento e das cópias dele no histórico de versões e na
+// auditoria, que de outra forma manteriam os dados. O documento fica marcado com anonimizadoEm.
+func (lh *LGPDHandler) anonimizar(ctx context.Context, recurso string, id primitive.ObjectID, col dbiface.Collection) error {
+	campos := camposPessoais(recursosPessoais[recurso])
+	set, unset := bson.M{"anonimizadoEm": time.Now()}, bson.M{}
+	for _, campo := range campos {
+		if campo.texto {
+			set[campo.nome] = campo.valor(id)
+		} else {
+			unset[campo.nome] = ""
+		}
+	}
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if _, err := col.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return err
+	}
+
+	for _, campo := range campos {
+		operador, valor := "$set", campo.valor(id)
+		if !campo.texto {
+			operador, valor = "$unset", ""
+		}
+		caminhos := []struct {
+			col     dbiface.Collection
+			caminho string
+		}{
+			{lh.Versoes.Col, "documento." + campo.nome},
+			{lh.Auditoria.Col, "antes." + campo.nome},
+			{lh.Auditoria.Col, "depois." + campo.nome},
+		}
+		for _, p := range caminhos {
+			filter := bson.M{"recurso": recurso, "documentoId": id, p.caminho: bson.M{"$exists": true}}
+			if _, err := p.col.UpdateMany(ctx, filter, bson.M{operador: bson.M{p.caminho: valor}}); err != nil {
+				return err
+			}
+		}
+		opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"a.campo": campo.nome}}})
+		alteracoes := bson.M{operador: bson.M{"alteracoes.$[a].antes": valor, "alteracoes.$[a].depois": valor}}
+		filter := bson.M{"recurso": recurso, "documentoId": id, "alteracoes.campo": campo.nome}
+		if _, err := lh.Auditoria.Col.UpdateMany(ctx, filter, alteracoes, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// anonimizarAtor substitui o login da conta nas entradas de auditoria e nas versões geradas por ela, e
+// remove o IP dessas entradas: o que o titular fez continua registrado, mas não mais quem fez.
+func (lh *LGPDHandler) anonimizarAtor(ctx context.Context, login string, conta primitive.ObjectID) error {
+	ator := ValorAnonimizado + "-" + conta.Hex()
+	update := bson.M{"$set": bson.M{"ator": ator}, "$unset": bson.M{"ip": ""}}
+	if _, err := lh.Auditoria.Col.UpdateMany(ctx, bson.M{"ator": login}, update); err != nil {
+		return err
+	}
+	_, err := lh.Versoes.Col.UpdateMany(ctx, bson.M{"ator": login}, bson.M{"$set": bson.M{"ator": ator}})
+	return err
+}
+
+// Anonimizar atende POST /lgpd/:recurso/:id/anonimizacao. As contas de usuário do titular também são
+// anonimizadas, o que as desativa e revoga os tokens já emitidos, e o login delas deixa de aparecer como
+// autor na auditoria e nas versões; o registro acadêmico é mantido.
+func (lh *LGPDHandler) Anonimizar(c echo.Context) error {
+	ctx := context.Background()
+	recurso, id, col, herr := lh.titular(c)
+	if herr != nil {
+		return herr
+	}
+	docs, err := buscarTodos(ctx, lh.Usuarios, filtroContas(recurso, id)) //o próprio titular, se for um usuário
+	if err != nil {
+		log.Errorf("Unable to find the user accounts: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to anonymize the data")
+	}
+	logins := make(map[primitive.ObjectID]string)
+	var contas []primitive.ObjectID
+	for _, doc := range docs {
+		conta, ok := doc["_id"].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		if login, _ := doc["login"].(string); login != "" {
+			logins[conta] = login
+		}
+		if recurso != "usuarios" {
+			contas = append(contas, conta)
+		}
+	}
+
+	ev := eventoDaRequisicao(c)
+	if err := lh.anonimizar(ctx, recurso, id, col); err != nil {
+		log.Errorf("Unable to anonymize %s %s: %v", recurso, id.Hex(), err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to anonymize the data")
+	}
+	lh.Auditoria.Registrar(ctx, ev, recurso, OperacaoAnonimizacao, id, nil, nil)
+	for _, conta := range contas {
+		if err := lh.anonimizar(ctx, "usuarios", conta, lh.Usuarios); err != nil {
+			log.Errorf("Unable to anonymize usuarios %s: %v", conta.Hex(), err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to anonymize the data")
+		}
+		lh.Auditoria.Registrar(ctx, ev, "usuarios", OperacaoAnonimizacao, conta, nil, nil)
+	}
+	//por último, para cobrir também as entradas acima quando o próprio titular pediu a anonimização
+	for conta, login := range logins {
+		if err := lh.Revogacoes.RevogarUsuario(ctx, login); err != nil {
+			log.Errorf("Unable to revoke the tokens of usuarios %s: %v", conta.Hex(), err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to anonymize the data")
+		}
+		if err := lh.anonimizarAtor(ctx, login, conta); err != nil {
+			log.Errorf("Unable to anonymize the actor of usuarios %s: %v", conta.Hex(), err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to anonymize the data")
+		}
+	}
+	return c.JSON(http.StatusOK, bson.M{"anonimizados": 1 + len(contas)})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// titularLGPD prepara um aluno com uma conta de usuário, uma alteração feita pela própria conta e outra
+// feita pela secretaria.
+func titularLGPD(t *testing.T) (*LGPDHandler, Alunos, Usuarios) {
+	aluno := Alunos{ID: primitive.NewObjectID(), Nome: "Gil", Sobrenome: "Souza", Telefone: "+5511987654321", Email: "gil@escola.br"}
+	conta := novoUsuario(t, "gil", "Senha123")
+	conta.Papel, conta.AlunoID = "aluno", &aluno.ID
+	lh := &LGPDHandler{Cols: map[string]dbiface.Collection{"alunos": novaColecao(aluno)}, Usuarios: novaColecao(conta),
+		Auditoria: &Auditor{Col: novaColecao()}, Versoes: novoVersionador(), Revogacoes: &auth.Revogacoes{Col: novaColecao(), Validade: time.Hour}}
+	ctx := context.Background()
+	lh.Auditoria.Registrar(ctx, Evento{Ator: "gil", IP: "10.0.0.1"}, "alunos", OperacaoAlteracao, aluno.ID, aluno, aluno)
+	lh.Auditoria.Registrar(ctx, Evento{Ator: "secretaria", IP: "10.0.0.2"}, "alunos", OperacaoAlteracao, aluno.ID, aluno, aluno)
+	lh.Versoes.Registrar(ctx, Evento{Ator: "gil"}, "alunos", OperacaoAlteracao, aluno.ID, aluno)
+	return lh, aluno, conta
+}
+
+func requisicaoLGPD(metodo, recurso string, id primitive.ObjectID, ator string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(metodo, "/lgpd/"+recurso+"/"+id.Hex(), nil), rec)
+	c.SetParamNames("recurso", "id")
+	c.SetParamValues(recurso, id.Hex())
+	c.Set(auth.ChaveAtor, ator)
+	return c, rec
+}
+
+func TestExportarLGPD(t *testing.T) {
+	lh, aluno, _ := titularLGPD(t)
+	c, rec := requisicaoLGPD(http.MethodGet, "alunos", aluno.ID, "secretaria")
+	if err := lh.Exportar(c); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get(echo.HeaderContentType) != "application/zip" || !rec.Flushed {
+		t.Errorf("the archive should be streamed as application/zip, got %q (flushed %v)", rec.Header().Get(echo.HeaderContentType), rec.Flushed)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arquivos := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		arquivos[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	var contas, historico, versoes []bson.M
+	for nome, destino := range map[string]*[]bson.M{"contas.json": &contas, "historico-alteracoes.json": &historico, "versoes.json": &versoes} {
+		if err := json.Unmarshal(arquivos[nome], destino); err != nil {
+			t.Errorf("%s: %v", nome, err)
+		}
+	}
+	if len(contas) != 1 || contas[0]["login"] != "gil" || contas[0]["senhaHash"] != nil {
+		t.Errorf("unexpected accounts %v", contas)
+	}
+	if len(historico) != 2 || len(versoes) != 1 {
+		t.Errorf("got %d audit entries and %d versions, want 2 and 1", len(historico), len(versoes))
+	}
+	if _, ok := arquivos["alunos.json"]; !ok {
+		t.Error("the archive lacks alunos.json")
+	}
+	entradas, _ := buscarAuditoria(context.Background(), bson.M{"operacao": OperacaoExportacao}, 0, lh.Auditoria.Col.(*colecaoMemoria))
+	if len(entradas) != 1 {
+		t.Errorf("the export should be audited once, got %d", len(entradas))
+	}
+}
+
+func TestExportarLGPDListasVazias(t *testing.T) {
+	var buf bytes.Buffer
+	if err := escreverLista(context.Background(), &buf, novaColecao(), bson.M{}, nil, nil); err != nil || buf.String() != "[]\n" {
+		t.Errorf("got %q, %v", buf.String(), err)
+	}
+}
+
+func TestAnonimizarTrocaOAtor(t *testing.T) {
+	lh, aluno, conta := titularLGPD(t)
+	c, _ := requisicaoLGPD(http.MethodPost, "alunos", aluno.ID, "gil") //o próprio titular pede
+	if err := lh.Anonimizar(c); err != nil {
+		t.Fatal(err)
+	}
+	anonimo := ValorAnonimizado + "-" + conta.ID.Hex()
+	entradas, _ := buscarAuditoria(context.Background(), bson.M{}, 0, lh.Auditoria.Col.(*colecaoMemoria))
+	for _, e := range entradas {
+		switch {
+		case e.Ator == "gil":
+			t.Errorf("the subject's login is still in the audit entry %+v", e)
+		case e.Ator == anonimo && e.IP != "":
+			t.Errorf("the subject's IP is still in the audit entry %+v", e)
+		case e.Ator == "secretaria" && e.IP != "10.0.0.2":
+			t.Errorf("the entries of other actors should not change: %+v", e)
+		}
+	}
+	var versao Versoes
+	if err := lh.Versoes.Col.FindOne(context.Background(), bson.M{}).Decode(&versao); err != nil || versao.Ator != anonimo {
+		t.Errorf("the version should have the anonymized actor, got %q (%v)", versao.Ator, err)
+	}
+	antigo := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{ID: "t1", Subject: "gil", IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}}
+	if revogado, err := lh.Revogacoes.Revogado(context.Background(), antigo); err != nil || !revogado {
+		t.Errorf("the tokens of the anonymized account should be revoked, got %v (%v)", revogado, err)
+	}
+}
+
+func TestAlunoAnonimizadoContinuaEditavel(t *testing.T) {
+	lh, aluno, _ := titularLGPD(t)
+	c, _ := requisicaoLGPD(http.MethodPost, "alunos", aluno.ID, "secretaria")
+	if err := lh.Anonimizar(c); err != nil {
+		t.Fatal(err)
+	}
+	col := lh.Cols["alunos"].(*colecaoMemoria)
+	doc := col.buscarID(aluno.ID)
+	if doc["nome"] != ValorAnonimizado || doc["email"] != nil || doc["telefone"] != nil || doc["anonimizadoEm"] == nil {
+		t.Fatalf("unexpected anonymized document %v", doc)
+	}
+
+	//um PUT do registro acadêmico passa na validação sem o telefone, e não tira a marca da anonimização
+	corpo := io.NopCloser(bytes.NewBufferString(`{"curso":4,"anonimizadoEm":null}`))
+	if _, err := alterarAluno(context.Background(), Evento{}, aluno.ID.Hex(), corpo, col, nil, historico{}); err != nil {
+		t.Fatalf("PUT on an anonymized student: %v", err)
+	}
+	if doc := col.buscarID(aluno.ID); !iguais(doc["curso"], int32(4)) || doc["anonimizadoEm"] == nil {
+		t.Errorf("unexpected document after the PUT %v", doc)
+	}
+	//já um aluno novo não escapa do telefone obrigatório declarando-se anonimizado
+	agora := aluno.ID.Timestamp()
+	novo := []Alunos{{Nome: "Lia", Sobrenome: "Reis", AnonimizadoEm: &agora}}
+	if _, err := inserirAluno(context.Background(), Evento{}, novo, novaColecao(), nil, nil, historico{}); err == nil {
+		t.Error("a new student without a phone number was accepted")
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*os documentos nunca são apagados pelos handlers: DELETE apenas marca deletedAt, e as consultas normais
+ignoram os documentos marcados. A remoção definitiva fica a cargo de PurgarLixeira*/
+
+func filtroAtivos(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+func filtroLixeira(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": true}
+	return filter
+}
+
+func moverParaLixeira(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Errorf("Cannot convert to ObjectID: %v", err)
+		return 0, problema.IDInvalido().HTTP()
+	}
+	res, err := collection.UpdateOne(ctx, filtroAtivos(bson.M{"_id": docID}), bson.M{"$set": bson.M{"deletedAt": time.Now()}})
+	if err != nil {
+		log.Errorf("Unable to move to trash: %v", err)
+		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to delete the document")
+	}
+	if res.MatchedCount == 0 {
+		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to find the document")
+	}
+	return res.ModifiedCount, nil
+}
+
+func restaurarDaLixeira(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Errorf("Cannot convert to ObjectID: %v", err)
+		return 0, problema.IDInvalido().HTTP()
+	}
+	res, err := collection.UpdateOne(ctx, filtroLixeira(bson.M{"_id": docID}), bson.M{"$unset": bson.M{"deletedAt": ""}})
+	if mongo.IsDuplicateKeyError(err) { //um índice único só entre os ativos, como o login dos usuários
+		return 0, problema.Novo(http.StatusConflict, problema.CodigoValorDuplicado, "Another active document has the same unique value").HTTP()
+	}
+	if err != nil {
+		log.Errorf("Unable to restore from trash: %v", err)
+		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to restore the document")
+	}
+	if res.MatchedCount == 0 {
+		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to find the document in the trash")
+	}
+	return res.ModifiedCount, nil
+}
+
+// loteDePurga limita quantos documentos cada passo da purga lê.
+const loteDePurga = 500
+
+// PurgarLixeira remove definitivamente os documentos que estão na lixeira há mais tempo que a retenção. Cada
+// remoção é registrada em auditoria apenas com o recurso e o ID: o conteúdo purgado não é copiado para lá.
+func PurgarLixeira(ctx context.Context, recurso string, collection dbiface.Collection, retencao time.Duration, auditoria *Auditor) (int64, error) {
+	limite := time.Now().Add(-retencao)
+	opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(loteDePurga)
+	var total int64
+	for {
+		cursor, err := collection.Find(ctx, bson.M{"deletedAt": bson.M{"$lte": limite}}, opts)
+		if err != nil {
+			return total, err
+		}
+		var docs []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return total, err
+		}
+		if len(docs) == 0 {
+			return total, nil
+		}
+		//um a um, para que a auditoria registre só os documentos de fato removidos; o filtro repete a data
+		//para não purgar um documento restaurado entre a leitura e a remoção
+		for _, doc := range docs {
+			res, err := collection.DeleteOne(ctx, bson.M{"_id": doc.ID, "deletedAt": bson.M{"$lte": limite}})
+			if err != nil {
+				return total, err
+			}
+			if res.DeletedCount == 1 {
+				total++
+				auditoria.Registrar(ctx, EventoSistema, recurso, OperacaoPurga, doc.ID, nil, nil)
+			}
+		}
+		if len(docs) < loteDePurga {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/problema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestPurgarLixeira(t *testing.T) {
+	antigo, recente := time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour)
+	vencido := Alunos{ID: primitive.NewObjectID(), Nome: "Caio", DeletadoEm: &antigo}
+	alunos := novaColecao(
+		vencido,
+		Alunos{ID: primitive.NewObjectID(), Nome: "Duda", DeletadoEm: &recente},
+		Alunos{ID: primitive.NewObjectID(), Nome: "Eva"},
+	)
+	auditoria := novaColecao()
+	n, err := PurgarLixeira(context.Background(), "alunos", alunos, 24*time.Hour, &Auditor{Col: auditoria})
+	if err != nil || n != 1 {
+		t.Fatalf("PurgarLixeira = %d, %v; want 1 document purged", n, err)
+	}
+	if alunos.buscarID(vencido.ID) != nil || len(alunos.docs) != 2 {
+		t.Error("only the document in the trash for longer than the retention should be purged")
+	}
+	entrada := conferirAuditoria(t, "purge", auditoria, vencido.ID, OperacaoPurga, EventoSistema)
+	if entrada.Antes != nil || entrada.Recurso != "alunos" {
+		t.Errorf("the purge entry should name the resource and not copy the document: %+v", entrada)
+	}
+	if n, _ := PurgarLixeira(context.Background(), "alunos", alunos, 24*time.Hour, &Auditor{Col: auditoria}); n != 0 {
+		t.Errorf("a second purge removed %d documents", n)
+	}
+	if entradas, _ := buscarAuditoria(context.Background(), bson.M{}, 0, auditoria); len(entradas) != 1 {
+		t.Errorf("a purge with nothing to remove wrote %d audit entries", len(entradas)-1)
+	}
+}
+
+// colecaoRestaurada restaura um documento logo depois da leitura, como outra requisição faria entre a
+// leitura e a remoção da purga.
+type colecaoRestaurada struct {
+	*colecaoMemoria
+	id primitive.ObjectID
+}
+
+func (c colecaoRestaurada) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	cursor, err := c.colecaoMemoria.Find(ctx, filter, opts...)
+	c.colecaoMemoria.UpdateOne(ctx, bson.M{"_id": c.id}, bson.M{"$unset": bson.M{"deletedAt": ""}})
+	return cursor, err
+}
+
+func TestPurgarLixeiraAuditaSoOsRemovidos(t *testing.T) {
+	antigo := time.Now().Add(-48 * time.Hour)
+	vencido := Alunos{ID: primitive.NewObjectID(), Nome: "Caio", DeletadoEm: &antigo}
+	restaurado := Alunos{ID: primitive.NewObjectID(), Nome: "Duda", DeletadoEm: &antigo}
+	alunos := colecaoRestaurada{novaColecao(vencido, restaurado), restaurado.ID}
+	auditoria := novaColecao()
+	n, err := PurgarLixeira(context.Background(), "alunos", alunos, 24*time.Hour, &Auditor{Col: auditoria})
+	if err != nil || n != 1 {
+		t.Fatalf("PurgarLixeira = %d, %v; want 1 document purged", n, err)
+	}
+	if alunos.buscarID(restaurado.ID) == nil {
+		t.Error("the document restored during the purge should be kept")
+	}
+	conferirAuditoria(t, "purge", auditoria, vencido.ID, OperacaoPurga, EventoSistema)
+	if entradas, _ := buscarAuditoria(context.Background(), bson.M{"documentoId": restaurado.ID}, 0, auditoria); len(entradas) != 0 {
+		t.Errorf("the restored document should not be audited as purged: %+v", entradas)
+	}
+}
+
+func TestIDInvalido(t *testing.T) {
+	_, herr := moverParaLixeira(context.Background(), "nao-e-um-id", novaColecao())
+	if p := problema.Converter(herr); p.Status != http.StatusBadRequest || p.Codigo != problema.CodigoIDInvalido {
+		t.Errorf("an invalid id answered %d %s, want 400 %s", p.Status, p.Codigo, problema.CodigoIDInvalido)
+	}
+}
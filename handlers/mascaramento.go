@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dados pessoais são marcados nas structs com a tag sensivel, que lista quem pode vê-los em claro: papéis
+// da API, "proprio" (o aluno ou professor do próprio documento) e "vinculado" (o responsável pelo aluno do
+// documento). Para os demais, textos saem mascarados e campos de outros tipos saem zerados, por isso devem
+// usar omitempty. A marcação vale para respostas, exportações e logs (ParaLog).
+
+// recursosPessoais liga o nome dos recursos com dados pessoais à struct, para tratar documentos genéricos da
+// auditoria e do histórico de versões (mascaramento e LGPD).
+var recursosPessoais = map[string]reflect.Type{
+	"alunos":      reflect.TypeOf(Alunos{}),
+	"professores": reflect.TypeOf(Professores{}),
+	"usuarios":    reflect.TypeOf(Usuarios{}),
+}
+
+type campoSensivel struct {
+	indice     int
+	nome       string //nome no BSON, usado nos documentos genéricos
+	permitidos []string
+}
+
+var camposSensiveis sync.Map //reflect.Type -> []campoSensivel
+
+func sensiveis(t reflect.Type) []campoSensivel {
+	if campos, ok := camposSensiveis.Load(t); ok {
+		return campos.([]campoSensivel)
+	}
+	var campos []campoSensivel
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("sensivel")
+		if !ok {
+			continue
+		}
+		nome := strings.Split(f.Tag.Get("bson"), ",")[0]
+		if nome == "" {
+			nome = strings.ToLower(f.Name)
+		}
+		campos = append(campos, campoSensivel{indice: i, nome: nome, permitidos: strings.Split(tag, ",")})
+	}
+	camposSensiveis.Store(t, campos)
+	return campos
+}
+
+func podeVer(permitidos []string, claims *auth.Claims, id string) bool {
+	if claims == nil {
+		return false
+	}
+	for _, p := range permitidos {
+		switch p {
+		case "proprio":
+			if id != "" && (claims.AlunoID == id || claims.ProfessorID == id) {
+				return true
+			}
+		case "vinculado":
+			if id != "" && contem(claims.Vinculados, id) {
+				return true
+			}
+		default:
+			if claims.Papel != "" && p == claims.Papel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contem(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// MascararTexto mantém só os dois últimos caracteres, suficientes para o titular reconhecer o dado.
+func MascararTexto(s string) string {
+	r := []rune(s)
+	if len(r) <= 4 {
+		return strings.Repeat("*", len(r))
+	}
+	return strings.Repeat("*", len(r)-2) + string(r[len(r)-2:])
+}
+
+// Mascarar devolve uma cópia de v (struct, ponteiro ou slice) com os campos sensíveis que claims não pode
+// ver mascarados. Claims nil mascara tudo.
+func Mascarar(v interface{}, claims *auth.Claims) interface{} {
+	if v == nil {
+		return nil
+	}
+	return mascararValor(reflect.ValueOf(v), claims).Interface()
+}
+
+// ParaLog mascara todos os campos sensíveis de v antes de escrevê-lo em um log.
+func ParaLog(v interface{}) interface{} {
+	return Mascarar(v, nil)
+}
+
+func mascararValor(v reflect.Value, claims *auth.Claims) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copia := reflect.New(v.Elem().Type())
+		copia.Elem().Set(mascararValor(v.Elem(), claims))
+		return copia
+	case reflect.Slice:
+		if v.IsNil() || (v.Type().Elem().Kind() != reflect.Struct && v.Type().Elem().Kind() != reflect.Ptr) {
+			return v
+		}
+		copia := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copia.Index(i).Set(mascararValor(v.Index(i), claims))
+		}
+		return copia
+	case reflect.Struct:
+		campos := sensiveis(v.Type())
+		if len(campos) == 0 {
+			return v
+		}
+		copia := reflect.New(v.Type()).Elem()
+		copia.Set(v)
+		var id string
+		if f := copia.FieldByName("ID"); f.IsValid() {
+			if oid, ok := f.Interface().(primitive.ObjectID); ok {
+				id = oid.Hex()
+			}
+		}
+		for _, campo := range campos {
+			if podeVer(campo.permitidos, claims, id) {
+				continue
+			}
+			f := copia.Field(campo.indice)
+			if f.Kind() == reflect.String {
+				f.SetString(MascararTexto(f.String()))
+			} else {
+				f.Set(reflect.Zero(f.Type()))
+			}
+		}
+		return copia
+	}
+	return v
+}
+
+// mascararDocumento aplica a marcação da struct do recurso a um documento genérico, alterando-o.
+func mascararDocumento(recurso string, doc bson.M, claims *auth.Claims) {
+	t, ok := recursosPessoais[recurso]
+	if !ok || doc == nil {
+		return
+	}
+	var id string
+	if oid, ok := doc["_id"].(primitive.ObjectID); ok {
+		id = oid.Hex()
+	}
+	for _, campo := range sensiveis(t) {
+		valor, existe := doc[campo.nome]
+		if !existe || podeVer(campo.permitidos, claims, id) {
+			continue
+		}
+		if s, ok := valor.(string); ok {
+			doc[campo.nome] = MascararTexto(s)
+		} else {
+			delete(doc, campo.nome)
+		}
+	}
+}
+
+// mascararAuditoria mascara os documentos e as alterações das entradas de auditoria.
+func mascararAuditoria(entradas []Auditoria, claims *auth.Claims) {
+	for i := range entradas {
+		t, ok := recursosPessoais[entradas[i].Recurso]
+		if !ok {
+			continue
+		}
+		mascararDocumento(entradas[i].Recurso, entradas[i].Antes, claims)
+		mascararDocumento(entradas[i].Recurso, entradas[i].Depois, claims)
+		id := entradas[i].DocumentoID.Hex()
+		for j, alteracao := range entradas[i].Alteracoes {
+			for _, campo := range sensiveis(t) {
+				if campo.nome != alteracao.Campo || podeVer(campo.permitidos, claims, id) {
+					continue
+				}
+				if s, ok := alteracao.Antes.(string); ok {
+					entradas[i].Alteracoes[j].Antes = MascararTexto(s)
+				} else {
+					entradas[i].Alteracoes[j].Antes = nil
+				}
+				if s, ok := alteracao.Depois.(string); ok {
+					entradas[i].Alteracoes[j].Depois = MascararTexto(s)
+				} else {
+					entradas[i].Alteracoes[j].Depois = nil
+				}
+			}
+		}
+	}
+}
+
+// claimsDaRequisicao devolve as claims do token da requisição, ou nil.
+func claimsDaRequisicao(c echo.Context) *auth.Claims {
+	claims, _ := c.Get(auth.ChaveClaims).(*auth.Claims)
+	return claims
+}
+
+// mascarar aplica Mascarar com as claims da requisição.
+func mascarar(c echo.Context, v interface{}) interface{} {
+	return Mascarar(v, claimsDaRequisicao(c))
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMascararTexto(t *testing.T) {
+	casos := map[string]string{"": "", "abc": "***", "abcd": "****", "abcde": "***de", "josé@x.br": "*******br"}
+	for entrada, esperado := range casos {
+		if s := MascararTexto(entrada); s != esperado {
+			t.Errorf("MascararTexto(%q) = %q, want %q", entrada, s, esperado)
+		}
+	}
+}
+
+func TestMascarar(t *testing.T) {
+	id := primitive.NewObjectID()
+	aluno := Alunos{ID: id, Nome: "Ana", Sobrenome: "Lima", Telefone: "+5511987654321", Email: "ana@escola.br", CPF: "52998224725"}
+	casos := []struct {
+		nome    string
+		claims  *auth.Claims
+		emClaro bool
+	}{
+		{"secretaria", &auth.Claims{Papel: "secretaria"}, true},
+		{"the student of the record", &auth.Claims{Papel: "aluno", AlunoID: id.Hex()}, true},
+		{"a linked guardian", &auth.Claims{Papel: "responsavel", Vinculados: []string{id.Hex()}}, true},
+		{"another student", &auth.Claims{Papel: "aluno", AlunoID: primitive.NewObjectID().Hex()}, false},
+		{"professor", &auth.Claims{Papel: "professor"}, false},
+		{"no claims", nil, false},
+	}
+	for _, caso := range casos {
+		m := Mascarar(aluno, caso.claims).(Alunos)
+		if m.Nome != "Ana" || m.Sobrenome != "Lima" {
+			t.Errorf("%s: public fields were masked: %+v", caso.nome, m)
+		}
+		if emClaro := m.CPF == aluno.CPF && m.Email == aluno.Email && m.Telefone == aluno.Telefone; emClaro != caso.emClaro {
+			t.Errorf("%s: got %+v, want the sensitive fields in clear = %v", caso.nome, m, caso.emClaro)
+		}
+		if !caso.emClaro && m.CPF != "*********25" {
+			t.Errorf("%s: cpf = %q", caso.nome, m.CPF)
+		}
+	}
+	if aluno.CPF != "52998224725" {
+		t.Error("Mascarar changed the original value")
+	}
+
+	//ponteiros e listas são copiados e mascarados item a item
+	lista := Mascarar([]*Alunos{&aluno}, nil).([]*Alunos)
+	if lista[0].CPF == aluno.CPF || lista[0] == &aluno {
+		t.Errorf("the slice was not masked: %+v", lista[0])
+	}
+	//tipos sem campos sensíveis passam inalterados
+	if curso := Mascarar(Cursos{Nome: "Física"}, nil).(Cursos); curso.Nome != "Física" {
+		t.Errorf("unexpected course %+v", curso)
+	}
+}
+
+func TestMascararDocumento(t *testing.T) {
+	id := primitive.NewObjectID()
+	doc := bson.M{"_id": id, "nome": "Ana", "cpf": "52998224725", "telefone": "+5511987654321"}
+	mascararDocumento("alunos", doc, &auth.Claims{Papel: "professor"})
+	if doc["nome"] != "Ana" || doc["cpf"] != "*********25" || doc["telefone"] == "+5511987654321" {
+		t.Errorf("unexpected document %v", doc)
+	}
+	doc = bson.M{"_id": id, "cpf": "52998224725"}
+	mascararDocumento("alunos", doc, &auth.Claims{Papel: "aluno", AlunoID: id.Hex()})
+	if doc["cpf"] != "52998224725" {
+		t.Errorf("the student should see their own cpf, got %v", doc["cpf"])
+	}
+}
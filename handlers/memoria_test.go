@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// colecaoMemoria é uma dbiface.Collection em memória para os testes dos handlers. Entende os filtros e as
+// atualizações que os handlers usam: igualdade (inclusive com listas), $exists, $in, $ne, $lt, $lte, $gt e
+// $gte nos filtros; $set, $unset, $inc, $push e $pull nas atualizações. Ordenação e projeção são ignoradas.
+type colecaoMemoria struct {
+	mu   sync.Mutex
+	docs []bson.M
+	//escritas conta as chamadas que alteram a coleção, para os testes que conferem que nada foi gravado
+	escritas int
+}
+
+var _ dbiface.Collection = (*colecaoMemoria)(nil)
+
+func novaColecao(docs ...interface{}) *colecaoMemoria {
+	col := &colecaoMemoria{}
+	for _, doc := range docs {
+		if _, err := col.InsertOne(context.Background(), doc); err != nil {
+			panic(err)
+		}
+	}
+	col.escritas = 0
+	return col
+}
+
+// documentoBSON converte qualquer valor para bson.M como o driver o gravaria.
+func documentoBSON(doc interface{}) bson.M {
+	dados, err := bson.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+	var m bson.M
+	if err := bson.Unmarshal(dados, &m); err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func copiar(doc bson.M) bson.M {
+	return documentoBSON(doc)
+}
+
+func campo(doc bson.M, caminho string) (interface{}, bool) {
+	var atual interface{} = doc
+	for _, parte := range strings.Split(caminho, ".") {
+		m, ok := atual.(bson.M)
+		if !ok {
+			return nil, false
+		}
+		if atual, ok = m[parte]; !ok {
+			return nil, false
+		}
+	}
+	return atual, true
+}
+
+func iguais(a, b interface{}) bool {
+	if x, ok := numero(a); ok {
+		y, ok := numero(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// contemValor diz se o valor do documento é igual a alvo ou, sendo uma lista, tem um item igual a alvo.
+func contemValor(valor, alvo interface{}) bool {
+	if iguais(valor, alvo) {
+		return true
+	}
+	if lista, ok := valor.(primitive.A); ok {
+		for _, item := range lista {
+			if iguais(item, alvo) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func corresponde(doc bson.M, filtro interface{}) bool {
+	for chave, condicao := range documentoBSON(filtro) {
+		if chave == "$or" {
+			algum := false
+			for _, alternativa := range condicao.(primitive.A) {
+				algum = algum || corresponde(doc, alternativa)
+			}
+			if !algum {
+				return false
+			}
+			continue
+		}
+		valor, existe := campo(doc, chave)
+		operadores, ok := condicao.(bson.M)
+		if !ok || len(operadores) == 0 || !strings.HasPrefix(primeiraChave(operadores), "$") {
+			if !existe || !contemValor(valor, condicao) {
+				return false
+			}
+			continue
+		}
+		for op, alvo := range operadores {
+			if !operador(op, valor, existe, alvo) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func primeiraChave(m bson.M) string {
+	for k := range m {
+		return k
+	}
+	return ""
+}
+
+func operador(op string, valor interface{}, existe bool, alvo interface{}) bool {
+	switch op {
+	case "$exists":
+		return existe == alvo.(bool)
+	case "$ne":
+		return !existe || !contemValor(valor, alvo)
+	case "$in":
+		for _, item := range alvo.(primitive.A) {
+			if existe && contemValor(valor, item) {
+				return true
+			}
+		}
+		return false
+	case "$lt", "$lte", "$gt", "$gte":
+		if !existe {
+			return false
+		}
+		c, ok := comparar(valor, alvo)
+		if !ok {
+			return false
+		}
+		return map[string]bool{"$lt": c < 0, "$lte": c <= 0, "$gt": c > 0, "$gte": c >= 0}[op]
+	}
+	panic(fmt.Sprintf("colecaoMemoria: unsupported operator %s", op))
+}
+
+func atualizar(doc bson.M, atualizacao interface{}) {
+	for op, campos := range documentoBSON(atualizacao) {
+		for chave, valor := range campos.(bson.M) {
+			switch op {
+			case "$set":
+				doc[chave] = valor
+			case "$unset":
+				delete(doc, chave)
+			case "$inc":
+				atual, _ := numero(doc[chave])
+				n, _ := numero(valor)
+				if _, fracionario := valor.(float64); fracionario {
+					doc[chave] = atual + n
+				} else {
+					doc[chave] = int64(atual + n)
+				}
+			case "$push":
+				lista, _ := doc[chave].(primitive.A)
+				doc[chave] = append(lista, valor)
+			case "$pull":
+				lista, _ := doc[chave].(primitive.A)
+				restante := primitive.A{}
+				for _, item := range lista {
+					if !iguais(item, valor) {
+						restante = append(restante, item)
+					}
+				}
+				doc[chave] = restante
+			default:
+				panic(fmt.Sprintf("colecaoMemoria: unsupported update %s", op))
+			}
+		}
+	}
+}
+
+func (c *colecaoMemoria) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	doc := documentoBSON(document)
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+	for _, d := range c.docs {
+		if iguais(d["_id"], doc["_id"]) {
+			return nil, mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}}
+		}
+	}
+	c.docs = append(c.docs, doc)
+	c.escritas++
+	return &mongo.InsertOneResult{InsertedID: doc["_id"]}, nil
+}
+
+func (c *colecaoMemoria) encontrar(filter interface{}) []bson.M {
+	var encontrados []bson.M
+	for _, doc := range c.docs {
+		if corresponde(doc, filter) {
+			encontrados = append(encontrados, doc)
+		}
+	}
+	return encontrados
+}
+
+func (c *colecaoMemoria) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var docs []interface{}
+	for _, doc := range c.encontrar(filter) {
+		docs = append(docs, copiar(doc))
+	}
+	return mongo.NewCursorFromDocuments(docs, nil, nil)
+}
+
+func (c *colecaoMemoria) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	encontrados := c.encontrar(filter)
+	if len(encontrados) == 0 {
+		return mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+	}
+	return mongo.NewSingleResultFromDocument(copiar(encontrados[0]), nil, nil)
+}
+
+// inserirDoFiltro cria o documento de um upsert com as igualdades do filtro.
+func (c *colecaoMemoria) inserirDoFiltro(filter interface{}) bson.M {
+	doc := bson.M{}
+	for chave, valor := range documentoBSON(filter) {
+		if _, operador := valor.(bson.M); !operador && !strings.HasPrefix(chave, "$") {
+			doc[chave] = valor
+		}
+	}
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+	c.docs = append(c.docs, doc)
+	return doc
+}
+
+func (c *colecaoMemoria) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := options.MergeFindOneAndUpdateOptions(opts...)
+	var doc bson.M
+	if encontrados := c.encontrar(filter); len(encontrados) > 0 {
+		doc = encontrados[0]
+	} else if o.Upsert != nil && *o.Upsert {
+		doc = c.inserirDoFiltro(filter)
+	} else {
+		return mongo.NewSingleResultFromDocument(bson.M{}, mongo.ErrNoDocuments, nil)
+	}
+	antes := copiar(doc)
+	atualizar(doc, update)
+	c.escritas++
+	if o.ReturnDocument != nil && *o.ReturnDocument == options.After {
+		return mongo.NewSingleResultFromDocument(copiar(doc), nil, nil)
+	}
+	return mongo.NewSingleResultFromDocument(antes, nil, nil)
+}
+
+func (c *colecaoMemoria) atualizarVarios(filter, update interface{}, limite int) *mongo.UpdateResult {
+	res := &mongo.UpdateResult{}
+	for _, doc := range c.encontrar(filter) {
+		if limite > 0 && int(res.MatchedCount) == limite {
+			break
+		}
+		antes := copiar(doc)
+		atualizar(doc, update)
+		res.MatchedCount++
+		if !reflect.DeepEqual(antes, documentoBSON(doc)) {
+			res.ModifiedCount++
+		}
+	}
+	c.escritas++
+	return res
+}
+
+func (c *colecaoMemoria) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := options.MergeUpdateOptions(opts...)
+	if o.Upsert != nil && *o.Upsert && len(c.encontrar(filter)) == 0 {
+		doc := c.inserirDoFiltro(filter)
+		atualizar(doc, update)
+		c.escritas++
+		return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: doc["_id"]}, nil
+	}
+	return c.atualizarVarios(filter, update, 1), nil
+}
+
+func (c *colecaoMemoria) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.atualizarVarios(filter, update, 0), nil
+}
+
+func (c *colecaoMemoria) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := &mongo.UpdateResult{}
+	for i, doc := range c.docs {
+		if corresponde(doc, filter) {
+			novo := documentoBSON(replacement)
+			novo["_id"] = doc["_id"]
+			c.docs[i] = novo
+			res.MatchedCount, res.ModifiedCount = 1, 1
+			break
+		}
+	}
+	c.escritas++
+	return res, nil
+}
+
+func (c *colecaoMemoria) excluir(filter interface{}, limite int) *mongo.DeleteResult {
+	res := &mongo.DeleteResult{}
+	restantes := c.docs[:0]
+	for _, doc := range c.docs {
+		if (limite == 0 || int(res.DeletedCount) < limite) && corresponde(doc, filter) {
+			res.DeletedCount++
+			continue
+		}
+		restantes = append(restantes, doc)
+	}
+	c.docs = restantes
+	c.escritas++
+	return res
+}
+
+func (c *colecaoMemoria) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.excluir(filter, 1), nil
+}
+
+func (c *colecaoMemoria) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.excluir(filter, 0), nil
+}
+
+// buscarID devolve o documento gravado com o _id, ou nil.
+func (c *colecaoMemoria) buscarID(id primitive.ObjectID) bson.M {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, doc := range c.docs {
+		if doc["_id"] == id {
+			return copiar(doc)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// parametrosPaginacao não são campos dos documentos e ficam fora do filtro das listagens.
+var parametrosPaginacao = map[string]bool{"limite": true, "pagina": true}
+
+// parametrosListagem são os de paginação mais os de ordenação, projeção e formato de exportação.
+var parametrosListagem = map[string]bool{"limite": true, "pagina": true, "ordem": true, "campos": true, "format": true}
+
+// paginacao converte ?limite=&pagina= (a partir de 1) nas opções da consulta. Sem limite a listagem devolve
+// todos os documentos.
+func paginacao(q url.Values) (*options.FindOptions, *echo.HTTPError) {
+	valor := q.Get("limite")
+	if valor == "" {
+		return options.Find(), nil
+	}
+	limite, err := strconv.ParseInt(valor, 10, 64)
+	if err != nil || limite <= 0 {
+		return nil, problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "limite must be a positive number").HTTP()
+	}
+	pagina := int64(1)
+	if valor := q.Get("pagina"); valor != "" {
+		pagina, err = strconv.ParseInt(valor, 10, 64)
+		if err != nil || pagina <= 0 {
+			return nil, problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "pagina must be a positive number").HTTP()
+		}
+	}
+	return opcoesPagina(limite, pagina), nil
+}
+
+// opcoesPagina ordena pelo _id, para que as páginas não se sobreponham.
+func opcoesPagina(limite, pagina int64) *options.FindOptions {
+	return options.Find().SetSort(bson.M{"_id": 1}).SetLimit(limite).SetSkip((pagina - 1) * limite)
+}
+
+// listaDeCampos separa "nome, -matricula" nos nomes dos campos, recusando nomes vazios.
+func listaDeCampos(valor, parametro string) ([]string, *echo.HTTPError) {
+	var campos []string
+	for _, campo := range strings.Split(valor, ",") {
+		campo = strings.TrimSpace(campo)
+		if nome := strings.TrimPrefix(campo, "-"); nome == "" || strings.HasPrefix(nome, "$") {
+			return nil, problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, parametro+" must be a comma-separated list of fields").HTTP()
+		}
+		campos = append(campos, campo)
+	}
+	return campos, nil
+}
+
+// ordenacao converte ?ordem=nome,-matricula (o "-" inverte a ordem) na ordenação da consulta. O _id
+// desempata, para que as páginas continuem sem se sobrepor.
+func ordenacao(q url.Values) (bson.D, *echo.HTTPError) {
+	if q.Get("ordem") == "" {
+		return nil, nil
+	}
+	campos, herr := listaDeCampos(q.Get("ordem"), "ordem")
+	if herr != nil {
+		return nil, herr
+	}
+	ordem := bson.D{}
+	comID := false
+	for _, campo := range campos {
+		sentido := 1
+		if strings.HasPrefix(campo, "-") {
+			campo, sentido = campo[1:], -1
+		}
+		comID = comID || campo == "_id"
+		ordem = append(ordem, bson.E{Key: campo, Value: sentido})
+	}
+	if !comID {
+		ordem = append(ordem, bson.E{Key: "_id", Value: 1})
+	}
+	return ordem, nil
+}
+
+// camposProjetados lê ?campos=nome,sobrenome. Como na projeção do MongoDB, o _id vem sempre, e como a
+// primeira coluna das exportações.
+func camposProjetados(q url.Values) ([]string, *echo.HTTPError) {
+	if q.Get("campos") == "" {
+		return nil, nil
+	}
+	lista, herr := listaDeCampos(q.Get("campos"), "campos")
+	if herr != nil {
+		return nil, herr
+	}
+	campos := []string{"_id"}
+	for _, campo := range lista {
+		if strings.HasPrefix(campo, "-") {
+			return nil, problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "campos must be a comma-separated list of fields").HTTP()
+		}
+		if campo != "_id" {
+			campos = append(campos, campo)
+		}
+	}
+	return campos, nil
+}
+
+// selecionarCampos mantém do documento, já convertido para JSON, apenas os campos projetados. Caminhos como
+// "endereco.cidade" mantêm o campo de primeiro nível inteiro.
+func selecionarCampos(valores map[string]interface{}, campos []string) map[string]interface{} {
+	selecao := make(map[string]interface{}, len(campos))
+	for _, campo := range campos {
+		raiz := strings.SplitN(campo, ".", 2)[0]
+		if valor, ok := valores[raiz]; ok {
+			selecao[raiz] = valor
+		}
+	}
+	return selecao
+}
+
+// projetar aplica ?campos= à resposta JSON de uma listagem: sem a seleção, os campos fora da projeção
+// apareceriam com o valor zero da struct. Os mapas da projeção são descritos pelo tipo da lista, para que o
+// XML siga as tags xml do modelo.
+func projetar(c echo.Context, lista interface{}) interface{} {
+	campos, _ := camposProjetados(c.QueryParams())
+	if len(campos) == 0 {
+		return lista
+	}
+	dados, err := json.Marshal(lista)
+	if err != nil {
+		return lista
+	}
+	var docs []map[string]interface{}
+	if err := json.Unmarshal(dados, &docs); err != nil {
+		return lista
+	}
+	for i := range docs {
+		docs[i] = selecionarCampos(docs[i], campos)
+	}
+	negociacao.Origem(c, reflect.TypeOf(lista))
+	return docs
+}
+
+// conferirCampo recusa filtros e ordenações por campos sensíveis do modelo que claims não pode ver: os
+// valores sairiam mascarados, mas quais documentos casam com o filtro, ou a ordem deles, revelariam o dado.
+// Só os papéis contam, pois "proprio" e "vinculado" dependem de cada documento da listagem.
+func conferirCampo(campo string, modelo reflect.Type, claims *auth.Claims) *echo.HTTPError {
+	raiz := strings.SplitN(campo, ".", 2)[0]
+	for _, sensivel := range sensiveis(modelo) {
+		if sensivel.nome == raiz && !podeVer(sensivel.permitidos, claims, "") {
+			return problema.Novo(http.StatusForbidden, problema.CodigoCampoRestrito, fmt.Sprintf("Not allowed to filter or sort by %s", raiz)).HTTP()
+		}
+	}
+	return nil
+}
+
+// consultaListagem monta o filtro e as opções das listagens de modelo a partir dos parâmetros de consulta:
+// cada parâmetro, exceto os de paginação, ordenação, projeção e formato, é uma igualdade sobre o campo de
+// mesmo nome. Operadores e campos sensíveis que claims não pode ver são recusados.
+func consultaListagem(q url.Values, lixeira bool, modelo interface{}, claims *auth.Claims) (bson.M, *options.FindOptions, *echo.HTTPError) {
+	t := reflect.TypeOf(modelo)
+	filter := make(bson.M)
+	for k, v := range q {
+		if parametrosListagem[k] {
+			continue
+		}
+		if k == "" || strings.HasPrefix(k, "$") {
+			return nil, nil, problema.Novo(http.StatusBadRequest, problema.CodigoFiltroInvalido, fmt.Sprintf("Invalid filter %q", k)).HTTP()
+		}
+		if herr := conferirCampo(k, t, claims); herr != nil {
+			return nil, nil, herr
+		}
+		filter[k] = v[0]
+	}
+	if filter["_id"] != nil { //convertendo o id, que no filter é um string, para um primitiveObjectID
+		docID, err := primitive.ObjectIDFromHex(filter["_id"].(string))
+		if err != nil {
+			return nil, nil, problema.IDInvalido().HTTP()
+		}
+		filter["_id"] = docID
+	}
+	if lixeira {
+		filtroLixeira(filter)
+	} else {
+		filtroAtivos(filter)
+	}
+	opts, herr := paginacao(q)
+	if herr != nil {
+		return nil, nil, herr
+	}
+	ordem, herr := ordenacao(q)
+	if herr != nil {
+		return nil, nil, herr
+	}
+	for _, e := range ordem {
+		if herr := conferirCampo(e.Key, t, claims); herr != nil {
+			return nil, nil, herr
+		}
+	}
+	if ordem != nil {
+		opts.SetSort(ordem)
+	}
+	campos, herr := camposProjetados(q)
+	if herr != nil {
+		return nil, nil, herr
+	}
+	if campos != nil {
+		projecao := bson.M{}
+		for _, campo := range campos {
+			projecao[campo] = 1
+		}
+		opts.SetProjection(projecao)
+	}
+	return filter, opts, nil
+}
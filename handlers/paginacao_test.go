@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConsultaListagemRecusaCamposSensiveis(t *testing.T) {
+	secretaria := &auth.Claims{Papel: "secretaria"}
+	professor := &auth.Claims{Papel: "professor"}
+	responsavel := &auth.Claims{Papel: "responsavel", Vinculados: []string{"5f1d7f1e2b3c4d5e6f708192"}}
+
+	casos := []struct {
+		nome   string
+		q      url.Values
+		claims *auth.Claims
+		status int //0 quando a consulta é aceita
+	}{
+		{"filter by a public field", url.Values{"nome": {"Ana"}}, professor, 0},
+		{"sort by a public field", url.Values{"ordem": {"-matricula"}}, professor, 0},
+		{"the secretaria filters by telefone", url.Values{"telefone": {"+5511987654321"}}, secretaria, 0},
+		{"the secretaria sorts by cpf", url.Values{"ordem": {"cpf"}}, secretaria, 0},
+		{"filter by telefone", url.Values{"telefone": {"+5511987654321"}}, professor, http.StatusForbidden},
+		{"filter by a nested sensitive path", url.Values{"cpf.0": {"1"}}, professor, http.StatusForbidden},
+		{"sort by cpf", url.Values{"ordem": {"nome,-cpf"}}, professor, http.StatusForbidden},
+		{"a linked guardian probing other students", url.Values{"email": {"a@b.c"}}, responsavel, http.StatusForbidden},
+		{"no token", url.Values{"rg": {"1"}}, nil, http.StatusForbidden},
+		{"operator as a filter", url.Values{"$where": {"sleep(1000)"}}, secretaria, http.StatusBadRequest},
+	}
+	for _, caso := range casos {
+		_, _, herr := consultaListagem(caso.q, false, Alunos{}, caso.claims)
+		switch {
+		case caso.status == 0 && herr != nil:
+			t.Errorf("%s: %v", caso.nome, herr)
+		case caso.status != 0 && (herr == nil || herr.Code != caso.status):
+			t.Errorf("%s: got %v, want %d", caso.nome, herr, caso.status)
+		}
+	}
+}
+
+func TestConsultaListagem(t *testing.T) {
+	q := url.Values{"nome": {"Ana"}, "ordem": {"-nome"}, "limite": {"10"}, "pagina": {"3"}, "campos": {"nome"}}
+	filter, opts, herr := consultaListagem(q, false, Alunos{}, nil)
+	if herr != nil {
+		t.Fatal(herr)
+	}
+	if filter["nome"] != "Ana" || filter["deletedAt"] == nil || len(filter) != 2 {
+		t.Errorf("unexpected filter %v", filter)
+	}
+	if *opts.Limit != 10 || *opts.Skip != 20 {
+		t.Errorf("limit %d, skip %d", *opts.Limit, *opts.Skip)
+	}
+	if ordem := opts.Sort.(bson.D); len(ordem) != 2 || ordem[0].Key != "nome" || ordem[0].Value != -1 || ordem[1].Key != "_id" {
+		t.Errorf("unexpected sort %v", ordem)
+	}
+}
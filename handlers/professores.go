@@ -6,37 +6,66 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"reflect"
 
+	"github.com/krunal4amity/tronicscorp/auth"
 	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/problema"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-type Professores struct {
-	ID            primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Registro      int                `json:"registro" bson:"registro"`
-	Nome          string             `json:"nome" bson:"nome"`
-	Sobrenome     string             `json:"sobrenome" bson:"sobrenome"`
-	Telefone      int                `json:"telefone" bson:"telefone"`
-}
+type Professores = modelos.Professores
 
 type ProfessoresHandler struct {
-	Col dbiface.Collection
+	Col         dbiface.Collection
+	Registros   *GeradorNumero
+	Regras      *Regras
+	Auditoria   *Auditor
+	Versoes     *Versionador
+	Importacoes *Importador
+}
+
+func (uh *ProfessoresHandler) historico() historico {
+	return historico{recurso: "professores", auditoria: uh.Auditoria, versoes: uh.Versoes}
 }
 
-func inserirProfessor(ctx context.Context, professores []Professores, collection dbiface.Collection) ([]interface{}, *echo.HTTPError) {
+func inserirProfessor(ctx context.Context, ev Evento, professores []Professores, collection dbiface.Collection, registros *GeradorNumero, regras *Regras, hist historico) ([]interface{}, *echo.HTTPError) {
 	var insertedIDs []interface{}
+	if err := validarLista(professores); err != nil { //nenhum professor é gravado se algum for inválido
+		return insertedIDs, err
+	}
+	liberar, herr := regras.travar(ctx, "professores", professores)
+	if herr != nil {
+		return insertedIDs, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificarLista(ctx, "professores", professores); err != nil {
+		return insertedIDs, err
+	}
 
-	for _, professor := range professores {
+	for i, professor := range professores {
 		professor.ID = primitive.NewObjectID()
+		if professor.Registro == 0 && registros != nil { //registro explícito é mantido (importação de legado)
+			registro, err := registros.Gerar(ctx, 0)
+			if err != nil {
+				log.Errorf("Unable to generate the registro: %v", err)
+				return insertedIDs, echo.NewHTTPError(http.StatusInternalServerError, "Unable to generate the registro")
+			}
+			professor.Registro = registro
+		}
 		insertID, err := collection.InsertOne(ctx, professor)
 		if err != nil {
 			log.Errorf("Unable to insert: %v", err)
-			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to databse")
+			return insertedIDs, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
 		}
 		insertedIDs = append(insertedIDs, insertID.InsertedID)
+		professores[i] = professor //ID e números gerados ficam disponíveis para quem chamou
+		hist.registrar(ctx, ev, OperacaoInsercao, professor.ID, nil, professor)
 	}
 	return insertedIDs, nil
 }
@@ -46,10 +75,10 @@ func (uh *ProfessoresHandler) InserirProfessor(c echo.Context) error {
 
 	if err := c.Bind(&professores); err != nil {
 		log.Errorf("Unable to bind: %v", err)
-		return c.JSON(http.StatusBadRequest, "Unable to parse request payload")
+		return problema.CorpoInvalido().HTTP()
 	}
 
-	IDs, err := inserirProfessor(context.Background(), professores, uh.Col)
+	IDs, err := inserirProfessor(context.Background(), eventoDaRequisicao(c), professores, uh.Col, uh.Registros, uh.Regras, uh.historico())
 	if err != nil {
 		return err
 	}
@@ -57,21 +86,29 @@ func (uh *ProfessoresHandler) InserirProfessor(c echo.Context) error {
 	return c.JSON(http.StatusCreated, IDs)
 }
 
-func buscarProfessores(ctx context.Context, q url.Values, collection dbiface.Collection) ([]Professores, *echo.HTTPError) {
-	var professores []Professores
+// ImportarProfessores inclui os professores de uma planilha CSV ou XLSX pelo mesmo caminho do POST
+// /professores; a coluna de disciplinas traz os ids separados por vírgula.
+func (uh *ProfessoresHandler) ImportarProfessores(c echo.Context) error {
+	return uh.Importacoes.importar(c, importavel{recurso: "professores", tipo: reflect.TypeOf(Professores{}),
+		regras: uh.Regras,
+		inserir: func(ctx context.Context, ev Evento, doc interface{}) (interface{}, *echo.HTTPError) {
+			professores := []Professores{doc.(Professores)}
+			_, err := inserirProfessor(ctx, ev, professores, uh.Col, uh.Registros, uh.Regras, uh.historico())
+			return professores[0], err
+		}})
+}
 
-	filter := make(map[string]interface{})
-	for k, v := range q {
-		filter[k] = v[0]
-	}
-	if filter["_id"] != nil {
-		docID, err := primitive.ObjectIDFromHex(filter["_id"].(string))
-		if err != nil {
-			return professores, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
-		}
-		filter["_id"] = docID
+func (uh *ProfessoresHandler) BuscarImportacao(c echo.Context) error {
+	return uh.Importacoes.buscar(c, "professores")
+}
+
+func buscarProfessores(ctx context.Context, q url.Values, collection dbiface.Collection, lixeira bool, claims *auth.Claims) ([]Professores, *echo.HTTPError) {
+	var professores []Professores
+	filter, opts, herr := consultaListagem(q, lixeira, Professores{}, claims)
+	if herr != nil {
+		return professores, herr
 	}
-	cursor, err := collection.Find(ctx, bson.M(filter)) /*filter, que corresponde ao q url.Values, está sendo
+	cursor, err := collection.Find(ctx, filter, opts) /*filter, que corresponde ao q url.Values, está sendo
 	convertido para bson. Filter é argumento do método Find e está sendo definido logo acima*/
 	if err != nil {
 		log.Errorf("Unable to find the teacher: %v", err)
@@ -80,26 +117,31 @@ func buscarProfessores(ctx context.Context, q url.Values, collection dbiface.Col
 	err = cursor.All(ctx, &professores)
 	if err != nil {
 		log.Errorf("Unable to read the cursor: %v", err)
-		return professores, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return professores, problema.CorpoInvalido().HTTP()
 	}
 	return professores, nil
 }
 
 func (uh *ProfessoresHandler) BuscarProfessores(c echo.Context) error {
-	professores, err := buscarProfessores(context.Background(), c.QueryParams(), uh.Col)
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "professores", uh.Col, Professores{}, false)
+	}
+	professores, err := buscarProfessores(context.Background(), c.QueryParams(), uh.Col, false, claimsDaRequisicao(c))
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, professores)
+	return c.JSON(http.StatusOK, projetar(c, mascarar(c, professores)))
 }
 
 func buscarProfessor(ctx context.Context, id string, collection dbiface.Collection) (Professores, *echo.HTTPError) {
 	var professores Professores
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return professores, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert do ObjectID")
+		return professores, problema.IDInvalido().HTTP()
 	}
-	res := collection.FindOne(ctx, bson.M{"_id": docID})
+	res := collection.FindOne(ctx, filtroAtivos(bson.M{"_id": docID}))
 	err = res.Decode(&professores)
 	if err != nil {
 		return professores, echo.NewHTTPError(http.StatusNotFound, "Unable to find the teacher")
@@ -108,25 +150,36 @@ func buscarProfessor(ctx context.Context, id string, collection dbiface.Collecti
 }
 
 func (uh *ProfessoresHandler) BuscarProfessor(c echo.Context) error {
+	if asOf := c.QueryParam("asOf"); asOf != "" { //estado do documento em uma data passada
+		var professores Professores
+		if err := uh.Versoes.versaoEm(context.Background(), "professores", c.Param("id"), asOf, &professores); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, mascarar(c, professores))
+	}
 	professores, err := buscarProfessor(context.Background(), c.Param("id"), uh.Col)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, professores)
+	return c.JSON(http.StatusOK, mascarar(c, professores))
 }
 
-func alterarProfessor(ctx context.Context, id string, reqBody io.ReadCloser, collection dbiface.Collection) (Professores, *echo.HTTPError) {
+func alterarProfessor(ctx context.Context, ev Evento, id string, reqBody io.ReadCloser, collection dbiface.Collection, regras *Regras, hist historico) (Professores, *echo.HTTPError) {
 	var professores Professores
+	antes, herr := buscarProfessor(ctx, id, collection) //cópia própria, já que o corpo é decodificado sobre professores
+	if herr != nil {
+		return professores, herr
+	}
 
 	//convertendo o id, que é um string, para primitive.ObjectID
 	docID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		log.Errorf("Cannot convert to ObjectID: %v", err)
-		return professores, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return professores, problema.IDInvalido().HTTP()
 	}
 
 	//procurar se o professor existe, caso contrário erro 404 (Not Found)
-	filter := bson.M{"_id": docID}
+	filter := filtroAtivos(bson.M{"_id": docID})
 	res := collection.FindOne(ctx, filter)
 	if err := res.Decode(&professores); err != nil {
 		log.Errorf("Unable to decode to teacher: %v", err)
@@ -137,9 +190,23 @@ func alterarProfessor(ctx context.Context, id string, reqBody io.ReadCloser, col
 	//JSON decodificação do reqBody
 	if err := json.NewDecoder(reqBody).Decode(&professores); err != nil {
 		log.Errorf("Unable to decode using reqBody: %v", err)
-		return professores, echo.NewHTTPError(http.StatusBadRequest, "Unable to parse request payload")
+		return professores, problema.CorpoInvalido().HTTP()
 	} /*ler o reqBody e usar as informações inseridas para popular os campos de professores, que "representa" a
 	struct Professores*/
+	professores.DeletadoEm = nil //a lixeira só é alterada pelas rotas de DELETE e restaurar
+
+	//validação da requisição
+	if err := validar(professores); err != nil {
+		return professores, err
+	}
+	liberar, herr := regras.travar(ctx, "professores", professores)
+	if herr != nil {
+		return professores, herr
+	}
+	defer liberar() //até a gravação
+	if err := regras.verificar(ctx, "professores", professores); err != nil {
+		return professores, err
+	}
 
 	//atualização do professor
 	_, err = collection.UpdateOne(ctx, filter, bson.M{"$set": professores}) /* _, err, pois UpdateOne possui 2
@@ -148,34 +215,71 @@ func alterarProfessor(ctx context.Context, id string, reqBody io.ReadCloser, col
 		log.Errorf("Unable to update the teacher: %v", err)
 		return professores, echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the teacher")
 	}
+	hist.registrar(ctx, ev, OperacaoAlteracao, antes.ID, antes, professores)
 	return professores, nil
 }
 
 func (uh *ProfessoresHandler) AtualizarProfessor(c echo.Context) error {
-	professores, err := alterarProfessor(context.Background(), c.Param("id"), c.Request().Body, uh.Col)
+	if err := negociacao.CorpoJSON(c, Professores{}); err != nil { //corpos em XML ou MessagePack seguem como JSON
+		return err
+	}
+	professores, err := alterarProfessor(context.Background(), eventoDaRequisicao(c), c.Param("id"), c.Request().Body, uh.Col,
+		uh.Regras, uh.historico())
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, professores)
+	return c.JSON(http.StatusOK, mascarar(c, professores))
 }
 
-func deletarProfessor(ctx context.Context, id string, collection dbiface.Collection) (int64, *echo.HTTPError) {
-	docID, err := primitive.ObjectIDFromHex(id)
+func deletarProfessor(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	antes, err := buscarProfessor(ctx, id, collection)
 	if err != nil {
-		log.Errorf("Unable to delete the teacher: %v", err)
-		return 0, echo.NewHTTPError(http.StatusInternalServerError, "Unable to convert to ObjectID")
+		return 0, err
 	}
-	res, err := collection.DeleteOne(ctx, bson.M{"_id": docID})
+	total, err := moverParaLixeira(ctx, id, collection) //o professor vai para a lixeira em vez de ser removido
 	if err != nil {
-		return 0, echo.NewHTTPError(http.StatusNotFound, "Unable to delete the teacher")
+		return 0, err
 	}
-	return res.DeletedCount, nil
+	hist.registrar(ctx, ev, OperacaoExclusao, antes.ID, antes, nil)
+	return total, nil
 }
 
 func (uh *ProfessoresHandler) DeletarProfessor(c echo.Context) error {
-	del, err := deletarProfessor(context.Background(), c.Param("id"), uh.Col)
+	del, err := deletarProfessor(context.Background(), eventoDaRequisicao(c), c.Param("id"), uh.Col, uh.historico())
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, del)
 }
+
+func (uh *ProfessoresHandler) BuscarLixeira(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		return exportarCadastro(c, formato, "professores", uh.Col, Professores{}, true)
+	}
+	professores, err := buscarProfessores(context.Background(), c.QueryParams(), uh.Col, true, claimsDaRequisicao(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, projetar(c, mascarar(c, professores)))
+}
+
+func restaurarProfessor(ctx context.Context, ev Evento, id string, collection dbiface.Collection, hist historico) (int64, *echo.HTTPError) {
+	total, err := restaurarDaLixeira(ctx, id, collection)
+	if err != nil {
+		return 0, err
+	}
+	if depois, err := buscarProfessor(ctx, id, collection); err == nil {
+		hist.registrar(ctx, ev, OperacaoRestauracao, depois.ID, nil, depois)
+	}
+	return total, nil
+}
+
+func (uh *ProfessoresHandler) RestaurarProfessor(c echo.Context) error {
+	count, err := restaurarProfessor(context.Background(), eventoDaRequisicao(c), c.Param("id"), uh.Col, uh.historico())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, count)
+}
@@ -0,0 +1,613 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Tipos de regra de negócio. As regras são avaliadas sobre o documento completo que será gravado, com os
+// campos pelo nome no BSON, depois da validação das tags.
+const (
+	RegraMultiplo   = "multiplo"   //campo numérico múltiplo de Valor
+	RegraComparacao = "comparacao" //campo comparado a OutroCampo do mesmo documento com Operador
+	RegraSoma       = "soma"       //soma de Somar nos documentos referenciados por campo, dividida por Divisor, até Maximo
+	RegraDistintos  = "distintos"  //documentos referenciados pelos Campos não podem repetir o valor de Chave
+	RegraExistente  = "existente"  //toda referência em Campo ou Campos aponta para um documento ativo da Colecao
+)
+
+// regrasIntegridade são as referências entre os recursos, conferidas mesmo sem arquivo de regras: um aluno
+// ou uma disciplina não aponta para um curso inexistente, nem um professor para uma disciplina inexistente.
+var regrasIntegridade = []Regra{
+	{Nome: "curso-existente", Recurso: "alunos", Tipo: RegraExistente, Campos: []string{"curso", "cursos"},
+		Colecao: "cursos", Referencia: "codigo", Mensagem: "the course does not exist",
+		Mensagens: map[string]string{"pt-BR": "o curso não existe"}},
+	{Nome: "curso-existente", Recurso: "disciplinas", Tipo: RegraExistente, Campo: "curso",
+		Colecao: "cursos", Referencia: "codigo", Mensagem: "the course does not exist",
+		Mensagens: map[string]string{"pt-BR": "o curso não existe"}},
+	{Nome: "disciplina-existente", Recurso: "professores", Tipo: RegraExistente, Campo: "disciplinas",
+		Colecao: "disciplinas", Mensagem: "the subject does not exist",
+		Mensagens: map[string]string{"pt-BR": "a disciplina não existe"}},
+}
+
+// Regra é uma regra de negócio declarada no arquivo de regras (REGRAS_ARQUIVO). Colecao, Referencia, Somar
+// e Chave descrevem os documentos referenciados pelas regras entre documentos; apenas os ativos contam.
+type Regra struct {
+	Nome       string   `json:"nome"`
+	Recurso    string   `json:"recurso"` //recurso cujas escritas a regra confere
+	Tipo       string   `json:"tipo"`
+	Campo      string   `json:"campo,omitempty"`
+	Campos     []string `json:"campos,omitempty"` //regra distintos: campos com as referências, escalares ou listas
+	Valor      float64  `json:"valor,omitempty"`
+	Operador   string   `json:"operador,omitempty"` //eq, ne, gt, gte, lt ou lte
+	OutroCampo string   `json:"outroCampo,omitempty"`
+	Colecao    string   `json:"colecao,omitempty"`
+	Referencia string   `json:"referencia,omitempty"` //campo dos documentos referenciados, "_id" quando vazio
+	Somar      string   `json:"somar,omitempty"`
+	Divisor    float64  `json:"divisor,omitempty"`
+	Maximo     float64  `json:"maximo,omitempty"`
+	Chave      string   `json:"chave,omitempty"`
+	Mensagem   string   `json:"mensagem,omitempty"` //texto da violação; há um texto padrão por tipo
+	//texto da violação em outros idiomas, por exemplo {"pt-BR": "..."}; sem ele, Mensagem passa pelo catálogo
+	Mensagens map[string]string `json:"mensagens,omitempty"`
+}
+
+// Regras avalia as regras de negócio nas escritas. Um *Regras nil não confere nada.
+type Regras struct {
+	Lista []Regra
+	Cols  map[string]dbiface.Collection //coleção de cada recurso que as regras podem referenciar
+	//travas das escritas conferidas por regras entre documentos, compartilhadas pelas instâncias; sem a
+	//coleção, duas escritas simultâneas podem passar cada uma na regra e violá-la juntas
+	Travas dbiface.Collection
+}
+
+const (
+	duracaoTrava = 30 * time.Second //uma trava vencida é de uma instância que caiu no meio da escrita
+	esperaTrava  = 5 * time.Second
+)
+
+// travar impede que outra escrita confira as mesmas regras entre documentos (soma e distintos) sobre os
+// mesmos documentos até a função devolvida ser chamada: a verificação e a gravação do recurso ficam entre as
+// duas. docs é o documento a gravar ou uma lista deles. Cada trava é de uma regra e de um documento: os
+// referenciados pelos docs (a disciplina de um professor, o curso de um aluno), os próprios docs e os que os
+// referenciam. Duas escritas só esperam uma pela outra quando compartilham um desses documentos; sem regras
+// desse tipo para o recurso, não trava nada.
+func (r *Regras) travar(ctx context.Context, recurso string, docs interface{}) (func(), *echo.HTTPError) {
+	var nomes []string
+	if r != nil && r.Travas != nil {
+		var err error
+		if nomes, err = r.nomesDasTravas(ctx, recurso, docs); err != nil {
+			log.Errorf("Unable to find the documents to lock: %v", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to evaluate the business rules")
+		}
+	}
+	sort.Strings(nomes) //sempre na mesma ordem, para duas escritas não esperarem uma pela outra
+	dono := primitive.NewObjectID()
+	var obtidas []string
+	liberar := func() {
+		for _, nome := range obtidas {
+			if _, err := r.Travas.DeleteOne(context.Background(), bson.M{"_id": nome, "dono": dono}); err != nil {
+				log.Errorf("Unable to release the lock %s: %v", nome, err)
+			}
+		}
+	}
+	for _, nome := range nomes {
+		if err := r.obterTrava(ctx, nome, dono); err != nil {
+			liberar()
+			if err == errTravaOcupada {
+				return nil, problema.Novo(http.StatusConflict, problema.CodigoConcorrencia,
+					"Another request is changing related documents, try again").HTTP()
+			}
+			log.Errorf("Unable to acquire the lock %s: %v", nome, err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "Unable to evaluate the business rules")
+		}
+		obtidas = append(obtidas, nome)
+	}
+	return liberar, nil
+}
+
+var errTravaOcupada = errors.New("lock held by another request")
+
+// nomesDasTravas devolve, sem repetição, as travas de cada regra entre documentos envolvida na escrita dos
+// docs, no formato "regra:<recurso>:<nome>:<coleção>=<valor>".
+func (r *Regras) nomesDasTravas(ctx context.Context, recurso string, docs interface{}) ([]string, error) {
+	lista := reflect.ValueOf(docs)
+	if lista.Kind() != reflect.Slice {
+		lista = reflect.ValueOf([]interface{}{docs})
+	}
+	vistos := make(map[string]bool)
+	var nomes []string
+	travar := func(regra Regra, colecao string, valor interface{}) {
+		if oid, ok := valor.(primitive.ObjectID); ok {
+			valor = oid.Hex()
+		}
+		nome := fmt.Sprintf("regra:%s:%s:%s=%v", regra.Recurso, regra.Nome, colecao, chaveReferencia(valor))
+		if !vistos[nome] {
+			vistos[nome] = true
+			nomes = append(nomes, nome)
+		}
+	}
+	for i := 0; i < lista.Len(); i++ {
+		var campos bson.M
+		for _, regra := range r.Lista {
+			if (regra.Tipo != RegraSoma && regra.Tipo != RegraDistintos) || (regra.Recurso != recurso && regra.Colecao != recurso) {
+				continue
+			}
+			if campos == nil {
+				var err error
+				if campos, err = camposBSON(lista.Index(i).Interface()); err != nil {
+					return nil, err
+				}
+			}
+			if regra.Recurso == recurso { //o documento e os que ele referencia
+				if id := campos["_id"]; id != nil {
+					travar(regra, regra.Recurso, id)
+				}
+				for _, campo := range regra.camposReferencia() {
+					for _, ref := range referencias(campos[campo]) {
+						travar(regra, regra.Colecao, ref)
+					}
+				}
+			}
+			if regra.Colecao == recurso && r.Cols[regra.Recurso] != nil { //o documento e os que o referenciam
+				valor := campos[regra.campoReferenciado()]
+				if valor == nil {
+					continue
+				}
+				travar(regra, regra.Colecao, valor)
+				var alternativas []bson.M
+				for _, campo := range regra.camposReferencia() {
+					alternativas = append(alternativas, bson.M{campo: valor})
+				}
+				dependentes, err := buscarTodos(ctx, r.Cols[regra.Recurso], filtroAtivos(bson.M{"$or": alternativas}))
+				if err != nil {
+					return nil, fmt.Errorf("rule %s: %v", regra.Nome, err)
+				}
+				for _, dependente := range dependentes {
+					travar(regra, regra.Recurso, dependente["_id"])
+				}
+			}
+		}
+	}
+	return nomes, nil
+}
+
+func (r *Regras) obterTrava(ctx context.Context, nome string, dono primitive.ObjectID) error {
+	limite := time.Now().Add(esperaTrava)
+	for {
+		agora := time.Now()
+		_, err := r.Travas.InsertOne(ctx, bson.M{"_id": nome, "dono": dono, "expiraEm": agora.Add(duracaoTrava)})
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		if _, err := r.Travas.DeleteOne(ctx, bson.M{"_id": nome, "expiraEm": bson.M{"$lt": agora}}); err != nil {
+			return err
+		}
+		if agora.After(limite) {
+			return errTravaOcupada
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// CarregarRegras lê as regras de um arquivo JSON com uma lista de Regra, que se somam às regrasIntegridade
+// cujas coleções estão em cols. Um arquivo inexistente resulta em apenas essas; uma regra incompleta é um
+// erro, para não ser ignorada em silêncio.
+func CarregarRegras(arquivo string, cols map[string]dbiface.Collection) (*Regras, error) {
+	r := &Regras{Cols: cols}
+	for _, regra := range regrasIntegridade {
+		if cols[regra.Colecao] != nil {
+			r.Lista = append(r.Lista, regra)
+		}
+	}
+	conteudo, err := ioutil.ReadFile(arquivo)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	var lista []Regra
+	if err := json.Unmarshal(conteudo, &lista); err != nil {
+		return nil, fmt.Errorf("%s: %v", arquivo, err)
+	}
+	for _, regra := range lista {
+		if err := regra.conferir(cols); err != nil {
+			return nil, fmt.Errorf("%s: rule %q: %v", arquivo, regra.Nome, err)
+		}
+	}
+	r.Lista = append(r.Lista, lista...)
+	return r, nil
+}
+
+func (regra Regra) conferir(cols map[string]dbiface.Collection) error {
+	if regra.Nome == "" || regra.Recurso == "" {
+		return fmt.Errorf("nome and recurso are required")
+	}
+	switch regra.Tipo {
+	case RegraMultiplo:
+		if regra.Campo == "" || regra.Valor == 0 {
+			return fmt.Errorf("campo and valor are required")
+		}
+	case RegraComparacao:
+		if regra.Campo == "" || regra.OutroCampo == "" || operadores[regra.Operador] == nil {
+			return fmt.Errorf("campo, outroCampo and a valid operador are required")
+		}
+	case RegraSoma:
+		if regra.Campo == "" || regra.Somar == "" || cols[regra.Colecao] == nil {
+			return fmt.Errorf("campo, somar and a known colecao are required")
+		}
+	case RegraDistintos:
+		if len(regra.Campos) == 0 || regra.Chave == "" || cols[regra.Colecao] == nil {
+			return fmt.Errorf("campos, chave and a known colecao are required")
+		}
+	case RegraExistente:
+		if (regra.Campo == "" && len(regra.Campos) == 0) || cols[regra.Colecao] == nil {
+			return fmt.Errorf("campo or campos and a known colecao are required")
+		}
+	default:
+		return fmt.Errorf("unknown tipo %q", regra.Tipo)
+	}
+	return nil
+}
+
+var operadores = map[string]func(int) bool{
+	"eq":  func(c int) bool { return c == 0 },
+	"ne":  func(c int) bool { return c != 0 },
+	"gt":  func(c int) bool { return c > 0 },
+	"gte": func(c int) bool { return c >= 0 },
+	"lt":  func(c int) bool { return c < 0 },
+	"lte": func(c int) bool { return c <= 0 },
+}
+
+// verificar confere um documento do recurso e devolve as violações no formato problem+json (422). Quando
+// outros documentos referenciam o que está sendo alterado, as regras deles são conferidas de novo.
+func (r *Regras) verificar(ctx context.Context, recurso string, doc interface{}) *echo.HTTPError {
+	if r == nil {
+		return nil
+	}
+	violacoes, err := r.avaliar(ctx, recurso, doc, "")
+	if err == nil {
+		var dependentes []problema.ErroCampo
+		dependentes, err = r.avaliarDependentes(ctx, recurso, doc)
+		violacoes = append(violacoes, dependentes...)
+	}
+	return respostaRegras(violacoes, err)
+}
+
+// verificarLista confere todos os documentos de um lote antes de gravar qualquer um.
+func (r *Regras) verificarLista(ctx context.Context, recurso string, docs interface{}) *echo.HTTPError {
+	if r == nil {
+		return nil
+	}
+	lista := reflect.ValueOf(docs)
+	var violacoes []problema.ErroCampo
+	for i := 0; i < lista.Len(); i++ {
+		v, err := r.avaliar(ctx, recurso, lista.Index(i).Interface(), fmt.Sprintf("[%d].", i))
+		if err != nil {
+			return respostaRegras(nil, err)
+		}
+		violacoes = append(violacoes, v...)
+	}
+	return respostaRegras(violacoes, nil)
+}
+
+func respostaRegras(violacoes []problema.ErroCampo, err error) *echo.HTTPError {
+	if err != nil {
+		log.Errorf("Unable to evaluate the business rules: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to evaluate the business rules")
+	}
+	if len(violacoes) == 0 {
+		return nil
+	}
+	p := problema.Novo(http.StatusUnprocessableEntity, problema.CodigoRegra, "The request violates business rules")
+	p.Erros = violacoes
+	return p.HTTP()
+}
+
+// camposBSON converte o documento para os nomes do BSON, sobre os quais as regras são avaliadas.
+func camposBSON(doc interface{}) (bson.M, error) {
+	var campos bson.M
+	conteudo, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = bson.Unmarshal(conteudo, &campos)
+	return campos, err
+}
+
+func (r *Regras) avaliar(ctx context.Context, recurso string, doc interface{}, prefixo string) ([]problema.ErroCampo, error) {
+	var violacoes []problema.ErroCampo
+	var campos bson.M
+	for _, regra := range r.Lista {
+		if regra.Recurso != recurso {
+			continue
+		}
+		if campos == nil { //o documento é convertido para os nomes do BSON uma única vez
+			var err error
+			if campos, err = camposBSON(doc); err != nil {
+				return nil, err
+			}
+		}
+		ok, err := r.cumprida(ctx, regra, campos, nil)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", regra.Nome, err)
+		}
+		if !ok {
+			campo := regra.Campo
+			if campo == "" {
+				campo = strings.Join(regra.Campos, ",")
+			}
+			violacoes = append(violacoes, problema.ErroCampo{Campo: prefixo + campo, Regra: regra.Nome,
+				Mensagem: regra.mensagem(), Traducoes: regra.Mensagens})
+		}
+	}
+	return violacoes, nil
+}
+
+// avaliarDependentes confere as regras soma e distintos dos documentos que referenciam doc, com doc no lugar
+// da versão gravada: aumentar a cargaHoraria de uma disciplina pode levar um professor além das 40 horas. A
+// violação aponta o campo de doc que a causou.
+func (r *Regras) avaliarDependentes(ctx context.Context, recurso string, doc interface{}) ([]problema.ErroCampo, error) {
+	var violacoes []problema.ErroCampo
+	var campos bson.M
+	for _, regra := range r.Lista {
+		if regra.Colecao != recurso || (regra.Tipo != RegraSoma && regra.Tipo != RegraDistintos) || r.Cols[regra.Recurso] == nil {
+			continue
+		}
+		if campos == nil {
+			var err error
+			if campos, err = camposBSON(doc); err != nil {
+				return nil, err
+			}
+		}
+		valor := campos[regra.campoReferenciado()]
+		if valor == nil {
+			continue
+		}
+		var alternativas []bson.M
+		for _, campo := range regra.camposReferencia() {
+			alternativas = append(alternativas, bson.M{campo: valor})
+		}
+		dependentes, err := buscarTodos(ctx, r.Cols[regra.Recurso], filtroAtivos(bson.M{"$or": alternativas}))
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %v", regra.Nome, err)
+		}
+		for _, dependente := range dependentes {
+			ok, err := r.cumprida(ctx, regra, dependente, campos)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %v", regra.Nome, err)
+			}
+			if !ok {
+				campo := regra.Somar
+				if regra.Tipo == RegraDistintos {
+					campo = regra.Chave
+				}
+				violacoes = append(violacoes, problema.ErroCampo{Campo: campo, Regra: regra.Nome,
+					Mensagem: regra.mensagem(), Traducoes: regra.Mensagens})
+				break
+			}
+		}
+	}
+	return violacoes, nil
+}
+
+func (regra Regra) mensagem() string {
+	if regra.Mensagem != "" {
+		return regra.Mensagem
+	}
+	switch regra.Tipo {
+	case RegraMultiplo:
+		return fmt.Sprintf("%s must be a multiple of %v", regra.Campo, regra.Valor)
+	case RegraComparacao:
+		return fmt.Sprintf("%s must be %s %s", regra.Campo, regra.Operador, regra.OutroCampo)
+	case RegraSoma:
+		return fmt.Sprintf("the %s of the %s in %s exceeds %v", regra.Somar, regra.Colecao, regra.Campo, regra.Maximo)
+	case RegraExistente:
+		return fmt.Sprintf("the %s referenced in %s must exist", regra.Colecao, strings.Join(regra.camposReferencia(), ", "))
+	}
+	return fmt.Sprintf("the %s in %s must have distinct %s", regra.Colecao, strings.Join(regra.Campos, ", "), regra.Chave)
+}
+
+// cumprida avalia a regra em doc. Nas regras entre documentos, um novo documento referenciado, ainda não
+// gravado, substitui a versão do banco com o mesmo _id.
+func (r *Regras) cumprida(ctx context.Context, regra Regra, doc bson.M, novo bson.M) (bool, error) {
+	switch regra.Tipo {
+	case RegraMultiplo:
+		n, ok := numero(doc[regra.Campo])
+		if !ok { //campo ausente fica a cargo da validação
+			return true, nil
+		}
+		return math.Mod(n, regra.Valor) == 0, nil
+	case RegraComparacao:
+		a, b := doc[regra.Campo], doc[regra.OutroCampo]
+		if a == nil || b == nil {
+			return true, nil
+		}
+		c, ok := comparar(a, b)
+		if !ok {
+			return false, nil
+		}
+		return operadores[regra.Operador](c), nil
+	case RegraSoma:
+		refs := referencias(doc[regra.Campo])
+		if len(refs) == 0 {
+			return true, nil
+		}
+		docs, err := r.referenciados(ctx, regra, refs, novo)
+		if err != nil {
+			return false, err
+		}
+		var soma float64
+		for _, d := range docs {
+			n, _ := numero(d[regra.Somar])
+			soma += n
+		}
+		if regra.Divisor != 0 {
+			soma /= regra.Divisor
+		}
+		return soma <= regra.Maximo, nil
+	case RegraDistintos:
+		var refs []interface{}
+		for _, campo := range regra.Campos {
+			refs = append(refs, referencias(doc[campo])...)
+		}
+		if len(refs) < 2 {
+			return true, nil
+		}
+		docs, err := r.referenciados(ctx, regra, refs, novo)
+		if err != nil {
+			return false, err
+		}
+		vistos := make(map[interface{}]bool)
+		for _, d := range docs {
+			chave := d[regra.Chave]
+			if chave == nil || chave == "" {
+				continue
+			}
+			if vistos[chave] {
+				return false, nil
+			}
+			vistos[chave] = true
+		}
+		return true, nil
+	case RegraExistente:
+		var refs []interface{}
+		for _, campo := range regra.camposReferencia() {
+			refs = append(refs, referencias(doc[campo])...)
+		}
+		if len(refs) == 0 {
+			return true, nil
+		}
+		docs, err := r.referenciados(ctx, regra, refs, novo)
+		if err != nil {
+			return false, err
+		}
+		existentes := make(map[interface{}]bool)
+		for _, d := range docs {
+			existentes[chaveReferencia(d[regra.campoReferenciado()])] = true
+		}
+		for _, ref := range refs {
+			if !existentes[chaveReferencia(ref)] {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return true, nil
+}
+
+// camposReferencia devolve Campos ou, na falta deles, Campo.
+func (regra Regra) camposReferencia() []string {
+	if len(regra.Campos) > 0 {
+		return regra.Campos
+	}
+	return []string{regra.Campo}
+}
+
+// campoReferenciado devolve Referencia ou, na falta dela, "_id".
+func (regra Regra) campoReferenciado() string {
+	if regra.Referencia != "" {
+		return regra.Referencia
+	}
+	return "_id"
+}
+
+// chaveReferencia iguala os números de tipos diferentes (o código gravado como int32 ou int64, por exemplo)
+// para comparar referências.
+func chaveReferencia(valor interface{}) interface{} {
+	if n, ok := numero(valor); ok {
+		return n
+	}
+	return valor
+}
+
+// referenciados busca os documentos ativos da coleção da regra que os valores refs apontam, com novo no
+// lugar do documento de mesmo _id.
+func (r *Regras) referenciados(ctx context.Context, regra Regra, refs []interface{}, novo bson.M) ([]bson.M, error) {
+	docs, err := buscarTodos(ctx, r.Cols[regra.Colecao], filtroAtivos(bson.M{regra.campoReferenciado(): bson.M{"$in": refs}}))
+	if err != nil || novo == nil {
+		return docs, err
+	}
+	for i, d := range docs {
+		if d["_id"] == novo["_id"] {
+			docs[i] = novo
+		}
+	}
+	return docs, nil
+}
+
+// referencias devolve os valores de um campo de referência, que pode ser um valor único ou uma lista.
+func referencias(valor interface{}) []interface{} {
+	switch v := valor.(type) {
+	case nil:
+		return nil
+	case primitive.A:
+		return []interface{}(v)
+	case []interface{}:
+		return v
+	}
+	return []interface{}{valor}
+}
+
+func numero(valor interface{}) (float64, bool) {
+	switch v := valor.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// comparar ordena números, datas e textos; valores de tipos diferentes não são comparáveis.
+func comparar(a, b interface{}) (int, bool) {
+	if x, ok := numero(a); ok {
+		y, ok := numero(b)
+		switch {
+		case !ok:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	if x, ok := a.(primitive.DateTime); ok {
+		y, ok := b.(primitive.DateTime)
+		if !ok {
+			return 0, false
+		}
+		return comparar(int64(x), int64(y))
+	}
+	x, ok := a.(string)
+	y, ok2 := b.(string)
+	if !ok || !ok2 {
+		return 0, false
+	}
+	return strings.Compare(x, y), true
+}
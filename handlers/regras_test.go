@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// violacoes devolve as regras violadas de um erro 422 das regras de negócio.
+func violacoes(t *testing.T, err *echo.HTTPError) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	p := problema.Converter(err)
+	if p.Status != http.StatusUnprocessableEntity {
+		t.Fatalf("got %v, want a 422", err)
+	}
+	var nomes []string
+	for _, e := range p.Erros {
+		nomes = append(nomes, e.Campo+":"+e.Regra)
+	}
+	return nomes
+}
+
+// escola monta as regras de regras.json sobre coleções em memória; as coleções não informadas ficam vazias.
+func escola(t *testing.T, cols map[string]*colecaoMemoria) *Regras {
+	t.Helper()
+	ifaces := make(map[string]dbiface.Collection)
+	for _, recurso := range []string{"alunos", "professores", "cursos", "disciplinas"} {
+		if cols[recurso] == nil {
+			cols[recurso] = novaColecao()
+		}
+		ifaces[recurso] = cols[recurso]
+	}
+	regras, err := CarregarRegras(filepath.Join("..", "regras.json"), ifaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regras.Travas = novaColecao()
+	return regras
+}
+
+func TestRegrasDeUmDocumento(t *testing.T) {
+	regras := &Regras{Lista: []Regra{
+		{Nome: "par", Recurso: "x", Tipo: RegraMultiplo, Campo: "n", Valor: 2},
+		{Nome: "periodo", Recurso: "x", Tipo: RegraComparacao, Campo: "inicio", Operador: "lt", OutroCampo: "fim"},
+	}}
+	type doc struct {
+		N      int        `bson:"n,omitempty"`
+		Inicio *time.Time `bson:"inicio,omitempty"`
+		Fim    *time.Time `bson:"fim,omitempty"`
+		Nome   string     `bson:"nome,omitempty"`
+	}
+	hoje, amanha := time.Now(), time.Now().Add(24*time.Hour)
+	casos := []struct {
+		nome string
+		doc  doc
+		want []string
+	}{
+		{"valid", doc{N: 4, Inicio: &hoje, Fim: &amanha}, nil},
+		{"odd number", doc{N: 3}, []string{"n:par"}},
+		{"reversed dates", doc{N: 2, Inicio: &amanha, Fim: &hoje}, []string{"inicio:periodo"}},
+		{"missing fields are left to the validator", doc{Inicio: &hoje}, nil},
+	}
+	for _, caso := range casos {
+		if got := violacoes(t, regras.verificar(context.Background(), "x", caso.doc)); strings.Join(got, " ") != strings.Join(caso.want, " ") {
+			t.Errorf("%s: got %v, want %v", caso.nome, got, caso.want)
+		}
+	}
+	if err := regras.verificar(context.Background(), "outro", doc{N: 3}); err != nil {
+		t.Errorf("rules of another resource were applied: %v", err)
+	}
+	var nenhuma *Regras
+	if err := nenhuma.verificar(context.Background(), "x", doc{N: 3}); err != nil {
+		t.Errorf("a nil *Regras should not check anything, got %v", err)
+	}
+}
+
+func TestCarregarRegrasRecusaIncompletas(t *testing.T) {
+	dir := t.TempDir()
+	cols := map[string]dbiface.Collection{"disciplinas": novaColecao()}
+	for nome, conteudo := range map[string]string{
+		"unknown type":       `[{"nome":"a","recurso":"x","tipo":"media"}]`,
+		"multiple of zero":   `[{"nome":"a","recurso":"x","tipo":"multiplo","campo":"n"}]`,
+		"unknown operator":   `[{"nome":"a","recurso":"x","tipo":"comparacao","campo":"a","outroCampo":"b","operador":"="}]`,
+		"unknown collection": `[{"nome":"a","recurso":"x","tipo":"soma","campo":"d","somar":"h","colecao":"turmas"}]`,
+		"nameless":           `[{"recurso":"x","tipo":"multiplo","campo":"n","valor":2}]`,
+		"not JSON":           `{`,
+	} {
+		arquivo := filepath.Join(dir, "regras.json")
+		ioutil.WriteFile(arquivo, []byte(conteudo), 0600)
+		if _, err := CarregarRegras(arquivo, cols); err == nil {
+			t.Errorf("%s: the file should be refused", nome)
+		}
+	}
+	regras, err := CarregarRegras(filepath.Join(dir, "inexistente.json"), cols)
+	if err != nil || len(regras.Lista) != 1 { //só a disciplina-existente, cuja coleção foi informada
+		t.Errorf("a missing file should leave only the integrity rules, got %v (%v)", regras, err)
+	}
+}
+
+func TestRegraSomaReavaliaDisciplinaAlterada(t *testing.T) {
+	d1 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Cálculo", CargaHoraria: 300}
+	d2 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Física", CargaHoraria: 240}
+	professores := novaColecao(Professores{ID: primitive.NewObjectID(), Nome: "Ana", Sobrenome: "Lima",
+		Disciplinas: []primitive.ObjectID{d1.ID, d2.ID}})
+	disciplinas := novaColecao(d1, d2)
+	regras := escola(t, map[string]*colecaoMemoria{"professores": professores, "disciplinas": disciplinas})
+	ctx := context.Background()
+
+	//540h / 15 semanas = 36h semanais; 630h passariam de 40h
+	_, err := atualizarDisciplina(ctx, Evento{}, d1.ID.Hex(), ioutil.NopCloser(strings.NewReader(`{"cargaHoraria":390}`)), disciplinas, regras, historico{})
+	if got := violacoes(t, err); strings.Join(got, " ") != "cargaHoraria:professor-ate-40h-semanais" {
+		t.Errorf("raising the hours past 40h a week: got %v", got)
+	}
+	if disciplinas.escritas != 0 {
+		t.Error("the subject was written despite the violation")
+	}
+	_, err = atualizarDisciplina(ctx, Evento{}, d1.ID.Hex(), ioutil.NopCloser(strings.NewReader(`{"cargaHoraria":345}`)), disciplinas, regras, historico{})
+	if err != nil {
+		t.Errorf("39h a week should be accepted: %v", err)
+	}
+	//a disciplina de um professor que ninguém leciona não é limitada
+	livre := Disciplinas{ID: primitive.NewObjectID(), Nome: "Estágio", CargaHoraria: 600}
+	disciplinas.InsertOne(ctx, livre)
+	if _, err := atualizarDisciplina(ctx, Evento{}, livre.ID.Hex(), ioutil.NopCloser(strings.NewReader(`{"cargaHoraria":900}`)), disciplinas, regras, historico{}); err != nil {
+		t.Errorf("a subject nobody teaches: %v", err)
+	}
+}
+
+func TestRegraDistintosReavaliaCursoAlterado(t *testing.T) {
+	tecnico := Cursos{ID: primitive.NewObjectID(), Codigo: 1, Nome: "Redes", Nivel: "tecnico"}
+	graduacao := Cursos{ID: primitive.NewObjectID(), Codigo: 2, Nome: "Computação", Nivel: "graduacao"}
+	cursos := novaColecao(tecnico, graduacao)
+	alunos := novaColecao(Alunos{ID: primitive.NewObjectID(), Nome: "Gil", Sobrenome: "Souza", Telefone: "+5511987654321",
+		Curso: 1, Cursos: []int{2}})
+	regras := escola(t, map[string]*colecaoMemoria{"alunos": alunos, "cursos": cursos})
+
+	_, err := atualizarCurso(context.Background(), Evento{}, graduacao.ID.Hex(), ioutil.NopCloser(strings.NewReader(`{"nivel":"tecnico"}`)), cursos, regras, historico{})
+	if got := violacoes(t, err); strings.Join(got, " ") != "nivel:um-curso-por-nivel" {
+		t.Errorf("two courses of the same level for a student: got %v", got)
+	}
+	//nem apontar para um curso que não existe
+	aluno := Alunos{Nome: "Lia", Sobrenome: "Reis", Telefone: "+5511987654321", Curso: 1, Cursos: []int{1, 3}}
+	if got := violacoes(t, regras.verificarLista(context.Background(), "alunos", []Alunos{aluno})); strings.Join(got, " ") != "[0].curso,cursos:curso-existente" {
+		t.Errorf("a reference to a missing course: got %v", got)
+	}
+}
+
+// colecaoLenta demora a devolver as leituras, para que as escritas simultâneas leiam antes de qualquer uma
+// gravar.
+type colecaoLenta struct {
+	*colecaoMemoria
+}
+
+func (c colecaoLenta) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	cursor, err := c.colecaoMemoria.Find(ctx, filter, opts...)
+	time.Sleep(20 * time.Millisecond)
+	return cursor, err
+}
+
+// Cada escrita, sozinha, respeita as 40h; juntas passariam delas. A trava faz a segunda ver a primeira.
+func TestRegraSomaComEscritasSimultaneas(t *testing.T) {
+	for rodada := 0; rodada < 5; rodada++ {
+		d1 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Cálculo", CargaHoraria: 300}
+		d2 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Física", CargaHoraria: 240}
+		professor := Professores{ID: primitive.NewObjectID(), Nome: "Ana", Sobrenome: "Lima", Disciplinas: []primitive.ObjectID{d1.ID}}
+		professores, disciplinas := novaColecao(professor), novaColecao(d1, d2)
+		regras := escola(t, map[string]*colecaoMemoria{"professores": professores, "disciplinas": disciplinas})
+		regras.Cols["professores"], regras.Cols["disciplinas"] = colecaoLenta{professores}, colecaoLenta{disciplinas}
+		ctx := context.Background()
+
+		var (
+			wg    sync.WaitGroup
+			erros = make([]*echo.HTTPError, 2)
+		)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			corpo := `{"disciplinas":["` + d1.ID.Hex() + `","` + d2.ID.Hex() + `"]}`
+			_, erros[0] = alterarProfessor(ctx, Evento{}, professor.ID.Hex(), ioutil.NopCloser(strings.NewReader(corpo)), professores, regras, historico{})
+		}()
+		go func() {
+			defer wg.Done()
+			_, erros[1] = atualizarDisciplina(ctx, Evento{}, d1.ID.Hex(), ioutil.NopCloser(strings.NewReader(`{"cargaHoraria":375}`)), disciplinas, regras, historico{})
+		}()
+		wg.Wait()
+		if (erros[0] == nil) == (erros[1] == nil) {
+			t.Fatalf("round %d: exactly one write should pass, got %v and %v", rodada, erros[0], erros[1])
+		}
+		if n := len(regras.Travas.(*colecaoMemoria).docs); n != 0 {
+			t.Fatalf("round %d: %d locks were not released", rodada, n)
+		}
+	}
+}
+
+func TestTravaVencidaEDescartada(t *testing.T) {
+	regras := escola(t, map[string]*colecaoMemoria{})
+	ctx := context.Background()
+	disciplina := Disciplinas{ID: primitive.NewObjectID(), Nome: "Cálculo", CargaHoraria: 60}
+	//uma instância caiu com a trava; a próxima escrita espera o vencimento em vez de travar para sempre
+	regras.Travas.InsertOne(ctx, map[string]interface{}{"_id": "regra:professores:professor-ate-40h-semanais:disciplinas=" + disciplina.ID.Hex(),
+		"dono": primitive.NewObjectID(), "expiraEm": time.Now().Add(-time.Second)})
+	liberar, err := regras.travar(ctx, "disciplinas", disciplina)
+	if err != nil {
+		t.Fatalf("an expired lock should be taken over: %v", err)
+	}
+	liberar()
+}
+
+// A trava é de cada documento: escritas sobre disciplinas e professores sem relação entre si não esperam.
+func TestTravaPorDocumento(t *testing.T) {
+	d1 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Cálculo", CargaHoraria: 60}
+	d2 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Física", CargaHoraria: 60}
+	d3 := Disciplinas{ID: primitive.NewObjectID(), Nome: "Química", CargaHoraria: 60}
+	ana := Professores{ID: primitive.NewObjectID(), Nome: "Ana", Sobrenome: "Lima", Disciplinas: []primitive.ObjectID{d1.ID, d2.ID}}
+	regras := escola(t, map[string]*colecaoMemoria{"professores": novaColecao(ana), "disciplinas": novaColecao(d1, d2, d3)})
+	ctx := context.Background()
+
+	liberar, err := regras.travar(ctx, "disciplinas", d1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer liberar()
+	livre := func(recurso string, doc interface{}) bool {
+		t.Helper()
+		curto, cancelar := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancelar()
+		outra, err := regras.travar(curto, recurso, doc)
+		if err == nil {
+			outra()
+		}
+		return err == nil
+	}
+	if !livre("disciplinas", d3) {
+		t.Error("a subject nobody shares with the locked one should not wait")
+	}
+	if !livre("professores", Professores{Nome: "Bia", Sobrenome: "Reis", Disciplinas: []primitive.ObjectID{d3.ID}}) {
+		t.Error("a new teacher of another subject should not wait")
+	}
+	//a professora da disciplina travada e as outras disciplinas dela esperam
+	if livre("professores", ana) {
+		t.Error("the teacher of the locked subject should wait")
+	}
+	if livre("disciplinas", d2) {
+		t.Error("another subject of the same teacher should wait")
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Sequencias struct {
+	ID    string `json:"_id" bson:"_id"` //chave do contador, por exemplo "matricula:2026:3"
+	Valor int64  `json:"valor" bson:"valor"`
+}
+
+type GeradorSequencia struct {
+	Col dbiface.Collection
+}
+
+// Proximo incrementa o contador identificado pela chave e retorna o novo valor. O $inc com upsert é atômico no
+// MongoDB, então duas requisições simultâneas nunca recebem o mesmo número.
+func (g *GeradorSequencia) Proximo(ctx context.Context, chave string) (int64, error) {
+	var seq Sequencias
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var err error
+	for tentativa := 0; tentativa < 3; tentativa++ {
+		res := g.Col.FindOneAndUpdate(ctx, bson.M{"_id": chave}, bson.M{"$inc": bson.M{"valor": 1}}, opts)
+		err = res.Decode(&seq)
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		} //dois upserts concorrentes criando o mesmo contador: o perdedor tenta de novo e apenas incrementa
+	}
+	if err != nil {
+		return 0, err
+	}
+	return seq.Valor, nil
+}
+
+var tokenPadrao = regexp.MustCompile(`\{(ano|curso|seq|dv)(?::(\d+))?\}`)
+
+// GeradorNumero monta matrículas e registros a partir de um padrão configurável (ver config.PropriedadesDB).
+// O contador é separado por ano e/ou curso conforme esses campos aparecem no padrão.
+type GeradorNumero struct {
+	Sequencias *GeradorSequencia
+	Prefixo    string //identifica o contador, por exemplo "matricula" ou "registro"
+	Padrao     string
+}
+
+func (g *GeradorNumero) Gerar(ctx context.Context, curso int) (int, error) {
+	ano := time.Now().Year()
+	chave := g.Prefixo
+	if strings.Contains(g.Padrao, "{ano") {
+		chave += fmt.Sprintf(":%d", ano)
+	}
+	if strings.Contains(g.Padrao, "{curso") {
+		chave += fmt.Sprintf(":%d", curso)
+	}
+	seq, err := g.Sequencias.Proximo(ctx, chave)
+	if err != nil {
+		return 0, err
+	}
+	return formatarNumero(g.Padrao, ano, curso, seq)
+}
+
+func formatarNumero(padrao string, ano int, curso int, seq int64) (int, error) {
+	var b strings.Builder
+	resto := padrao
+	for {
+		loc := tokenPadrao.FindStringSubmatchIndex(resto)
+		if loc == nil {
+			b.WriteString(resto)
+			break
+		}
+		b.WriteString(resto[:loc[0]])
+		campo := resto[loc[2]:loc[3]]
+		largura := 0
+		if loc[4] >= 0 {
+			largura, _ = strconv.Atoi(resto[loc[4]:loc[5]])
+		}
+		switch campo {
+		case "ano":
+			valor := strconv.Itoa(ano)
+			if largura > 0 && largura < len(valor) {
+				valor = valor[len(valor)-largura:] //{ano:2} usa apenas os últimos dígitos
+			}
+			b.WriteString(valor)
+		case "curso":
+			valor := fmt.Sprintf("%0*d", largura, curso)
+			if largura > 0 && len(valor) > largura { //um código maior se misturaria aos dígitos seguintes
+				return 0, fmt.Errorf("course %d does not fit in %d digits", curso, largura)
+			}
+			b.WriteString(valor)
+		case "seq":
+			valor := fmt.Sprintf("%0*d", largura, seq)
+			if largura > 0 && len(valor) > largura {
+				return 0, fmt.Errorf("sequence %d does not fit in %d digits", seq, largura)
+			}
+			b.WriteString(valor)
+		case "dv":
+			b.WriteString(strconv.Itoa(digitoVerificador(b.String())))
+		}
+		resto = resto[loc[1]:]
+	}
+	numero, err := strconv.Atoi(b.String())
+	if err != nil {
+		return 0, fmt.Errorf("pattern %q does not produce a valid number: %v", padrao, err)
+	}
+	return numero, nil
+}
+
+// digitoVerificador calcula o dígito módulo 11 com pesos de 2 a 9, da direita para a esquerda
+func digitoVerificador(digitos string) int {
+	soma, peso := 0, 2
+	for i := len(digitos) - 1; i >= 0; i-- {
+		if digitos[i] < '0' || digitos[i] > '9' {
+			continue
+		}
+		soma += int(digitos[i]-'0') * peso
+		peso++
+		if peso > 9 {
+			peso = 2
+		}
+	}
+	dv := 11 - soma%11
+	if dv >= 10 {
+		return 0
+	}
+	return dv
+}
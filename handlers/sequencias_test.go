@@ -0,0 +1,54 @@
+package handlers
+
+import "testing"
+
+func TestFormatarNumero(t *testing.T) {
+	casos := []struct {
+		padrao string
+		curso  int
+		seq    int64
+		numero int
+	}{
+		{"{ano}{curso:3}{seq:4}", 7, 42, 20260070042},
+		{"{ano:2}{seq:5}", 0, 1, 2600001},
+		{"{ano:2}{seq:5}{dv}", 0, 1, 26000016},
+		{"9{curso:2}{seq}", 12, 345, 912345},
+	}
+	for _, caso := range casos {
+		numero, err := formatarNumero(caso.padrao, 2026, caso.curso, caso.seq)
+		if err != nil {
+			t.Errorf("%s: %v", caso.padrao, err)
+			continue
+		}
+		if numero != caso.numero {
+			t.Errorf("%s: got %d, want %d", caso.padrao, numero, caso.numero)
+		}
+	}
+}
+
+func TestFormatarNumeroNaoCabe(t *testing.T) {
+	if _, err := formatarNumero("{ano}{curso:3}{seq:4}", 2026, 1000, 1); err == nil {
+		t.Error("a course code with 4 digits in {curso:3} should fail")
+	}
+	if _, err := formatarNumero("{ano}{seq:4}", 2026, 0, 10000); err == nil {
+		t.Error("a sequence with 5 digits in {seq:4} should fail")
+	}
+	if _, err := formatarNumero("A{seq}", 2026, 0, 1); err == nil {
+		t.Error("a pattern with letters should fail")
+	}
+}
+
+func TestDigitoVerificador(t *testing.T) {
+	casos := map[string]int{
+		"2600001":  6, //soma 2*1 + 6*7 + 2*8 = 60, 11 - 60%11 = 6
+		"0":        0, //11 - 0 = 11, que vira 0
+		"1":        9, //11 - 2 = 9
+		"260-0001": 6, //separadores são ignorados
+		"9":        4, //11 - 18%11 = 4
+	}
+	for digitos, dv := range casos {
+		if got := digitoVerificador(digitos); got != dv {
+			t.Errorf("digitoVerificador(%q) = %d, want %d", digitos, got, dv)
+		}
+	}
+}
@@ -0,0 +1,539 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/mailer"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const OperacaoSenha = "password" //troca ou redefinição de senha, auditada sem os hashes
+
+type Usuarios struct {
+	ID          primitive.ObjectID   `json:"_id,omitempty" bson:"_id,omitempty"`
+	Login       string               `json:"login" bson:"login" validate:"required,max=50" lgpd:"anonimizar,unico"`
+	Email       string               `json:"email" bson:"email" validate:"required,email" sensivel:"secretaria" lgpd:"anonimizar,unico"`
+	Papel       string               `json:"papel" bson:"papel" validate:"required,oneof=secretaria professor aluno responsavel"`
+	AlunoID     *primitive.ObjectID  `json:"alunoId,omitempty" bson:"alunoId,omitempty"`         //papel aluno
+	ProfessorID *primitive.ObjectID  `json:"professorId,omitempty" bson:"professorId,omitempty"` //papel professor
+	Vinculados  []primitive.ObjectID `json:"vinculados,omitempty" bson:"vinculados,omitempty"`   //papel responsavel
+	SenhaHash   string               `json:"-" bson:"senhaHash" lgpd:"anonimizar"`               //o valor anonimizado não é um hash válido: a conta deixa de entrar
+	Tentativas  int                  `json:"-" bson:"tentativas"`                                //falhas de login seguidas
+	//bloqueio temporário depois de tentativas demais
+	BloqueadoAte *time.Time `json:"bloqueadoAte,omitempty" bson:"bloqueadoAte,omitempty"`
+	ResetHash    string     `json:"-" bson:"resetHash,omitempty"` //sha256 do token de redefinição
+	ResetExpira  *time.Time `json:"-" bson:"resetExpira,omitempty"`
+	//conta do provedor OIDC vinculada no primeiro login com o e-mail verificado
+	OIDCEmissor string     `json:"-" bson:"oidcEmissor,omitempty"`
+	OIDCSub     string     `json:"-" bson:"oidcSub,omitempty" lgpd:"anonimizar,unico"`
+	DeletadoEm  *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+// semSegredos remove os hashes antes de gravar o usuário na auditoria e no histórico de versões.
+func (u Usuarios) semSegredos() Usuarios {
+	u.SenhaHash = ""
+	u.ResetHash = ""
+	u.ResetExpira = nil
+	return u
+}
+
+// PoliticaSenha define os requisitos mínimos das senhas.
+type PoliticaSenha struct {
+	TamanhoMinimo int
+}
+
+func (p PoliticaSenha) Validar(login, senha string) error {
+	if len([]rune(senha)) < p.TamanhoMinimo {
+		return fmt.Errorf("the password must have at least %d characters", p.TamanhoMinimo)
+	}
+	if len(senha) > 72 { //limite do bcrypt
+		return fmt.Errorf("the password must have at most 72 bytes")
+	}
+	var maiuscula, minuscula, digito bool
+	for _, r := range senha {
+		switch {
+		case unicode.IsUpper(r):
+			maiuscula = true
+		case unicode.IsLower(r):
+			minuscula = true
+		case unicode.IsDigit(r):
+			digito = true
+		}
+	}
+	if !maiuscula || !minuscula || !digito {
+		return fmt.Errorf("the password must mix upper case letters, lower case letters and digits")
+	}
+	if senha == login {
+		return fmt.Errorf("the password must be different from the login")
+	}
+	return nil
+}
+
+type UsuariosHandler struct {
+	Col            dbiface.Collection
+	Auditoria      *Auditor
+	Versoes        *Versionador
+	Mailer         mailer.Mailer
+	Revogacoes     *auth.Revogacoes //a troca de senha revoga os tokens já emitidos
+	PoliticaSenha  PoliticaSenha
+	MaxTentativas  int           //falhas seguidas antes do bloqueio
+	Bloqueio       time.Duration //duração do bloqueio
+	ValidadeReset  time.Duration //validade do token de redefinição
+	URLRedefinicao string        //página que recebe o token, enviada por e-mail
+}
+
+// senhaFalsa é comparada quando o login não existe, para que a resposta leve o mesmo tempo.
+var senhaFalsa, _ = bcrypt.GenerateFromPassword([]byte("senha-inexistente"), bcrypt.DefaultCost)
+
+// Verificar implementa auth.Credenciais com bloqueio após MaxTentativas falhas seguidas. A senha é conferida
+// antes do bloqueio: quem não a conhece recebe a mesma resposta de um login inexistente.
+func (uh *UsuariosHandler) Verificar(ctx context.Context, login, senha string) (auth.Identidade, error) {
+	var usuario Usuarios
+	if err := uh.Col.FindOne(ctx, filtroAtivos(bson.M{"login": login})).Decode(&usuario); err != nil {
+		bcrypt.CompareHashAndPassword(senhaFalsa, []byte(senha))
+		return auth.Identidade{}, auth.ErrCredenciaisInvalidas
+	}
+	bloqueada := usuario.BloqueadoAte != nil && usuario.BloqueadoAte.After(time.Now())
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.SenhaHash), []byte(senha)); err != nil {
+		if !bloqueada {
+			uh.registrarFalha(ctx, usuario.ID)
+		}
+		return auth.Identidade{}, auth.ErrCredenciaisInvalidas
+	}
+	if bloqueada {
+		return auth.Identidade{}, auth.ErrContaBloqueada
+	}
+	if usuario.Tentativas > 0 || usuario.BloqueadoAte != nil {
+		update := bson.M{"$set": bson.M{"tentativas": 0}, "$unset": bson.M{"bloqueadoAte": ""}}
+		if _, err := uh.Col.UpdateOne(ctx, bson.M{"_id": usuario.ID}, update); err != nil {
+			log.Errorf("Unable to reset the failed logins: %v", err)
+		}
+	}
+	return identidadeDoUsuario(usuario), nil
+}
+
+// Buscar implementa auth.Credenciais para a renovação dos tokens. A anonimização troca o login, então uma
+// conta anonimizada também deixa de ser encontrada.
+func (uh *UsuariosHandler) Buscar(ctx context.Context, login string) (auth.Identidade, error) {
+	var usuario Usuarios
+	err := uh.Col.FindOne(ctx, filtroAtivos(bson.M{"login": login})).Decode(&usuario)
+	if err == mongo.ErrNoDocuments {
+		return auth.Identidade{}, auth.ErrContaInexistente
+	}
+	if err != nil {
+		return auth.Identidade{}, err
+	}
+	return identidadeDoUsuario(usuario), nil
+}
+
+// registrarFalha conta a falha de login com $inc e bloqueia a conta pelo total devolvido, para que falhas
+// simultâneas não se percam.
+func (uh *UsuariosHandler) registrarFalha(ctx context.Context, id primitive.ObjectID) {
+	var usuario Usuarios
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := uh.Col.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"tentativas": 1}}, opts).Decode(&usuario)
+	if err != nil {
+		log.Errorf("Unable to record the failed login: %v", err)
+		return
+	}
+	if usuario.Tentativas < uh.MaxTentativas {
+		return
+	}
+	update := bson.M{"$set": bson.M{"tentativas": 0, "bloqueadoAte": time.Now().Add(uh.Bloqueio)}}
+	if _, err := uh.Col.UpdateOne(ctx, bson.M{"_id": id, "tentativas": bson.M{"$gte": uh.MaxTentativas}}, update); err != nil {
+		log.Errorf("Unable to lock the account: %v", err)
+	}
+}
+
+// BuscarIdentidade implementa auth.Vinculador: um login pelo provedor OIDC herda o papel e os vínculos da
+// conta local vinculada ao sub. No primeiro login a conta é vinculada pelo e-mail, apenas se o provedor o
+// verificou; o login do provedor nunca é usado, pois qualquer um pode escolher um igual ao de outra conta.
+func (uh *UsuariosHandler) BuscarIdentidade(ctx context.Context, conta auth.ContaExterna) (auth.Identidade, bool) {
+	var usuario Usuarios
+	err := uh.Col.FindOne(ctx, filtroAtivos(bson.M{"oidcEmissor": conta.Emissor, "oidcSub": conta.Sub})).Decode(&usuario)
+	if err == nil {
+		return identidadeDoUsuario(usuario), true
+	}
+	if !conta.EmailVerificado || conta.Email == "" {
+		return auth.Identidade{}, false
+	}
+	filter := filtroAtivos(bson.M{"email": conta.Email, "oidcSub": bson.M{"$exists": false}})
+	update := bson.M{"$set": bson.M{"oidcEmissor": conta.Emissor, "oidcSub": conta.Sub}}
+	if err := uh.Col.FindOneAndUpdate(ctx, filter, update).Decode(&usuario); err != nil {
+		return auth.Identidade{}, false
+	}
+	depois := usuario
+	depois.OIDCEmissor, depois.OIDCSub = conta.Emissor, conta.Sub
+	ev := Evento{Ator: usuario.Login}
+	uh.Auditoria.Registrar(ctx, ev, "usuarios", OperacaoAlteracao, usuario.ID, usuario.semSegredos(), depois.semSegredos())
+	uh.Versoes.Registrar(ctx, ev, "usuarios", OperacaoAlteracao, usuario.ID, depois.semSegredos())
+	return identidadeDoUsuario(depois), true
+}
+
+func identidadeDoUsuario(usuario Usuarios) auth.Identidade {
+	identidade := auth.Identidade{Usuario: usuario.Login, Papel: usuario.Papel}
+	if usuario.AlunoID != nil {
+		identidade.AlunoID = usuario.AlunoID.Hex()
+	}
+	if usuario.ProfessorID != nil {
+		identidade.ProfessorID = usuario.ProfessorID.Hex()
+	}
+	for _, id := range usuario.Vinculados {
+		identidade.Vinculados = append(identidade.Vinculados, id.Hex())
+	}
+	return identidade
+}
+
+func hashSenha(senha string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(senha), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// NovoUsuario é o corpo de POST /usuarios: o usuário e a senha inicial.
+type NovoUsuario struct {
+	Usuarios
+	Senha string `json:"senha"`
+}
+
+func inserirUsuario(ctx context.Context, req NovoUsuario, politica PoliticaSenha, collection dbiface.Collection) (Usuarios, *echo.HTTPError) {
+	usuario := req.Usuarios
+	if err := v.Struct(usuario); err != nil {
+		log.Errorf("Unable to validate the struct: %v", err)
+		return usuario, problema.Validacao(err).HTTP()
+	}
+	if err := politica.Validar(usuario.Login, req.Senha); err != nil {
+		return usuario, problema.Novo(http.StatusBadRequest, problema.CodigoSenhaFraca, err.Error()).HTTP()
+	}
+	if err := collection.FindOne(ctx, filtroAtivos(bson.M{"login": usuario.Login})).Err(); err != mongo.ErrNoDocuments {
+		return usuario, problema.Novo(http.StatusConflict, problema.CodigoLoginEmUso, "The login is already in use").HTTP()
+	}
+	if err := collection.FindOne(ctx, filtroAtivos(bson.M{"email": usuario.Email})).Err(); err != mongo.ErrNoDocuments {
+		return usuario, problema.Novo(http.StatusConflict, problema.CodigoEmailEmUso, "The e-mail is already in use").HTTP()
+	}
+	hash, err := hashSenha(req.Senha)
+	if err != nil {
+		log.Errorf("Unable to hash the password: %v", err)
+		return usuario, echo.NewHTTPError(http.StatusInternalServerError, "Unable to create the user")
+	}
+	usuario.ID = primitive.NewObjectID()
+	usuario.SenhaHash = hash
+	usuario.Tentativas, usuario.BloqueadoAte, usuario.ResetHash, usuario.ResetExpira, usuario.DeletadoEm = 0, nil, "", nil, nil
+	if _, err := collection.InsertOne(ctx, usuario); mongo.IsDuplicateKeyError(err) {
+		//outro cadastro com o mesmo login ou e-mail passou pela consulta acima ao mesmo tempo; os índices únicos barram
+		return usuario, problema.Novo(http.StatusConflict, problema.CodigoValorDuplicado, "The login or e-mail is already in use").HTTP()
+	} else if err != nil {
+		log.Errorf("Unable to insert: %v", err)
+		return usuario, echo.NewHTTPError(http.StatusInternalServerError, "Unable to connect to database")
+	}
+	return usuario, nil
+}
+
+func (uh *UsuariosHandler) InserirUsuario(c echo.Context) error {
+	var req NovoUsuario
+	if err := c.Bind(&req); err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	usuario, err := inserirUsuario(context.Background(), req, uh.PoliticaSenha, uh.Col)
+	if err != nil {
+		return err
+	}
+	ev := eventoDaRequisicao(c)
+	uh.Auditoria.Registrar(context.Background(), ev, "usuarios", OperacaoInsercao, usuario.ID, nil, usuario.semSegredos())
+	uh.Versoes.Registrar(context.Background(), ev, "usuarios", OperacaoInsercao, usuario.ID, usuario.semSegredos())
+	return c.JSON(http.StatusCreated, usuario.ID)
+}
+
+func filtroUsuarios(q url.Values) bson.M {
+	filter := bson.M{}
+	for _, campo := range []string{"login", "email", "papel"} { //apenas campos sem segredos podem ser filtrados
+		if valor := q.Get(campo); valor != "" {
+			filter[campo] = valor
+		}
+	}
+	return filtroAtivos(filter)
+}
+
+func buscarUsuarios(ctx context.Context, q url.Values, collection dbiface.Collection) ([]Usuarios, *echo.HTTPError) {
+	var usuarios []Usuarios
+	cursor, err := collection.Find(ctx, filtroUsuarios(q))
+	if err != nil {
+		log.Errorf("Unable to find the user: %v", err)
+		return usuarios, echo.NewHTTPError(http.StatusNotFound, "Unable to find the user")
+	}
+	if err := cursor.All(ctx, &usuarios); err != nil {
+		log.Errorf("Unable to read the cursor: %v", err)
+		return usuarios, problema.CorpoInvalido().HTTP()
+	}
+	return usuarios, nil
+}
+
+func (uh *UsuariosHandler) BuscarUsuarios(c echo.Context) error {
+	if formato, herr := formatoExportacao(c); herr != nil {
+		return herr
+	} else if formato != "" {
+		claims := claimsDaRequisicao(c)
+		return exportarListagem(c, formato, exportacao{nome: "usuarios", col: uh.Col, filter: filtroUsuarios(c.QueryParams()),
+			modelo: Usuarios{}, tratar: func(doc interface{}) interface{} { return Mascarar(doc, claims) }})
+	}
+	usuarios, err := buscarUsuarios(context.Background(), c.QueryParams(), uh.Col)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, mascarar(c, usuarios))
+}
+
+func buscarUsuario(ctx context.Context, id string, collection dbiface.Collection) (Usuarios, *echo.HTTPError) {
+	var usuario Usuarios
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return usuario, problema.IDInvalido().HTTP()
+	}
+	if err := collection.FindOne(ctx, filtroAtivos(bson.M{"_id": docID})).Decode(&usuario); err != nil {
+		return usuario, echo.NewHTTPError(http.StatusNotFound, "Unable to find the user")
+	}
+	return usuario, nil
+}
+
+func (uh *UsuariosHandler) BuscarUsuario(c echo.Context) error {
+	usuario, err := buscarUsuario(context.Background(), c.Param("id"), uh.Col)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, mascarar(c, usuario))
+}
+
+// AtualizarUsuario altera e-mail, papel e vínculos. A senha só muda pelas rotas próprias.
+func (uh *UsuariosHandler) AtualizarUsuario(c echo.Context) error {
+	ctx := context.Background()
+	antes, err := buscarUsuario(ctx, c.Param("id"), uh.Col)
+	if err != nil {
+		return err
+	}
+	depois := antes
+	if err := c.Bind(&depois); err != nil {
+		log.Errorf("Unable to bind: %v", err)
+		return problema.CorpoInvalido().HTTP()
+	}
+	depois.ID, depois.Login = antes.ID, antes.Login
+	if err := v.Struct(depois); err != nil {
+		log.Errorf("Unable to validate the struct: %v", err)
+		return problema.Validacao(err).HTTP()
+	}
+	update := bson.M{"$set": bson.M{
+		"email":       depois.Email,
+		"papel":       depois.Papel,
+		"alunoId":     depois.AlunoID,
+		"professorId": depois.ProfessorID,
+		"vinculados":  depois.Vinculados,
+	}}
+	if _, err := uh.Col.UpdateOne(ctx, bson.M{"_id": antes.ID}, update); mongo.IsDuplicateKeyError(err) {
+		return problema.Novo(http.StatusConflict, problema.CodigoEmailEmUso, "The e-mail is already in use").HTTP()
+	} else if err != nil {
+		log.Errorf("Unable to update the user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the user")
+	}
+	ev := eventoDaRequisicao(c)
+	uh.Auditoria.Registrar(ctx, ev, "usuarios", OperacaoAlteracao, antes.ID, antes.semSegredos(), depois.semSegredos())
+	uh.Versoes.Registrar(ctx, ev, "usuarios", OperacaoAlteracao, antes.ID, depois.semSegredos())
+	//os tokens já emitidos levam o papel e os vínculos antigos
+	if mudouAcesso(antes, depois) {
+		if err := uh.Revogacoes.RevogarUsuario(ctx, antes.Login); err != nil {
+			log.Errorf("Unable to revoke the tokens of %q: %v", antes.Login, err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to update the user")
+		}
+	}
+	return c.JSON(http.StatusOK, mascarar(c, depois))
+}
+
+// mudouAcesso diz se a alteração mexeu no que os tokens carregam: o papel e os vínculos.
+func mudouAcesso(antes, depois Usuarios) bool {
+	identidade := identidadeDoUsuario(antes)
+	nova := identidadeDoUsuario(depois)
+	return identidade.Papel != nova.Papel || identidade.AlunoID != nova.AlunoID || identidade.ProfessorID != nova.ProfessorID ||
+		strings.Join(identidade.Vinculados, ",") != strings.Join(nova.Vinculados, ",")
+}
+
+func (uh *UsuariosHandler) DeletarUsuario(c echo.Context) error {
+	ctx := context.Background()
+	antes, err := buscarUsuario(ctx, c.Param("id"), uh.Col)
+	if err != nil {
+		return err
+	}
+	del, err := moverParaLixeira(ctx, c.Param("id"), uh.Col)
+	if err != nil {
+		return err
+	}
+	ev := eventoDaRequisicao(c)
+	uh.Auditoria.Registrar(ctx, ev, "usuarios", OperacaoExclusao, antes.ID, antes.semSegredos(), nil)
+	uh.Versoes.Registrar(ctx, ev, "usuarios", OperacaoExclusao, antes.ID, antes.semSegredos())
+	//a conta na lixeira não renova os tokens, mas os access tokens valeriam até expirar
+	if err := uh.Revogacoes.RevogarUsuario(ctx, antes.Login); err != nil {
+		log.Errorf("Unable to revoke the tokens of %q: %v", antes.Login, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to delete the document")
+	}
+	return c.JSON(http.StatusOK, del)
+}
+
+// definirSenha grava a nova senha na conta que atende ao filtro. Na redefinição o filtro inclui o token, que é
+// consumido na mesma operação: de dois pedidos com o mesmo token, só um troca a senha.
+func (uh *UsuariosHandler) definirSenha(ctx context.Context, c echo.Context, usuario Usuarios, filtro bson.M, senha string) *echo.HTTPError {
+	if err := uh.PoliticaSenha.Validar(usuario.Login, senha); err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoSenhaFraca, err.Error()).HTTP()
+	}
+	hash, err := hashSenha(senha)
+	if err != nil {
+		log.Errorf("Unable to hash the password: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to change the password")
+	}
+	update := bson.M{
+		"$set":   bson.M{"senhaHash": hash, "tentativas": 0},
+		"$unset": bson.M{"bloqueadoAte": "", "resetHash": "", "resetExpira": ""},
+	}
+	if err := uh.Col.FindOneAndUpdate(ctx, filtro, update).Err(); err == mongo.ErrNoDocuments {
+		return resetInvalido()
+	} else if err != nil {
+		log.Errorf("Unable to update the password: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to change the password")
+	}
+	//sessões abertas com a senha antiga, inclusive por quem a descobriu, não sobrevivem à troca
+	if err := uh.Revogacoes.RevogarUsuario(ctx, usuario.Login); err != nil {
+		log.Errorf("Unable to revoke the tokens of %q: %v", usuario.Login, err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to change the password")
+	}
+	uh.Auditoria.Registrar(ctx, eventoDaRequisicao(c), "usuarios", OperacaoSenha, usuario.ID, nil, nil)
+	return nil
+}
+
+// TrocaSenha é o corpo de POST /usuarios/eu/senha.
+type TrocaSenha struct {
+	SenhaAtual string `json:"senhaAtual"`
+	NovaSenha  string `json:"novaSenha"`
+}
+
+// TrocarSenha atende POST /usuarios/eu/senha para o usuário do token.
+func (uh *UsuariosHandler) TrocarSenha(c echo.Context) error {
+	ctx := context.Background()
+	var req TrocaSenha
+	if err := c.Bind(&req); err != nil {
+		return problema.CorpoInvalido().HTTP()
+	}
+	login, _ := c.Get(auth.ChaveAtor).(string)
+	var usuario Usuarios
+	if err := uh.Col.FindOne(ctx, filtroAtivos(bson.M{"login": login})).Decode(&usuario); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Unable to find the user")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.SenhaHash), []byte(req.SenhaAtual)); err != nil {
+		return problema.Novo(http.StatusForbidden, problema.CodigoSenhaAtual, "The current password is wrong").HTTP()
+	}
+	if err := uh.definirSenha(ctx, c, usuario, bson.M{"_id": usuario.ID}, req.NovaSenha); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func hashToken(token string) string {
+	soma := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(soma[:])
+}
+
+// PedidoRedefinicao é o corpo de POST /usuarios/senha/esqueci.
+type PedidoRedefinicao struct {
+	Login string `json:"login"`
+}
+
+// EsqueciSenha atende POST /usuarios/senha/esqueci. A resposta é sempre 202, exista o login ou não: a busca
+// da conta e o envio do e-mail ficam para depois da resposta, para que nem o tempo nem uma falha do envio
+// revelem quais logins estão cadastrados.
+func (uh *UsuariosHandler) EsqueciSenha(c echo.Context) error {
+	var req PedidoRedefinicao
+	if err := c.Bind(&req); err != nil {
+		return problema.CorpoInvalido().HTTP()
+	}
+	go uh.enviarRedefinicao(req.Login)
+	return c.NoContent(http.StatusAccepted)
+}
+
+// enviarRedefinicao grava o token de redefinição da conta e o envia por e-mail. Como roda depois da resposta,
+// as falhas só vão para o log.
+func (uh *UsuariosHandler) enviarRedefinicao(login string) {
+	ctx := context.Background()
+	var usuario Usuarios
+	if err := uh.Col.FindOne(ctx, filtroAtivos(bson.M{"login": login})).Decode(&usuario); err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Errorf("Unable to find the account for the password reset: %v", err)
+		}
+		return
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Errorf("Unable to generate the reset token: %v", err)
+		return
+	}
+	token := hex.EncodeToString(b)
+	expira := time.Now().Add(uh.ValidadeReset)
+	update := bson.M{"$set": bson.M{"resetHash": hashToken(token), "resetExpira": expira}} //só o hash fica no banco
+	if _, err := uh.Col.UpdateOne(ctx, bson.M{"_id": usuario.ID}, update); err != nil {
+		log.Errorf("Unable to store the reset token: %v", err)
+		return
+	}
+
+	msg := mailer.Mensagem{
+		Para:    usuario.Email,
+		Assunto: "Redefinição de senha",
+		Corpo: fmt.Sprintf("Olá, %s.\r\n\r\nPara redefinir sua senha, acesse %s?token=%s\r\n"+
+			"O link vale até %s. Se você não pediu a redefinição, ignore esta mensagem.\r\n",
+			usuario.Login, uh.URLRedefinicao, token, expira.Format("02/01/2006 15:04")),
+	}
+	if err := uh.Mailer.Enviar(ctx, msg); err != nil {
+		log.Errorf("Unable to send the reset e-mail: %v", err)
+	}
+}
+
+// RedefinicaoSenha é o corpo de POST /usuarios/senha/redefinir.
+type RedefinicaoSenha struct {
+	Token     string `json:"token"`
+	NovaSenha string `json:"novaSenha"`
+}
+
+func resetInvalido() *echo.HTTPError {
+	return problema.Novo(http.StatusBadRequest, problema.CodigoResetInvalido, "Invalid or expired reset token").HTTP()
+}
+
+// RedefinirSenha atende POST /usuarios/senha/redefinir com o token recebido por e-mail.
+func (uh *UsuariosHandler) RedefinirSenha(c echo.Context) error {
+	ctx := context.Background()
+	var req RedefinicaoSenha
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return problema.CorpoInvalido().HTTP()
+	}
+	var usuario Usuarios
+	filter := filtroAtivos(bson.M{"resetHash": hashToken(req.Token), "resetExpira": bson.M{"$gt": time.Now()}})
+	if err := uh.Col.FindOne(ctx, filter).Decode(&usuario); err != nil {
+		return resetInvalido()
+	}
+	filter["_id"] = usuario.ID
+	if err := uh.definirSenha(ctx, c, usuario, filter, req.NovaSenha); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
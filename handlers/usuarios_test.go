@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/mailer"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func novoUsuario(t *testing.T, login, senha string) Usuarios {
+	hash, err := bcrypt.GenerateFromPassword([]byte(senha), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Usuarios{ID: primitive.NewObjectID(), Login: login, Email: login + "@escola.br", Papel: "secretaria", SenhaHash: string(hash)}
+}
+
+func TestVerificarBloqueiaAposFalhasSimultaneas(t *testing.T) {
+	usuario := novoUsuario(t, "ana", "Senha123")
+	uh := &UsuariosHandler{Col: novaColecao(usuario), MaxTentativas: 3, Bloqueio: time.Hour}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uh.Verificar(ctx, "ana", "errada")
+		}()
+	}
+	wg.Wait()
+	if doc := uh.Col.(*colecaoMemoria).buscarID(usuario.ID); doc["bloqueadoAte"] == nil {
+		t.Fatalf("three simultaneous failures should lock the account, got %v", doc)
+	}
+	if _, err := uh.Verificar(ctx, "ana", "Senha123"); err != auth.ErrContaBloqueada {
+		t.Errorf("the right password on a locked account: got %v, want ErrContaBloqueada", err)
+	}
+}
+
+func TestVerificarNaoRevelaBloqueio(t *testing.T) {
+	usuario := novoUsuario(t, "ana", "Senha123")
+	ate := time.Now().Add(time.Hour)
+	usuario.BloqueadoAte = &ate
+	col := novaColecao(usuario)
+	uh := &UsuariosHandler{Col: col, MaxTentativas: 3, Bloqueio: time.Hour}
+	ctx := context.Background()
+
+	//sem a senha, a conta bloqueada responde como um login inexistente
+	_, bloqueada := uh.Verificar(ctx, "ana", "errada")
+	_, inexistente := uh.Verificar(ctx, "ninguem", "errada")
+	if bloqueada != auth.ErrCredenciaisInvalidas || inexistente != auth.ErrCredenciaisInvalidas {
+		t.Errorf("a wrong password on a locked account got %v, an unknown login got %v", bloqueada, inexistente)
+	}
+	if col.escritas != 0 {
+		t.Error("failures during the lock should not be counted")
+	}
+
+	//vencido o bloqueio, a senha certa entra e zera as falhas
+	antes := time.Now().Add(-time.Minute)
+	usuario.BloqueadoAte, usuario.Tentativas = &antes, 2
+	uh.Col = novaColecao(usuario)
+	if _, err := uh.Verificar(ctx, "ana", "Senha123"); err != nil {
+		t.Fatalf("after the lock: %v", err)
+	}
+	if doc := uh.Col.(*colecaoMemoria).buscarID(usuario.ID); doc["bloqueadoAte"] != nil || !iguais(doc["tentativas"], int32(0)) {
+		t.Errorf("a successful login should clear the failures, got %v", doc)
+	}
+}
+
+func TestBuscarContaNaRenovacao(t *testing.T) {
+	ana := novoUsuario(t, "ana", "Senha123")
+	aluno := primitive.NewObjectID()
+	ana.Papel, ana.AlunoID = "aluno", &aluno
+	col := novaColecao(ana)
+	uh := &UsuariosHandler{Col: col}
+	ctx := context.Background()
+	identidade, err := uh.Buscar(ctx, "ana")
+	if err != nil || identidade.Papel != "aluno" || identidade.AlunoID != aluno.Hex() {
+		t.Errorf("got %+v, %v", identidade, err)
+	}
+	col.docs[0]["deletedAt"] = primitive.NewDateTimeFromTime(time.Now())
+	if _, err := uh.Buscar(ctx, "ana"); err != auth.ErrContaInexistente {
+		t.Errorf("an account in the trash: got %v, want ErrContaInexistente", err)
+	}
+}
+
+func TestInserirUsuarioRecusaEmailRepetido(t *testing.T) {
+	col := novaColecao(novoUsuario(t, "ana", "Senha123"))
+	req := NovoUsuario{Usuarios: Usuarios{Login: "outra", Email: "ana@escola.br", Papel: "secretaria"}, Senha: "Senha456"}
+	_, err := inserirUsuario(context.Background(), req, PoliticaSenha{TamanhoMinimo: 8}, col)
+	if err == nil || err.Code != http.StatusConflict || problema.Converter(err).Codigo != problema.CodigoEmailEmUso {
+		t.Errorf("got %v, want 409 %s", err, problema.CodigoEmailEmUso)
+	}
+	//a política de senha tem um código próprio, distinto das demais falhas de validação
+	req = NovoUsuario{Usuarios: Usuarios{Login: "outra", Email: "outra@escola.br", Papel: "secretaria"}, Senha: "curta"}
+	_, err = inserirUsuario(context.Background(), req, PoliticaSenha{TamanhoMinimo: 8}, col)
+	if err == nil || problema.Converter(err).Codigo != problema.CodigoSenhaFraca {
+		t.Errorf("got %v, want %s", err, problema.CodigoSenhaFraca)
+	}
+}
+
+// mailerTeste entrega as mensagens num canal e falha se erro estiver preenchido.
+type mailerTeste struct {
+	enviadas chan mailer.Mensagem
+	erro     error
+}
+
+func (m *mailerTeste) Enviar(ctx context.Context, msg mailer.Mensagem) error {
+	m.enviadas <- msg
+	return m.erro
+}
+
+func TestEsqueciSenhaRespondeIgualParaQualquerLogin(t *testing.T) {
+	ana := novoUsuario(t, "ana", "Senha123")
+	e := echo.New()
+	for _, caso := range []struct {
+		login string
+		erro  error
+	}{{"ana", nil}, {"ana", errors.New("smtp down")}, {"inexistente", nil}} {
+		m := &mailerTeste{enviadas: make(chan mailer.Mensagem, 1), erro: caso.erro}
+		uh := &UsuariosHandler{Col: novaColecao(ana), Mailer: m, ValidadeReset: time.Hour}
+		req := httptest.NewRequest(http.MethodPost, "/usuarios/senha/esqueci", strings.NewReader(`{"login":"`+caso.login+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := uh.EsqueciSenha(e.NewContext(req, rec)); err != nil || rec.Code != http.StatusAccepted {
+			t.Fatalf("%s (%v): got %d %v, want 202", caso.login, caso.erro, rec.Code, err)
+		}
+		select {
+		case msg := <-m.enviadas:
+			if caso.login != "ana" || msg.Para != ana.Email {
+				t.Errorf("%s: unexpected e-mail to %s", caso.login, msg.Para)
+			}
+		case <-time.After(time.Second):
+			if caso.login == "ana" {
+				t.Errorf("the reset e-mail should be sent after the response")
+			}
+		}
+	}
+}
+
+func TestRedefinirSenhaRevogaTokens(t *testing.T) {
+	usuario := novoUsuario(t, "ana", "Senha123")
+	usuario.ResetHash = hashToken("token-do-email")
+	expira := time.Now().Add(time.Hour)
+	usuario.ResetExpira = &expira
+	revogacoes := &auth.Revogacoes{Col: novaColecao(), Validade: time.Hour}
+	uh := &UsuariosHandler{Col: novaColecao(usuario), Revogacoes: revogacoes, PoliticaSenha: PoliticaSenha{TamanhoMinimo: 8}}
+	s := &auth.Servico{Chaves: map[string][]byte{"k": []byte("segredo")}, ChaveAtiva: "k", Emissor: "escola", DuracaoAcesso: time.Minute,
+		DuracaoRenovacao: time.Hour, Revogacoes: revogacoes}
+	ctx := context.Background()
+	tokens, err := s.Emitir(identidadeDoUsuario(usuario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Validar(ctx, tokens.RefreshToken, auth.TokenRenovacao); err != nil {
+		t.Fatalf("a fresh refresh token should be valid: %v", err)
+	}
+
+	e := echo.New()
+	corpo := `{"token":"token-do-email","novaSenha":"NovaSenha9"}`
+	req := httptest.NewRequest(http.MethodPost, "/usuarios/senha/redefinir", strings.NewReader(corpo))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := uh.RedefinirSenha(e.NewContext(req, httptest.NewRecorder())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Validar(ctx, tokens.RefreshToken, auth.TokenRenovacao); err == nil {
+		t.Error("the refresh token issued before the reset should be revoked")
+	}
+	if _, err := s.Validar(ctx, tokens.AccessToken, auth.TokenAcesso); err == nil {
+		t.Error("the access token issued before the reset should be revoked")
+	}
+}
+
+func TestRedefinirSenhaConsomeOToken(t *testing.T) {
+	usuario := novoUsuario(t, "ana", "Senha123")
+	usuario.ResetHash = hashToken("token-do-email")
+	expira := time.Now().Add(time.Hour)
+	usuario.ResetExpira = &expira
+	uh := &UsuariosHandler{Col: novaColecao(usuario), Revogacoes: &auth.Revogacoes{Col: novaColecao(), Validade: time.Hour},
+		PoliticaSenha: PoliticaSenha{TamanhoMinimo: 8}}
+	e := echo.New()
+
+	//o mesmo token usado em paralelo troca a senha uma vez só
+	var wg sync.WaitGroup
+	erros := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			corpo := `{"token":"token-do-email","novaSenha":"NovaSenha` + strconv.Itoa(i) + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/usuarios/senha/redefinir", strings.NewReader(corpo))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			erros <- uh.RedefinirSenha(e.NewContext(req, httptest.NewRecorder()))
+		}(i)
+	}
+	wg.Wait()
+	close(erros)
+	trocas := 0
+	for err := range erros {
+		if err == nil {
+			trocas++
+		} else if problema.Converter(err).Codigo != problema.CodigoResetInvalido {
+			t.Errorf("got %v, want %s", err, problema.CodigoResetInvalido)
+		}
+	}
+	if trocas != 1 {
+		t.Errorf("the token changed the password %d times, want 1", trocas)
+	}
+	if doc := uh.Col.(*colecaoMemoria).buscarID(usuario.ID); doc["resetHash"] != nil || doc["resetExpira"] != nil {
+		t.Errorf("the token should be removed, got %v", doc)
+	}
+}
+
+func TestAlterarUsuarioRevogaTokens(t *testing.T) {
+	ana := novoUsuario(t, "ana", "Senha123")
+	revogacoes := &auth.Revogacoes{Col: novaColecao(), Validade: time.Hour}
+	uh := &UsuariosHandler{Col: novaColecao(ana), Revogacoes: revogacoes, Auditoria: &Auditor{Col: novaColecao()}, Versoes: novoVersionador()}
+	s := &auth.Servico{Chaves: map[string][]byte{"k": []byte("segredo")}, ChaveAtiva: "k", Emissor: "escola", DuracaoAcesso: time.Minute,
+		DuracaoRenovacao: time.Hour, Revogacoes: revogacoes}
+	ctx := context.Background()
+	e := echo.New()
+	pedir := func(metodo, corpo string, handler echo.HandlerFunc) {
+		t.Helper()
+		req := httptest.NewRequest(metodo, "/usuarios/"+ana.ID.Hex(), strings.NewReader(corpo))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(ana.ID.Hex())
+		if err := handler(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	valido := func(tokens auth.Tokens) bool {
+		_, err := s.Validar(ctx, tokens.RefreshToken, auth.TokenRenovacao)
+		return err == nil
+	}
+
+	tokens, _ := s.Emitir(identidadeDoUsuario(ana))
+	pedir(http.MethodPut, `{"email":"ana.silva@escola.br","papel":"secretaria"}`, uh.AtualizarUsuario)
+	if !valido(tokens) {
+		t.Error("changing only the e-mail should keep the tokens")
+	}
+	pedir(http.MethodPut, `{"email":"ana.silva@escola.br","papel":"professor"}`, uh.AtualizarUsuario)
+	if valido(tokens) {
+		t.Error("the tokens issued with the old role should be revoked")
+	}
+
+	time.Sleep(2 * time.Millisecond) //o iat tem precisão de milissegundos
+	tokens, _ = s.Emitir(auth.Identidade{Usuario: "ana", Papel: "professor"})
+	pedir(http.MethodDelete, "", uh.DeletarUsuario)
+	if valido(tokens) {
+		t.Error("the tokens of a deleted account should be revoked")
+	}
+}
+
+func TestBuscarIdentidadeVinculaPorEmailVerificado(t *testing.T) {
+	ana := novoUsuario(t, "ana", "Senha123")
+	col := novaColecao(ana)
+	auditoria := novaColecao()
+	uh := &UsuariosHandler{Col: col, Auditoria: &Auditor{Col: auditoria}}
+	ctx := context.Background()
+	conta := auth.ContaExterna{Emissor: "https://idp", Sub: "s-1", Email: "ana@escola.br"}
+
+	//um e-mail não verificado pelo provedor não vincula
+	if _, ok := uh.BuscarIdentidade(ctx, conta); ok || col.escritas != 0 {
+		t.Fatal("an unverified e-mail should not link the account")
+	}
+	conta.EmailVerificado = true
+	identidade, ok := uh.BuscarIdentidade(ctx, conta)
+	if !ok || identidade.Usuario != "ana" {
+		t.Fatalf("a verified e-mail should link the account, got %+v", identidade)
+	}
+	conferirAuditoria(t, "link", auditoria, ana.ID, OperacaoAlteracao, Evento{Ator: "ana"})
+
+	//depois do vínculo vale o sub, mesmo que o e-mail mude no provedor
+	conta.Email, conta.EmailVerificado = "outro@escola.br", false
+	if identidade, ok := uh.BuscarIdentidade(ctx, conta); !ok || identidade.Usuario != "ana" {
+		t.Errorf("the stored sub should keep the link, got %+v", identidade)
+	}
+	//outra conta do provedor com o mesmo e-mail não toma a conta já vinculada
+	if _, ok := uh.BuscarIdentidade(ctx, auth.ContaExterna{Emissor: "https://idp", Sub: "s-2", Email: "ana@escola.br", EmailVerificado: true}); ok {
+		t.Error("an account already linked to another sub was linked again")
+	}
+}
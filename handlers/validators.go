@@ -1,39 +1,194 @@
 package handlers
 
-import "gopkg.in/go-playground/validator.v9"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
 
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// v é o único validador da API: toda criação e alteração passa por validar ou validarLista antes de gravar.
 var (
 	v = validator.New()
 )
 
-type AlunosValidator struct{
-	validator *validator.Validate
+func init() {
+	//os erros de validação apontam o campo pelo nome usado no JSON
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		nome := strings.Split(f.Tag.Get("json"), ",")[0]
+		if nome == "-" {
+			return ""
+		}
+		return nome
+	})
+	validadores := map[string]func(string) bool{
+		"cpf":      cpfValido,
+		"rg":       rgValido,
+		"cep":      cepValido,
+		"telefone": telefoneValido,
+	}
+	for tag, valido := range validadores {
+		valido := valido
+		v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return valido(fl.Field().String())
+		})
+	}
+	if err := i18n.RegistrarValidador(v); err != nil { //mensagens de validação em cada idioma
+		log.Fatalf("Unable to register the validation messages: %v", err)
+	}
 }
 
-func (a *AlunosValidator) Validate(i interface{}) error{
-	return a.validator.Struct(i)
+// validar confere um documento e devolve os campos reprovados no formato problem+json.
+func validar(doc interface{}) *echo.HTTPError {
+	if err := v.Struct(doc); err != nil {
+		log.Errorf("Unable to validate the struct: %v", err)
+		return problema.Validacao(err).HTTP()
+	}
+	return nil
 }
 
-type ProfessoresValidator struct{
-	validator *validator.Validate
+// validarLista confere todos os documentos de um lote antes de gravar qualquer um, apontando o índice de
+// cada campo reprovado ("[2].telefone").
+func validarLista(docs interface{}) *echo.HTTPError {
+	lista := reflect.ValueOf(docs)
+	var campos []problema.ErroCampo
+	for i := 0; i < lista.Len(); i++ {
+		if err := v.Struct(lista.Index(i).Interface()); err != nil {
+			campos = append(campos, problema.CamposValidacao(err, fmt.Sprintf("[%d].", i))...)
+		}
+	}
+	if len(campos) == 0 {
+		return nil
+	}
+	p := problema.Novo(http.StatusBadRequest, problema.CodigoValidacao, "Unable to validate request payload")
+	p.Erros = campos
+	return p.HTTP()
 }
 
-func (p *ProfessoresValidator) Validate(i interface{}) error{
-	return p.validator.Struct(i)
+func somenteDigitos(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
 }
 
-type CursosValidator struct{
-	validator *validator.Validate
+var formatoCPF = regexp.MustCompile(`^(\d{11}|\d{3}\.\d{3}\.\d{3}-\d{2})$`)
+
+// cpfValido aceita o CPF com ou sem pontuação e confere os dois dígitos verificadores.
+func cpfValido(cpf string) bool {
+	if !formatoCPF.MatchString(cpf) {
+		return false
+	}
+	d := somenteDigitos(cpf)
+	if strings.Count(d, d[:1]) == len(d) { //000.000.000-00, 111.111.111-11...
+		return false
+	}
+	for _, n := range []int{9, 10} {
+		soma := 0
+		for i := 0; i < n; i++ {
+			soma += int(d[i]-'0') * (n + 1 - i)
+		}
+		dv := soma * 10 % 11 % 10
+		if dv != int(d[n]-'0') {
+			return false
+		}
+	}
+	return true
 }
 
-func (c *CursosValidator) Validate(i interface{}) error{
-	return c.validator.Struct(i)
+var formatoRG = regexp.MustCompile(`^[0-9]{5,13}[0-9Xx]$`)
+
+// rgValido confere apenas o formato do RG: cada estado tem a própria numeração e nem todos usam dígito
+// verificador.
+func rgValido(rg string) bool {
+	return formatoRG.MatchString(strings.NewReplacer(".", "", "-", "", " ", "").Replace(rg))
 }
 
-type DisciplinasValidator struct{
-	validator *validator.Validate
+var formatoCEP = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+
+func cepValido(cep string) bool {
+	return formatoCEP.MatchString(cep) && somenteDigitos(cep) != "00000000"
 }
 
-func (d *DisciplinasValidator) Validate(i interface{}) error{
-	return d.validator.Struct(i)
+var formatoE164 = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// telefoneValido exige o formato E.164 gravado por modelos.NormalizarTelefone. Números brasileiros têm DDD válido
+// e, quando têm nove dígitos, são celulares começando por 9.
+func telefoneValido(telefone string) bool {
+	if !formatoE164.MatchString(telefone) {
+		return false
+	}
+	if !strings.HasPrefix(telefone, "+55") {
+		return true
+	}
+	nacional := telefone[3:]
+	if len(nacional) != 10 && len(nacional) != 11 {
+		return false
+	}
+	if nacional[0] == '0' || nacional[1] == '0' { //DDDs vão de 11 a 99
+		return false
+	}
+	return len(nacional) == 10 || nacional[2] == '9'
+}
+
+// Telefone é reexportado de modelos, onde fica a normalização para E.164.
+type Telefone = modelos.Telefone
+
+// MigrarTelefones grava em E.164 os telefones antigos da coleção, guardados como inteiro ou texto livre.
+// Números de 8 ou 9 dígitos, cadastrados sem DDD, recebem o dddPadrao; sem ele, ou quando o número continua
+// inválido, o documento fica como está e entra na contagem de pendentes, para correção manual. Pode rodar a
+// cada início: telefones já migrados não são regravados.
+func MigrarTelefones(ctx context.Context, collection dbiface.Collection, dddPadrao string) (migrados, pendentes int, err error) {
+	filter := bson.M{"telefone": bson.M{"$exists": true, "$ne": ""}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"telefone": 1}))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Telefone bson.RawValue      `bson:"telefone"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return migrados, pendentes, err
+		}
+		var telefone Telefone
+		if err := telefone.UnmarshalBSONValue(doc.Telefone.Type, doc.Telefone.Value); err != nil || telefone == "" {
+			pendentes++
+			continue
+		}
+		if digitos := somenteDigitos(string(telefone)); !telefoneValido(string(telefone)) && dddPadrao != "" &&
+			(len(digitos) == 8 || len(digitos) == 9) {
+			telefone = Telefone(modelos.NormalizarTelefone(dddPadrao + digitos))
+		}
+		if !telefoneValido(string(telefone)) {
+			log.Warnf("Unable to migrate the phone number of %s", doc.ID.Hex())
+			pendentes++
+			continue
+		}
+		if texto, ok := doc.Telefone.StringValueOK(); ok && texto == string(telefone) {
+			continue
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{"telefone": string(telefone)}}); err != nil {
+			return migrados, pendentes, err
+		}
+		migrados++
+	}
+	return migrados, pendentes, cursor.Err()
 }
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCpfValido(t *testing.T) {
+	casos := map[string]bool{
+		"529.982.247-25": true,
+		"52998224725":    true,
+		"529.982.247-24": false, //segundo dígito errado
+		"529.982.247-35": false, //primeiro dígito errado
+		"111.111.111-11": false, //dígitos repetidos passam na conta, mas não são CPFs
+		"00000000000":    false,
+		"529982247-25":   false, //pontuação pela metade
+		"5299822472":     false,
+		"":               false,
+	}
+	for cpf, esperado := range casos {
+		if cpfValido(cpf) != esperado {
+			t.Errorf("cpfValido(%q) = %v, want %v", cpf, !esperado, esperado)
+		}
+	}
+}
+
+func TestTelefoneValido(t *testing.T) {
+	casos := map[string]bool{
+		"+5511987654321":  true,  //celular
+		"+551133334444":   true,  //fixo
+		"+14155552671":    true,  //internacional, só o formato E.164 é conferido
+		"+5511887654321":  false, //celular com nove dígitos deve começar por 9
+		"+550187654321":   false, //DDD 01
+		"+5510987654321":  false, //DDD 10
+		"+55119876543210": false,
+		"+55987654321":    false, //sem DDD
+		"5511987654321":   false, //sem o +
+		"+0011987654321":  false,
+		"anonimizado":     false,
+	}
+	for telefone, esperado := range casos {
+		if telefoneValido(telefone) != esperado {
+			t.Errorf("telefoneValido(%q) = %v, want %v", telefone, !esperado, esperado)
+		}
+	}
+}
+
+func TestTelefoneLegadoNoBanco(t *testing.T) {
+	for _, legado := range []interface{}{int32(1133334444), int64(11987654321), float64(11987654321), "(11) 98765-4321"} {
+		dados, _ := bson.Marshal(bson.M{"telefone": legado})
+		var doc struct {
+			Telefone Telefone `bson:"telefone"`
+		}
+		if err := bson.Unmarshal(dados, &doc); err != nil || !telefoneValido(string(doc.Telefone)) {
+			t.Errorf("%T %v was read as %q (%v)", legado, legado, doc.Telefone, err)
+		}
+	}
+}
+
+func TestMigrarTelefones(t *testing.T) {
+	id := func() primitive.ObjectID { return primitive.NewObjectID() }
+	docs := []bson.M{
+		{"_id": id(), "telefone": int64(11987654321)}, //inteiro com DDD
+		{"_id": id(), "telefone": int32(987654321)},   //inteiro sem DDD
+		{"_id": id(), "telefone": "3333-4444"},        //texto sem DDD
+		{"_id": id(), "telefone": "+5511987654321"},   //já migrado
+		{"_id": id(), "telefone": "12"},               //inválido de qualquer forma
+		{"_id": id(), "telefone": ""},                 //anonimizado
+		{"_id": id(), "nome": "sem telefone"},
+	}
+	esperados := []interface{}{"+5511987654321", "+5521987654321", "+552133334444", "+5511987654321", "12", "", nil}
+	var lista []interface{}
+	for _, doc := range docs {
+		lista = append(lista, doc)
+	}
+	col := novaColecao(lista...)
+	migrados, pendentes, err := MigrarTelefones(context.Background(), col, "21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrados != 3 || pendentes != 1 {
+		t.Errorf("got %d migrated and %d pending, want 3 and 1", migrados, pendentes)
+	}
+	for i, doc := range docs {
+		if obtido := col.buscarID(doc["_id"].(primitive.ObjectID))["telefone"]; obtido != esperados[i] {
+			t.Errorf("document %d: telefone = %v, want %v", i, obtido, esperados[i])
+		}
+	}
+
+	//sem DDD padrão, os números sem DDD ficam pendentes e nada mais é gravado
+	col = novaColecao(bson.M{"_id": id(), "telefone": int32(987654321)}, bson.M{"_id": id(), "telefone": "+5511987654321"})
+	if migrados, pendentes, _ := MigrarTelefones(context.Background(), col, ""); migrados != 0 || pendentes != 1 || col.escritas != 0 {
+		t.Errorf("without a default area code: got %d migrated, %d pending and %d writes", migrados, pendentes, col.escritas)
+	}
+}
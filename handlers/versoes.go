@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/modelos"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const OperacaoReversao = "rollback"
+
+// Versoes guarda o documento completo como ficou depois de cada escrita, numerado a partir de 1.
+type Versoes = modelos.Versoes
+
+type Versionador struct {
+	Col        dbiface.Collection
+	Sequencias *GeradorSequencia //numeração das versões por documento
+}
+
+// Registrar grava uma nova versão do documento. Assim como o Auditor, um Versionador nil não faz nada e
+// falhas são apenas logadas.
+func (v *Versionador) Registrar(ctx context.Context, ev Evento, recurso, operacao string, id primitive.ObjectID, documento interface{}) {
+	if v == nil {
+		return
+	}
+	doc := paraDocumento(documento)
+	if doc == nil {
+		return
+	}
+	agora := time.Now()
+	if operacao == OperacaoExclusao {
+		doc["deletedAt"] = agora //a versão registra que o documento estava na lixeira a partir daqui
+	}
+	numero, err := v.Sequencias.Proximo(ctx, "versao:"+recurso+":"+id.Hex())
+	if err != nil {
+		log.Errorf("Unable to number the version of %s %s: %v", recurso, id.Hex(), err)
+		return
+	}
+	versao := Versoes{
+		ID:          primitive.NewObjectID(),
+		Recurso:     recurso,
+		DocumentoID: id,
+		Versao:      numero,
+		Operacao:    operacao,
+		Ator:        ev.Ator,
+		Data:        agora,
+		Documento:   doc,
+	}
+	if _, err := v.Col.InsertOne(ctx, versao); err != nil {
+		log.Errorf("Unable to record the version of %s %s: %v", recurso, id.Hex(), err)
+	}
+}
+
+// interpretarAsOf aceita uma data (2026-03-01, considerada até o fim do dia) ou um instante RFC 3339.
+func interpretarAsOf(valor string) (time.Time, error) {
+	if data, err := time.ParseInLocation("2006-01-02", valor, time.Local); err == nil {
+		return data.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Parse(time.RFC3339, valor)
+}
+
+// versaoEm decodifica em destino o documento como estava no instante asOf.
+func (v *Versionador) versaoEm(ctx context.Context, recurso, id, asOf string, destino interface{}) *echo.HTTPError {
+	if v == nil {
+		return echo.NewHTTPError(http.StatusNotImplemented, "Versioning is not enabled")
+	}
+	instante, err := interpretarAsOf(asOf)
+	if err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "asOf must be a date (2006-01-02) or an RFC 3339 timestamp").HTTP()
+	}
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return problema.IDInvalido().HTTP()
+	}
+	var versao Versoes
+	filter := bson.M{"recurso": recurso, "documentoId": docID, "data": bson.M{"$lte": instante}}
+	res := v.Col.FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"versao": -1}))
+	if err := res.Decode(&versao); err != nil || versao.Documento["deletedAt"] != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "The document did not exist at the requested time")
+	}
+	data, err := bson.Marshal(versao.Documento)
+	if err == nil {
+		err = bson.Unmarshal(data, destino)
+	}
+	if err != nil {
+		log.Errorf("Unable to decode the version: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Unable to decode the version")
+	}
+	return nil
+}
+
+// ListarVersoes atende GET /<recurso>/:id/versoes.
+func (v *Versionador) ListarVersoes(recurso string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		docID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			return problema.IDInvalido().HTTP()
+		}
+		filter := bson.M{"recurso": recurso, "documentoId": docID}
+		opts := options.Find().SetSort(bson.M{"versao": -1})
+		if formato, herr := formatoExportacao(c); herr != nil {
+			return herr
+		} else if formato != "" {
+			claims := claimsDaRequisicao(c)
+			return exportarListagem(c, formato, exportacao{nome: recurso + "-" + docID.Hex() + "-versoes", col: v.Col,
+				filter: filter, opts: opts, modelo: Versoes{}, tratar: func(doc interface{}) interface{} {
+					mascararDocumento(recurso, doc.(*Versoes).Documento, claims)
+					return doc
+				}})
+		}
+		var versoes []Versoes
+		cursor, err := v.Col.Find(context.Background(), filter, opts)
+		if err != nil {
+			log.Errorf("Unable to find the versions: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to find the versions")
+		}
+		if err := cursor.All(context.Background(), &versoes); err != nil {
+			log.Errorf("Unable to read the cursor: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to read the versions")
+		}
+		for _, versao := range versoes {
+			mascararDocumento(recurso, versao.Documento, claimsDaRequisicao(c))
+		}
+		return c.JSON(http.StatusOK, versoes)
+	}
+}
+
+// RestaurarVersao atende POST /<recurso>/:id/versoes/:v/restaurar: o documento volta ao conteúdo da versão
+// escolhida, com a mesma validação e as mesmas regras de negócio (inclusive as referências) de um PUT.
+// Um documento na lixeira continua lá, a menos que a requisição peça sairDaLixeira=true. A reversão gera uma
+// nova versão e uma entrada de auditoria. modelo é um valor da struct do recurso.
+func (v *Versionador) RestaurarVersao(recurso string, collection dbiface.Collection, modelo interface{}, regras *Regras, auditoria *Auditor) echo.HandlerFunc {
+	tipo := reflect.TypeOf(modelo)
+	return func(c echo.Context) error {
+		ctx := context.Background()
+		docID, err := primitive.ObjectIDFromHex(c.Param("id"))
+		if err != nil {
+			return problema.IDInvalido().HTTP()
+		}
+		numero, err := strconv.ParseInt(c.Param("v"), 10, 64)
+		if err != nil {
+			return problema.Novo(http.StatusBadRequest, problema.CodigoParametroInvalido, "The version must be a number").HTTP()
+		}
+
+		var versao Versoes
+		res := v.Col.FindOne(ctx, bson.M{"recurso": recurso, "documentoId": docID, "versao": numero})
+		if err := res.Decode(&versao); err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "Unable to find the version")
+		}
+		var antes bson.M
+		if err := collection.FindOne(ctx, bson.M{"_id": docID}).Decode(&antes); err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "Unable to find the document")
+		}
+
+		//a versão passa pela struct do recurso, como o corpo de um PUT
+		conteudo := versao.Documento
+		delete(conteudo, "deletedAt")
+		conteudo["_id"] = docID
+		doc := reflect.New(tipo)
+		dados, err := bson.Marshal(conteudo)
+		if err == nil {
+			err = bson.Unmarshal(dados, doc.Interface())
+		}
+		if err != nil {
+			log.Errorf("Unable to decode the version: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to decode the version")
+		}
+		if err := validar(doc.Interface()); err != nil {
+			return err
+		}
+		liberar, herr := regras.travar(ctx, recurso, doc.Elem().Interface())
+		if herr != nil {
+			return herr
+		}
+		defer liberar() //até a gravação
+		if err := regras.verificar(ctx, recurso, doc.Elem().Interface()); err != nil {
+			return err
+		}
+
+		depois := paraDocumento(doc.Interface())
+		if antes["deletedAt"] != nil && c.QueryParam("sairDaLixeira") != "true" {
+			depois["deletedAt"] = antes["deletedAt"]
+		}
+		if _, err := collection.ReplaceOne(ctx, bson.M{"_id": docID}, depois); err != nil {
+			log.Errorf("Unable to restore the version: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "Unable to restore the version")
+		}
+
+		ev := eventoDaRequisicao(c)
+		auditoria.Registrar(ctx, ev, recurso, OperacaoReversao, docID, antes, depois)
+		v.Registrar(ctx, ev, recurso, OperacaoReversao, docID, depois)
+		if dados, err := bson.Marshal(depois); err == nil { //a resposta mostra também a lixeira mantida
+			bson.Unmarshal(dados, doc.Interface())
+		}
+		return c.JSON(http.StatusOK, mascarar(c, doc.Interface()))
+	}
+}
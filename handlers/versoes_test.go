@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func novoVersionador() *Versionador {
+	return &Versionador{Col: novaColecao(), Sequencias: &GeradorSequencia{Col: novaColecao()}}
+}
+
+// restaurarVersao chama POST /alunos/:id/versoes/:v/restaurar com a query string dada.
+func restaurarVersao(ver *Versionador, alunos *colecaoMemoria, regras *Regras, id primitive.ObjectID, v, query string) error {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/alunos/"+id.Hex()+"/versoes/"+v+"/restaurar?"+query, nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id", "v")
+	c.SetParamValues(id.Hex(), v)
+	return ver.RestaurarVersao("alunos", alunos, Alunos{}, regras, &Auditor{Col: novaColecao()})(c)
+}
+
+func TestRestaurarVersaoValidaComoPUT(t *testing.T) {
+	cursos := novaColecao(Cursos{ID: primitive.NewObjectID(), Codigo: 1, Nome: "Química"})
+	regras, err := CarregarRegras("inexistente.json", map[string]dbiface.Collection{"cursos": cursos})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ver := novoVersionador()
+	atual := Alunos{ID: primitive.NewObjectID(), Nome: "Gil", Sobrenome: "Souza", Telefone: "+5511987654321", Curso: 1}
+	alunos := novaColecao(atual)
+	ctx := context.Background()
+	//versão 1 sem sobrenome, que a validação recusa; versão 2 com um curso que não existe mais
+	ver.Registrar(ctx, Evento{}, "alunos", OperacaoInsercao, atual.ID, Alunos{Nome: "Gil", Telefone: "+5511987654321", Curso: 1})
+	ver.Registrar(ctx, Evento{}, "alunos", OperacaoAlteracao, atual.ID, Alunos{Nome: "Gil", Sobrenome: "Souza", Telefone: "+5511987654321", Curso: 9})
+	ver.Registrar(ctx, Evento{}, "alunos", OperacaoAlteracao, atual.ID, atual)
+
+	for _, v := range []string{"1", "2"} {
+		err := restaurarVersao(ver, alunos, regras, atual.ID, v, "")
+		herr, ok := err.(*echo.HTTPError)
+		if !ok || (herr.Code != http.StatusBadRequest && herr.Code != http.StatusUnprocessableEntity) {
+			t.Errorf("version %s: got %v, want a validation error", v, err)
+		}
+	}
+	if alunos.escritas != 0 {
+		t.Error("an invalid version was written")
+	}
+	if err := restaurarVersao(ver, alunos, regras, atual.ID, "3", ""); err != nil {
+		t.Errorf("version 3: %v", err)
+	}
+}
+
+func TestRestaurarVersaoMantemLixeira(t *testing.T) {
+	ver := novoVersionador()
+	agora := time.Now()
+	aluno := Alunos{ID: primitive.NewObjectID(), Nome: "Iris", Sobrenome: "Lima", Telefone: "+5511987654321"}
+	ver.Registrar(context.Background(), Evento{}, "alunos", OperacaoInsercao, aluno.ID, aluno)
+	naLixeira := aluno
+	naLixeira.Nome, naLixeira.DeletadoEm = "Íris", &agora
+	alunos := novaColecao(naLixeira)
+
+	if err := restaurarVersao(ver, alunos, nil, aluno.ID, "1", ""); err != nil {
+		t.Fatal(err)
+	}
+	doc := alunos.buscarID(aluno.ID)
+	if doc["nome"] != "Iris" || doc["deletedAt"] == nil {
+		t.Errorf("the version should be restored in the trash, got %v", doc)
+	}
+	if err := restaurarVersao(ver, alunos, nil, aluno.ID, "1", "sairDaLixeira=true"); err != nil {
+		t.Fatal(err)
+	}
+	if doc := alunos.buscarID(aluno.ID); doc["deletedAt"] != nil {
+		t.Errorf("sairDaLixeira=true should take the document out of the trash, got %v", doc)
+	}
+}
+
+func TestPoliticaRegistraVersoes(t *testing.T) {
+	aluno := Alunos{ID: primitive.NewObjectID(), Nome: "Juca", Curso: 3}
+	alunos, cursos := novaColecao(aluno), novaColecao(Cursos{ID: primitive.NewObjectID(), Codigo: 3, Nome: "Letras"})
+	ver := novoVersionador()
+	relacoes := []Relacao{{Recurso: "alunos", Col: alunos, Campo: "curso", Politica: Cascata, Versoes: ver}}
+	curso := cursos.docs[0]["_id"].(primitive.ObjectID)
+	if _, err := excluirComIntegridade(context.Background(), Evento{}, curso.Hex(), 3, cursos, relacoes); err != nil {
+		t.Fatal(err)
+	}
+	var versao Versoes
+	if err := ver.Col.FindOne(context.Background(), bson.M{"recurso": "alunos", "documentoId": aluno.ID}).Decode(&versao); err != nil {
+		t.Fatalf("the cascaded delete was not versioned: %v", err)
+	}
+	if versao.Operacao != OperacaoExclusao || versao.Documento["deletedAt"] == nil {
+		t.Errorf("unexpected version %+v", versao)
+	}
+}
+
+// TestEscritasRegistramHistorico confere que as funções de escrita compartilhadas pelo REST e pelo gRPC
+// registram a auditoria e a versão de cada operação.
+func TestEscritasRegistramHistorico(t *testing.T) {
+	ctx := context.Background()
+	alunos, auditoria := novaColecao(), novaColecao()
+	hist := historico{recurso: "alunos", auditoria: &Auditor{Col: auditoria}, versoes: novoVersionador()}
+	ev := Evento{Ator: "secretaria"}
+	novos := []Alunos{{Nome: "Lia", Sobrenome: "Rocha", Telefone: "+5511987654321"}}
+	if _, err := inserirAluno(ctx, ev, novos, alunos, nil, nil, hist); err != nil {
+		t.Fatal(err)
+	}
+	id := novos[0].ID.Hex()
+	corpo := ioutil.NopCloser(strings.NewReader(`{"nome":"Lia Maria"}`))
+	if _, err := alterarAluno(ctx, ev, id, corpo, alunos, nil, hist); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deletarAluno(ctx, ev, id, alunos, hist); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restaurarAluno(ctx, ev, id, alunos, hist); err != nil {
+		t.Fatal(err)
+	}
+
+	operacoes := []string{OperacaoInsercao, OperacaoAlteracao, OperacaoExclusao, OperacaoRestauracao}
+	if len(auditoria.docs) != len(operacoes) {
+		t.Fatalf("got %d audit entries, want %d", len(auditoria.docs), len(operacoes))
+	}
+	for i, op := range operacoes {
+		if entrada := auditoria.docs[i]; entrada["operacao"] != op || entrada["ator"] != "secretaria" {
+			t.Errorf("audit entry %d = %v, want %s", i, entrada, op)
+		}
+	}
+	var versao Versoes
+	if err := hist.versoes.Col.FindOne(ctx, bson.M{"versao": int64(2)}).Decode(&versao); err != nil {
+		t.Fatal(err)
+	}
+	if versao.Documento["nome"] != "Lia Maria" || len(hist.versoes.Col.(*colecaoMemoria).docs) != len(operacoes) {
+		t.Errorf("version 2 = %+v", versao)
+	}
+}
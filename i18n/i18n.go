@@ -0,0 +1,188 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"gopkg.in/go-playground/validator.v9"
+	traducoesEn "gopkg.in/go-playground/validator.v9/translations/en"
+	traducoesPt "gopkg.in/go-playground/validator.v9/translations/pt_BR"
+)
+
+// Idiomas das respostas. As mensagens são escritas em inglês no código, que é o idioma de origem dos
+// catálogos; os logs continuam em inglês.
+const (
+	Ingles          = "en"
+	PortuguesBrasil = "pt-BR"
+)
+
+// Padrao é o idioma usado quando o Accept-Language não pede nenhum dos idiomas disponíveis.
+var Padrao = Ingles
+
+// catalogos traduz as mensagens em inglês para cada idioma. Chaves com verbos do fmt (%s, %d, %q, %v)
+// também traduzem as mensagens montadas com fmt.Sprintf, mantendo os valores na mesma ordem.
+var catalogos = map[string]map[string]string{
+	PortuguesBrasil: ptBR,
+}
+
+type modelo struct {
+	expressao *regexp.Regexp
+	traducao  string
+}
+
+var (
+	verbos  = regexp.MustCompile(`%[sdqv]`)
+	modelos = make(map[string][]modelo)
+)
+
+func init() {
+	for idioma, catalogo := range catalogos {
+		chaves := make([]string, 0, len(catalogo))
+		for chave := range catalogo {
+			if verbos.MatchString(chave) {
+				chaves = append(chaves, chave)
+			}
+		}
+		sort.Slice(chaves, func(i, j int) bool { return len(chaves[i]) > len(chaves[j]) }) //o mais específico primeiro
+		for _, chave := range chaves {
+			partes := verbos.Split(chave, -1)
+			for i := range partes {
+				partes[i] = regexp.QuoteMeta(partes[i])
+			}
+			modelos[idioma] = append(modelos[idioma], modelo{
+				expressao: regexp.MustCompile("^" + strings.Join(partes, "(.+?)") + "$"),
+				traducao:  verbos.ReplaceAllString(catalogo[chave], "%s"),
+			})
+		}
+	}
+}
+
+// Negociar escolhe o idioma da resposta a partir do cabeçalho Accept-Language, respeitando os pesos (q).
+// Qualquer variante do português recebe pt-BR.
+func Negociar(acceptLanguage string) string {
+	melhor, peso := Padrao, 0.0
+	for _, item := range strings.Split(acceptLanguage, ",") {
+		partes := strings.Split(strings.TrimSpace(item), ";")
+		q := 1.0
+		for _, parametro := range partes[1:] {
+			parametro = strings.TrimSpace(parametro)
+			if strings.HasPrefix(parametro, "q=") {
+				if valor, err := strconv.ParseFloat(parametro[2:], 64); err == nil {
+					q = valor
+				}
+			}
+		}
+		idioma := suportado(partes[0])
+		if idioma != "" && q > peso {
+			melhor, peso = idioma, q
+		}
+	}
+	return melhor
+}
+
+func suportado(etiqueta string) string {
+	principal := strings.ToLower(strings.Split(strings.Replace(etiqueta, "_", "-", -1), "-")[0])
+	switch principal {
+	case "pt":
+		return PortuguesBrasil
+	case "en":
+		return Ingles
+	}
+	return ""
+}
+
+// Traduzir devolve a mensagem no idioma pedido, ou a própria mensagem quando o catálogo não a tem.
+func Traduzir(idioma, mensagem string) string {
+	catalogo, ok := catalogos[idioma]
+	if !ok || mensagem == "" {
+		return mensagem
+	}
+	if traducao, ok := catalogo[mensagem]; ok {
+		return traducao
+	}
+	for _, m := range modelos[idioma] {
+		valores := m.expressao.FindStringSubmatch(mensagem)
+		if valores == nil {
+			continue
+		}
+		argumentos := make([]interface{}, len(valores)-1)
+		for i, valor := range valores[1:] {
+			argumentos[i] = valor
+		}
+		return fmt.Sprintf(m.traducao, argumentos...)
+	}
+	return mensagem
+}
+
+var (
+	universal   = ut.New(en.New(), en.New(), pt_BR.New())
+	tradutores  = map[string]string{Ingles: "en", PortuguesBrasil: "pt_BR"}
+	validadores = map[string]map[string]string{ //mensagens das validações próprias da API e das sem tradução padrão
+		Ingles: {
+			"cpf":              "{0} must be a valid CPF",
+			"rg":               "{0} must be a valid RG",
+			"cep":              "{0} must be a valid CEP",
+			"telefone":         "{0} must be a valid phone number in E.164 format",
+			"required_without": "{0} is a required field",
+		},
+		PortuguesBrasil: {
+			"cpf":              "{0} deve ser um CPF válido",
+			"rg":               "{0} deve ser um RG válido",
+			"cep":              "{0} deve ser um CEP válido",
+			"telefone":         "{0} deve ser um telefone válido no formato E.164",
+			"required_without": "{0} é um campo requerido",
+		},
+	}
+)
+
+func tradutor(idioma string) ut.Translator {
+	t, _ := universal.GetTranslator(tradutores[idioma])
+	return t
+}
+
+// RegistrarValidador registra no validador as mensagens de cada idioma, inclusive as das validações
+// próprias da API.
+func RegistrarValidador(v *validator.Validate) error {
+	if err := traducoesEn.RegisterDefaultTranslations(v, tradutor(Ingles)); err != nil {
+		return err
+	}
+	if err := traducoesPt.RegisterDefaultTranslations(v, tradutor(PortuguesBrasil)); err != nil {
+		return err
+	}
+	for idioma, mensagens := range validadores {
+		for tag, mensagem := range mensagens {
+			tag, mensagem := tag, mensagem
+			registrar := func(t ut.Translator) error { return t.Add(tag, mensagem, true) }
+			traduzir := func(t ut.Translator, fe validator.FieldError) string {
+				texto, _ := t.T(tag, fe.Field())
+				return texto
+			}
+			if err := v.RegisterTranslation(tag, tradutor(idioma), registrar, traduzir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MensagemValidacao descreve uma falha do validador no idioma pedido. Regras sem mensagem registrada
+// recebem um texto genérico com o nome da regra.
+func MensagemValidacao(idioma string, fe validator.FieldError) string {
+	if t := tradutor(idioma); t != nil {
+		texto := fe.Translate(t)
+		if original, ok := fe.(error); !ok || texto != original.Error() { //sem mensagem, Translate devolve o erro
+			return texto
+		}
+	}
+	regra := fe.Tag()
+	if fe.Param() != "" {
+		regra += "=" + fe.Param()
+	}
+	return Traduzir(idioma, fmt.Sprintf("failed the %s rule", regra))
+}
@@ -0,0 +1,174 @@
+package i18n
+
+// ptBR é o catálogo em português das mensagens devolvidas pela API. Mensagens novas devem ser incluídas
+// aqui; as que faltarem saem em inglês.
+var ptBR = map[string]string{
+	//títulos dos problemas (http.StatusText) e erros padrão do Echo
+	"Bad Request":              "Requisição inválida",
+	"Unauthorized":             "Não autenticado",
+	"Forbidden":                "Acesso negado",
+	"Not Found":                "Não encontrado",
+	"Method Not Allowed":       "Método não permitido",
+	"Conflict":                 "Conflito",
+	"Request Entity Too Large": "Requisição grande demais",
+	"Unsupported Media Type":   "Tipo de conteúdo não suportado",
+	"Unprocessable Entity":     "Requisição não processável",
+	"Locked":                   "Bloqueado",
+	"Too Many Requests":        "Requisições demais",
+	"Internal Server Error":    "Erro interno do servidor",
+	"Bad Gateway":              "Falha no serviço externo",
+	"Service Unavailable":      "Serviço indisponível",
+	"Internal server error":    "Erro interno do servidor",
+
+	//validação e regras de negócio
+	"Unable to validate request payload":                        "Os dados enviados não são válidos",
+	"Unable to parse request payload":                           "Não foi possível ler os dados enviados",
+	"failed the %s rule":                                        "não atende à regra %s",
+	"The request violates business rules":                       "A requisição viola regras de negócio",
+	"Unable to evaluate the business rules":                     "Não foi possível avaliar as regras de negócio",
+	"Another request is changing related documents, try again":  "Outra requisição está alterando documentos relacionados, tente novamente",
+	"%s must be a multiple of %v":                               "%s deve ser múltiplo de %v",
+	"%s must be %s %s":                                          "%s deve ser %s %s",
+	"the %s of the %s in %s exceeds %v":                         "a soma de %s das %s em %s passa de %v",
+	"the %s in %s must have distinct %s":                        "os %s em %s devem ter %s diferentes",
+	"Dates must use the RFC 3339 format":                        "As datas devem usar o formato RFC 3339",
+	"asOf must be a date (2006-01-02) or an RFC 3339 timestamp": "asOf deve ser uma data (2006-01-02) ou um instante RFC 3339",
+	"The version must be a number":                              "A versão deve ser um número",
+	"limite must be a positive number":                          "limite deve ser um número positivo",
+	"pagina must be a positive number":                          "pagina deve ser um número positivo",
+
+	//documentos
+	"Unable to connect to database":                 "Não foi possível acessar o banco de dados",
+	"Unable to convert to ObjectID":                 "Identificador inválido",
+	"Unable to find the student":                    "Aluno não encontrado",
+	"Unable to find the teacher":                    "Professor não encontrado",
+	"Unable to find the course":                     "Curso não encontrado",
+	"Unable to find the discipline":                 "Disciplina não encontrada",
+	"Unable to find the user":                       "Usuário não encontrado",
+	"Unable to find the document":                   "Documento não encontrado",
+	"Unable to find the document in the trash":      "Documento não encontrado na lixeira",
+	"Unable to update the student":                  "Não foi possível alterar o aluno",
+	"Unable to update the teacher":                  "Não foi possível alterar o professor",
+	"Unable to update the course":                   "Não foi possível alterar o curso",
+	"Unable to update the discipline":               "Não foi possível alterar a disciplina",
+	"Unable to update the user":                     "Não foi possível alterar o usuário",
+	"Unable to update the dependents":               "Não foi possível alterar os dependentes",
+	"Unable to generate the matricula":              "Não foi possível gerar a matrícula",
+	"Unable to generate the registro":               "Não foi possível gerar o registro",
+	"Unable to create the user":                     "Não foi possível criar o usuário",
+	"Unable to delete the document":                 "Não foi possível excluir o documento",
+	"Unable to delete: the document has dependents": "Não é possível excluir: há documentos dependentes",
+	"Unable to check the dependents":                "Não foi possível verificar os dependentes",
+	"Unable to restore the document":                "Não foi possível restaurar o documento",
+	"The login is already in use":                   "O login já está em uso",
+	"The e-mail is already in use":                  "O e-mail já está em uso",
+	"The login or e-mail is already in use":         "O login ou o e-mail já está em uso",
+
+	"Another active document has the same unique value": "Outro documento ativo tem o mesmo valor único",
+
+	//auditoria e versões
+	"Unable to find the audit entries":                 "Não foi possível buscar o histórico de alterações",
+	"Unable to read the audit entries":                 "Não foi possível ler o histórico de alterações",
+	"Versioning is not enabled":                        "O versionamento não está ativado",
+	"Unable to find the version":                       "Versão não encontrada",
+	"Unable to find the versions":                      "Não foi possível buscar as versões",
+	"Unable to read the versions":                      "Não foi possível ler as versões",
+	"Unable to decode the version":                     "Não foi possível ler a versão",
+	"Unable to restore the version":                    "Não foi possível restaurar a versão",
+	"The document did not exist at the requested time": "O documento não existia na data pedida",
+
+	//autenticação e acesso
+	"Missing bearer token":                                                    "Token de acesso ausente",
+	"Invalid or expired token":                                                "Token inválido ou expirado",
+	"Invalid or expired refresh token":                                        "Token de renovação inválido ou expirado",
+	"Invalid username or password":                                            "Usuário ou senha inválidos",
+	"Account temporarily locked after repeated failed logins":                 "Conta bloqueada temporariamente após tentativas de login sem sucesso",
+	"The account has no role in this API":                                     "A conta não tem papel nesta API",
+	"Unable to issue the tokens":                                              "Não foi possível emitir os tokens",
+	"Unable to renew the tokens":                                              "Não foi possível renovar os tokens",
+	"Unable to revoke the token":                                              "Não foi possível revogar o token",
+	"Role %q is not allowed to %s":                                            "O papel %q não tem permissão para %s",
+	"Students can only read their own record":                                 "Alunos só podem consultar o próprio cadastro",
+	"Guardians can only read the records of their linked students":            "Responsáveis só podem consultar o cadastro dos alunos vinculados",
+	"The current password is wrong":                                           "A senha atual está incorreta",
+	"Unable to change the password":                                           "Não foi possível alterar a senha",
+	"Invalid or expired reset token":                                          "Token de redefinição inválido ou expirado",
+	"the password must have at least %d characters":                           "a senha deve ter pelo menos %d caracteres",
+	"the password must have at most 72 bytes":                                 "a senha deve ter no máximo 72 bytes",
+	"the password must mix upper case letters, lower case letters and digits": "a senha deve misturar letras maiúsculas, minúsculas e dígitos",
+	"the password must be different from the login":                           "a senha deve ser diferente do login",
+
+	//login pelo provedor de identidade
+	"Unable to start the login":                   "Não foi possível iniciar o login",
+	"Missing login state":                         "Estado do login ausente",
+	"Invalid login state":                         "Estado do login inválido",
+	"The identity provider refused the login: %s": "O provedor de identidade recusou o login: %s",
+	"Unable to reach the identity provider":       "Não foi possível acessar o provedor de identidade",
+	"Unable to exchange the authorization code":   "Não foi possível trocar o código de autorização",
+	"Invalid ID token":                            "Token de identidade inválido",
+	"Unable to complete the login":                "Não foi possível concluir o login",
+
+	//chaves de API e limites
+	"Unable to create the API key":       "Não foi possível criar a chave de API",
+	"Unable to find the API key":         "Chave de API não encontrada",
+	"Unable to find the API keys":        "Não foi possível buscar as chaves de API",
+	"Unable to update the API key":       "Não foi possível alterar a chave de API",
+	"unknown scope %q":                   "escopo desconhecido %q",
+	"The API key lacks the scope %s":     "A chave de API não tem o escopo %s",
+	"API key rate limit exceeded":        "Limite de requisições da chave de API excedido",
+	"Invalid or expired API key":         "Chave de API inválida ou expirada",
+	"Too many requests, try again later": "Requisições demais, tente novamente mais tarde",
+
+	//GraphQL
+	"The query is required":           "A consulta é obrigatória",
+	"Syntax error at position %d: %s": "Erro de sintaxe na posição %d: %s",
+	"Unknown operation %q":            "Operação desconhecida %q",
+	"An operation name is required when the query has more than one operation": "Informe o nome da operação quando a consulta tiver mais de uma",
+	"Only query operations are supported, not %s":                              "Apenas operações query são suportadas, não %s",
+	"Cannot query field %q on type %q":                                         "O campo %q não existe no tipo %q",
+	"Field %q of type %q must have a selection of subfields":                   "O campo %q do tipo %q exige a seleção de subcampos",
+	"Field %q must not have a selection since type %q has no subfields":        "O campo %q não aceita seleção, o tipo %q não tem subcampos",
+	"The query exceeds the maximum depth of %d":                                "A consulta passa da profundidade máxima de %d",
+	"Unknown argument %q on field %q":                                          "Argumento %q desconhecido no campo %q",
+	"Argument %q of field %q is required":                                      "O argumento %q do campo %q é obrigatório",
+	"Unknown fragment %q":                                                      "Fragmento desconhecido %q",
+	"Unknown directive @%s":                                                    "Diretiva desconhecida @%s",
+
+	//gRPC
+	"The method %s is not allowed":   "O método %s não é permitido",
+	"Unable to export the documents": "Não foi possível exportar os documentos",
+	"Unable to encode the response":  "Não foi possível codificar a resposta",
+	"The field %q cannot be updated": "O campo %q não pode ser alterado",
+
+	//importação de planilhas
+	"The spreadsheet is required in the arquivo field": "A planilha é obrigatória no campo arquivo",
+	"Unsupported spreadsheet format, use csv or xlsx":  "Formato de planilha não suportado, use csv ou xlsx",
+	"Unable to read the spreadsheet":                   "Não foi possível ler a planilha",
+	"The spreadsheet is empty":                         "A planilha está vazia",
+	"Unable to parse the column mapping":               "Não foi possível interpretar o mapeamento de colunas",
+	"Unknown field %q in the column mapping":           "Campo desconhecido %q no mapeamento de colunas",
+	"Column %q not found in the spreadsheet":           "A coluna %q não foi encontrada na planilha",
+	"The field %q is mapped to more than one column":   "O campo %q está mapeado para mais de uma coluna",
+	"No column of the spreadsheet matches a field":     "Nenhuma coluna da planilha corresponde a um campo",
+	"invalid value %q":                                 "valor inválido %q",
+	"Unable to start the import":                       "Não foi possível iniciar a importação",
+	"Unable to find the import":                        "Importação não encontrada",
+
+	//exportação das listagens
+	"format must be one of json, csv, xlsx or ndjson": "format deve ser json, csv, xlsx ou ndjson",
+	"ordem must be a comma-separated list of fields":  "ordem deve ser uma lista de campos separados por vírgula",
+	"campos must be a comma-separated list of fields": "campos deve ser uma lista de campos separados por vírgula",
+	"Not allowed to filter or sort by %s":             "Não é permitido filtrar ou ordenar por %s",
+	"Invalid filter %q":                               "Filtro inválido %q",
+
+	//XML e MessagePack
+	"Unable to read the request body":      "Não foi possível ler o corpo da requisição",
+	"Unable to parse the XML body":         "Não foi possível interpretar o corpo XML",
+	"Unable to parse the MessagePack body": "Não foi possível interpretar o corpo MessagePack",
+
+	//LGPD
+	"Unknown data subject type":       "Tipo de titular desconhecido",
+	"Unable to find the data subject": "Titular não encontrado",
+	"Unable to export the data":       "Não foi possível exportar os dados",
+	"Unable to anonymize the data":    "Não foi possível anonimizar os dados",
+}
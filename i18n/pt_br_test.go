@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// chamadasComMensagem são as funções que recebem a mensagem devolvida ao cliente, com a posição dela.
+var chamadasComMensagem = map[string]int{
+	"echo.NewHTTPError": 1,
+	"problema.Novo":     2,
+}
+
+// TestCatalogoCompleto confere que toda mensagem literal devolvida pela API tem tradução em pt-BR.
+func TestCatalogoCompleto(t *testing.T) {
+	conjunto := token.NewFileSet()
+	err := filepath.WalkDir("..", func(caminho string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) && caminho != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(caminho, ".go") || strings.HasSuffix(caminho, "_test.go") {
+			return nil
+		}
+		arquivo, err := parser.ParseFile(conjunto, caminho, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(arquivo, func(n ast.Node) bool {
+			chamada, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			posicao, ok := chamadasComMensagem[nomeFuncao(chamada.Fun)]
+			if !ok || len(chamada.Args) <= posicao {
+				return true
+			}
+			mensagem := chamada.Args[posicao]
+			if sprintf, ok := mensagem.(*ast.CallExpr); ok && nomeFuncao(sprintf.Fun) == "fmt.Sprintf" {
+				mensagem = sprintf.Args[0] //o modelo da mensagem é a chave do catálogo
+			}
+			literal, ok := mensagem.(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				return true
+			}
+			texto, _ := strconv.Unquote(literal.Value)
+			if _, ok := ptBR[texto]; !ok {
+				t.Errorf("%s: %q has no pt-BR translation", conjunto.Position(literal.Pos()), texto)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nomeFuncao(expr ast.Expr) string {
+	seletor, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	pacote, ok := seletor.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return pacote.Name + "." + seletor.Sel.Name
+}
@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Mensagem struct {
+	Para    string
+	Assunto string
+	Corpo   string
+}
+
+// Mailer envia as mensagens do sistema, como o token de redefinição de senha.
+type Mailer interface {
+	Enviar(ctx context.Context, msg Mensagem) error
+}
+
+func formatar(remetente string, msg Mensagem) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", remetente)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.Para)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Assunto)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Corpo)
+	return []byte(b.String())
+}
+
+// Arquivo grava cada mensagem como um .eml no diretório, para desenvolvimento local.
+type Arquivo struct {
+	Diretorio string
+	Remetente string
+}
+
+func (a *Arquivo) Enviar(ctx context.Context, msg Mensagem) error {
+	if err := os.MkdirAll(a.Diretorio, 0700); err != nil {
+		return err
+	}
+	nome := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), strings.NewReplacer("@", "_", "/", "_").Replace(msg.Para))
+	return ioutil.WriteFile(filepath.Join(a.Diretorio, nome), formatar(a.Remetente, msg), 0600)
+}
+
+// SMTP envia pelo servidor informado; em desenvolvimento pode apontar para um stub como o MailHog.
+type SMTP struct {
+	Endereco  string //host:porta
+	Usuario   string //vazio para servidores sem autenticação
+	Senha     string
+	Remetente string
+}
+
+func (s *SMTP) Enviar(ctx context.Context, msg Mensagem) error {
+	var autenticacao smtp.Auth
+	if s.Usuario != "" {
+		host := s.Endereco
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		autenticacao = smtp.PlainAuth("", s.Usuario, s.Senha, host)
+	}
+	return smtp.SendMail(s.Endereco, autenticacao, s.Remetente, []string{msg.Para}, formatar(s.Remetente, msg))
+}
@@ -2,16 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/krunal4amity/tronicscorp/auth"
 	"github.com/krunal4amity/tronicscorp/config"
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"github.com/krunal4amity/tronicscorp/escolapb"
 	"github.com/krunal4amity/tronicscorp/handlers"
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/krunal4amity/tronicscorp/mailer"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/openapi"
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/krunal4amity/tronicscorp/ratelimit"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
 )
 
 var (
@@ -22,6 +39,14 @@ var (
 	professoresCol *mongo.Collection
 	cursosCol      *mongo.Collection
 	disciplinasCol *mongo.Collection
+	sequenciasCol  *mongo.Collection
+	auditoriaCol   *mongo.Collection
+	versoesCol     *mongo.Collection
+	revogadosCol   *mongo.Collection
+	usuariosCol    *mongo.Collection
+	chavesAPICol   *mongo.Collection
+	importacoesCol *mongo.Collection
+	travasCol      *mongo.Collection
 	cfg            config.PropriedadesDB
 )
 
@@ -42,8 +67,25 @@ func init() {
 	professoresCol = db.Collection(cfg.ProfessoresCollection)
 	cursosCol = db.Collection(cfg.CursosCollection)
 	disciplinasCol = db.Collection(cfg.DisciplinasCollection)
+	sequenciasCol = db.Collection(cfg.SequenciasCollection)
+	auditoriaCol = db.Collection(cfg.AuditoriaCollection)
+	versoesCol = db.Collection(cfg.VersoesCollection)
+	revogadosCol = db.Collection(cfg.RevogadosCollection)
+	usuariosCol = db.Collection(cfg.UsuariosCollection)
+	chavesAPICol = db.Collection(cfg.ChavesAPICollection)
+	importacoesCol = db.Collection(cfg.ImportacoesCollection)
+	travasCol = db.Collection(cfg.TravasCollection)
 } //responsável pela conexão com a API
 
+func contem(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
+
 func mensagemServidor(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		fmt.Println("estamos dentro")
@@ -52,51 +94,463 @@ func mensagemServidor(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-func main() {
-	e := echo.New()
-	e.Logger.SetLevel(log.DEBUG)
-	e.Use(middleware.Logger())  // Logger
-	e.Use(middleware.Recover()) // Recover
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
-	}))
-	e.Pre(middleware.RemoveTrailingSlash())
-	e.Pre(mensagemServidor)
-	/*e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
-		Format: `$(time_rfc3339_nano) $(remote_ip) $(host) $(method) $(uri) $(user_agent) ` +
-			`$(status) $(error) $(latency_human)` + "\n",
-	}))*/
+// agendarPurga remove periodicamente os documentos que estão na lixeira há mais tempo que a retenção, até o
+// contexto ser cancelado, registrando cada remoção em auditoria. cols associa o nome de cada recurso à sua
+// coleção. Um intervalo que não é positivo é recusado: o laço rodaria sem pausa.
+func agendarPurga(ctx context.Context, retencao, intervalo time.Duration, cols map[string]dbiface.Collection, auditoria *handlers.Auditor) error {
+	if intervalo <= 0 {
+		return fmt.Errorf("the purge interval must be positive, got %v", intervalo)
+	}
+	if retencao < 0 {
+		return fmt.Errorf("the trash retention cannot be negative, got %v", retencao)
+	}
+	go func() {
+		ticker := time.NewTicker(intervalo)
+		defer ticker.Stop()
+		for {
+			for recurso, col := range cols {
+				n, err := handlers.PurgarLixeira(ctx, recurso, col, retencao, auditoria)
+				if err != nil {
+					log.Errorf("Unable to purge the trash of %s: %v", recurso, err)
+					continue
+				}
+				if n > 0 {
+					log.Infof("Purged %d documents from the trash of %s", n, recurso)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
 
-	h := &handlers.AlunosHandler{Col: alunosCol}
-	uh := &handlers.ProfessoresHandler{Col: professoresCol}
-	ah := &handlers.CursosHandler{Col: cursosCol}
-	oh := &handlers.DisciplinasHandler{Col: disciplinasCol}
+func novoMailer() mailer.Mailer {
+	if cfg.Mailer == "smtp" {
+		return &mailer.SMTP{Endereco: cfg.SMTPEndereco, Usuario: cfg.SMTPUsuario, Senha: cfg.SMTPSenha, Remetente: cfg.MailerRemetente}
+	}
+	return &mailer.Arquivo{Diretorio: cfg.MailerDiretorio, Remetente: cfg.MailerRemetente}
+}
+
+// servicoAuth monta o emissor de tokens com as chaves da configuração; contas são os usuários cadastrados
+func servicoAuth(contas auth.Credenciais) *auth.Servico {
+	chaves := make(map[string][]byte)
+	for kid, segredo := range cfg.JWTChaves {
+		chaves[kid] = []byte(segredo)
+	}
+	ativa := cfg.JWTChaveAtiva
+	if len(chaves) == 0 { //sem chaves configuradas os tokens só valem até o servidor reiniciar
+		log.Warn("JWT_CHAVES is not set, using a random signing key")
+		segredo := make([]byte, 32)
+		if _, err := rand.Read(segredo); err != nil {
+			log.Fatalf("Unable to generate the signing key: %v", err)
+		}
+		ativa = "efemera"
+		chaves[ativa] = segredo
+	}
+	if _, ok := chaves[ativa]; !ok {
+		log.Fatalf("JWT_CHAVE_ATIVA %q is not one of the keys in JWT_CHAVES", ativa)
+	}
+
+	return &auth.Servico{
+		Chaves:           chaves,
+		ChaveAtiva:       ativa,
+		Emissor:          cfg.JWTEmissor,
+		DuracaoAcesso:    cfg.JWTDuracaoAcesso,
+		DuracaoRenovacao: cfg.JWTDuracaoRenovacao,
+		Credenciais:      auth.Cadeia{auth.CredenciaisFixas{Usuario: cfg.AdminUsuario, Senha: cfg.AdminSenha}, contas},
+		Revogacoes:       &auth.Revogacoes{Col: revogadosCol, Validade: cfg.JWTDuracaoRenovacao},
+	}
+}
+
+// politicaExclusao lê uma política de exclusão da configuração; um valor desconhecido impede a inicialização
+func politicaExclusao(variavel, valor string) handlers.Politica {
+	p, err := handlers.ConferirPolitica(valor)
+	if err != nil {
+		log.Fatalf("%s: %v", variavel, err)
+	}
+	return p
+}
+
+// criarIndices cria os índices que as rotas esperam encontrar
+func criarIndices() {
+	//o índice TTL remove os tokens revogados quando eles expirariam de qualquer forma
+	_, err := revogadosCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expiraEm": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the TTL index for revoked tokens: %v", err)
+	}
+	_, err = chavesAPICol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"hash": 1}, //busca da chave a cada requisição de integração
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the index for API keys: %v", err)
+	}
+	//login e e-mail únicos entre os usuários ativos: os da lixeira têm deletedAt distintos e não conflitam
+	for _, campo := range []string{"login", "email"} {
+		_, err = usuariosCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys:    bson.D{{Key: campo, Value: 1}, {Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		})
+		if err != nil {
+			log.Errorf("Unable to create the unique index for the user %s: %v", campo, err)
+		}
+	}
+	//cada conta do provedor OIDC vincula no máximo um usuário ativo
+	_, err = usuariosCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "oidcEmissor", Value: 1}, {Key: "oidcSub", Value: 1}, {Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"oidcSub": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the unique index for the OIDC accounts: %v", err)
+	}
+	//o código do curso, referenciado por alunos, disciplinas e professores, é único entre os cursos ativos
+	_, err = cursosCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "codigo", Value: 1}, {Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the unique index for the course code: %v", err)
+	}
+	_, err = importacoesCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"criadaEm": 1}, //os relatórios de importação ficam disponíveis por 30 dias
+		Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the TTL index for imports: %v", err)
+	}
+	_, err = travasCol.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"expiraEm": 1}, //as travas vencidas também são descartadas ao tentar obtê-las
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Errorf("Unable to create the TTL index for locks: %v", err)
+	}
+}
+
+// migrarTelefones converte para E.164 os telefones dos cadastros anteriores à validação
+func migrarTelefones() {
+	if cfg.TelefoneDDDPadrao != "" && (len(cfg.TelefoneDDDPadrao) != 2 || cfg.TelefoneDDDPadrao[0] == '0') {
+		log.Fatalf("TELEFONE_DDD_PADRAO %q is not a valid area code", cfg.TelefoneDDDPadrao)
+	}
+	for recurso, col := range map[string]dbiface.Collection{"alunos": alunosCol, "professores": professoresCol} {
+		migrados, pendentes, err := handlers.MigrarTelefones(context.Background(), col, cfg.TelefoneDDDPadrao)
+		if err != nil {
+			log.Errorf("Unable to migrate the phone numbers of %s: %v", recurso, err)
+		}
+		if migrados > 0 || pendentes > 0 {
+			log.Infof("Migrated %d phone numbers of %s, %d left invalid", migrados, recurso, pendentes)
+		}
+	}
+}
+
+// registrarRotas configura a autenticação, os limites e todas as rotas da API. Toda rota registrada aqui
+// deve estar descrita em documentacao (openapi.go) e na política (permissoes.go). Devolve o servidor gRPC
+// com os serviços do cadastro escolar, cujos métodos são autorizados pelas rotas em rotasGRPC.
+func registrarRotas(e *echo.Echo) *grpc.Server {
+	seq := &handlers.GeradorSequencia{Col: sequenciasCol}
+	aud := &handlers.Auditor{Col: auditoriaCol}
+	ver := &handlers.Versionador{Col: versoesCol, Sequencias: seq}
+	ush := &handlers.UsuariosHandler{Col: usuariosCol, Auditoria: aud, Versoes: ver, Mailer: novoMailer(),
+		PoliticaSenha: handlers.PoliticaSenha{TamanhoMinimo: cfg.SenhaTamanhoMinimo},
+		MaxTentativas: cfg.LoginMaxTentativas, Bloqueio: cfg.LoginBloqueio,
+		ValidadeReset: cfg.ResetValidade, URLRedefinicao: cfg.ResetURL}
+
+	store := storeLimites()
+	svc := servicoAuth(ush)
+	ush.Revogacoes = svc.Revogacoes
+	svc.OIDC = provedorOIDC(ush)
+	kh := &handlers.ChavesAPIHandler{Col: chavesAPICol, Auditoria: aud, LimitePadrao: cfg.ChaveAPILimitePadrao}
+	svc.ChavesAPI, svc.Limites = kh, store
+	intermediarios := []echo.MiddlewareFunc{
+		//o limite por IP vem antes da autenticação para proteger o login
+		ratelimit.Middleware(limites("ip", cfg.LimiteIP, store, ratelimit.PorIP)),
+		svc.Middleware(rotasPublicas...),      //todas as demais rotas exigem um access token
+		politica.Middleware(rotasPublicas...), //e um papel autorizado na política (permissoes.go)
+		ratelimit.Middleware(limites("usuario", cfg.LimiteUsuario, store, func(c echo.Context) string {
+			ator, _ := c.Get(auth.ChaveAtor).(string)
+			return ator
+		})),
+	}
+	e.Use(intermediarios...)
+	e.POST("/auth/login", svc.Login)
+	e.POST("/auth/refresh", svc.Renovar)
+	e.POST("/auth/logout", svc.Logout)
+	if svc.OIDC != nil {
+		e.GET("/auth/oidc/login", svc.LoginOIDC)
+		e.GET("/auth/oidc/callback", svc.CallbackOIDC)
+	}
+	doc := documentacao(svc.OIDC != nil)
+	e.GET("/openapi.json", doc.Handler)
+	swagger := openapi.SwaggerUI(cfg.SwaggerUIDir)
+	if swagger == nil {
+		log.Warnf("Swagger UI not found in %s, /docs will load it from the CDN", cfg.SwaggerUIDir)
+	}
+	e.GET("/docs", openapi.Pagina("/openapi.json", swagger))
+	e.GET("/docs/:arquivo", openapi.Arquivos(swagger))
+
+	regras, err := handlers.CarregarRegras(cfg.RegrasArquivo, map[string]dbiface.Collection{
+		"alunos": alunosCol, "professores": professoresCol, "cursos": cursosCol, "disciplinas": disciplinasCol,
+	})
+	if err != nil {
+		log.Fatalf("Unable to load the business rules: %v", err)
+	}
+	regras.Travas = travasCol
+	imp := &handlers.Importador{Col: importacoesCol, LimiteSincrono: cfg.ImportacaoLimiteSincrono}
+	if n, err := imp.MarcarInterrompidas(context.Background()); err != nil {
+		log.Errorf("Unable to mark the interrupted imports as failed: %v", err)
+	} else if n > 0 {
+		log.Warnf("%d interrupted imports were marked as failed", n)
+	}
+	h := &handlers.AlunosHandler{Col: alunosCol, Auditoria: aud, Versoes: ver, Regras: regras, Importacoes: imp,
+		Matriculas: &handlers.GeradorNumero{Sequencias: seq, Prefixo: "matricula", Padrao: cfg.PadraoMatricula}}
+	uh := &handlers.ProfessoresHandler{Col: professoresCol, Auditoria: aud, Versoes: ver, Regras: regras, Importacoes: imp,
+		Registros: &handlers.GeradorNumero{Sequencias: seq, Prefixo: "registro", Padrao: cfg.PadraoRegistro}}
+	ah := &handlers.CursosHandler{Col: cursosCol, Auditoria: aud, Versoes: ver, Regras: regras, Relacoes: []handlers.Relacao{
+		{Recurso: "alunos", Col: alunosCol, Campo: "curso", Politica: politicaExclusao("POLITICA_CURSO_ALUNOS", cfg.PoliticaCursoAlunos), Auditoria: aud, Versoes: ver},
+		{Recurso: "alunos", Col: alunosCol, Campo: "cursos", Lista: true, Politica: handlers.Anular, Auditoria: aud, Versoes: ver}, //cursos além do principal
+		{Recurso: "disciplinas", Col: disciplinasCol, Campo: "curso", Politica: politicaExclusao("POLITICA_CURSO_DISCIPLINAS", cfg.PoliticaCursoDisciplinas), Auditoria: aud, Versoes: ver},
+	}}
+	oh := &handlers.DisciplinasHandler{Col: disciplinasCol, Auditoria: aud, Versoes: ver, Regras: regras, Importacoes: imp, Relacoes: []handlers.Relacao{
+		{Recurso: "professores", Col: professoresCol, Campo: "disciplinas", Lista: true,
+			Politica: politicaExclusao("POLITICA_DISCIPLINA_PROFESSORES", cfg.PoliticaDisciplinaProfessores), Auditoria: aud, Versoes: ver},
+	}}
 
 	e.POST("/alunos", h.InserirAluno, middleware.BodyLimit("1M"))
 	e.GET("/alunos", h.BuscarAlunos)
+	e.GET("/alunos/lixeira", h.BuscarLixeira)
 	e.GET("/alunos/:id", h.BuscarAluno)
 	e.PUT("/alunos/:id", h.AtualizarAluno, middleware.BodyLimit("1M"))
 	e.DELETE("/alunos/:id", h.DeletarAluno)
+	e.POST("/alunos/:id/restaurar", h.RestaurarAluno)
+	e.POST("/alunos/importar", h.ImportarAlunos, middleware.BodyLimit("20M"))
+	e.GET("/alunos/importar/:id", h.BuscarImportacao)
+	e.GET("/alunos/:id/historico-alteracoes", aud.HistoricoAlteracoes("alunos"))
+	e.GET("/alunos/:id/versoes", ver.ListarVersoes("alunos"))
+	e.POST("/alunos/:id/versoes/:v/restaurar", ver.RestaurarVersao("alunos", alunosCol, handlers.Alunos{}, regras, aud))
 
 	e.POST("/professores", uh.InserirProfessor, middleware.BodyLimit("1M"))
 	e.GET("/professores", uh.BuscarProfessores)
+	e.GET("/professores/lixeira", uh.BuscarLixeira)
 	e.GET("/professores/:id", uh.BuscarProfessor)
 	e.PUT("/professores/:id", uh.AtualizarProfessor, middleware.BodyLimit("1M"))
 	e.DELETE("/professores/:id", uh.DeletarProfessor)
+	e.POST("/professores/:id/restaurar", uh.RestaurarProfessor)
+	e.POST("/professores/importar", uh.ImportarProfessores, middleware.BodyLimit("20M"))
+	e.GET("/professores/importar/:id", uh.BuscarImportacao)
+	e.GET("/professores/:id/historico-alteracoes", aud.HistoricoAlteracoes("professores"))
+	e.GET("/professores/:id/versoes", ver.ListarVersoes("professores"))
+	e.POST("/professores/:id/versoes/:v/restaurar", ver.RestaurarVersao("professores", professoresCol, handlers.Professores{}, regras, aud))
 
 	e.POST("/cursos", ah.InserirCurso, middleware.BodyLimit("1M"))
 	e.GET("/cursos", ah.BuscarCursos)
+	e.GET("/cursos/lixeira", ah.BuscarLixeira)
 	e.GET("/cursos/:id", ah.BuscarCurso)
 	e.PUT("/cursos/:id", ah.AtualizarCurso, middleware.BodyLimit("1M"))
 	e.DELETE("/cursos/:id", ah.DeletarCurso)
+	e.POST("/cursos/:id/restaurar", ah.RestaurarCurso)
+	e.GET("/cursos/:id/historico-alteracoes", aud.HistoricoAlteracoes("cursos"))
+	e.GET("/cursos/:id/versoes", ver.ListarVersoes("cursos"))
+	e.POST("/cursos/:id/versoes/:v/restaurar", ver.RestaurarVersao("cursos", cursosCol, handlers.Cursos{}, regras, aud))
 
 	e.POST("/disciplinas", oh.InserirDisciplina, middleware.BodyLimit("1M"))
 	e.GET("/disciplinas", oh.BuscarDisciplinas)
+	e.GET("/disciplinas/lixeira", oh.BuscarLixeira)
 	e.GET("/disciplinas/:id", oh.BuscarDisciplina)
 	e.PUT("/disciplinas/:id", oh.AtualizarDisciplina, middleware.BodyLimit("1M"))
 	e.DELETE("/disciplinas/:id", oh.DeletarDisciplina)
+	e.POST("/disciplinas/:id/restaurar", oh.RestaurarDisciplina)
+	e.POST("/disciplinas/importar", oh.ImportarDisciplinas, middleware.BodyLimit("20M"))
+	e.GET("/disciplinas/importar/:id", oh.BuscarImportacao)
+	e.GET("/disciplinas/:id/historico-alteracoes", aud.HistoricoAlteracoes("disciplinas"))
+	e.GET("/disciplinas/:id/versoes", ver.ListarVersoes("disciplinas"))
+	e.POST("/disciplinas/:id/versoes/:v/restaurar", ver.RestaurarVersao("disciplinas", disciplinasCol, handlers.Disciplinas{}, regras, aud))
+
+	e.POST("/usuarios", ush.InserirUsuario, middleware.BodyLimit("1M"))
+	e.GET("/usuarios", ush.BuscarUsuarios)
+	e.GET("/usuarios/:id", ush.BuscarUsuario)
+	e.PUT("/usuarios/:id", ush.AtualizarUsuario, middleware.BodyLimit("1M"))
+	e.DELETE("/usuarios/:id", ush.DeletarUsuario)
+	e.GET("/usuarios/:id/historico-alteracoes", aud.HistoricoAlteracoes("usuarios"))
+	e.POST("/usuarios/eu/senha", ush.TrocarSenha)
+	e.POST("/usuarios/senha/esqueci", ush.EsqueciSenha)
+	e.POST("/usuarios/senha/redefinir", ush.RedefinirSenha)
+
+	e.POST("/chaves-api", kh.InserirChave, middleware.BodyLimit("1M"))
+	e.GET("/chaves-api", kh.BuscarChaves)
+	e.GET("/chaves-api/:id", kh.BuscarChave)
+	e.PUT("/chaves-api/:id", kh.AtualizarChave, middleware.BodyLimit("1M"))
+	e.DELETE("/chaves-api/:id", kh.DeletarChave)
+	e.GET("/chaves-api/:id/historico-alteracoes", aud.HistoricoAlteracoes("chaves-api"))
+	lh := &handlers.LGPDHandler{Usuarios: usuariosCol, Auditoria: aud, Versoes: ver, Revogacoes: svc.Revogacoes, Cols: map[string]dbiface.Collection{
+		"alunos": alunosCol, "professores": professoresCol, "usuarios": usuariosCol,
+	}}
+	e.GET("/lgpd/:recurso/:id/exportacao", lh.Exportar)
+	e.POST("/lgpd/:recurso/:id/anonimizacao", lh.Anonimizar)
+	e.GET("/auditoria", aud.BuscarAuditoria)
+
+	gh := &handlers.GraphQLHandler{Alunos: alunosCol, Professores: professoresCol, Cursos: cursosCol, Disciplinas: disciplinasCol}
+	e.POST("/graphql", gh.Consultar, middleware.BodyLimit("1M"))
+	e.GET("/graphql", gh.Esquema)
+
+	//o gRPC passa pelos mesmos middlewares, com cada método tratado como a rota equivalente em rotasGRPC
+	ponte := &handlers.PonteGRPC{Echo: e, Rotas: rotasGRPC, Intermediarios: intermediarios}
+	g := grpc.NewServer(grpc.ChainUnaryInterceptor(ponte.Unario), grpc.ChainStreamInterceptor(ponte.Fluxo))
+	escolapb.RegisterAlunosServer(g, &handlers.AlunosGRPC{H: h})
+	escolapb.RegisterProfessoresServer(g, &handlers.ProfessoresGRPC{H: uh})
+	escolapb.RegisterCursosServer(g, &handlers.CursosGRPC{H: ah})
+	escolapb.RegisterDisciplinasServer(g, &handlers.DisciplinasGRPC{H: oh})
+	return g
+}
+
+// storeLimites escolhe onde ficam os baldes dos limites de requisições
+func storeLimites() ratelimit.Store {
+	if cfg.LimiteStore == "redis" {
+		cliente := redis.NewClient(&redis.Options{Addr: cfg.RedisEndereco, Password: cfg.RedisSenha, DB: cfg.RedisDB,
+			PoolSize: cfg.RedisPool})
+		return ratelimit.NovoRedis(cliente, "smartschool:limite:")
+	}
+	return ratelimit.NovaMemoria()
+}
+
+// extratorIP só aceita X-Forwarded-For vindo dos proxies em PROXIES_CONFIAVEIS; sem proxies configurados vale
+// o endereço da conexão, para que um cliente não escolha o próprio IP e escape dos limites
+func extratorIP() echo.IPExtractor {
+	if len(cfg.ProxiesConfiaveis) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	opcoes := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, faixa := range cfg.ProxiesConfiaveis {
+		_, rede, err := net.ParseCIDR(faixa)
+		if err != nil {
+			log.Fatalf("Invalid trusted proxy range %q: %v", faixa, err)
+		}
+		opcoes = append(opcoes, echo.TrustIPRange(rede))
+	}
+	return echo.ExtractIPFromXFFHeader(opcoes...)
+}
+
+// limites converte a configuração "grupo:requisições" no limite padrão e nos limites por grupo de rotas
+func limites(nome string, porGrupo map[string]int, store ratelimit.Store, id ratelimit.Identificador) ratelimit.Config {
+	config := ratelimit.Config{Nome: nome, Store: store, Identificador: id, Grupos: make(map[string]ratelimit.Limite)}
+	for grupo, n := range porGrupo {
+		limite := ratelimit.Limite{Requisicoes: n, Periodo: cfg.LimitePeriodo}
+		if grupo == "padrao" {
+			config.Padrao = limite
+		} else {
+			config.Grupos[grupo] = limite
+		}
+	}
+	return config
+}
+
+// provedorOIDC configura o login pelo provedor de identidade; contas vincula o login a uma conta local
+func provedorOIDC(contas auth.Vinculador) *auth.OIDC {
+	if cfg.OIDCEmissor == "" {
+		return nil
+	}
+	return &auth.OIDC{
+		Emissor:      cfg.OIDCEmissor,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Escopos:      cfg.OIDCEscopos,
+		Audiencia:    cfg.OIDCAudiencia,
+		ClaimPapeis:  cfg.OIDCClaimPapeis,
+		MapaPapeis:   cfg.OIDCPapeis,
+		Vinculador:   contas,
+		Cliente:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func main() {
+	e := echo.New()
+	e.HTTPErrorHandler = problema.Tratador        //todas as respostas de erro em application/problem+json
+	e.Binder = &negociacao.Binder{}               //corpos em XML e MessagePack, além de JSON
+	e.JSONSerializer = negociacao.Serializador{}  //as tags xml dão os nomes na conversão para XML
+	e.IPExtractor = extratorIP()                  //o IP do cliente usado nos limites e na auditoria
+	i18n.Padrao = i18n.Negociar(cfg.IdiomaPadrao) //aceita também "pt" e variantes como "en-US"
+	e.Logger.SetLevel(log.DEBUG)
+	e.Use(middleware.Logger())    // Logger
+	e.Use(middleware.Recover())   // Recover
+	e.Use(middleware.RequestID()) // X-Request-ID, registrado na auditoria
+	if cfg.CORSCredenciais && contem(cfg.CORSOrigens, "*") {
+		log.Fatal("CORS_CREDENCIAIS requires explicit CORS_ORIGENS instead of *")
+	}
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins:     cfg.CORSOrigens,
+		AllowMethods:     cfg.CORSMetodos,
+		AllowHeaders:     cfg.CORSCabecalhos,
+		AllowCredentials: cfg.CORSCredenciais,
+		ExposeHeaders:    []string{echo.HeaderXRequestID, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+		MaxAge:           cfg.CORSMaxAge,
+	}))
+	e.Use(middleware.SecureWithConfig(middleware.SecureConfig{
+		XSSProtection:         "1; mode=block",
+		ContentTypeNosniff:    "nosniff",
+		XFrameOptions:         "DENY",
+		HSTSMaxAge:            cfg.HSTSMaxAge,
+		ContentSecurityPolicy: cfg.CSP,
+		ReferrerPolicy:        "no-referrer",
+	}))
+	e.Use(negociacao.Middleware) //respostas em XML ou MessagePack conforme o Accept
+	e.Pre(middleware.RemoveTrailingSlash())
+	e.Pre(mensagemServidor)
+	/*e.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Format: `$(time_rfc3339_nano) $(remote_ip) $(host) $(method) $(uri) $(user_agent) ` +
+			`$(status) $(error) $(latency_human)` + "\n",
+	}))*/
+
+	criarIndices()
+	migrarTelefones()
+	g := registrarRotas(e)
+
+	err := agendarPurga(context.Background(), cfg.RetencaoLixeira, cfg.IntervaloPurga, map[string]dbiface.Collection{
+		"alunos": alunosCol, "professores": professoresCol, "cursos": cursosCol, "disciplinas": disciplinasCol,
+		"usuarios": usuariosCol, "chaves-api": chavesAPICol,
+	}, &handlers.Auditor{Col: auditoriaCol})
+	if err != nil {
+		log.Fatalf("LIXEIRA_INTERVALO_PURGA or LIXEIRA_RETENCAO is invalid: %v", err)
+	}
+
+	var certificado *certificadoRecarregavel
+	if cfg.TLSCertificado != "" {
+		certificado, err = novoCertificadoRecarregavel(cfg.TLSCertificado, cfg.TLSChave, cfg.TLSRecarga)
+		if err != nil {
+			log.Fatalf("Unable to load the TLS certificate: %v", err)
+		}
+	}
+	if cfg.GRPCPort != "" {
+		go servirGRPC(g, fmt.Sprintf("%s:%s", cfg.Host, cfg.GRPCPort), certificado)
+	}
 
 	e.Logger.Print(fmt.Sprintf("Listening on port: %s", cfg.Port))
-	e.Logger.Fatal(e.Start(fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)))
+	endereco := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+	if certificado == nil {
+		e.Logger.Fatal(e.Start(endereco))
+	}
+	e.Logger.Fatal(e.StartServer(&http.Server{
+		Addr:      endereco,
+		TLSConfig: &tls.Config{GetCertificate: certificado.GetCertificate, MinVersion: tls.VersionTLS12},
+	}))
+}
+
+// servirGRPC atende o gRPC em endereco, com o mesmo certificado da API REST quando houver
+func servirGRPC(g *grpc.Server, endereco string, certificado *certificadoRecarregavel) {
+	lis, err := net.Listen("tcp", endereco)
+	if err != nil {
+		log.Fatalf("Unable to listen for gRPC: %v", err)
+	}
+	if certificado != nil {
+		lis = tls.NewListener(lis, &tls.Config{GetCertificate: certificado.GetCertificate, MinVersion: tls.VersionTLS12,
+			NextProtos: []string{"h2"}})
+	}
+	log.Infof("gRPC listening on: %s", endereco)
+	log.Fatal(g.Serve(lis))
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/krunal4amity/tronicscorp/client"
+	"github.com/labstack/echo/v4"
+)
+
+// TestDocumentacaoCobreRotas falha quando uma rota é registrada sem constar do documento OpenAPI, ou o
+// documento descreve uma rota que não existe.
+func TestDocumentacaoCobreRotas(t *testing.T) {
+	e := echo.New()
+	registrarRotas(e)
+	registradas := make(map[string]bool)
+	for _, r := range e.Routes() {
+		registradas[r.Method+" "+r.Path] = true
+	}
+	documentadas := make(map[string]bool)
+	for _, op := range documentacao(cfg.OIDCEmissor != "").Operacoes {
+		documentadas[op.Chave()] = true
+	}
+
+	var faltando, sobrando []string
+	for rota := range registradas {
+		if !documentadas[rota] {
+			faltando = append(faltando, rota)
+		}
+	}
+	for rota := range documentadas {
+		if !registradas[rota] {
+			sobrando = append(sobrando, rota)
+		}
+	}
+	sort.Strings(faltando)
+	sort.Strings(sobrando)
+	for _, rota := range faltando {
+		t.Errorf("route %s is not in the OpenAPI document (openapi.go)", rota)
+	}
+	for _, rota := range sobrando {
+		t.Errorf("the OpenAPI document describes %s, which is not registered", rota)
+	}
+}
+
+// TestClienteUsaRotasRegistradas chama todos os métodos exportados do pacote client contra um servidor que
+// só anota as requisições, e confere que cada uma corresponde a uma rota registrada.
+func TestClienteUsaRotasRegistradas(t *testing.T) {
+	e := echo.New()
+	registrarRotas(e)
+	registradas := make(map[string]bool)
+	for _, r := range e.Routes() {
+		registradas[r.Method+" "+r.Path] = true
+	}
+
+	var recebidas []*http.Request
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebidas = append(recebidas, r)
+		w.Write([]byte("null"))
+	}))
+	defer servidor.Close()
+
+	cli := reflect.ValueOf(&client.Cliente{URL: servidor.URL})
+	tipoContexto := reflect.TypeOf((*context.Context)(nil)).Elem()
+	for i := 0; i < cli.NumMethod(); i++ {
+		metodo, nome := cli.Method(i), cli.Type().Method(i).Name
+		argumentos := make([]reflect.Value, metodo.Type().NumIn())
+		for j := range argumentos {
+			switch tipo := metodo.Type().In(j); {
+			case tipo == tipoContexto:
+				argumentos[j] = reflect.ValueOf(context.Background())
+			case tipo.Kind() == reflect.String: //recurso dos métodos genéricos; serve de texto para os demais
+				argumentos[j] = reflect.ValueOf("alunos").Convert(tipo)
+			default:
+				argumentos[j] = reflect.Zero(tipo)
+			}
+		}
+		recebidas = nil
+		resultados := metodo.Call(argumentos)
+		if err, _ := resultados[len(resultados)-1].Interface().(error); err != nil {
+			t.Errorf("%s: %v", nome, err)
+		}
+		for _, r := range recebidas {
+			c := e.NewContext(r, nil)
+			e.Router().Find(r.Method, r.URL.Path, c)
+			if !registradas[r.Method+" "+c.Path()] {
+				t.Errorf("%s calls %s %s, which is not a registered route", nome, r.Method, r.URL.Path)
+			}
+		}
+	}
+}
+
+// TestRotasGRPC falha quando um método gRPC não tem rota equivalente em rotasGRPC, ou a rota não está
+// registrada ou não consta da política; sem ela a ponte negaria todas as chamadas ao método.
+func TestRotasGRPC(t *testing.T) {
+	e := echo.New()
+	g := registrarRotas(e)
+	registradas := make(map[string]bool)
+	for _, r := range e.Routes() {
+		registradas[r.Method+" "+r.Path] = true
+	}
+
+	var metodos []string
+	for servico, info := range g.GetServiceInfo() {
+		for _, m := range info.Methods {
+			metodos = append(metodos, "/"+servico+"/"+m.Name)
+		}
+	}
+	sort.Strings(metodos)
+	for _, metodo := range metodos {
+		rota, ok := rotasGRPC[metodo]
+		switch {
+		case !ok:
+			t.Errorf("gRPC method %s is not in rotasGRPC (permissoes.go)", metodo)
+		case !registradas[rota]:
+			t.Errorf("gRPC method %s maps to %s, which is not registered", metodo, rota)
+		case politica[rota] == nil:
+			t.Errorf("gRPC method %s maps to %s, which is not in the policy", metodo, rota)
+		}
+	}
+	if len(metodos) != len(rotasGRPC) {
+		t.Errorf("rotasGRPC has %d methods, the gRPC server has %d", len(rotasGRPC), len(metodos))
+	}
+}
+
+func TestExtratorIP(t *testing.T) {
+	requisicao := func(remoto string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoto + ":4000"
+		req.Header.Set(echo.HeaderXForwardedFor, "1.2.3.4")
+		return req
+	}
+	original := cfg.ProxiesConfiaveis
+	defer func() { cfg.ProxiesConfiaveis = original }()
+
+	cfg.ProxiesConfiaveis = nil
+	if ip := extratorIP()(requisicao("10.0.0.5")); ip != "10.0.0.5" {
+		t.Errorf("without trusted proxies X-Forwarded-For should be ignored, got %s", ip)
+	}
+	cfg.ProxiesConfiaveis = []string{"10.0.0.0/24"}
+	if ip := extratorIP()(requisicao("10.0.0.5")); ip != "1.2.3.4" {
+		t.Errorf("a trusted proxy should forward the client IP, got %s", ip)
+	}
+	if ip := extratorIP()(requisicao("192.168.0.9")); ip != "192.168.0.9" {
+		t.Errorf("an untrusted peer should not choose its IP, got %s", ip)
+	}
+}
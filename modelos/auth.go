@@ -0,0 +1,19 @@
+package modelos
+
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"` //segundos até o access token expirar
+}
+
+// RequisicaoLogin é o corpo de POST /auth/login.
+type RequisicaoLogin struct {
+	Usuario string `json:"usuario"`
+	Senha   string `json:"senha"`
+}
+
+// RequisicaoRenovacao é o corpo de POST /auth/refresh e POST /auth/logout.
+type RequisicaoRenovacao struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,124 @@
+// Package modelos reúne os documentos trocados com a API: o cadastro escolar, o histórico, os tokens e o
+// corpo das respostas de erro. Não depende do servidor, para que o pacote client e outros serviços em Go
+// usem os mesmos tipos sem importar handlers ou auth, que os reexportam.
+package modelos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Alunos struct {
+	ID primitive.ObjectID `json:"_id,omitempty" xml:"id" bson:"_id,omitempty"` /*onitempty serve para caso o
+	campo não tenha sido preenchido, não haverá nenhum valor padrão, será ignorado*/
+	Matricula  int        `json:"matricula" bson:"matricula"` //gerada na inserção quando não informada
+	Nome       string     `json:"nome" bson:"nome" validate:"required,max=20" lgpd:"anonimizar"`
+	Sobrenome  string     `json:"sobrenome" bson:"sobrenome" validate:"required,max=20" lgpd:"anonimizar"`
+	Telefone   Telefone   `json:"telefone,omitempty" bson:"telefone" validate:"required_without=AnonimizadoEm,omitempty,telefone" sensivel:"secretaria,proprio,vinculado" lgpd:"anonimizar,remover"`
+	Email      string     `json:"email,omitempty" bson:"email,omitempty" validate:"omitempty,email" sensivel:"secretaria,proprio,vinculado" lgpd:"anonimizar,remover"`
+	CPF        string     `json:"cpf,omitempty" bson:"cpf,omitempty" validate:"omitempty,cpf" sensivel:"secretaria,proprio,vinculado" lgpd:"anonimizar,remover"`
+	RG         string     `json:"rg,omitempty" bson:"rg,omitempty" validate:"omitempty,rg" sensivel:"secretaria,proprio,vinculado" lgpd:"anonimizar,remover"`
+	CEP        string     `json:"cep,omitempty" bson:"cep,omitempty" validate:"omitempty,cep" sensivel:"secretaria,proprio,vinculado" lgpd:"anonimizar,remover"`
+	Curso      int        `json:"curso,omitempty" bson:"curso,omitempty"`         //código do curso (Cursos.Codigo)
+	Cursos     []int      `json:"cursos,omitempty" bson:"cursos,omitempty"`       //outros cursos em andamento, além do principal
+	DeletadoEm *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` //preenchido quando está na lixeira
+	//preenchido pela anonimização (LGPD), que remove o telefone: o registro acadêmico segue editável sem ele
+	AnonimizadoEm *time.Time `json:"anonimizadoEm,omitempty" bson:"anonimizadoEm,omitempty"`
+}
+
+type Professores struct {
+	ID          primitive.ObjectID   `json:"_id,omitempty" xml:"id" bson:"_id,omitempty"`
+	Registro    int                  `json:"registro" bson:"registro"`
+	Nome        string               `json:"nome" bson:"nome" validate:"required,max=20" lgpd:"anonimizar"`
+	Sobrenome   string               `json:"sobrenome" bson:"sobrenome" validate:"required,max=20" lgpd:"anonimizar"`
+	Telefone    Telefone             `json:"telefone,omitempty" bson:"telefone" validate:"omitempty,telefone" sensivel:"secretaria,proprio" lgpd:"anonimizar,remover"`
+	Email       string               `json:"email,omitempty" bson:"email,omitempty" validate:"omitempty,email" sensivel:"secretaria,proprio" lgpd:"anonimizar,remover"`
+	CPF         string               `json:"cpf,omitempty" bson:"cpf,omitempty" validate:"omitempty,cpf" sensivel:"secretaria,proprio" lgpd:"anonimizar,remover"`
+	RG          string               `json:"rg,omitempty" bson:"rg,omitempty" validate:"omitempty,rg" sensivel:"secretaria,proprio" lgpd:"anonimizar,remover"`
+	CEP         string               `json:"cep,omitempty" bson:"cep,omitempty" validate:"omitempty,cep" sensivel:"secretaria,proprio" lgpd:"anonimizar,remover"`
+	Disciplinas []primitive.ObjectID `json:"disciplinas,omitempty" bson:"disciplinas,omitempty"` //disciplinas que leciona
+	DeletadoEm  *time.Time           `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`     //preenchido quando está na lixeira
+}
+
+type Cursos struct {
+	ID         primitive.ObjectID `json:"_id,omitempty" xml:"id" bson:"_id,omitempty"`
+	Codigo     int                `json:"codigo" bson:"codigo" validate:"gte=0"` //compõe a matrícula dos alunos do curso
+	Nome       string             `json:"nome" bson:"nome" validate:"required"`
+	Nivel      string             `json:"nivel,omitempty" bson:"nivel,omitempty"`         //por exemplo "tecnico" ou "graduacao"
+	DeletadoEm *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` //preenchido quando está na lixeira
+}
+
+type Disciplinas struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" xml:"id" bson:"_id,omitempty"`
+	Nome         string             `json:"nome" bson:"nome" validate:"required"`
+	CargaHoraria int                `json:"cargaHoraria" bson:"cargaHoraria" validate:"gte=0"`
+	Curso        int                `json:"curso,omitempty" bson:"curso,omitempty"`         //código do curso (Cursos.Codigo)
+	DeletadoEm   *time.Time         `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` //preenchido quando está na lixeira
+}
+
+// NormalizarTelefone converte as formas usuais de escrever um telefone para E.164: "(11) 98765-4321" e
+// 11987654321 viram "+5511987654321"; números com + ou 00 são tratados como internacionais. O que não for
+// reconhecido volta sem alteração, para o validador recusar.
+func NormalizarTelefone(telefone string) string {
+	texto := strings.TrimSpace(telefone)
+	digitos := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, texto)
+	switch {
+	case strings.HasPrefix(texto, "+"):
+		return "+" + digitos
+	case strings.HasPrefix(texto, "00") && len(digitos) > 2:
+		return "+" + digitos[2:]
+	case len(digitos) == 10 || len(digitos) == 11:
+		return "+55" + digitos
+	case (len(digitos) == 12 || len(digitos) == 13) && strings.HasPrefix(digitos, "55"):
+		return "+" + digitos
+	}
+	return texto
+}
+
+// Telefone é gravado como texto em E.164. Aceita números no JSON e no banco, onde cadastros antigos
+// guardavam o telefone como inteiro.
+type Telefone string
+
+func (t *Telefone) UnmarshalJSON(data []byte) error {
+	var texto string
+	if err := json.Unmarshal(data, &texto); err != nil {
+		var numero json.Number
+		if err := json.Unmarshal(data, &numero); err != nil {
+			return fmt.Errorf("telefone must be a string or a number")
+		}
+		texto = numero.String()
+	}
+	*t = Telefone(NormalizarTelefone(texto))
+	return nil
+}
+
+func (t *Telefone) UnmarshalBSONValue(tipo bsontype.Type, data []byte) error {
+	valor := bson.RawValue{Type: tipo, Value: data}
+	switch tipo {
+	case bsontype.String:
+		*t = Telefone(NormalizarTelefone(valor.StringValue()))
+	case bsontype.Int32:
+		*t = Telefone(NormalizarTelefone(strconv.Itoa(int(valor.Int32()))))
+	case bsontype.Int64:
+		*t = Telefone(NormalizarTelefone(strconv.FormatInt(valor.Int64(), 10)))
+	case bsontype.Double:
+		*t = Telefone(NormalizarTelefone(strconv.FormatFloat(valor.Double(), 'f', 0, 64)))
+	case bsontype.Null, bsontype.Undefined:
+		*t = ""
+	default:
+		return fmt.Errorf("cannot decode %v into a Telefone", tipo)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package modelos
+
+import "testing"
+
+func TestNormalizarTelefone(t *testing.T) {
+	casos := map[string]string{
+		"(11) 98765-4321":   "+5511987654321",
+		"11987654321":       "+5511987654321",
+		" 11 3333-4444 ":    "+551133334444",
+		"55 11 98765-4321":  "+5511987654321",
+		"+1 (415) 555-2671": "+14155552671",
+		"0044 20 7946 0958": "+442079460958",
+		"98765-4321":        "98765-4321", //sem DDD não é adivinhado: o validador recusa
+		"ramal 12":          "ramal 12",
+		"":                  "",
+	}
+	for entrada, esperado := range casos {
+		if obtido := NormalizarTelefone(entrada); obtido != esperado {
+			t.Errorf("NormalizarTelefone(%q) = %q, want %q", entrada, obtido, esperado)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package modelos
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Auditoria é uma entrada do histórico de escritas. A coleção é somente de inserção: nenhuma rota altera ou
+// remove entradas.
+type Auditoria struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Recurso     string             `json:"recurso" bson:"recurso"`
+	DocumentoID primitive.ObjectID `json:"documentoId" bson:"documentoId"`
+	Operacao    string             `json:"operacao" bson:"operacao"`
+	Ator        string             `json:"ator" bson:"ator"`
+	IP          string             `json:"ip" bson:"ip"`
+	RequestID   string             `json:"requestId" bson:"requestId"`
+	Data        time.Time          `json:"data" bson:"data"`
+	Antes       bson.M             `json:"antes,omitempty" bson:"antes,omitempty"`
+	Depois      bson.M             `json:"depois,omitempty" bson:"depois,omitempty"`
+	Alteracoes  []Alteracao        `json:"alteracoes,omitempty" bson:"alteracoes,omitempty"`
+}
+
+type Alteracao struct {
+	Campo  string      `json:"campo" bson:"campo"`
+	Antes  interface{} `json:"antes,omitempty" bson:"antes,omitempty"`
+	Depois interface{} `json:"depois,omitempty" bson:"depois,omitempty"`
+}
+
+// Versoes guarda o documento completo como ficou depois de cada escrita, numerado a partir de 1.
+type Versoes struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Recurso     string             `json:"recurso" bson:"recurso"`
+	DocumentoID primitive.ObjectID `json:"documentoId" bson:"documentoId"`
+	Versao      int64              `json:"versao" bson:"versao"`
+	Operacao    string             `json:"operacao" bson:"operacao"`
+	Ator        string             `json:"ator" bson:"ator"`
+	Data        time.Time          `json:"data" bson:"data"`
+	Documento   bson.M             `json:"documento" bson:"documento"`
+}
+
+// Politica diz o que acontece com os dependentes na exclusão de um curso ou disciplina.
+type Politica string
+
+const (
+	Restringir Politica = "restrict" //a exclusão é recusada com 409 enquanto houver dependentes
+	Cascata    Politica = "cascade"  //os dependentes também vão para a lixeira
+	Anular     Politica = "nullify"  //a referência é removida dos dependentes
+)
+
+type Dependentes struct {
+	Recurso  string               `json:"recurso"`
+	Politica Politica             `json:"politica"`
+	IDs      []primitive.ObjectID `json:"ids"`
+}
+
+type RelatorioExclusao struct {
+	Permitido   bool          `json:"permitido"`
+	Dependentes []Dependentes `json:"dependentes"`
+}
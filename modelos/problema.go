@@ -0,0 +1,61 @@
+package modelos
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Problema é o corpo problem+json das respostas de erro, como os clientes o recebem: os textos já vêm no
+// idioma pedido e Codigo identifica a falha sem depender deles. Membros próprios do erro, como os
+// dependentes que impedem uma exclusão, ficam em Extensoes.
+type Problema struct {
+	Tipo      string
+	Titulo    string
+	Status    int
+	Detalhe   string
+	Instancia string
+	Codigo    string
+	RequestID string
+	Erros     []ErroCampo
+	Extensoes map[string]interface{}
+}
+
+// ErroCampo aponta o campo do payload que não passou em uma regra de validação.
+type ErroCampo struct {
+	Campo    string `json:"campo"`
+	Regra    string `json:"regra"`
+	Mensagem string `json:"mensagem"`
+}
+
+func (p *Problema) Error() string {
+	return fmt.Sprintf("%s: %s", p.Codigo, p.Detalhe)
+}
+
+// UnmarshalJSON lê uma resposta problem+json; membros desconhecidos vão para Extensoes.
+func (p *Problema) UnmarshalJSON(dados []byte) error {
+	var corpo map[string]json.RawMessage
+	if err := json.Unmarshal(dados, &corpo); err != nil {
+		return err
+	}
+	*p = Problema{}
+	membros := map[string]interface{}{"type": &p.Tipo, "title": &p.Titulo, "status": &p.Status, "code": &p.Codigo,
+		"detail": &p.Detalhe, "instance": &p.Instancia, "requestId": &p.RequestID, "errors": &p.Erros}
+	for nome, valor := range corpo {
+		destino, ok := membros[nome]
+		if !ok {
+			var extensao interface{}
+			if err := json.Unmarshal(valor, &extensao); err != nil {
+				return err
+			}
+			if p.Extensoes == nil {
+				p.Extensoes = make(map[string]interface{})
+			}
+			p.Extensoes[nome] = extensao
+			continue
+		}
+		if err := json.Unmarshal(valor, destino); err != nil {
+			return fmt.Errorf("%s: %v", nome, err)
+		}
+	}
+	return nil
+}
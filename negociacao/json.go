@@ -0,0 +1,66 @@
+package negociacao
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// par é um membro de objeto JSON. Os objetos são lidos como listas de pares para manter a ordem dos campos
+// das structs, que o encoding/json segue e que os outros formatos devem repetir.
+type par struct {
+	chave string
+	valor interface{}
+}
+
+type objeto []par
+
+// lerJSON lê um documento JSON como nil, bool, json.Number, string, []interface{} ou objeto.
+func lerJSON(dados []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(dados))
+	d.UseNumber()
+	v, err := lerValorJSON(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return v, nil
+}
+
+func lerValorJSON(d *json.Decoder) (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := objeto{}
+		for d.More() {
+			chave, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			valor, err := lerValorJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, par{chave.(string), valor})
+		}
+		_, err = d.Token()
+		return obj, err
+	case json.Delim('['):
+		lista := []interface{}{}
+		for d.More() {
+			valor, err := lerValorJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			lista = append(lista, valor)
+		}
+		_, err = d.Token()
+		return lista, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,307 @@
+package negociacao
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// JSONParaMsgpack converte um documento JSON em MessagePack: inteiros ocupam o menor formato que os
+// comporta, os demais números vão como float64 e os objetos mantêm a ordem dos campos.
+func JSONParaMsgpack(dados []byte) ([]byte, error) {
+	v, err := lerJSON(dados)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := escreverMsgpack(&b, v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func escreverMsgpack(b *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			escreverInteiro(b, n)
+		} else if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			b.WriteByte(0xcf)
+			binary.Write(b, binary.BigEndian, n)
+		} else if f, err := v.Float64(); err == nil {
+			b.WriteByte(0xcb)
+			binary.Write(b, binary.BigEndian, math.Float64bits(f))
+		} else {
+			return err
+		}
+	case string:
+		escreverCabecalho(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		b.WriteString(v)
+	case []interface{}:
+		escreverCabecalho(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := escreverMsgpack(b, item); err != nil {
+				return err
+			}
+		}
+	case objeto:
+		escreverCabecalho(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, p := range v {
+			escreverMsgpack(b, p.chave)
+			if err := escreverMsgpack(b, p.valor); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected JSON value %T", v)
+	}
+	return nil
+}
+
+func escreverInteiro(b *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= math.MaxInt8:
+		b.WriteByte(byte(n)) //positive fixint
+	case n >= -32 && n < 0:
+		b.WriteByte(byte(int8(n))) //negative fixint
+	case n >= 0 && n <= math.MaxUint8:
+		b.Write([]byte{0xcc, byte(n)})
+	case n >= 0 && n <= math.MaxUint16:
+		b.WriteByte(0xcd)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		b.WriteByte(0xce)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	case n >= 0:
+		b.WriteByte(0xcf)
+		binary.Write(b, binary.BigEndian, uint64(n))
+	case n >= math.MinInt8:
+		b.Write([]byte{0xd0, byte(int8(n))})
+	case n >= math.MinInt16:
+		b.WriteByte(0xd1)
+		binary.Write(b, binary.BigEndian, int16(n))
+	case n >= math.MinInt32:
+		b.WriteByte(0xd2)
+		binary.Write(b, binary.BigEndian, int32(n))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, n)
+	}
+}
+
+// escreverCabecalho grava o tipo e o tamanho de textos, listas e mapas: a forma "fix" (fixo | tamanho)
+// até limiteFix e depois as formas de 8 (quando existe), 16 e 32 bits.
+func escreverCabecalho(b *bytes.Buffer, n int, fixo byte, limiteFix int, de8, de16, de32 byte) {
+	switch {
+	case n < limiteFix:
+		b.WriteByte(fixo | byte(n))
+	case de8 != 0 && n <= math.MaxUint8:
+		b.Write([]byte{de8, byte(n)})
+	case n <= math.MaxUint16:
+		b.WriteByte(de16)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(de32)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
+
+var errMsgpackTruncado = errors.New("truncated MessagePack data")
+
+// leitorMsgpack decodifica MessagePack a partir de um corpo já lido por inteiro; os tamanhos declarados são
+// conferidos com os bytes restantes antes de qualquer alocação.
+type leitorMsgpack struct {
+	dados []byte
+	pos   int
+}
+
+// MsgpackParaJSON converte um corpo MessagePack para JSON. As chaves dos mapas devem ser textos; bin vira
+// base64, como o encoding/json espera para []byte, e a extensão de data (-1) vira uma data RFC 3339.
+func MsgpackParaJSON(dados []byte) ([]byte, error) {
+	l := &leitorMsgpack{dados: dados}
+	v, err := l.valor(0)
+	if err != nil {
+		return nil, err
+	}
+	if l.pos != len(l.dados) {
+		return nil, errors.New("unexpected data after the MessagePack value")
+	}
+	return json.Marshal(v)
+}
+
+func (l *leitorMsgpack) bytes(n int) ([]byte, error) {
+	if n < 0 || n > len(l.dados)-l.pos {
+		return nil, errMsgpackTruncado
+	}
+	b := l.dados[l.pos : l.pos+n]
+	l.pos += n
+	return b, nil
+}
+
+func (l *leitorMsgpack) inteiro(n int) (uint64, error) {
+	b, err := l.bytes(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (l *leitorMsgpack) valor(profundidade int) (interface{}, error) {
+	if profundidade > profundidadeMaxima {
+		return nil, errors.New("the MessagePack document is nested too deeply")
+	}
+	cab, err := l.bytes(1)
+	if err != nil {
+		return nil, err
+	}
+	c := cab[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return l.mapa(int(c&0x0f), profundidade)
+	case c >= 0x90 && c <= 0x9f:
+		return l.lista(int(c&0x0f), profundidade)
+	case c >= 0xa0 && c <= 0xbf:
+		return l.texto(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6: //bin 8, 16 e 32
+		n, err := l.inteiro(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return l.bytes(int(n))
+	case 0xc7, 0xc8, 0xc9: //ext 8, 16 e 32
+		n, err := l.inteiro(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return l.extensao(int(n))
+	case 0xca:
+		n, err := l.inteiro(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := l.inteiro(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return l.inteiro(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		tamanho := 1 << (c - 0xd0)
+		n, err := l.inteiro(tamanho)
+		deslocamento := uint(64 - 8*tamanho) //estende o sinal
+		return int64(n<<deslocamento) >> deslocamento, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: //fixext 1, 2, 4, 8 e 16
+		return l.extensao(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb: //str 8, 16 e 32
+		n, err := l.inteiro(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return l.texto(int(n))
+	case 0xdc, 0xdd:
+		n, err := l.inteiro(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return l.lista(int(n), profundidade)
+	case 0xde, 0xdf:
+		n, err := l.inteiro(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return l.mapa(int(n), profundidade)
+	}
+	return nil, fmt.Errorf("invalid MessagePack type 0x%02x", c)
+}
+
+func (l *leitorMsgpack) texto(n int) (interface{}, error) {
+	b, err := l.bytes(n)
+	return string(b), err
+}
+
+func (l *leitorMsgpack) lista(n, profundidade int) (interface{}, error) {
+	if n > len(l.dados)-l.pos { //cada item ocupa ao menos um byte
+		return nil, errMsgpackTruncado
+	}
+	lista := make([]interface{}, n)
+	for i := range lista {
+		v, err := l.valor(profundidade + 1)
+		if err != nil {
+			return nil, err
+		}
+		lista[i] = v
+	}
+	return lista, nil
+}
+
+func (l *leitorMsgpack) mapa(n, profundidade int) (interface{}, error) {
+	if n > (len(l.dados)-l.pos)/2 {
+		return nil, errMsgpackTruncado
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		chave, err := l.valor(profundidade + 1)
+		if err != nil {
+			return nil, err
+		}
+		texto, ok := chave.(string)
+		if !ok {
+			return nil, errors.New("MessagePack map keys must be strings")
+		}
+		if m[texto], err = l.valor(profundidade + 1); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// extensao aceita apenas a data (tipo -1) nas formas de 32, 64 e 96 bits.
+func (l *leitorMsgpack) extensao(n int) (interface{}, error) {
+	tipo, err := l.bytes(1)
+	if err != nil {
+		return nil, err
+	}
+	dados, err := l.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(tipo[0]) != -1 {
+		return nil, fmt.Errorf("unsupported MessagePack extension %d", int8(tipo[0]))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(dados)), 0).UTC(), nil
+	case 8:
+		v := binary.BigEndian.Uint64(dados)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(dados[4:])), int64(binary.BigEndian.Uint32(dados))).UTC(), nil
+	}
+	return nil, errors.New("invalid MessagePack timestamp")
+}
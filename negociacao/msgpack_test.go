@@ -0,0 +1,155 @@
+package negociacao
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// normalizar leva os números a int64, uint64 (só acima do int64) ou float64, para comparar o JSON com o
+// que o decodificador de referência devolve, que usa uint64 para os formatos sem sinal.
+func normalizar(v interface{}) interface{} {
+	switch v := v.(type) {
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return n
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizar(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = normalizar(v[k])
+		}
+	}
+	return v
+}
+
+func lerJSONTeste(t *testing.T, dados []byte) interface{} {
+	t.Helper()
+	d := json.NewDecoder(bytes.NewReader(dados))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		t.Fatalf("%v in %s", err, dados)
+	}
+	return normalizar(v)
+}
+
+// lerMsgpackTeste decodifica com a biblioteca vmihailenco/msgpack, que serve de referência.
+func lerMsgpackTeste(t *testing.T, dados []byte) interface{} {
+	t.Helper()
+	d := msgpack.NewDecoder(bytes.NewReader(dados))
+	d.UseLooseInterfaceDecoding(true)
+	v, err := d.DecodeInterface()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return normalizar(v)
+}
+
+func TestMsgpackInteiros(t *testing.T) {
+	dados, err := JSONParaMsgpack([]byte(`[1,-1,200,-200,70000,1.5]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := []byte{0x96, 0x01, 0xff, 0xcc, 0xc8, 0xd1, 0xff, 0x38, 0xce, 0x00, 0x01, 0x11, 0x70,
+		0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}
+	if !bytes.Equal(dados, esperado) {
+		t.Errorf("got % x, want % x", dados, esperado)
+	}
+}
+
+func TestMsgpackIdaEVolta(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	var campos []string
+	for i := 0; i < 17; i++ { //map 16
+		campos = append(campos, strconv.Quote("campo"+strconv.Itoa(i))+":"+strconv.Itoa(i))
+	}
+	documentos := []string{
+		`[0,127,128,-1,-32,-33,255,256,65535,65536,4294967295,4294967296,9223372036854775807,18446744073709551615,` +
+			`-128,-129,-32768,-32769,-2147483648,-2147483649,-9223372036854775808,1.5,-0.25,1e+300,0.1]`,
+		`{"_id":"` + id + `","outros":["` + id + `",null],"apelido":null,"ativo":false,"notas":[[7.5,[10,[]]],[],[[null]]]}`,
+		`{` + strings.Join(campos, ",") + `,"lista":[1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17]}`,              //array 16
+		`{"texto":"` + strings.Repeat("ação ", 60) + `","curto":"` + strings.Repeat("x", 31) + `","vazio":""}`, //str 16, fixstr 31
+		`null`,
+	}
+	for _, documento := range documentos {
+		empacotado, err := JSONParaMsgpack([]byte(documento))
+		if err != nil {
+			t.Fatalf("%.40s: %v", documento, err)
+		}
+		esperado := lerJSONTeste(t, []byte(documento))
+		if referencia := lerMsgpackTeste(t, empacotado); !reflect.DeepEqual(referencia, esperado) {
+			t.Errorf("%.40s: the reference decoder read %v", documento, referencia)
+		}
+		dados, err := MsgpackParaJSON(empacotado)
+		if err != nil {
+			t.Fatalf("%.40s: %v", documento, err)
+		}
+		if lido := lerJSONTeste(t, dados); !reflect.DeepEqual(lido, esperado) {
+			t.Errorf("%.40s: round trip gave %s", documento, dados)
+		}
+	}
+}
+
+func TestMsgpackDaReferencia(t *testing.T) {
+	id := primitive.NewObjectID().Hex()
+	dados, err := msgpack.Marshal(map[string]interface{}{
+		"_id":    id,
+		"nada":   nil,
+		"foto":   []byte{1, 2, 0xff},
+		"lista":  []interface{}{int8(-5), uint16(500), int64(-1 << 40), float32(0.5), []interface{}{"a", nil, true}},
+		"grande": uint64(18446744073709551615),
+		//as três formas da extensão de data: 32 bits, 64 bits (com nanossegundos) e 96 bits (antes de 1970)
+		"datas": []interface{}{time.Unix(1700000000, 0), time.Unix(1700000000, 123456789), time.Date(1960, 1, 2, 3, 4, 5, 6, time.UTC)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	corpo, err := MsgpackParaJSON(dados)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := `{"_id":"` + id + `","datas":["2023-11-14T22:13:20Z","2023-11-14T22:13:20.123456789Z","1960-01-02T03:04:05.000000006Z"],` +
+		`"foto":"AQL/","grande":18446744073709551615,"lista":[-5,500,-1099511627776,0.5,["a",null,true]],"nada":null}`
+	if string(corpo) != esperado {
+		t.Errorf("got\n%s\nwant\n%s", corpo, esperado)
+	}
+}
+
+func TestMsgpackInvalido(t *testing.T) {
+	casos := map[string][]byte{
+		"truncated":                     {0x92, 0x01},
+		"unexpected data":               {0x01, 0x02},
+		"keys must be strings":          {0x81, 0x01, 0x02},
+		"truncated MessagePack data":    {0xdd, 0xff, 0xff, 0xff, 0xff}, //4 bilhões de itens declarados
+		"nested too deeply":             append(bytes.Repeat([]byte{0x91}, profundidadeMaxima+2), 0xc0),
+		"unsupported":                   {0xd4, 0x05, 0x00},
+		"invalid MessagePack type":      {0xc1},
+		"invalid MessagePack timestamp": {0xd5, 0xff, 0x00, 0x00},
+	}
+	for erro, dados := range casos {
+		if _, err := MsgpackParaJSON(dados); err == nil || !strings.Contains(err.Error(), erro) {
+			t.Errorf("% x: got %v, want an error with %q", dados, err, erro)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package negociacao troca o JSON da API por XML ou MessagePack quando o cliente pede. As respostas saem em
+// JSON dos handlers e são convertidas na saída, conforme o Accept; os corpos em XML ou MessagePack são
+// convertidos para JSON na entrada, conforme o Content-Type. Assim as tags json das structs de handlers
+// continuam descrevendo os campos em qualquer formato: as chaves MessagePack são os nomes JSON, e os
+// elementos XML também, salvo nos campos com tag xml, que dá o nome do elemento.
+package negociacao
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	MIMEJSON    = "application/json"
+	MIMEXML     = "application/xml"
+	MIMEMsgpack = "application/msgpack"
+
+	//tipos das respostas de erro; o MessagePack não tem um tipo próprio para problemas
+	MIMEProblemaJSON = "application/problem+json"
+	MIMEProblemaXML  = "application/problem+xml"
+)
+
+// Alternativos são os tipos aceitos e produzidos no lugar do JSON.
+var Alternativos = []string{MIMEXML, MIMEMsgpack}
+
+// sinonimos associa os tipos aceitos no Accept e no Content-Type ao formato que a API usa.
+var sinonimos = map[string]string{
+	MIMEJSON: MIMEJSON, MIMEProblemaJSON: MIMEJSON, "text/json": MIMEJSON, "*/*": MIMEJSON, "application/*": MIMEJSON,
+	MIMEXML: MIMEXML, MIMEProblemaXML: MIMEXML, "text/xml": MIMEXML,
+	MIMEMsgpack: MIMEMsgpack, "application/x-msgpack": MIMEMsgpack, "application/vnd.msgpack": MIMEMsgpack,
+}
+
+// tipoBase tira os parâmetros do tipo de conteúdo: "application/json; charset=UTF-8" é "application/json".
+func tipoBase(tipo string) string {
+	return strings.ToLower(strings.TrimSpace(strings.SplitN(tipo, ";", 2)[0]))
+}
+
+// Negociar escolhe o formato da resposta a partir do Accept, respeitando os pesos (q); no empate vale o
+// tipo listado primeiro. Sem nenhum tipo conhecido, a resposta continua em JSON.
+func Negociar(accept string) string {
+	melhor, peso := MIMEJSON, 0.0
+	for _, item := range strings.Split(accept, ",") {
+		partes := strings.Split(item, ";")
+		q := 1.0
+		for _, parametro := range partes[1:] {
+			parametro = strings.TrimSpace(parametro)
+			if strings.HasPrefix(parametro, "q=") {
+				if valor, err := strconv.ParseFloat(parametro[2:], 64); err == nil {
+					q = valor
+				}
+			}
+		}
+		if formato, ok := sinonimos[tipoBase(partes[0])]; ok && q > peso {
+			melhor, peso = formato, q
+		}
+	}
+	return melhor
+}
+
+// Middleware converte as respostas JSON, inclusive as de erro, para o formato negociado. Respostas em outros
+// tipos (exportações, arquivos, HTML) passam sem alteração.
+func Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := c.Response()
+		r.Header().Add(echo.HeaderVary, echo.HeaderAccept)
+		formato := Negociar(c.Request().Header.Get(echo.HeaderAccept))
+		if formato == MIMEJSON {
+			return next(c)
+		}
+		conversor := &escritor{ResponseWriter: r.Writer, formato: formato}
+		r.Writer = conversor
+		defer func() { r.Writer = conversor.ResponseWriter }()
+		//o erro é tratado aqui para que a resposta de erro também passe pela conversão
+		if err := next(c); err != nil {
+			c.Error(err)
+		}
+		origem, _ := c.Get(chaveOrigem).(reflect.Type)
+		return conversor.concluir(origem)
+	}
+}
+
+// chaveOrigem guarda no contexto o tipo do valor que o handler serializou em JSON.
+const chaveOrigem = "negociacao.origem"
+
+// Serializador é o serializador JSON do Echo que anota o tipo de cada resposta, para que o Middleware use as
+// tags xml das structs ao converter para XML.
+type Serializador struct {
+	echo.DefaultJSONSerializer
+}
+
+func (s Serializador) Serialize(c echo.Context, i interface{}, indent string) error {
+	if _, ok := c.Get(chaveOrigem).(reflect.Type); !ok {
+		c.Set(chaveOrigem, reflect.TypeOf(i))
+	}
+	return s.DefaultJSONSerializer.Serialize(c, i, indent)
+}
+
+// Origem informa o tipo que descreve a resposta quando o handler serializa uma forma genérica dela, como os
+// mapas de uma projeção de campos: as tags xml do tipo continuam dando os nomes dos elementos.
+func Origem(c echo.Context, t reflect.Type) {
+	c.Set(chaveOrigem, t)
+}
+
+// escritor retém as respostas JSON até o fim do handler para convertê-las de uma vez; as demais seguem
+// direto para o cliente.
+type escritor struct {
+	http.ResponseWriter
+	formato  string
+	status   int
+	problema bool
+	retendo  bool
+	corpo    bytes.Buffer
+}
+
+func (e *escritor) WriteHeader(status int) {
+	switch tipoBase(e.Header().Get(echo.HeaderContentType)) {
+	case MIMEJSON:
+		e.status, e.retendo = status, true
+	case MIMEProblemaJSON:
+		e.status, e.retendo, e.problema = status, true, true
+	default:
+		e.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (e *escritor) Write(dados []byte) (int, error) {
+	if e.retendo {
+		return e.corpo.Write(dados)
+	}
+	return e.ResponseWriter.Write(dados)
+}
+
+func (e *escritor) Flush() {
+	if f, ok := e.ResponseWriter.(http.Flusher); ok && !e.retendo {
+		f.Flush()
+	}
+}
+
+// concluir converte e envia a resposta retida; origem é o tipo serializado pelo handler, quando conhecido.
+func (e *escritor) concluir(origem reflect.Type) error {
+	if !e.retendo {
+		return nil
+	}
+	var (
+		corpo []byte
+		err   error
+	)
+	tipo := e.formato
+	switch {
+	case e.corpo.Len() == 0: //HEAD e respostas sem corpo
+	case e.formato == MIMEXML && e.problema:
+		tipo = MIMEProblemaXML
+		corpo, err = JSONParaXML(e.corpo.Bytes(), nil, "problem", "urn:ietf:rfc:7807")
+	case e.formato == MIMEXML:
+		corpo, err = JSONParaXML(e.corpo.Bytes(), origem, "resposta", "")
+	default:
+		corpo, err = JSONParaMsgpack(e.corpo.Bytes())
+	}
+	if err != nil {
+		//o JSON foi gerado pela própria API; se não puder ser convertido, segue como está
+		tipo, corpo = e.Header().Get(echo.HeaderContentType), e.corpo.Bytes()
+	}
+	if tipo == MIMEXML || tipo == MIMEProblemaXML {
+		tipo += "; charset=UTF-8"
+	}
+	e.Header().Set(echo.HeaderContentType, tipo)
+	e.Header().Del(echo.HeaderContentLength)
+	e.ResponseWriter.WriteHeader(e.status)
+	_, err = e.ResponseWriter.Write(corpo)
+	return err
+}
+
+// CorpoJSON troca um corpo em XML ou MessagePack pelo JSON equivalente, para que a requisição siga pelo
+// mesmo caminho das requisições JSON. destino é o valor (ou ponteiro) em que o corpo será decodificado: o
+// XML não distingue números de textos, e o tipo de cada campo decide. Outros tipos de conteúdo ficam como
+// estão.
+func CorpoJSON(c echo.Context, destino interface{}) error {
+	req := c.Request()
+	formato := sinonimos[tipoBase(req.Header.Get(echo.HeaderContentType))]
+	if formato != MIMEXML && formato != MIMEMsgpack {
+		return nil
+	}
+	dados, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return problema.Novo(http.StatusBadRequest, problema.CodigoCorpoInvalido, "Unable to read the request body").HTTP()
+	}
+	var corpo []byte
+	if formato == MIMEXML {
+		if corpo, err = XMLParaJSON(dados, reflect.TypeOf(destino)); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Unable to parse the XML body")
+		}
+	} else if corpo, err = MsgpackParaJSON(dados); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Unable to parse the MessagePack body")
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(corpo))
+	req.ContentLength = int64(len(corpo))
+	req.Header.Set(echo.HeaderContentType, MIMEJSON)
+	return nil
+}
+
+// Binder é o echo.DefaultBinder com os corpos em XML e MessagePack convertidos por CorpoJSON.
+type Binder struct {
+	echo.DefaultBinder
+}
+
+func (b *Binder) Bind(i interface{}, c echo.Context) error {
+	if err := CorpoJSON(c, i); err != nil {
+		return err
+	}
+	return b.DefaultBinder.Bind(i, c)
+}
@@ -0,0 +1,106 @@
+package negociacao
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/krunal4amity/tronicscorp/problema"
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type cursoTeste struct {
+	ID     primitive.ObjectID `json:"_id,omitempty" xml:"id"`
+	Codigo int                `json:"codigo"`
+	Nome   string             `json:"nome"`
+	Notas  []*float64         `json:"notas"`
+}
+
+func TestNegociar(t *testing.T) {
+	casos := []struct{ accept, formato string }{
+		{"", MIMEJSON},
+		{"text/html, application/xml;q=0.9", MIMEXML},
+		{"application/xml;q=0.5, application/x-msgpack", MIMEMsgpack},
+		{"application/msgpack;q=0.2, */*;q=0.8", MIMEJSON},
+		{"APPLICATION/XML; charset=utf-8", MIMEXML},
+	}
+	for _, caso := range casos {
+		if obtido := Negociar(caso.accept); obtido != caso.formato {
+			t.Errorf("Negociar(%q) = %s, want %s", caso.accept, obtido, caso.formato)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Binder = &Binder{}
+	e.JSONSerializer = Serializador{}
+	e.HTTPErrorHandler = problema.Tratador
+	e.Use(Middleware)
+	nota := 9.5
+	id := primitive.NewObjectID()
+	e.POST("/cursos", func(c echo.Context) error {
+		var curso cursoTeste
+		if err := c.Bind(&curso); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, []cursoTeste{curso})
+	})
+	e.GET("/cursos", func(c echo.Context) error {
+		//uma projeção de campos responde com mapas, descritos pelo tipo da lista
+		Origem(c, reflect.TypeOf([]cursoTeste{}))
+		return c.JSON(http.StatusOK, []map[string]interface{}{{"_id": id.Hex(), "nome": "Redes"}})
+	})
+	pedir := func(metodo, tipo, accept string, corpo []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(metodo, "/cursos", bytes.NewReader(corpo))
+		req.Header.Set(echo.HeaderContentType, tipo)
+		req.Header.Set(echo.HeaderAccept, accept)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	corpo, err := msgpack.Marshal(map[string]interface{}{"_id": id.Hex(), "codigo": 3, "nome": "Redes", "notas": []interface{}{nil, nota}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := pedir(http.MethodPost, "application/x-msgpack", MIMEMsgpack, corpo)
+	if rec.Code != http.StatusCreated || rec.Header().Get(echo.HeaderContentType) != MIMEMsgpack {
+		t.Fatalf("got %d %s: %s", rec.Code, rec.Header().Get(echo.HeaderContentType), rec.Body)
+	}
+	resposta := lerMsgpackTeste(t, rec.Body.Bytes())
+	esperado := []interface{}{map[string]interface{}{"_id": id.Hex(), "codigo": int64(3), "nome": "Redes", "notas": []interface{}{nil, nota}}}
+	if !reflect.DeepEqual(resposta, esperado) {
+		t.Errorf("got %v, want %v", resposta, esperado)
+	}
+
+	rec = pedir(http.MethodPost, "text/xml", MIMEXML, []byte(`<curso><id>`+id.Hex()+`</id><codigo>3</codigo><nome>Redes</nome>`+
+		`<notas><item nil="true"/><item>9.5</item></notas></curso>`))
+	esperadoXML := `<resposta><item><id>` + id.Hex() + `</id><codigo>3</codigo><nome>Redes</nome><notas><item nil="true"/><item>9.5</item></notas></item></resposta>`
+	if rec.Code != http.StatusCreated || !strings.HasSuffix(rec.Body.String(), esperadoXML) {
+		t.Errorf("got %d %s", rec.Code, rec.Body)
+	}
+
+	rec = pedir(http.MethodGet, "", MIMEXML, nil)
+	if !strings.HasSuffix(rec.Body.String(), `<resposta><item><id>`+id.Hex()+`</id><nome>Redes</nome></item></resposta>`) {
+		t.Errorf("the projection should follow the xml tags of the described type: %s", rec.Body)
+	}
+
+	//os erros também são convertidos
+	rec = pedir(http.MethodPost, MIMEXML, MIMEXML, []byte(`<curso><codigo>`))
+	if rec.Code != http.StatusBadRequest || !strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), MIMEProblemaXML) ||
+		!strings.Contains(rec.Body.String(), "Unable to parse the XML body") {
+		t.Errorf("got %d %s", rec.Code, rec.Body)
+	}
+	rec = pedir(http.MethodPost, MIMEJSON, MIMEJSON, []byte(`{"_id":"`+id.Hex()+`","codigo":3}`))
+	var ida []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &ida); err != nil || len(ida) != 1 || ida[0]["_id"] != id.Hex() {
+		t.Errorf("JSON should pass unchanged: %s", rec.Body)
+	}
+}
@@ -0,0 +1,334 @@
+package negociacao
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// profundidadeMaxima limita o aninhamento dos corpos recebidos, contra documentos feitos para esgotar a pilha.
+const profundidadeMaxima = 64
+
+// JSONParaXML converte um documento JSON em XML: cada membro de objeto é um elemento com o nome do campo,
+// cada elemento de lista é um <item> e null é um elemento com nil="true". Campos cujo nome não é um nome XML
+// válido saem como <campo nome="...">. t é o tipo que gerou o JSON, quando conhecido: os campos de structs
+// com tag xml saem com o nome da tag.
+func JSONParaXML(dados []byte, t reflect.Type, raiz, namespace string) ([]byte, error) {
+	v, err := lerJSON(dados)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	escreverXML(&b, raiz, namespace, v, t)
+	return b.Bytes(), nil
+}
+
+func escreverXML(b *bytes.Buffer, nome, namespace string, v interface{}, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fechamento := nome
+	b.WriteByte('<')
+	if nomeXML(nome) {
+		b.WriteString(nome)
+	} else {
+		fechamento = "campo"
+		b.WriteString(`campo nome="`)
+		xml.EscapeText(b, []byte(nome))
+		b.WriteByte('"')
+	}
+	if namespace != "" {
+		b.WriteString(` xmlns="`)
+		xml.EscapeText(b, []byte(namespace))
+		b.WriteByte('"')
+	}
+	switch v := v.(type) {
+	case nil:
+		b.WriteString(` nil="true"/>`)
+		return
+	case objeto:
+		b.WriteByte('>')
+		var campos map[string]campo
+		if t != nil && t.Kind() == reflect.Struct {
+			campos = camposJSON(t)
+		}
+		for _, p := range v {
+			nome, tipo := p.chave, elemento(t)
+			if c, ok := campos[p.chave]; ok {
+				if c.xml == "-" {
+					continue
+				}
+				nome, tipo = c.xml, c.tipo
+			}
+			escreverXML(b, nome, "", p.valor, tipo)
+		}
+	case []interface{}:
+		b.WriteByte('>')
+		for _, item := range v {
+			escreverXML(b, "item", "", item, elemento(t))
+		}
+	case string:
+		b.WriteByte('>')
+		xml.EscapeText(b, []byte(v))
+	default:
+		fmt.Fprintf(b, ">%v", v)
+	}
+	b.WriteString("</" + fechamento + ">")
+}
+
+// elemento é o tipo dos itens de listas e mapas; nil para os demais tipos, inclusive os desconhecidos.
+func elemento(t reflect.Type) reflect.Type {
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+		return t.Elem()
+	}
+	return nil
+}
+
+// nomeXML diz se o nome de um campo pode ser usado como nome de elemento.
+func nomeXML(nome string) bool {
+	if nome == "" || strings.HasPrefix(strings.ToLower(nome), "xml") {
+		return false
+	}
+	for i, r := range nome {
+		if unicode.IsLetter(r) || r == '_' || (i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.')) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// no é um elemento XML recebido, ainda sem tipo.
+type no struct {
+	nome   string
+	nulo   bool
+	texto  string
+	filhos []*no
+}
+
+func lerNo(d *xml.Decoder, inicio xml.StartElement, profundidade int) (*no, error) {
+	if profundidade > profundidadeMaxima {
+		return nil, errors.New("the XML document is nested too deeply")
+	}
+	n := &no{nome: inicio.Name.Local}
+	for _, a := range inicio.Attr {
+		switch {
+		case a.Name.Local == "nil":
+			n.nulo = a.Value == "true"
+		case a.Name.Local == "nome" && n.nome == "campo":
+			n.nome = a.Value
+		}
+	}
+	var texto strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			filho, err := lerNo(d, tok, profundidade+1)
+			if err != nil {
+				return nil, err
+			}
+			n.filhos = append(n.filhos, filho)
+		case xml.CharData:
+			texto.Write(tok)
+		case xml.EndElement:
+			n.texto = texto.String()
+			return n, nil
+		}
+	}
+}
+
+// XMLParaJSON converte um documento no formato de JSONParaXML para JSON, consultando t (o tipo em que o
+// JSON será decodificado) para saber quais textos são números, booleanos ou listas. O nome da raiz é livre.
+func XMLParaJSON(dados []byte, t reflect.Type) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(dados))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New("the XML document is empty")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if inicio, ok := tok.(xml.StartElement); ok {
+			raiz, err := lerNo(d, inicio, 0)
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(valorXML(raiz, t))
+		}
+	}
+}
+
+var (
+	tipoUnmarshalerJSON  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	tipoUnmarshalerTexto = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	numeroJSON           = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// valorXML devolve o valor de n como o encoding/json o leria em um campo do tipo t. Textos que não
+// correspondem ao tipo seguem como texto, e o erro sai na decodificação do JSON, como em um corpo JSON
+// com o tipo errado.
+func valorXML(n *no, t reflect.Type) interface{} {
+	if n.nulo {
+		return nil
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return inferirXML(n)
+	}
+	//ObjectID, datas e tipos como Telefone são lidos do texto pelos próprios métodos
+	p := reflect.PtrTo(t)
+	if len(n.filhos) == 0 && (p.Implements(tipoUnmarshalerJSON) || p.Implements(tipoUnmarshalerTexto)) {
+		return n.texto
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return n.texto
+	case reflect.Bool:
+		switch strings.TrimSpace(n.texto) {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		return n.texto
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		if numero := strings.TrimSpace(n.texto); numeroJSON.MatchString(numero) {
+			return json.Number(numero)
+		}
+		return n.texto
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return strings.TrimSpace(n.texto) //base64, como no JSON
+		}
+		lista := make([]interface{}, len(n.filhos))
+		for i, filho := range n.filhos {
+			lista[i] = valorXML(filho, t.Elem())
+		}
+		return lista
+	case reflect.Map:
+		m := make(map[string]interface{}, len(n.filhos))
+		for _, filho := range n.filhos {
+			m[filho.nome] = valorXML(filho, t.Elem())
+		}
+		return m
+	case reflect.Struct:
+		porNomeXML := make(map[string]string)
+		campos := camposJSON(t)
+		for nome, c := range campos {
+			if c.xml != "-" {
+				porNomeXML[c.xml] = nome
+			}
+		}
+		m := make(map[string]interface{}, len(n.filhos))
+		for _, filho := range n.filhos {
+			nome, ok := porNomeXML[filho.nome]
+			if !ok {
+				if _, ok := campos[filho.nome]; ok {
+					continue //o nome JSON de um campo com outro nome XML, ou fora do XML
+				}
+				nome = filho.nome //campo desconhecido, que o encoding/json ignora como em um corpo JSON
+			}
+			m[nome] = valorXML(filho, campos[nome].tipo)
+		}
+		return m
+	}
+	return inferirXML(n)
+}
+
+// inferirXML lê os elementos sem tipo conhecido: só <item> como filhos é uma lista, outros filhos formam um
+// objeto e, sem filhos, o valor é o texto.
+func inferirXML(n *no) interface{} {
+	if n.nulo {
+		return nil
+	}
+	if len(n.filhos) == 0 {
+		return n.texto
+	}
+	lista := true
+	for _, filho := range n.filhos {
+		lista = lista && filho.nome == "item"
+	}
+	if lista {
+		itens := make([]interface{}, len(n.filhos))
+		for i, filho := range n.filhos {
+			itens[i] = inferirXML(filho)
+		}
+		return itens
+	}
+	m := make(map[string]interface{}, len(n.filhos))
+	for _, filho := range n.filhos {
+		m[filho.nome] = inferirXML(filho)
+	}
+	return m
+}
+
+// campo é um campo de struct como o encoding/json o vê, com o nome do elemento XML correspondente.
+type campo struct {
+	tipo reflect.Type
+	xml  string
+}
+
+// camposJSON associa os nomes JSON dos campos da struct aos seus tipos e nomes XML, incluindo os das structs
+// embutidas, que o encoding/json promove quando a struct externa não tem um campo com o mesmo nome. O nome
+// XML é o da tag xml (as opções, como attr, são ignoradas) ou, sem ela, o próprio nome JSON; "-" deixa o
+// campo fora do XML.
+func camposJSON(t reflect.Type) map[string]campo {
+	campos := make(map[string]campo)
+	promovidos := make(map[string]campo)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		nome := strings.Split(f.Tag.Get("json"), ",")[0]
+		if nome == "-" {
+			continue
+		}
+		if f.Anonymous && nome == "" {
+			embutido := f.Type
+			if embutido.Kind() == reflect.Ptr {
+				embutido = embutido.Elem()
+			}
+			if embutido.Kind() == reflect.Struct {
+				for nome, c := range camposJSON(embutido) {
+					promovidos[nome] = c
+				}
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if nome == "" {
+			nome = f.Name
+		}
+		nomeXML := strings.Split(f.Tag.Get("xml"), ",")[0]
+		if i := strings.LastIndexByte(nomeXML, ' '); i >= 0 { //"namespace nome"
+			nomeXML = nomeXML[i+1:]
+		}
+		if nomeXML == "" {
+			nomeXML = nome
+		}
+		campos[nome] = campo{f.Type, nomeXML}
+	}
+	for nome, c := range promovidos {
+		if _, ok := campos[nome]; !ok {
+			campos[nome] = c
+		}
+	}
+	return campos
+}
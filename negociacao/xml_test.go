@@ -0,0 +1,120 @@
+package negociacao
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type embutidoTeste struct {
+	Criado time.Time `json:"criado"`
+}
+
+type registroTeste struct {
+	ID        primitive.ObjectID   `json:"_id,omitempty" xml:"id"`
+	Nome      string               `json:"nome" xml:"nomeCompleto,omitempty"`
+	Idade     int                  `json:"idade"`
+	Saldo     float64              `json:"saldo"`
+	Grande    uint64               `json:"grande"`
+	Ativo     bool                 `json:"ativo"`
+	Apelido   *string              `json:"apelido"`
+	Notas     [][]float64          `json:"notas"`
+	Vazia     []int                `json:"vazia"`
+	Opcionais []*int               `json:"opcionais"`
+	Outros    []primitive.ObjectID `json:"outros"`
+	Pesos     map[string]int       `json:"pesos"`
+	Foto      []byte               `json:"foto"`
+	Interno   string               `json:"interno" xml:"-"`
+	embutidoTeste
+}
+
+func TestXMLIdaEVolta(t *testing.T) {
+	sete := 7
+	original := registroTeste{
+		ID:            primitive.NewObjectID(),
+		Nome:          "Zé <& Cia>",
+		Idade:         -3,
+		Saldo:         -12.75e-3,
+		Grande:        18446744073709551615,
+		Ativo:         true,
+		Notas:         [][]float64{{7.5, 10}, {}, {0}},
+		Vazia:         []int{},
+		Opcionais:     []*int{nil, &sete},
+		Outros:        []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()},
+		Pesos:         map[string]int{"prova": 2, "1ª nota": 1},
+		Foto:          []byte{0, 1, 0xff},
+		Interno:       "fora do XML",
+		embutidoTeste: embutidoTeste{time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+	}
+	dados, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tipo := reflect.TypeOf(original)
+	documento, err := JSONParaXML(dados, tipo, "resposta", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	texto := string(documento)
+	for _, trecho := range []string{"<id>" + original.ID.Hex() + "</id>", "<nomeCompleto>Zé &lt;&amp; Cia&gt;</nomeCompleto>",
+		`<apelido nil="true"/>`, `<item nil="true"/>`, `<campo nome="1ª nota">1</campo>`, "<grande>18446744073709551615</grande>"} {
+		if !strings.Contains(texto, trecho) {
+			t.Errorf("%s not found in\n%s", trecho, texto)
+		}
+	}
+	if strings.Contains(texto, "_id") || strings.Contains(texto, "<nome>") || strings.Contains(texto, "interno") {
+		t.Errorf("the xml tags should replace the JSON names:\n%s", texto)
+	}
+
+	dados, err = XMLParaJSON(documento, reflect.PtrTo(tipo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lido registroTeste
+	if err := json.Unmarshal(dados, &lido); err != nil {
+		t.Fatalf("%v in %s", err, dados)
+	}
+	original.Interno = ""
+	if !reflect.DeepEqual(lido, original) {
+		t.Errorf("got\n%+v\nwant\n%+v", lido, original)
+	}
+
+	//os nomes JSON dos campos renomeados e os campos fora do XML não são lidos
+	dados, err = XMLParaJSON([]byte(`<r><_id>x</_id><nome>a</nome><interno>b</interno><outro>1</outro></r>`), tipo)
+	if err != nil || string(dados) != `{"outro":"1"}` {
+		t.Errorf("got %s, %v", dados, err)
+	}
+}
+
+func TestXMLSemTipo(t *testing.T) {
+	documento, err := JSONParaXML([]byte(`{"a":[1,null,[2.5,[]],{"b":"x"}],"c":null,"d":{}}`), nil, "resposta", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := `<resposta><a><item>1</item><item nil="true"/><item><item>2.5</item><item></item></item><item><b>x</b></item></a>` +
+		`<c nil="true"/><d></d></resposta>`
+	if !strings.HasSuffix(string(documento), esperado) {
+		t.Errorf("got %s", documento)
+	}
+	//sem o tipo, os valores são textos e os elementos vazios também
+	dados, err := XMLParaJSON(documento, nil)
+	if err != nil || string(dados) != `{"a":["1",null,["2.5",""],{"b":"x"}],"c":null,"d":""}` {
+		t.Errorf("got %s, %v", dados, err)
+	}
+}
+
+func TestXMLInvalido(t *testing.T) {
+	profundo := strings.Repeat("<a>", profundidadeMaxima+2) + strings.Repeat("</a>", profundidadeMaxima+2)
+	for _, documento := range []string{"", "<a>", "<a></b>", profundo} {
+		if _, err := XMLParaJSON([]byte(documento), nil); err == nil {
+			t.Errorf("%.20q: no error", documento)
+		}
+	}
+	if _, err := JSONParaXML([]byte(`{"a":1}{`), nil, "resposta", ""); err == nil {
+		t.Error("invalid JSON: no error")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/krunal4amity/tronicscorp/auth"
+	"github.com/krunal4amity/tronicscorp/handlers"
+	"github.com/krunal4amity/tronicscorp/negociacao"
+	"github.com/krunal4amity/tronicscorp/openapi"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// cadastro descreve as rotas comuns a alunos, professores, cursos e disciplinas: inclusão em lote, consulta,
+// alteração, lixeira, histórico de alterações e versões. modelo é um valor da struct do recurso.
+func cadastro(recurso string, modelo interface{}, lista interface{}) []openapi.Operacao {
+	base, item := "/"+recurso, "/"+recurso+"/:id"
+	return []openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: base, Resumo: "Inclui um lote de " + recurso, Corpo: lista,
+			Resposta: []primitive.ObjectID{}, Status: http.StatusCreated},
+		{Metodo: http.MethodGet, Caminho: base, Resumo: "Lista " + recurso + " ativos, filtrando pelos campos da query string; " +
+			"ordem=nome,-matricula ordena e campos=nome,sobrenome projeta", Resposta: lista,
+			Consultas: []string{"limite", "pagina", "ordem", "campos"}, Exportavel: true},
+		{Metodo: http.MethodGet, Caminho: base + "/lixeira", Resumo: "Lista " + recurso + " na lixeira", Resposta: lista,
+			Consultas: []string{"limite", "pagina", "ordem", "campos"}, Exportavel: true},
+		{Metodo: http.MethodGet, Caminho: item, Resumo: "Busca um documento, ou o estado dele em asOf", Resposta: modelo,
+			Consultas: []string{"asOf"}},
+		{Metodo: http.MethodPut, Caminho: item, Resumo: "Altera um documento", Corpo: modelo, Resposta: modelo},
+		{Metodo: http.MethodDelete, Caminho: item, Resumo: "Move um documento para a lixeira", Resposta: int64(0)},
+		{Metodo: http.MethodPost, Caminho: item + "/restaurar", Resumo: "Restaura um documento da lixeira", Resposta: int64(0)},
+		{Metodo: http.MethodGet, Caminho: item + "/historico-alteracoes", Resumo: "Histórico de alterações do documento",
+			Resposta: []handlers.Auditoria{}, Exportavel: true},
+		{Metodo: http.MethodGet, Caminho: item + "/versoes", Resumo: "Versões completas do documento",
+			Resposta: []handlers.Versoes{}, Exportavel: true},
+		{Metodo: http.MethodPost, Caminho: item + "/versoes/:v/restaurar", Resumo: "Volta o documento para a versão v, " +
+			"validado como em um PUT; um documento na lixeira continua lá, a menos que sairDaLixeira=true",
+			Resposta: modelo, Consultas: []string{"sairDaLixeira"}},
+	}
+}
+
+// importacao descreve a importação de planilhas do recurso e a consulta do relatório.
+func importacao(recurso string) []openapi.Operacao {
+	return []openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/" + recurso + "/importar", Resumo: "Importa " + recurso + " de uma planilha CSV ou XLSX; " +
+			"com dryRun=true apenas valida. Planilhas grandes são processadas em segundo plano (202 com Location)",
+			Corpo: handlers.FormularioImportacao{}, TipoCorpo: "multipart/form-data", Resposta: handlers.Importacao{},
+			Consultas: []string{"dryRun"}},
+		{Metodo: http.MethodGet, Caminho: "/" + recurso + "/importar/:id", Resumo: "Andamento e relatório de uma importação",
+			Resposta: handlers.Importacao{}},
+	}
+}
+
+// ajustar altera as operações de ops identificadas pela chave ("GET /cursos").
+func ajustar(ops []openapi.Operacao, chave string, ajuste func(*openapi.Operacao)) []openapi.Operacao {
+	for i := range ops {
+		if ops[i].Chave() == chave {
+			ajuste(&ops[i])
+		}
+	}
+	return ops
+}
+
+func comStatus(status int) func(*openapi.Operacao) {
+	return func(op *openapi.Operacao) { op.Status = status }
+}
+
+func comSimulacao(op *openapi.Operacao) { //?dryRun=true devolve o relatório de dependentes sem excluir
+	op.Consultas = append(op.Consultas, "dryRun")
+	op.Resumo += "; com dryRun=true apenas informa os dependentes (RelatorioExclusao)"
+}
+
+// documentacao descreve todas as rotas registradas em registrarRotas. O teste em main_test.go falha quando
+// as duas listas divergem.
+func documentacao(oidc bool) *openapi.Documento {
+	var ops []openapi.Operacao
+	publica := func(grupo []openapi.Operacao) []openapi.Operacao {
+		for i := range grupo {
+			grupo[i].Publica = true
+		}
+		return grupo
+	}
+	comTag := func(tag string, grupo []openapi.Operacao) {
+		for i := range grupo {
+			grupo[i].Tag = tag
+		}
+		ops = append(ops, grupo...)
+	}
+
+	comTag("documentacao", publica([]openapi.Operacao{
+		{Metodo: http.MethodGet, Caminho: "/openapi.json", Resumo: "Este documento", Tipo: "application/json"},
+		{Metodo: http.MethodGet, Caminho: "/docs", Resumo: "Documentação navegável (Swagger UI)", Tipo: "text/html"},
+		{Metodo: http.MethodGet, Caminho: "/docs/:arquivo", Resumo: "Arquivos do Swagger UI", Tipo: "application/javascript"},
+	}))
+	autenticacao := publica([]openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/auth/login", Resumo: "Entra com usuário e senha",
+			Corpo: auth.RequisicaoLogin{}, Resposta: auth.Tokens{}},
+		{Metodo: http.MethodPost, Caminho: "/auth/refresh", Resumo: "Renova os tokens com o refresh token",
+			Corpo: auth.RequisicaoRenovacao{}, Resposta: auth.Tokens{}},
+	})
+	autenticacao = append(autenticacao, openapi.Operacao{Metodo: http.MethodPost, Caminho: "/auth/logout",
+		Resumo: "Revoga o access token e, se enviado, o refresh token", Corpo: auth.RequisicaoRenovacao{},
+		Status: http.StatusNoContent})
+	if oidc {
+		autenticacao = append(autenticacao, publica([]openapi.Operacao{
+			{Metodo: http.MethodGet, Caminho: "/auth/oidc/login", Resumo: "Redireciona para o provedor de identidade",
+				Status: http.StatusFound},
+			{Metodo: http.MethodGet, Caminho: "/auth/oidc/callback", Resumo: "Retorno do provedor de identidade",
+				Resposta: auth.Tokens{}, Consultas: []string{"code", "state", "error"}},
+		})...)
+	}
+	comTag("autenticacao", autenticacao)
+
+	comTag("alunos", append(cadastro("alunos", handlers.Alunos{}, []handlers.Alunos{}), importacao("alunos")...))
+	comTag("professores", append(cadastro("professores", handlers.Professores{}, []handlers.Professores{}), importacao("professores")...))
+	cursos := cadastro("cursos", handlers.Cursos{}, []handlers.Cursos{})
+	cursos = ajustar(cursos, "GET /cursos", comStatus(http.StatusCreated))
+	cursos = ajustar(cursos, "PUT /cursos/:id", comStatus(http.StatusCreated))
+	comTag("cursos", ajustar(cursos, "DELETE /cursos/:id", comSimulacao))
+	disciplinas := cadastro("disciplinas", handlers.Disciplinas{}, []handlers.Disciplinas{})
+	disciplinas = ajustar(disciplinas, "GET /disciplinas", comStatus(http.StatusCreated))
+	disciplinas = ajustar(disciplinas, "DELETE /disciplinas/:id", comSimulacao)
+	comTag("disciplinas", append(disciplinas, importacao("disciplinas")...))
+
+	usuarios := []openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/usuarios", Resumo: "Cria um usuário com a senha inicial",
+			Corpo: handlers.NovoUsuario{}, Resposta: primitive.ObjectID{}, Status: http.StatusCreated},
+		{Metodo: http.MethodGet, Caminho: "/usuarios", Resumo: "Lista os usuários", Resposta: []handlers.Usuarios{},
+			Consultas: []string{"login", "email", "papel"}, Exportavel: true},
+		{Metodo: http.MethodGet, Caminho: "/usuarios/:id", Resumo: "Busca um usuário", Resposta: handlers.Usuarios{}},
+		{Metodo: http.MethodPut, Caminho: "/usuarios/:id", Resumo: "Altera um usuário", Corpo: handlers.Usuarios{},
+			Resposta: handlers.Usuarios{}},
+		{Metodo: http.MethodDelete, Caminho: "/usuarios/:id", Resumo: "Desativa um usuário", Resposta: int64(0)},
+		{Metodo: http.MethodGet, Caminho: "/usuarios/:id/historico-alteracoes", Resumo: "Histórico de alterações do usuário",
+			Resposta: []handlers.Auditoria{}, Exportavel: true},
+		{Metodo: http.MethodPost, Caminho: "/usuarios/eu/senha", Resumo: "Troca a senha do usuário do token e revoga todos os tokens já emitidos para ele",
+			Corpo: handlers.TrocaSenha{}, Status: http.StatusNoContent},
+	}
+	usuarios = append(usuarios, publica([]openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/usuarios/senha/esqueci", Resumo: "Envia o e-mail de redefinição de senha",
+			Corpo: handlers.PedidoRedefinicao{}, Status: http.StatusAccepted},
+		{Metodo: http.MethodPost, Caminho: "/usuarios/senha/redefinir", Resumo: "Redefine a senha com o token do e-mail e revoga os tokens já emitidos",
+			Corpo: handlers.RedefinicaoSenha{}, Status: http.StatusNoContent},
+	})...)
+	comTag("usuarios", usuarios)
+
+	comTag("chaves-api", []openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/chaves-api", Resumo: "Cria uma chave de API; a chave só aparece nesta resposta",
+			Corpo: handlers.ChavesAPI{}, Resposta: handlers.ChaveCriada{}, Status: http.StatusCreated},
+		{Metodo: http.MethodGet, Caminho: "/chaves-api", Resumo: "Lista as chaves de API", Resposta: []handlers.ChavesAPI{},
+			Exportavel: true},
+		{Metodo: http.MethodGet, Caminho: "/chaves-api/:id", Resumo: "Busca uma chave de API", Resposta: handlers.ChavesAPI{}},
+		{Metodo: http.MethodPut, Caminho: "/chaves-api/:id", Resumo: "Altera nome, escopos, limite ou validade",
+			Corpo: handlers.ChavesAPI{}, Resposta: handlers.ChavesAPI{}},
+		{Metodo: http.MethodDelete, Caminho: "/chaves-api/:id", Resumo: "Revoga uma chave de API", Resposta: int64(0)},
+		{Metodo: http.MethodGet, Caminho: "/chaves-api/:id/historico-alteracoes", Resumo: "Histórico de alterações da chave",
+			Resposta: []handlers.Auditoria{}, Exportavel: true},
+	})
+	comTag("lgpd", []openapi.Operacao{
+		{Metodo: http.MethodGet, Caminho: "/lgpd/:recurso/:id/exportacao", Resumo: "Exporta os dados do titular em um zip",
+			Tipo: "application/zip"},
+		{Metodo: http.MethodPost, Caminho: "/lgpd/:recurso/:id/anonimizacao", Resumo: "Anonimiza o titular e as contas dele",
+			Resposta: bson.M{}},
+	})
+	comTag("graphql", []openapi.Operacao{
+		{Metodo: http.MethodPost, Caminho: "/graphql", Resumo: "Consulta GraphQL sobre o cadastro escolar e suas relações, só para secretaria e professores",
+			Corpo: bson.M{}, Resposta: bson.M{}},
+		{Metodo: http.MethodGet, Caminho: "/graphql", Resumo: "Esquema GraphQL em SDL", Tipo: "text/plain"},
+	})
+	comTag("auditoria", []openapi.Operacao{
+		{Metodo: http.MethodGet, Caminho: "/auditoria", Resumo: "Consulta o histórico de escritas", Resposta: []handlers.Auditoria{},
+			Consultas: []string{"recurso", "documentoId", "ator", "operacao", "requestId", "de", "ate", "limite"}, Exportavel: true},
+	})
+
+	return &openapi.Documento{
+		Titulo:       "SmartSchool API",
+		Versao:       "1.0.0",
+		Descricao:    "Cadastro escolar: alunos, professores, cursos e disciplinas. Erros seguem a RFC 7807.",
+		Operacoes:    ops,
+		Exportacoes:  handlers.TiposExportacao,
+		Alternativos: negociacao.Alternativos,
+	}
+}
@@ -0,0 +1,483 @@
+package openapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operacao descreve uma rota no documento OpenAPI. Corpo e Resposta são valores do tipo enviado e devolvido
+// (por exemplo []handlers.Alunos{}); os esquemas saem das tags json e validate desses tipos.
+type Operacao struct {
+	Metodo     string
+	Caminho    string //no formato do Echo, com :parametro
+	Resumo     string
+	Tag        string
+	Corpo      interface{}
+	TipoCorpo  string //tipo de conteúdo do corpo quando não é JSON, como "multipart/form-data"
+	Resposta   interface{}
+	Status     int      //status de sucesso, 200 quando zero
+	Tipo       string   //tipo de conteúdo da resposta quando não é JSON, como "application/zip"
+	Consultas  []string //parâmetros aceitos na query string
+	Publica    bool     //não exige token nem chave de API
+	Exportavel bool     //aceita ?format= e os tipos de Documento.Exportacoes no Accept
+}
+
+// Chave identifica a operação como nas rotas do Echo e na política de acesso: "GET /alunos/:id".
+func (o Operacao) Chave() string {
+	return o.Metodo + " " + o.Caminho
+}
+
+// Documento reúne as operações da API e gera o OpenAPI 3 servido em /openapi.json.
+type Documento struct {
+	Titulo    string
+	Versao    string
+	Descricao string
+	Operacoes []Operacao
+	//formatos de ?format= e seus tipos de conteúdo, nas operações exportáveis
+	Exportacoes map[string]string
+	//tipos aceitos e produzidos no lugar do JSON, com os mesmos esquemas
+	Alternativos []string
+
+	geracao sync.Once
+	gerado  []byte
+}
+
+// Gerar monta o documento OpenAPI 3.0.
+func (d *Documento) Gerar() map[string]interface{} {
+	g := &gerador{esquemas: map[string]interface{}{"Problema": esquemaProblema}}
+	caminhos := make(map[string]map[string]interface{})
+	for _, op := range d.Operacoes {
+		caminho, parametros := caminhoOpenAPI(op.Caminho)
+		if caminhos[caminho] == nil {
+			caminhos[caminho] = make(map[string]interface{})
+		}
+		for _, consulta := range op.Consultas {
+			parametros = append(parametros, map[string]interface{}{
+				"name": consulta, "in": "query", "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+		respostas := g.respostas(op)
+		d.comAlternativos(respostas[strconv.Itoa(statusSucesso(op))])
+		if op.Exportavel && len(d.Exportacoes) > 0 {
+			parametros = append(parametros, d.parametroFormato())
+			d.conteudosExportados(respostas)
+		}
+		operacao := map[string]interface{}{
+			"summary":     op.Resumo,
+			"operationId": idOperacao(op),
+			"responses":   respostas,
+		}
+		if op.Tag != "" {
+			operacao["tags"] = []string{op.Tag}
+		}
+		if len(parametros) > 0 {
+			operacao["parameters"] = parametros
+		}
+		if op.Corpo != nil {
+			tipo := op.TipoCorpo
+			if tipo == "" {
+				tipo = "application/json"
+			}
+			corpo := map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{tipo: map[string]interface{}{"schema": g.esquema(reflect.TypeOf(op.Corpo))}},
+			}
+			d.comAlternativos(corpo)
+			operacao["requestBody"] = corpo
+		}
+		if op.Publica {
+			operacao["security"] = []interface{}{}
+		}
+		caminhos[caminho][strings.ToLower(op.Metodo)] = operacao
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]interface{}{"title": d.Titulo, "version": d.Versao, "description": d.Descricao},
+		"paths":   caminhos,
+		"components": map[string]interface{}{
+			"schemas": g.esquemas,
+			"securitySchemes": map[string]interface{}{
+				"token":    map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"chaveAPI": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"token": []string{}}, map[string]interface{}{"chaveAPI": []string{}}},
+	}
+}
+
+// Handler atende GET /openapi.json. O documento é gerado na primeira requisição.
+func (d *Documento) Handler(c echo.Context) error {
+	d.geracao.Do(func() {
+		d.gerado, _ = json.Marshal(d.Gerar())
+	})
+	return c.JSONBlob(http.StatusOK, d.gerado)
+}
+
+// comAlternativos repete o esquema JSON de um corpo ou resposta para cada tipo alternativo.
+func (d *Documento) comAlternativos(corpo interface{}) {
+	m, _ := corpo.(map[string]interface{})
+	conteudo, _ := m["content"].(map[string]interface{})
+	if esquema, ok := conteudo["application/json"]; ok {
+		for _, tipo := range d.Alternativos {
+			conteudo[tipo] = esquema
+		}
+	}
+}
+
+// parametroFormato descreve ?format=, que escolhe o JSON de sempre ou um dos formatos de exportação.
+func (d *Documento) parametroFormato() map[string]interface{} {
+	formatos := []string{"json"}
+	for formato := range d.Exportacoes {
+		formatos = append(formatos, formato)
+	}
+	sort.Strings(formatos[1:])
+	return map[string]interface{}{
+		"name": "format", "in": "query", "schema": map[string]interface{}{"type": "string", "enum": formatos},
+	}
+}
+
+// conteudosExportados acrescenta os tipos de exportação à resposta de sucesso, ao lado do JSON.
+func (d *Documento) conteudosExportados(respostas map[string]interface{}) {
+	for status, resposta := range respostas {
+		if status == "default" {
+			continue
+		}
+		sucesso := resposta.(map[string]interface{})
+		conteudo, _ := sucesso["content"].(map[string]interface{})
+		if conteudo == nil {
+			conteudo = make(map[string]interface{})
+			sucesso["content"] = conteudo
+		}
+		for _, tipo := range d.Exportacoes {
+			conteudo[tipo] = map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}}
+		}
+	}
+}
+
+// caminhoOpenAPI converte "/alunos/:id" em "/alunos/{id}" e lista os parâmetros do caminho.
+func caminhoOpenAPI(caminho string) (string, []interface{}) {
+	var parametros []interface{}
+	partes := strings.Split(caminho, "/")
+	for i, parte := range partes {
+		if strings.HasPrefix(parte, ":") {
+			nome := parte[1:]
+			partes[i] = "{" + nome + "}"
+			parametros = append(parametros, map[string]interface{}{
+				"name": nome, "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	return strings.Join(partes, "/"), parametros
+}
+
+func idOperacao(op Operacao) string {
+	id := strings.ToLower(op.Metodo)
+	for _, parte := range strings.FieldsFunc(op.Caminho, func(r rune) bool { return r == '/' || r == '-' }) {
+		if strings.HasPrefix(parte, ":") {
+			parte = "por" + strings.Title(parte[1:])
+		}
+		id += strings.Title(parte)
+	}
+	return id
+}
+
+var esquemaProblema = map[string]interface{}{
+	"type":        "object",
+	"description": "Erro no formato RFC 7807 (application/problem+json)",
+	"properties": map[string]interface{}{
+		"type":      map[string]interface{}{"type": "string"},
+		"title":     map[string]interface{}{"type": "string"},
+		"status":    map[string]interface{}{"type": "integer"},
+		"detail":    map[string]interface{}{"type": "string"},
+		"instance":  map[string]interface{}{"type": "string"},
+		"code":      map[string]interface{}{"type": "string"},
+		"requestId": map[string]interface{}{"type": "string"},
+		"errors": map[string]interface{}{"type": "array", "items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"campo":    map[string]interface{}{"type": "string"},
+				"regra":    map[string]interface{}{"type": "string"},
+				"mensagem": map[string]interface{}{"type": "string"},
+			},
+		}},
+	},
+	"required": []string{"type", "title", "status", "code"},
+}
+
+type gerador struct {
+	esquemas map[string]interface{} //components/schemas, um por struct nomeada
+}
+
+func statusSucesso(op Operacao) int {
+	if op.Status == 0 {
+		return http.StatusOK
+	}
+	return op.Status
+}
+
+func (g *gerador) respostas(op Operacao) map[string]interface{} {
+	status := statusSucesso(op)
+	sucesso := map[string]interface{}{"description": http.StatusText(status)}
+	switch {
+	case op.Tipo != "":
+		sucesso["content"] = map[string]interface{}{op.Tipo: map[string]interface{}{}}
+	case op.Resposta != nil:
+		sucesso["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": g.esquema(reflect.TypeOf(op.Resposta))}}
+	}
+	return map[string]interface{}{
+		strconv.Itoa(status): sucesso,
+		"default": map[string]interface{}{
+			"description": "Erro",
+			"content":     map[string]interface{}{"application/problem+json": map[string]interface{}{"schema": referencia("Problema")}},
+		},
+	}
+}
+
+func referencia(nome string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + nome}
+}
+
+var (
+	tipoObjectID = reflect.TypeOf(primitive.ObjectID{})
+	tipoData     = reflect.TypeOf(time.Time{})
+)
+
+// esquema descreve um tipo Go como o encoding/json o serializa. Structs nomeadas viram componentes.
+func (g *gerador) esquema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case tipoObjectID:
+		return map[string]interface{}{"type": "string", "pattern": "^[0-9a-f]{24}$"}
+	case tipoData:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		esquema := g.esquema(t.Elem())
+		if _, ref := esquema["$ref"]; ref {
+			return esquema
+		}
+		copia := map[string]interface{}{"nullable": true}
+		for k, v := range esquema {
+			copia[k] = v
+		}
+		return copia
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 { //arquivos enviados em formulários
+			return map[string]interface{}{"type": "string", "format": "binary"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.esquema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.esquema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objeto(t)
+		}
+		if _, ok := g.esquemas[t.Name()]; !ok {
+			g.esquemas[t.Name()] = map[string]interface{}{} //reserva o nome antes, para tipos recursivos
+			g.esquemas[t.Name()] = g.objeto(t)
+		}
+		return referencia(t.Name())
+	}
+	return map[string]interface{}{} //interface{}: qualquer valor
+}
+
+func (g *gerador) objeto(t reflect.Type) map[string]interface{} {
+	propriedades := make(map[string]interface{})
+	var obrigatorios []string
+	g.campos(t, propriedades, &obrigatorios)
+	objeto := map[string]interface{}{"type": "object", "properties": propriedades}
+	if len(obrigatorios) > 0 {
+		objeto["required"] = obrigatorios
+	}
+	return objeto
+}
+
+// campos inclui as propriedades de t, achatando as structs embutidas como o encoding/json faz.
+func (g *gerador) campos(t reflect.Type, propriedades map[string]interface{}, obrigatorios *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		nome := strings.Split(tag, ",")[0]
+		if nome == "-" || (f.PkgPath != "" && !f.Anonymous) {
+			continue
+		}
+		if f.Anonymous && nome == "" && f.Type.Kind() == reflect.Struct {
+			g.campos(f.Type, propriedades, obrigatorios)
+			continue
+		}
+		if nome == "" {
+			nome = f.Name
+		}
+		esquema := g.esquema(f.Type)
+		if _, ref := esquema["$ref"]; !ref {
+			copia := make(map[string]interface{}, len(esquema))
+			for k, v := range esquema {
+				copia[k] = v
+			}
+			if regras(f.Tag.Get("validate"), copia) {
+				*obrigatorios = append(*obrigatorios, nome)
+			}
+			if nomeXML := strings.Split(f.Tag.Get("xml"), ",")[0]; nomeXML != "" && nomeXML != "-" && nomeXML != nome {
+				copia["xml"] = map[string]interface{}{"name": nomeXML} //o elemento XML segue a tag xml (pacote negociacao)
+			}
+			if papeis, ok := f.Tag.Lookup("sensivel"); ok {
+				copia["description"] = "Dado pessoal, mascarado exceto para: " + strings.Replace(papeis, ",", ", ", -1)
+			}
+			esquema = copia
+		} else if regras(f.Tag.Get("validate"), map[string]interface{}{}) {
+			*obrigatorios = append(*obrigatorios, nome)
+		}
+		propriedades[nome] = esquema
+	}
+}
+
+// padroes descreve as validações próprias da API (handlers/validators.go).
+var padroes = map[string]map[string]interface{}{
+	"cpf":      {"pattern": `^(\d{11}|\d{3}\.\d{3}\.\d{3}-\d{2})$`, "description": "CPF com dígitos verificadores válidos"},
+	"rg":       {"pattern": `^[0-9.\- ]{5,16}[0-9Xx]$`},
+	"cep":      {"pattern": `^\d{5}-?\d{3}$`},
+	"telefone": {"pattern": `^\+[1-9][0-9]{7,14}$`, "description": "E.164; números brasileiros sem +55 são normalizados"},
+	"email":    {"format": "email"},
+}
+
+// regras aplica as tags validate ao esquema e informa se o campo é obrigatório.
+func regras(validate string, esquema map[string]interface{}) bool {
+	obrigatorio := false
+	texto := esquema["type"] == "string"
+	for _, regra := range strings.Split(validate, ",") {
+		nome, parametro := regra, ""
+		if i := strings.Index(regra, "="); i >= 0 {
+			nome, parametro = regra[:i], regra[i+1:]
+		}
+		numero, _ := strconv.ParseFloat(parametro, 64)
+		switch nome {
+		case "required":
+			obrigatorio = true
+		case "max", "lte":
+			if texto {
+				esquema["maxLength"] = int(numero)
+			} else {
+				esquema["maximum"] = numero
+			}
+		case "min", "gte":
+			if texto {
+				esquema["minLength"] = int(numero)
+			} else {
+				esquema["minimum"] = numero
+			}
+		case "gt", "lt":
+			limite := map[string]string{"gt": "minimum", "lt": "maximum"}[nome]
+			esquema[limite] = numero
+			esquema["exclusive"+strings.Title(limite)] = true
+		case "oneof":
+			esquema["enum"] = strings.Fields(parametro)
+		default:
+			for k, v := range padroes[nome] {
+				esquema[k] = v
+			}
+		}
+	}
+	return obrigatorio
+}
+
+// VersaoSwaggerUI é a versão do swagger-ui-dist carregada do CDN; a instalada localmente deve ser a mesma
+// (npm install swagger-ui-dist@5.17.14).
+const VersaoSwaggerUI = "5.17.14"
+
+const cdn = "https://unpkg.com"
+
+// arquivosSwagger são os arquivos do swagger-ui-dist usados pela página, com o tipo de conteúdo de cada um.
+var arquivosSwagger = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "application/javascript; charset=utf-8",
+}
+
+// SwaggerUI devolve os arquivos do swagger-ui-dist instalados em dir, ou nil se algum deles faltar.
+func SwaggerUI(dir string) fs.FS {
+	arquivos := os.DirFS(dir)
+	for nome := range arquivosSwagger {
+		if _, err := fs.Stat(arquivos, nome); err != nil {
+			return nil
+		}
+	}
+	return arquivos
+}
+
+// Pagina atende a documentação navegável (Swagger UI) do documento em especificacao. Com arquivos (veja
+// SwaggerUI), a página os carrega de /docs/<arquivo> e a CSP só permite a própria origem; sem eles, os
+// arquivos vêm do CDN na VersaoSwaggerUI. Em ambos os casos a CSP padrão da API é trocada por uma que os
+// permite, com nonce para o script.
+func Pagina(especificacao string, arquivos fs.FS) echo.HandlerFunc {
+	fonte, base := cdn, cdn+"/swagger-ui-dist@"+VersaoSwaggerUI
+	if arquivos != nil {
+		fonte, base = "'self'", "/docs"
+	}
+	return func(c echo.Context) error {
+		aleatorio := make([]byte, 16)
+		if _, err := rand.Read(aleatorio); err != nil {
+			return err
+		}
+		nonce := base64.StdEncoding.EncodeToString(aleatorio)
+		c.Response().Header().Set("Content-Security-Policy", fmt.Sprintf("default-src 'none'; "+
+			"script-src 'nonce-%s' %s; style-src 'unsafe-inline' %s; img-src 'self' data: %s; connect-src 'self'; "+
+			"frame-ancestors 'none'", nonce, fonte, fonte, fonte))
+		return c.HTML(http.StatusOK, fmt.Sprintf(paginaSwagger, base, base, nonce, especificacao))
+	}
+}
+
+// Arquivos atende GET /docs/:arquivo com os arquivos do Swagger UI usados pela página.
+func Arquivos(arquivos fs.FS) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		tipo, ok := arquivosSwagger[c.Param("arquivo")]
+		if !ok || arquivos == nil {
+			return echo.ErrNotFound
+		}
+		dados, err := fs.ReadFile(arquivos, c.Param("arquivo"))
+		if err != nil {
+			return err
+		}
+		c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+		return c.Blob(http.StatusOK, tipo, dados)
+	}
+}
+
+const paginaSwagger = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>SmartSchool API</title>
+<link rel="stylesheet" href="%s/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="%s/swagger-ui-bundle.js"></script>
+<script nonce="%s">
+window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui"});
+</script>
+</body>
+</html>
+`
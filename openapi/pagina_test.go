@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestPagina(t *testing.T) {
+	local := fstest.MapFS{
+		"swagger-ui.css":       {Data: []byte("body{}")},
+		"swagger-ui-bundle.js": {Data: []byte("var SwaggerUIBundle;")},
+		"package.json":         {Data: []byte("{}")},
+	}
+	e := echo.New()
+	e.GET("/docs", Pagina("/openapi.json", local))
+	e.GET("/docs/:arquivo", Arquivos(local))
+	e.GET("/cdn", Pagina("/openapi.json", nil))
+	e.GET("/cdn/:arquivo", Arquivos(nil))
+	pedir := func(caminho string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, caminho, nil))
+		return rec
+	}
+
+	rec := pedir("/docs")
+	if csp := rec.Header().Get("Content-Security-Policy"); strings.Contains(csp, cdn) || strings.Contains(rec.Body.String(), cdn) {
+		t.Errorf("with local files the page should not load the CDN: %s", csp)
+	}
+	if !strings.Contains(rec.Body.String(), `src="/docs/swagger-ui-bundle.js"`) {
+		t.Errorf("the page should load the local bundle:\n%s", rec.Body)
+	}
+	if rec := pedir("/docs/swagger-ui-bundle.js"); rec.Code != http.StatusOK || rec.Body.String() != "var SwaggerUIBundle;" ||
+		!strings.HasPrefix(rec.Header().Get(echo.HeaderContentType), "application/javascript") {
+		t.Errorf("GET /docs/swagger-ui-bundle.js = %d %q", rec.Code, rec.Body)
+	}
+	if rec := pedir("/docs/package.json"); rec.Code != http.StatusNotFound {
+		t.Errorf("files the page does not use should not be served, got %d", rec.Code)
+	}
+
+	rec = pedir("/cdn")
+	if !strings.Contains(rec.Body.String(), cdn+"/swagger-ui-dist@"+VersaoSwaggerUI+"/swagger-ui-bundle.js") {
+		t.Errorf("without local files the page should load a pinned version from the CDN:\n%s", rec.Body)
+	}
+	if rec := pedir("/cdn/swagger-ui.css"); rec.Code != http.StatusNotFound {
+		t.Errorf("without local files the assets should not be found, got %d", rec.Code)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	if SwaggerUI(t.TempDir()) != nil {
+		t.Error("a directory without the swagger-ui-dist files should not be used")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import "github.com/krunal4amity/tronicscorp/auth"
+
+var (
+	secretaria = auth.Permitir(auth.PapelSecretaria)
+	leitura    = auth.Permitir(auth.PapelSecretaria, auth.PapelProfessor)
+	todos      = auth.Permitir(auth.PapelSecretaria, auth.PapelProfessor, auth.PapelAluno, auth.PapelResponsavel)
+
+	proprioAluno = auth.Permitir(auth.PapelAluno).
+			Se(auth.ProprioAluno("id"), "Students can only read their own record")
+	alunoVinculado = auth.Permitir(auth.PapelResponsavel).
+			Se(auth.AlunoVinculado("id"), "Guardians can only read the records of their linked students")
+)
+
+// rotasPublicas não exigem token.
+var rotasPublicas = []string{"/auth/login", "/auth/refresh", "/auth/oidc/login", "/auth/oidc/callback",
+	"/usuarios/senha/esqueci", "/usuarios/senha/redefinir", "/openapi.json", "/docs", "/docs/:arquivo"}
+
+// politica declara quem pode acessar cada rota registrada em main; rotas ausentes são negadas. A secretaria
+// gerencia tudo, professores consultam o cadastro escolar (notas e frequência ainda não têm rotas), alunos
+// veem apenas o próprio cadastro e responsáveis os alunos vinculados. Chaves de API usam escopos em vez de papéis.
+var politica = auth.Politica{
+	"POST /auth/logout":       {todos},
+	"POST /usuarios/eu/senha": {todos},
+
+	"POST /alunos":                               {secretaria},
+	"GET /alunos":                                {leitura},
+	"GET /alunos/lixeira":                        {secretaria},
+	"GET /alunos/:id":                            {leitura, proprioAluno, alunoVinculado},
+	"PUT /alunos/:id":                            {secretaria},
+	"DELETE /alunos/:id":                         {secretaria},
+	"POST /alunos/:id/restaurar":                 {secretaria},
+	"POST /alunos/importar":                      {secretaria},
+	"GET /alunos/importar/:id":                   {secretaria},
+	"GET /alunos/:id/historico-alteracoes":       {secretaria},
+	"GET /alunos/:id/versoes":                    {secretaria},
+	"POST /alunos/:id/versoes/:v/restaurar":      {secretaria},
+	"POST /professores":                          {secretaria},
+	"GET /professores":                           {leitura},
+	"GET /professores/lixeira":                   {secretaria},
+	"GET /professores/:id":                       {leitura},
+	"PUT /professores/:id":                       {secretaria},
+	"DELETE /professores/:id":                    {secretaria},
+	"POST /professores/:id/restaurar":            {secretaria},
+	"POST /professores/importar":                 {secretaria},
+	"GET /professores/importar/:id":              {secretaria},
+	"GET /professores/:id/historico-alteracoes":  {secretaria},
+	"GET /professores/:id/versoes":               {secretaria},
+	"POST /professores/:id/versoes/:v/restaurar": {secretaria},
+	"POST /cursos":                               {secretaria},
+	"GET /cursos":                                {leitura},
+	"GET /cursos/lixeira":                        {secretaria},
+	"GET /cursos/:id":                            {leitura},
+	"PUT /cursos/:id":                            {secretaria},
+	"DELETE /cursos/:id":                         {secretaria},
+	"POST /cursos/:id/restaurar":                 {secretaria},
+	"GET /cursos/:id/historico-alteracoes":       {secretaria},
+	"GET /cursos/:id/versoes":                    {secretaria},
+	"POST /cursos/:id/versoes/:v/restaurar":      {secretaria},
+	"POST /disciplinas":                          {secretaria},
+	"GET /disciplinas":                           {leitura},
+	"GET /disciplinas/lixeira":                   {secretaria},
+	"GET /disciplinas/:id":                       {leitura},
+	"PUT /disciplinas/:id":                       {secretaria},
+	"DELETE /disciplinas/:id":                    {secretaria},
+	"POST /disciplinas/:id/restaurar":            {secretaria},
+	"POST /disciplinas/importar":                 {secretaria},
+	"GET /disciplinas/importar/:id":              {secretaria},
+	"GET /disciplinas/:id/historico-alteracoes":  {secretaria},
+	"GET /disciplinas/:id/versoes":               {secretaria},
+	"POST /disciplinas/:id/versoes/:v/restaurar": {secretaria},
+	"POST /usuarios":                             {secretaria},
+	"GET /usuarios":                              {secretaria},
+	"GET /usuarios/:id":                          {secretaria},
+	"PUT /usuarios/:id":                          {secretaria},
+	"DELETE /usuarios/:id":                       {secretaria},
+	"GET /usuarios/:id/historico-alteracoes":     {secretaria},
+	"POST /chaves-api":                           {secretaria},
+	"GET /chaves-api":                            {secretaria},
+	"GET /chaves-api/:id":                        {secretaria},
+	"PUT /chaves-api/:id":                        {secretaria},
+	"DELETE /chaves-api/:id":                     {secretaria},
+	"GET /chaves-api/:id/historico-alteracoes":   {secretaria},
+	"GET /lgpd/:recurso/:id/exportacao":          {secretaria},
+	"POST /lgpd/:recurso/:id/anonimizacao":       {secretaria},
+	"GET /auditoria":                             {secretaria},
+	"POST /graphql":                              {leitura}, //sem filtro por titular, alunos e responsáveis usam o REST
+	"GET /graphql":                               {leitura},
+}
+
+// rotasGRPC associa cada método gRPC à rota REST equivalente, cuja política e limites valem para a chamada;
+// métodos ausentes são negados.
+var rotasGRPC = map[string]string{
+	"/smartschool.v1.Alunos/InserirAluno":   "POST /alunos",
+	"/smartschool.v1.Alunos/BuscarAlunos":   "GET /alunos",
+	"/smartschool.v1.Alunos/ExportarAlunos": "GET /alunos",
+	"/smartschool.v1.Alunos/BuscarLixeira":  "GET /alunos/lixeira",
+	"/smartschool.v1.Alunos/BuscarAluno":    "GET /alunos/:id",
+	"/smartschool.v1.Alunos/AtualizarAluno": "PUT /alunos/:id",
+	"/smartschool.v1.Alunos/DeletarAluno":   "DELETE /alunos/:id",
+	"/smartschool.v1.Alunos/RestaurarAluno": "POST /alunos/:id/restaurar",
+
+	"/smartschool.v1.Professores/InserirProfessor":    "POST /professores",
+	"/smartschool.v1.Professores/BuscarProfessores":   "GET /professores",
+	"/smartschool.v1.Professores/ExportarProfessores": "GET /professores",
+	"/smartschool.v1.Professores/BuscarLixeira":       "GET /professores/lixeira",
+	"/smartschool.v1.Professores/BuscarProfessor":     "GET /professores/:id",
+	"/smartschool.v1.Professores/AtualizarProfessor":  "PUT /professores/:id",
+	"/smartschool.v1.Professores/DeletarProfessor":    "DELETE /professores/:id",
+	"/smartschool.v1.Professores/RestaurarProfessor":  "POST /professores/:id/restaurar",
+
+	"/smartschool.v1.Cursos/InserirCurso":   "POST /cursos",
+	"/smartschool.v1.Cursos/BuscarCursos":   "GET /cursos",
+	"/smartschool.v1.Cursos/ExportarCursos": "GET /cursos",
+	"/smartschool.v1.Cursos/BuscarLixeira":  "GET /cursos/lixeira",
+	"/smartschool.v1.Cursos/BuscarCurso":    "GET /cursos/:id",
+	"/smartschool.v1.Cursos/AtualizarCurso": "PUT /cursos/:id",
+	"/smartschool.v1.Cursos/DeletarCurso":   "DELETE /cursos/:id",
+	"/smartschool.v1.Cursos/RestaurarCurso": "POST /cursos/:id/restaurar",
+
+	"/smartschool.v1.Disciplinas/InserirDisciplina":   "POST /disciplinas",
+	"/smartschool.v1.Disciplinas/BuscarDisciplinas":   "GET /disciplinas",
+	"/smartschool.v1.Disciplinas/ExportarDisciplinas": "GET /disciplinas",
+	"/smartschool.v1.Disciplinas/BuscarLixeira":       "GET /disciplinas/lixeira",
+	"/smartschool.v1.Disciplinas/BuscarDisciplina":    "GET /disciplinas/:id",
+	"/smartschool.v1.Disciplinas/AtualizarDisciplina": "PUT /disciplinas/:id",
+	"/smartschool.v1.Disciplinas/DeletarDisciplina":   "DELETE /disciplinas/:id",
+	"/smartschool.v1.Disciplinas/RestaurarDisciplina": "POST /disciplinas/:id/restaurar",
+}
@@ -0,0 +1,227 @@
+package planilha
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Escritor grava uma planilha linha a linha, sem guardar as linhas já escritas: exportações de coleções
+// inteiras ocupam a memória de uma linha por vez. As células podem ser textos, números, booleanos ou nil
+// (célula vazia); outros valores são gravados como texto com fmt.
+type Escritor interface {
+	Escrever(celulas []interface{}) error
+	//Fechar completa o arquivo; sem ele, o XLSX fica inválido
+	Fechar() error
+}
+
+// NovoEscritor cria o escritor do formato indicado.
+func NovoEscritor(w io.Writer, formato, aba string) (Escritor, error) {
+	switch formato {
+	case FormatoCSV:
+		return NovoCSV(w), nil
+	case FormatoXLSX:
+		return NovoXLSX(w, aba)
+	}
+	return nil, ErrFormato
+}
+
+type escritorCSV struct {
+	destino io.Writer
+	w       *csv.Writer
+	inicio  bool
+}
+
+// NovoCSV grava com vírgula e o BOM do UTF-8, para que o Excel reconheça os acentos ao abrir o arquivo.
+// LerCSV aceita o resultado de volta.
+func NovoCSV(w io.Writer) Escritor {
+	return &escritorCSV{destino: w, w: csv.NewWriter(w), inicio: true}
+}
+
+func (e *escritorCSV) Escrever(celulas []interface{}) error {
+	if e.inicio {
+		e.inicio = false
+		if _, err := io.WriteString(e.destino, "\xef\xbb\xbf"); err != nil {
+			return err
+		}
+	}
+	linha := make([]string, len(celulas))
+	for i, celula := range celulas {
+		linha[i] = textoSeguro(celula)
+	}
+	return e.w.Write(linha)
+}
+
+func (e *escritorCSV) Fechar() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func textoCelula(celula interface{}) string {
+	switch v := celula.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(celula)
+}
+
+// iniciosDeFormula são os caracteres com que o Excel e o LibreOffice começam uma fórmula ao abrir a planilha,
+// mais a tabulação e o retorno de carro, que alguns programas descartam antes de interpretar a célula.
+const iniciosDeFormula = "=+-@\t\r"
+
+func comecaFormula(texto string) bool {
+	return texto != "" && strings.ContainsRune(iniciosDeFormula, rune(texto[0]))
+}
+
+// textoSeguro é o texto da célula do CSV com um apóstrofo na frente quando ele começaria uma fórmula: um nome
+// cadastrado como "=HYPERLINK(...)" seria executado por quem abrisse a exportação. Números e booleanos são
+// gravados como estão, para que "-3" continue sendo um número.
+func textoSeguro(celula interface{}) string {
+	texto := textoCelula(celula)
+	switch celula.(type) {
+	case bool, int, int32, int64, float64, json.Number:
+		return texto
+	}
+	if comecaFormula(texto) {
+		return "'" + texto
+	}
+	return texto
+}
+
+type escritorXLSX struct {
+	z     *zip.Writer
+	aba   *bufio.Writer
+	linha int
+}
+
+// NovoXLSX grava uma pasta de trabalho com uma única aba. As partes fixas vão logo no início e a aba por
+// último, para que o zip possa ser escrito direto na resposta, sem arquivo temporário; os textos ficam nas
+// próprias células (inlineStr), dispensando a tabela de textos compartilhados. Um texto inlineStr nunca é
+// lido como fórmula, mas o que começaria uma recebe o estilo quotePrefix, para que continue sendo texto se
+// alguém editar a célula; o valor é gravado como está.
+func NovoXLSX(w io.Writer, aba string) (Escritor, error) {
+	z := zip.NewWriter(w)
+	var nome bytes.Buffer
+	xml.EscapeText(&nome, []byte(truncar(aba, 31))) //o Excel limita o nome da aba a 31 caracteres
+	partes := []struct{ nome, conteudo string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + nome.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		//o estilo 1 (s="1") é o texto com quotePrefix
+		{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0" quotePrefix="1"/></cellXfs>` +
+			`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+			`</styleSheet>`},
+	}
+	for _, p := range partes {
+		f, err := z.Create(p.nome)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.conteudo); err != nil {
+			return nil, err
+		}
+	}
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	e := &escritorXLSX{z: z, aba: bufio.NewWriter(f)}
+	e.aba.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return e, nil
+}
+
+func (e *escritorXLSX) Escrever(celulas []interface{}) error {
+	e.linha++
+	fmt.Fprintf(e.aba, `<row r="%d">`, e.linha)
+	for i, celula := range celulas {
+		ref := letrasColuna(i) + strconv.Itoa(e.linha)
+		switch v := celula.(type) {
+		case nil:
+			continue
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(e.aba, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case int, int32, int64, float64, json.Number:
+			fmt.Fprintf(e.aba, `<c r="%s"><v>%s</v></c>`, ref, textoCelula(v))
+		default:
+			texto := textoCelula(v)
+			estilo := ""
+			if comecaFormula(texto) {
+				estilo = ` s="1"`
+			}
+			fmt.Fprintf(e.aba, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, estilo)
+			//xml.EscapeText troca os caracteres que o XML não admite pelo de substituição
+			xml.EscapeText(e.aba, []byte(truncar(texto, 32767)))
+			e.aba.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := e.aba.WriteString(`</row>`)
+	return err
+}
+
+func (e *escritorXLSX) Fechar() error {
+	e.aba.WriteString(`</sheetData></worksheet>`)
+	if err := e.aba.Flush(); err != nil {
+		return err
+	}
+	return e.z.Close()
+}
+
+// letrasColuna é o inverso de indiceColuna: 0 é "A", 27 é "AB".
+func letrasColuna(i int) string {
+	letras := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		letras = string(rune('A'+(i-1)%26)) + letras
+	}
+	return letras
+}
+
+// truncar corta o texto em n caracteres, sem partir um caractere de vários bytes.
+func truncar(texto string, n int) string {
+	for i := range texto {
+		if n == 0 {
+			return texto[:i]
+		}
+		n--
+	}
+	return texto
+}
@@ -0,0 +1,76 @@
+package planilha
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parteXLSX(t *testing.T, dados []byte, nome string) string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(dados), int64(len(dados)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := z.Open(nome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	conteudo, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(conteudo)
+}
+
+func TestEscritorNeutralizaFormulas(t *testing.T) {
+	linha := []interface{}{"=HYPERLINK(\"http://x\")", "+5511987654321", "-1", "@SUM(A1)", "\tcmd", "Ana", "'já citado", -3, -2.5, json.Number("-7"), true}
+	texto := []string{"'=HYPERLINK(\"http://x\")", "'+5511987654321", "'-1", "'@SUM(A1)", "'\tcmd", "Ana", "'já citado", "-3", "-2.5", "-7", "true"}
+
+	var csv bytes.Buffer
+	e := NovoCSV(&csv)
+	e.Escrever(linha)
+	if err := e.Fechar(); err != nil {
+		t.Fatal(err)
+	}
+	if linhas, err := LerCSV(csv.Bytes()); err != nil || !reflect.DeepEqual(linhas, [][]string{texto}) {
+		t.Errorf("csv: got %q, %v; want %q", linhas, err, texto)
+	}
+
+	var xlsx bytes.Buffer
+	e, err := NovoXLSX(&xlsx, "Alunos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Escrever(linha)
+	if err := e.Fechar(); err != nil {
+		t.Fatal(err)
+	}
+	//no XLSX o valor fica como está e o estilo quotePrefix marca os textos que começariam uma fórmula
+	original := []string{"=HYPERLINK(\"http://x\")", "+5511987654321", "-1", "@SUM(A1)", "\tcmd", "Ana", "'já citado", "-3", "-2.5", "-7", "true"}
+	linhas, err := LerXLSX(xlsx.Bytes())
+	if err != nil || !reflect.DeepEqual(linhas, [][]string{original}) {
+		t.Errorf("xlsx: got %q, %v; want %q", linhas, err, original)
+	}
+	aba := parteXLSX(t, xlsx.Bytes(), "xl/worksheets/sheet1.xml")
+	for _, celula := range []string{`<c r="A1" s="1" t="inlineStr">`, `<c r="D1" s="1" t="inlineStr">`, `<c r="F1" t="inlineStr">`, `<c r="G1" t="inlineStr">`} {
+		if !strings.Contains(aba, celula) {
+			t.Errorf("xlsx: %s not found in %s", celula, aba)
+		}
+	}
+	if estilos := parteXLSX(t, xlsx.Bytes(), "xl/styles.xml"); !strings.Contains(estilos, `quotePrefix="1"`) {
+		t.Errorf("xlsx: the quotePrefix style is missing: %s", estilos)
+	}
+
+	//Ler retira o apóstrofo que o Escritor pôs no CSV, para que a exportação possa ser importada de volta
+	for formato, dados := range map[string][]byte{FormatoCSV: csv.Bytes(), FormatoXLSX: xlsx.Bytes()} {
+		if linhas, err := Ler(dados, formato); err != nil || !reflect.DeepEqual(linhas, [][]string{original}) {
+			t.Errorf("%s round trip: got %q, %v", formato, linhas, err)
+		}
+	}
+}
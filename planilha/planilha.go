@@ -0,0 +1,335 @@
+// Package planilha lê planilhas CSV e XLSX como listas de linhas de texto e as grava linha a linha, sem
+// depender de uma biblioteca de Excel: um XLSX é um zip de XMLs, e só a primeira aba, os textos
+// compartilhados e os valores das células interessam às importações e exportações.
+package planilha
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	FormatoCSV  = "csv"
+	FormatoXLSX = "xlsx"
+)
+
+// limiteXML limita o tamanho descompactado de cada parte do XLSX, contra arquivos que se expandem demais.
+const limiteXML = 256 << 20
+
+// maxLinhas e maxColunas são os limites de uma aba do Excel (colunas até XFD). Uma referência além deles só
+// aparece em arquivos forjados, e alocaria as linhas e células até ela.
+const (
+	maxLinhas  = 1048576
+	maxColunas = 16384
+)
+
+var ErrFormato = errors.New("unsupported spreadsheet format")
+
+// Formato deduz o formato pelo nome do arquivo, ou "" quando não reconhece a extensão.
+func Formato(nome string) string {
+	switch strings.ToLower(path.Ext(nome)) {
+	case ".csv", ".txt":
+		return FormatoCSV
+	case ".xlsx":
+		return FormatoXLSX
+	}
+	return ""
+}
+
+// Ler devolve as linhas do arquivo no formato indicado. Linhas vazias no meio da planilha são mantidas, para
+// que o índice de cada linha corresponda à numeração vista no Excel. No CSV, o apóstrofo que o Escritor põe
+// antes de um texto que começaria uma fórmula é retirado, para que uma exportação possa ser importada de volta.
+func Ler(dados []byte, formato string) ([][]string, error) {
+	var linhas [][]string
+	var err error
+	switch formato {
+	case FormatoCSV:
+		linhas, err = LerCSV(dados)
+	case FormatoXLSX:
+		linhas, err = LerXLSX(dados)
+	default:
+		return nil, ErrFormato
+	}
+	for _, linha := range linhas {
+		for i, celula := range linha {
+			if formato == FormatoCSV && len(celula) > 1 && celula[0] == '\'' && comecaFormula(celula[1:]) {
+				linha[i] = celula[1:]
+			}
+		}
+	}
+	return linhas, err
+}
+
+// LerCSV aceita vírgula ou ponto e vírgula como separador (o Excel em português grava com ";"), o BOM do
+// UTF-8 e arquivos em Windows-1252, convertidos para UTF-8.
+func LerCSV(dados []byte) ([][]string, error) {
+	dados = bytes.TrimPrefix(dados, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(dados) {
+		dados = deWindows1252(dados)
+	}
+	primeira := dados
+	if i := bytes.IndexByte(dados, '\n'); i >= 0 {
+		primeira = dados[:i]
+	}
+	r := csv.NewReader(bytes.NewReader(dados))
+	if bytes.Count(primeira, []byte(";")) > bytes.Count(primeira, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+// deWindows1252 converte o texto da codificação padrão do Excel no Windows; os bytes de 0x80 a 0x9F que
+// diferem do Latin-1 são os únicos que precisam da tabela.
+func deWindows1252(dados []byte) []byte {
+	especiais := map[byte]rune{0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+		0x88: 'ˆ', 0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+		0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›', 0x9C: 'œ',
+		0x9E: 'ž', 0x9F: 'Ÿ'}
+	var b bytes.Buffer
+	for _, c := range dados {
+		if r, ok := especiais[c]; ok {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.Bytes()
+}
+
+// LerXLSX lê a primeira aba da pasta de trabalho. Números vêm como o Excel os grava ("123", "1.5"), sem a
+// formatação da célula; fórmulas valem pelo último resultado calculado.
+func LerXLSX(dados []byte) ([][]string, error) {
+	z, err := zip.NewReader(bytes.NewReader(dados), int64(len(dados)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %v", err)
+	}
+	partes := make(map[string]*zip.File)
+	for _, f := range z.File {
+		partes[f.Name] = f
+	}
+	aba, err := primeiraAba(partes)
+	if err != nil {
+		return nil, err
+	}
+	var textos []string
+	if f, ok := partes["xl/sharedStrings.xml"]; ok {
+		if textos, err = textosCompartilhados(f); err != nil {
+			return nil, err
+		}
+	}
+	f, ok := partes[aba]
+	if !ok {
+		return nil, fmt.Errorf("invalid xlsx file: missing %s", aba)
+	}
+	return linhasDaAba(f, textos)
+}
+
+func abrirParte(f *zip.File) (io.ReadCloser, *xml.Decoder, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid xlsx file: %v", err)
+	}
+	return rc, xml.NewDecoder(io.LimitReader(rc, limiteXML)), nil
+}
+
+// primeiraAba segue a relação da primeira aba do workbook.xml até o arquivo da planilha.
+func primeiraAba(partes map[string]*zip.File) (string, error) {
+	var workbook struct {
+		Abas []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var relacoes struct {
+		Relacoes []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	for nome, destino := range map[string]interface{}{"xl/workbook.xml": &workbook, "xl/_rels/workbook.xml.rels": &relacoes} {
+		f, ok := partes[nome]
+		if !ok {
+			return "", fmt.Errorf("invalid xlsx file: missing %s", nome)
+		}
+		rc, d, err := abrirParte(f)
+		if err != nil {
+			return "", err
+		}
+		err = d.Decode(destino)
+		rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("invalid xlsx file: %v", err)
+		}
+	}
+	if len(workbook.Abas) == 0 {
+		return "", errors.New("invalid xlsx file: the workbook has no sheets")
+	}
+	for _, r := range relacoes.Relacoes {
+		if r.ID != workbook.Abas[0].ID {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(r.Target, "/"), nil
+		}
+		return path.Join("xl", r.Target), nil
+	}
+	return "", errors.New("invalid xlsx file: the first sheet has no relationship")
+}
+
+// textoXML junta os <t> de um <si> ou <is>, inclusive os trechos com formatação (<r>), ignorando a
+// transcrição fonética (<rPh>).
+type textoXML struct {
+	T      string `xml:"t"`
+	Trecho []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t textoXML) String() string {
+	texto := t.T
+	for _, r := range t.Trecho {
+		texto += r.T
+	}
+	return texto
+}
+
+func textosCompartilhados(f *zip.File) ([]string, error) {
+	rc, d, err := abrirParte(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var textos []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return textos, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %v", err)
+		}
+		if inicio, ok := tok.(xml.StartElement); ok && inicio.Name.Local == "si" {
+			var si textoXML
+			if err := d.DecodeElement(&si, &inicio); err != nil {
+				return nil, fmt.Errorf("invalid xlsx file: %v", err)
+			}
+			textos = append(textos, si.String())
+		}
+	}
+}
+
+type celulaXML struct {
+	Ref   string   `xml:"r,attr"`
+	Tipo  string   `xml:"t,attr"`
+	Valor string   `xml:"v"`
+	Texto textoXML `xml:"is"`
+}
+
+// linhasDaAba percorre as <row> uma a uma, sem carregar o XML inteiro, posicionando cada célula pela
+// referência ("C7"), já que o Excel omite as células e linhas vazias.
+func linhasDaAba(f *zip.File, textos []string) ([][]string, error) {
+	rc, d, err := abrirParte(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var linhas [][]string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return linhas, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %v", err)
+		}
+		inicio, ok := tok.(xml.StartElement)
+		if !ok || inicio.Name.Local != "row" {
+			continue
+		}
+		var row struct {
+			Numero  int         `xml:"r,attr"`
+			Celulas []celulaXML `xml:"c"`
+		}
+		if err := d.DecodeElement(&row, &inicio); err != nil {
+			return nil, fmt.Errorf("invalid xlsx file: %v", err)
+		}
+		if row.Numero == 0 {
+			row.Numero = len(linhas) + 1
+		}
+		if row.Numero < 0 || row.Numero > maxLinhas {
+			return nil, fmt.Errorf("invalid xlsx file: row %d is beyond the sheet limit of %d rows", row.Numero, maxLinhas)
+		}
+		for len(linhas) < row.Numero {
+			linhas = append(linhas, nil)
+		}
+		var linha []string
+		for i, c := range row.Celulas {
+			coluna := i
+			if c.Ref != "" {
+				coluna = indiceColuna(c.Ref)
+			}
+			if coluna < 0 || coluna >= maxColunas {
+				return nil, fmt.Errorf("invalid xlsx file: cell %q is beyond the sheet limit of %d columns", c.Ref, maxColunas)
+			}
+			for len(linha) <= coluna {
+				linha = append(linha, "")
+			}
+			linha[coluna], err = valorCelula(c, textos)
+			if err != nil {
+				return nil, err
+			}
+		}
+		linhas[row.Numero-1] = linha
+	}
+}
+
+// indiceColuna converte as letras da referência em índice: "A1" é 0, "AB3" é 27. Referências além de
+// maxColunas param em maxColunas, sem estourar o int.
+func indiceColuna(ref string) int {
+	n := 0
+	for _, c := range strings.ToUpper(ref) {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		if n = n*26 + int(c-'A') + 1; n > maxColunas {
+			return maxColunas
+		}
+	}
+	return n - 1
+}
+
+func valorCelula(c celulaXML, textos []string) (string, error) {
+	switch c.Tipo {
+	case "s":
+		i, err := strconv.Atoi(c.Valor)
+		if err != nil || i < 0 || i >= len(textos) {
+			return "", fmt.Errorf("invalid xlsx file: shared string %q in %s", c.Valor, c.Ref)
+		}
+		return textos[i], nil
+	case "inlineStr":
+		return c.Texto.String(), nil
+	case "b":
+		if c.Valor == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	case "", "n":
+		//números grandes, como telefones e CPFs digitados sem formatação, podem vir em notação científica
+		if strings.ContainsAny(c.Valor, "eE") {
+			if f, err := strconv.ParseFloat(c.Valor, 64); err == nil {
+				return strconv.FormatFloat(f, 'f', -1, 64), nil
+			}
+		}
+	}
+	return c.Valor, nil
+}
@@ -0,0 +1,135 @@
+package planilha
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLerCSV(t *testing.T) {
+	casos := map[string]struct {
+		dados  string
+		linhas [][]string
+	}{
+		"vírgula": {"nome,curso\nAna,3\n", [][]string{{"nome", "curso"}, {"Ana", "3"}}},
+		"ponto e vírgula do Excel em português, com BOM": {"\xef\xbb\xbfnome;nota\r\nJosé;7,5\r\n",
+			[][]string{{"nome", "nota"}, {"José", "7,5"}}},
+		"Windows-1252": {"nome;obs\nJo\xe3o;\x93aspas\x94 \x80\n", [][]string{{"nome", "obs"}, {"João", "“aspas” €"}}},
+		"linhas de tamanhos diferentes e aspas soltas": {"a,b,c\n1\nx \"y\",2\n",
+			[][]string{{"a", "b", "c"}, {"1"}, {"x \"y\"", "2"}}},
+	}
+	for nome, caso := range casos {
+		linhas, err := LerCSV([]byte(caso.dados))
+		if err != nil {
+			t.Errorf("%s: %v", nome, err)
+			continue
+		}
+		if !reflect.DeepEqual(linhas, caso.linhas) {
+			t.Errorf("%s: got %q, want %q", nome, linhas, caso.linhas)
+		}
+	}
+}
+
+// pastaDeTrabalho monta um XLSX mínimo com a aba e, quando não vazios, os textos compartilhados.
+func pastaDeTrabalho(t *testing.T, aba, textos string) []byte {
+	t.Helper()
+	partes := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Dados" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="styles.xml"/><Relationship Id="rId7" Target="/xl/worksheets/dados.xml"/></Relationships>`,
+		"xl/worksheets/dados.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			aba + `</sheetData></worksheet>`,
+	}
+	if textos != "" {
+		partes["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + textos + `</sst>`
+	}
+	var b bytes.Buffer
+	z := zip.NewWriter(&b)
+	for nome, conteudo := range partes {
+		f, err := z.Create(nome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(conteudo))
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestLerXLSX(t *testing.T) {
+	dados := pastaDeTrabalho(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c></row>`+
+		`<row r="3"><c r="A3" t="inlineStr"><is><t>Ana</t></is></c><c r="C3"><v>5.5119876543E10</v></c>`+
+		`<c r="E3" t="b"><v>1</v></c><c r="F3" t="str"><f>A3</f><v>Ana</v></c></row>`+
+		`<row><c t="s"><v>1</v></c><c><v>12</v></c></row>`,
+		`<si><t>nome</t></si><si><r><t>te</t></r><r><t>lefone</t></r><rPh><t>x</t></rPh></si><si><t>ativo</t></si>`)
+	linhas, err := LerXLSX(dados)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperadas := [][]string{
+		{"nome", "telefone", "ativo"},
+		nil, //as linhas vazias são mantidas, para a numeração seguir a do Excel
+		{"Ana", "", "55119876543", "", "true", "Ana"},
+		{"telefone", "12"},
+	}
+	if !reflect.DeepEqual(linhas, esperadas) {
+		t.Errorf("got %q, want %q", linhas, esperadas)
+	}
+
+	//o que o Escritor grava é lido de volta
+	var b bytes.Buffer
+	e, err := NovoXLSX(&b, "Alunos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Escrever([]interface{}{"nome", "curso", "ativo"})
+	e.Escrever([]interface{}{"<Zé & Cia>", 3, false})
+	if err := e.Fechar(); err != nil {
+		t.Fatal(err)
+	}
+	if linhas, err = Ler(b.Bytes(), FormatoXLSX); err != nil || !reflect.DeepEqual(linhas, [][]string{{"nome", "curso", "ativo"}, {"<Zé & Cia>", "3", "false"}}) {
+		t.Errorf("round trip: got %q, %v", linhas, err)
+	}
+}
+
+func TestLerXLSXInvalido(t *testing.T) {
+	casos := []struct {
+		dados []byte
+		erro  string
+	}{
+		{[]byte("nome,curso"), "not a valid zip file"},
+		{pastaDeTrabalho(t, `<row r="1"><c r="A1" t="s"><v>3</v></c></row>`, `<si><t>a</t></si>`), `shared string "3" in A1`},
+		{pastaDeTrabalho(t, `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`, ""), "row 1048577 is beyond the sheet limit"},
+		{pastaDeTrabalho(t, `<row r="-2"><c><v>1</v></c></row>`, ""), "row -2 is beyond the sheet limit"},
+		{pastaDeTrabalho(t, `<row r="1"><c r="XFE1"><v>1</v></c></row>`, ""), `cell "XFE1" is beyond the sheet limit`},
+		{pastaDeTrabalho(t, `<row r="1"><c r="ZZZZZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`, ""), "is beyond the sheet limit of 16384 columns"},
+	}
+	for _, caso := range casos {
+		if _, err := LerXLSX(caso.dados); err == nil || !strings.Contains(err.Error(), caso.erro) {
+			t.Errorf("got %v, want an error with %q", err, caso.erro)
+		}
+	}
+
+	//a última linha e a última coluna do Excel ainda são aceitas
+	linhas, err := LerXLSX(pastaDeTrabalho(t, `<row r="2"><c r="XFD2"><v>1</v></c></row>`, ""))
+	if err != nil || len(linhas) != 2 || len(linhas[1]) != 16384 || linhas[1][16383] != "1" {
+		t.Errorf("got %d rows, %v", len(linhas), err)
+	}
+}
+
+func TestIndiceColuna(t *testing.T) {
+	for ref, indice := range map[string]int{"A1": 0, "Z9": 25, "AA1": 26, "ab3": 27, "XFD1048576": 16383, "XFE1": 16384} {
+		if obtido := indiceColuna(ref); obtido != indice {
+			t.Errorf("indiceColuna(%q) = %d, want %d", ref, obtido, indice)
+		}
+		if indice < maxColunas && letrasColuna(indice) != strings.ToUpper(strings.TrimRight(ref, "0123456789")) {
+			t.Errorf("letrasColuna(%d) = %s", indice, letrasColuna(indice))
+		}
+	}
+}
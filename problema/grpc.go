@@ -0,0 +1,67 @@
+package problema
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DominioGRPC identifica os erros da API no ErrorInfo dos status gRPC.
+const DominioGRPC = "smartschool"
+
+var codigosGRPC = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.FailedPrecondition,
+	http.StatusLocked:                codes.FailedPrecondition,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusBadGateway:            codes.Unavailable,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// StatusGRPC é o equivalente gRPC do Tratador: o erro vira um status com o detalhe traduzido como mensagem,
+// o código estável e as extensões em um ErrorInfo e os campos reprovados em um BadRequest.
+func StatusGRPC(err error, idioma string) *status.Status {
+	p := Converter(err).traduzir(idioma)
+	codigo, ok := codigosGRPC[p.Status]
+	if !ok {
+		codigo = codes.Unknown
+	}
+	if p.Codigo == CodigoDependentes { //não é duplicidade: a exclusão depende de outros documentos
+		codigo = codes.FailedPrecondition
+	}
+	mensagem := p.Detalhe
+	if mensagem == "" {
+		mensagem = p.Titulo
+	}
+	info := &errdetails.ErrorInfo{Reason: p.Codigo, Domain: DominioGRPC, Metadata: make(map[string]string)}
+	for nome, valor := range p.Extensoes {
+		if texto, err := json.Marshal(valor); err == nil {
+			info.Metadata[nome] = string(texto)
+		}
+	}
+	s := status.New(codigo, mensagem)
+	var comDetalhes *status.Status
+	if len(p.Erros) == 0 {
+		comDetalhes, err = s.WithDetails(info)
+	} else {
+		pedido := &errdetails.BadRequest{}
+		for _, e := range p.Erros {
+			pedido.FieldViolations = append(pedido.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: e.Campo, Description: e.Mensagem})
+		}
+		comDetalhes, err = s.WithDetails(info, pedido)
+	}
+	if err != nil {
+		return s
+	}
+	return comDetalhes
+}
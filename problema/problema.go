@@ -0,0 +1,269 @@
+package problema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/krunal4amity/tronicscorp/i18n"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// MIME é o tipo de conteúdo das respostas de erro (RFC 7807).
+const MIME = "application/problem+json"
+
+// Códigos estáveis, para que os clientes tratem erros sem depender do texto das mensagens.
+const (
+	CodigoRequisicaoInvalida = "invalid_request"
+	CodigoValidacao          = "validation_failed"
+	CodigoNaoAutenticado     = "unauthenticated"
+	CodigoProibido           = "forbidden"
+	CodigoNaoEncontrado      = "not_found"
+	CodigoMetodo             = "method_not_allowed"
+	CodigoConflito           = "conflict"
+	CodigoDependentes        = "has_dependents"
+	CodigoMuitoGrande        = "payload_too_large"
+	CodigoRegra              = "business_rule_violated"
+	CodigoContaBloqueada     = "account_locked"
+	CodigoLimite             = "rate_limited"
+	CodigoInterno            = "internal_error"
+	CodigoIndisponivel       = "upstream_unavailable"
+	//códigos de falhas específicas, que o status sozinho não distingue
+	CodigoCorpoInvalido      = "malformed_body"
+	CodigoParametroInvalido  = "invalid_parameter"
+	CodigoIDInvalido         = "invalid_id"
+	CodigoFiltroInvalido     = "invalid_filter"
+	CodigoCampoRestrito      = "restricted_field"
+	CodigoSenhaFraca         = "weak_password"
+	CodigoSenhaAtual         = "wrong_current_password"
+	CodigoResetInvalido      = "invalid_reset_token"
+	CodigoLoginEmUso         = "login_taken"
+	CodigoEmailEmUso         = "email_taken"
+	CodigoValorDuplicado     = "duplicate_value"
+	CodigoCredenciais        = "invalid_credentials"
+	CodigoTokenAusente       = "missing_token"
+	CodigoTokenInvalido      = "invalid_token"
+	CodigoChaveInvalida      = "invalid_api_key"
+	CodigoEscopoDesconhecido = "unknown_scope"
+	CodigoEscopoAusente      = "missing_scope"
+	CodigoPlanilhaInvalida   = "invalid_spreadsheet"
+	CodigoMapeamento         = "invalid_column_mapping"
+	CodigoConsultaAusente    = "query_required"
+	CodigoCampoImutavel      = "immutable_field"
+	CodigoConcorrencia       = "concurrent_change"
+	CodigoLogin              = "login_failed" //login pelo provedor de identidade
+)
+
+var codigosPorStatus = map[int]string{
+	http.StatusBadRequest:            CodigoRequisicaoInvalida,
+	http.StatusUnauthorized:          CodigoNaoAutenticado,
+	http.StatusForbidden:             CodigoProibido,
+	http.StatusNotFound:              CodigoNaoEncontrado,
+	http.StatusMethodNotAllowed:      CodigoMetodo,
+	http.StatusConflict:              CodigoConflito,
+	http.StatusRequestEntityTooLarge: CodigoMuitoGrande,
+	http.StatusUnprocessableEntity:   CodigoRegra,
+	http.StatusLocked:                CodigoContaBloqueada,
+	http.StatusTooManyRequests:       CodigoLimite,
+	http.StatusInternalServerError:   CodigoInterno,
+	http.StatusBadGateway:            CodigoIndisponivel,
+}
+
+// ErroCampo aponta o campo do payload que não passou em uma regra de validação. Mensagem está em inglês e é
+// traduzida na resposta, a partir da falha do validador, das traduções informadas ou do catálogo.
+type ErroCampo struct {
+	Campo     string               `json:"campo"`
+	Regra     string               `json:"regra"`
+	Mensagem  string               `json:"mensagem"`
+	Falha     validator.FieldError `json:"-"`
+	Traducoes map[string]string    `json:"-"` //mensagem por idioma, por exemplo das regras de negócio
+}
+
+// Problema é o corpo de todas as respostas de erro. Extensoes acrescenta membros próprios do erro, como os
+// dependentes que impedem uma exclusão.
+type Problema struct {
+	Tipo      string
+	Titulo    string
+	Status    int
+	Detalhe   string
+	Instancia string
+	Codigo    string
+	RequestID string
+	Erros     []ErroCampo
+	Extensoes map[string]interface{}
+}
+
+func Novo(status int, codigo, detalhe string) *Problema {
+	return &Problema{Status: status, Codigo: codigo, Detalhe: detalhe}
+}
+
+func (p *Problema) Error() string {
+	return fmt.Sprintf("%s: %s", p.Codigo, p.Detalhe)
+}
+
+// Com acrescenta um membro de extensão.
+func (p *Problema) Com(nome string, valor interface{}) *Problema {
+	if p.Extensoes == nil {
+		p.Extensoes = make(map[string]interface{})
+	}
+	p.Extensoes[nome] = valor
+	return p
+}
+
+// HTTP embrulha o problema em um *echo.HTTPError, para as funções que devolvem esse tipo.
+func (p *Problema) HTTP() *echo.HTTPError {
+	return &echo.HTTPError{Code: p.Status, Message: p}
+}
+
+func (p *Problema) MarshalJSON() ([]byte, error) {
+	corpo := make(map[string]interface{}, len(p.Extensoes)+8)
+	for nome, valor := range p.Extensoes {
+		corpo[nome] = valor
+	}
+	corpo["type"] = p.Tipo
+	corpo["title"] = p.Titulo
+	corpo["status"] = p.Status
+	corpo["code"] = p.Codigo
+	if p.Detalhe != "" {
+		corpo["detail"] = p.Detalhe
+	}
+	if p.Instancia != "" {
+		corpo["instance"] = p.Instancia
+	}
+	if p.RequestID != "" {
+		corpo["requestId"] = p.RequestID
+	}
+	if len(p.Erros) > 0 {
+		corpo["errors"] = p.Erros
+	}
+	return json.Marshal(corpo)
+}
+
+// Validacao converte os erros do validator.v9 em um problema com um item por campo. O nome do campo é o
+// registrado pela função de nomes do validador (o nome JSON), sem o nome da struct.
+func Validacao(err error) *Problema {
+	p := Novo(http.StatusBadRequest, CodigoValidacao, "Unable to validate request payload")
+	p.Erros = CamposValidacao(err, "")
+	return p
+}
+
+// CamposValidacao lista os campos reprovados, com o prefixo informado (por exemplo "[2]." em lotes).
+func CamposValidacao(err error, prefixo string) []ErroCampo {
+	var campos []ErroCampo
+	erros, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return campos
+	}
+	for _, fe := range erros {
+		campo := fe.Namespace()
+		if i := strings.Index(campo, "."); i >= 0 {
+			campo = campo[i+1:]
+		}
+		mensagem := fmt.Sprintf("failed the %s rule", fe.Tag())
+		if fe.Param() != "" {
+			mensagem = fmt.Sprintf("failed the %s=%s rule", fe.Tag(), fe.Param())
+		}
+		campos = append(campos, ErroCampo{Campo: prefixo + campo, Regra: fe.Tag(), Mensagem: mensagem, Falha: fe})
+	}
+	return campos
+}
+
+// CorpoInvalido é o problema dos corpos de requisição que não puderam ser lidos.
+func CorpoInvalido() *Problema {
+	return Novo(http.StatusBadRequest, CodigoCorpoInvalido, "Unable to parse request payload")
+}
+
+// IDInvalido é o problema dos identificadores que não são um ObjectID.
+func IDInvalido() *Problema {
+	return Novo(http.StatusBadRequest, CodigoIDInvalido, "Unable to convert to ObjectID")
+}
+
+// Converter traduz qualquer erro devolvido por um handler em um problema.
+func Converter(err error) *Problema {
+	var p *Problema
+	switch e := err.(type) {
+	case *Problema:
+		p = e
+	case *echo.HTTPError:
+		switch m := e.Message.(type) {
+		case *Problema:
+			p = m
+		case string:
+			p = Novo(e.Code, "", m)
+		default:
+			p = Novo(e.Code, "", fmt.Sprint(m))
+		}
+	default:
+		p = Novo(http.StatusInternalServerError, CodigoInterno, "Internal server error")
+	}
+	if p.Codigo == "" {
+		p.Codigo = codigosPorStatus[p.Status]
+		if p.Codigo == "" {
+			p.Codigo = strings.ToLower(strings.Replace(http.StatusText(p.Status), " ", "_", -1))
+		}
+	}
+	if p.Tipo == "" {
+		p.Tipo = "about:blank"
+	}
+	if p.Titulo == "" {
+		p.Titulo = http.StatusText(p.Status)
+	}
+	return p
+}
+
+// Traduzido converte o erro e devolve o problema com os textos no idioma pedido, para quem guarda o erro em
+// vez de respondê-lo, como os relatórios de importação.
+func Traduzido(err error, idioma string) Problema {
+	return Converter(err).traduzir(idioma)
+}
+
+// traduzir devolve uma cópia do problema com os textos no idioma pedido.
+func (p Problema) traduzir(idioma string) Problema {
+	p.Titulo = i18n.Traduzir(idioma, p.Titulo)
+	p.Detalhe = i18n.Traduzir(idioma, p.Detalhe)
+	erros := make([]ErroCampo, len(p.Erros))
+	for i, e := range p.Erros {
+		switch traducao, ok := e.Traducoes[idioma]; {
+		case e.Falha != nil:
+			e.Mensagem = i18n.MensagemValidacao(idioma, e.Falha)
+		case ok:
+			e.Mensagem = traducao
+		default:
+			e.Mensagem = i18n.Traduzir(idioma, e.Mensagem)
+		}
+		erros[i] = e
+	}
+	p.Erros = erros
+	return p
+}
+
+// Tratador substitui o HTTPErrorHandler do Echo: toda resposta de erro sai como problem+json, no idioma
+// negociado pelo Accept-Language, com o caminho da requisição em instance e o X-Request-ID em requestId.
+func Tratador(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	idioma := i18n.Negociar(c.Request().Header.Get("Accept-Language"))
+	p := Converter(err).traduzir(idioma) //cópia: problemas podem ser valores compartilhados
+	c.Response().Header().Set("Content-Language", idioma)
+	c.Response().Header().Add(echo.HeaderVary, "Accept-Language")
+	p.Instancia = c.Request().URL.Path
+	p.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+	if p.Status >= http.StatusInternalServerError {
+		log.Errorf("%s %s: %v", c.Request().Method, p.Instancia, err)
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(p.Status)
+	} else {
+		var corpo []byte
+		if corpo, err = json.Marshal(&p); err == nil {
+			err = c.Blob(p.Status, MIME, corpo)
+		}
+	}
+	if err != nil {
+		log.Errorf("Unable to write the error response: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/krunal4amity/tronicscorp/dbiface"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// colecaoPurgada conta as buscas de documentos vencidos, uma por purga, e não tem nenhum; os demais
+// métodos não são usados pela purga.
+type colecaoPurgada struct {
+	dbiface.Collection
+	purgas int32
+}
+
+func (c *colecaoPurgada) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	atomic.AddInt32(&c.purgas, 1)
+	return mongo.NewCursorFromDocuments(nil, nil, nil)
+}
+
+func TestAgendarPurgaRecusaIntervalo(t *testing.T) {
+	col := &colecaoPurgada{}
+	for _, intervalo := range []time.Duration{0, -time.Second} {
+		if err := agendarPurga(context.Background(), time.Hour, intervalo, map[string]dbiface.Collection{"alunos": col}, nil); err == nil {
+			t.Errorf("interval %v should be rejected", intervalo)
+		}
+	}
+	if err := agendarPurga(context.Background(), -time.Hour, time.Hour, map[string]dbiface.Collection{"alunos": col}, nil); err == nil {
+		t.Error("a negative retention should be rejected")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&col.purgas); n != 0 {
+		t.Errorf("a rejected schedule purged %d times", n)
+	}
+}
+
+func TestAgendarPurgaRepeteNoIntervalo(t *testing.T) {
+	col := &colecaoPurgada{}
+	ctx, cancelar := context.WithCancel(context.Background())
+	if err := agendarPurga(ctx, time.Hour, 10*time.Millisecond, map[string]dbiface.Collection{"alunos": col}, nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(55 * time.Millisecond)
+	cancelar()
+	n := atomic.LoadInt32(&col.purgas)
+	if n < 3 || n > 7 { //a primeira purga é imediata, depois uma a cada 10ms
+		t.Errorf("purged %d times in 55ms with a 10ms interval", n)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if depois := atomic.LoadInt32(&col.purgas); depois > n+1 {
+		t.Errorf("the purge kept running after the context was canceled: %d, then %d", n, depois)
+	}
+}
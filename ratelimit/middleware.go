@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// Identificador escolhe de quem é o balde da requisição; vazio dispensa o limite.
+type Identificador func(c echo.Context) string
+
+// PorIP limita pelo endereço do cliente, como determinado pelo e.IPExtractor: sem um extrator que só confie
+// nos proxies conhecidos, o cliente escolheria o próprio IP pelo X-Forwarded-For.
+func PorIP(c echo.Context) string {
+	return c.RealIP()
+}
+
+// Config define um limite por grupo de rotas: a chave de Grupos é o prefixo do caminho registrado no Echo
+// ("/auth", "/alunos") e vale o prefixo mais longo; as demais rotas usam o Padrao.
+type Config struct {
+	Nome          string //distingue os baldes de cada middleware ("ip", "usuario")
+	Store         Store
+	Identificador Identificador
+	Padrao        Limite
+	Grupos        map[string]Limite
+}
+
+func (cfg Config) limite(caminho string) (string, Limite) {
+	grupo, limite := "", cfg.Padrao
+	for prefixo, l := range cfg.Grupos {
+		if strings.HasPrefix(caminho, prefixo) && len(prefixo) > len(grupo) {
+			grupo, limite = prefixo, l
+		}
+	}
+	return grupo, limite
+}
+
+// Cabecalhos escreve RateLimit-Limit, RateLimit-Remaining e RateLimit-Reset e, quando negado, Retry-After.
+func Cabecalhos(c echo.Context, res Resultado) {
+	cabecalho := c.Response().Header()
+	cabecalho.Set("RateLimit-Limit", strconv.Itoa(res.Limite))
+	cabecalho.Set("RateLimit-Remaining", strconv.Itoa(res.Restante))
+	cabecalho.Set("RateLimit-Reset", strconv.Itoa(int(res.Reinicio.Seconds())))
+	if !res.Permitido {
+		cabecalho.Set("Retry-After", strconv.Itoa(int(res.Espera.Seconds())))
+	}
+}
+
+// Middleware aplica o balde de fichas da configuração. Se o Store falhar, a requisição passa: uma queda do
+// Redis não deve derrubar a API.
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := cfg.Identificador(c)
+			grupo, limite := cfg.limite(c.Path())
+			if id == "" || limite.Requisicoes <= 0 {
+				return next(c)
+			}
+			res, err := cfg.Store.Consumir(c.Request().Context(), cfg.Nome+":"+grupo+":"+id, limite)
+			if err != nil {
+				log.Errorf("Unable to check the rate limit: %v", err)
+				return next(c)
+			}
+			Cabecalhos(c, res)
+			if !res.Permitido {
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Too many requests, try again later")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limite é um balde de fichas: Requisicoes fichas por Periodo, acumulando no máximo Rajada (padrão: Requisicoes).
+type Limite struct {
+	Requisicoes int
+	Periodo     time.Duration
+	Rajada      int
+}
+
+func (l Limite) capacidade() float64 {
+	if l.Rajada > 0 {
+		return float64(l.Rajada)
+	}
+	return float64(l.Requisicoes)
+}
+
+// taxa em fichas por segundo
+func (l Limite) taxa() float64 {
+	return float64(l.Requisicoes) / l.Periodo.Seconds()
+}
+
+// Resultado descreve o balde depois de uma tentativa de consumo.
+type Resultado struct {
+	Permitido bool
+	Limite    int
+	Restante  int
+	Reinicio  time.Duration //até o balde encher de novo
+	Espera    time.Duration //até a próxima ficha, quando negado
+}
+
+func resultado(limite Limite, fichas float64, permitido bool) Resultado {
+	capacidade, taxa := limite.capacidade(), limite.taxa()
+	res := Resultado{Permitido: permitido, Limite: int(capacidade), Restante: int(fichas)}
+	if !permitido {
+		res.Espera = segundos((1 - fichas) / taxa)
+	}
+	res.Reinicio = segundos((capacidade - fichas) / taxa)
+	return res
+}
+
+// Store guarda os baldes. Memoria serve a uma instância; Redis compartilha os limites entre as instâncias
+// de um cluster.
+type Store interface {
+	Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error)
+}
+
+type balde struct {
+	fichas float64
+	visto  time.Time
+	cheio  time.Time //quando o balde volta à capacidade sem novos consumos
+}
+
+// Memoria guarda os baldes no processo. Cada instância da API limita sozinha.
+type Memoria struct {
+	mu     sync.Mutex
+	baldes map[string]*balde
+	limpo  time.Time
+}
+
+func NovaMemoria() *Memoria {
+	return &Memoria{baldes: make(map[string]*balde)}
+}
+
+// Consumir retira uma ficha do balde da chave, se houver.
+func (m *Memoria) Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	agora := time.Now()
+	capacidade, taxa := limite.capacidade(), limite.taxa()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if agora.Sub(m.limpo) > time.Minute {
+		m.limpar(agora)
+	}
+	b, ok := m.baldes[chave]
+	if !ok {
+		b = &balde{fichas: capacidade, visto: agora}
+		m.baldes[chave] = b
+	}
+	b.fichas = math.Min(capacidade, b.fichas+agora.Sub(b.visto).Seconds()*taxa)
+	b.visto = agora
+
+	permitido := b.fichas >= 1
+	if permitido {
+		b.fichas--
+	}
+	res := resultado(limite, b.fichas, permitido)
+	b.cheio = agora.Add(res.Reinicio)
+	return res, nil
+}
+
+// limpar descarta os baldes que já teriam enchido, equivalentes a um balde novo.
+func (m *Memoria) limpar(agora time.Time) {
+	for chave, b := range m.baldes {
+		if agora.After(b.cheio) {
+			delete(m.baldes, chave)
+		}
+	}
+	m.limpo = agora
+}
+
+func segundos(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
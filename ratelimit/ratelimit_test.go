@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// consumirVarias tenta n consumos seguidos e devolve o último resultado e quantos foram permitidos.
+func consumirVarias(t *testing.T, s Store, chave string, limite Limite, n int) (Resultado, int) {
+	var (
+		res       Resultado
+		permitido int
+	)
+	for i := 0; i < n; i++ {
+		var err error
+		if res, err = s.Consumir(context.Background(), chave, limite); err != nil {
+			t.Fatal(err)
+		}
+		if res.Permitido {
+			permitido++
+		}
+	}
+	return res, permitido
+}
+
+// conferirBalde vale para qualquer Store: o balde começa cheio, nega quando esvazia e cada chave tem o seu.
+func conferirBalde(t *testing.T, s Store) {
+	limite := Limite{Requisicoes: 3, Periodo: time.Minute}
+	res, permitido := consumirVarias(t, s, "ana", limite, 4)
+	if permitido != 3 {
+		t.Errorf("a bucket of 3 allowed %d of 4 requests", permitido)
+	}
+	if res.Permitido || res.Limite != 3 || res.Restante != 0 {
+		t.Errorf("unexpected result after emptying the bucket: %+v", res)
+	}
+	if res.Espera != 20*time.Second || res.Reinicio != time.Minute {
+		t.Errorf("one token every 20s: got Espera %v and Reinicio %v", res.Espera, res.Reinicio)
+	}
+	if res, _ := consumirVarias(t, s, "bia", limite, 1); !res.Permitido || res.Restante != 2 {
+		t.Errorf("another key should have its own bucket, got %+v", res)
+	}
+
+	//a rajada limita o acúmulo, não a taxa
+	rajada := Limite{Requisicoes: 60, Periodo: time.Minute, Rajada: 2}
+	if _, permitido := consumirVarias(t, s, "rajada", rajada, 5); permitido != 2 {
+		t.Errorf("a burst of 2 allowed %d requests", permitido)
+	}
+
+	//as fichas voltam com o tempo: uma a cada 10ms
+	rapido := Limite{Requisicoes: 100, Periodo: time.Second, Rajada: 1}
+	if _, permitido := consumirVarias(t, s, "rapido", rapido, 2); permitido != 1 {
+		t.Fatalf("a bucket of 1 allowed %d requests", permitido)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if res, _ := consumirVarias(t, s, "rapido", rapido, 1); !res.Permitido {
+		t.Error("the bucket should refill over time")
+	}
+}
+
+func TestMemoria(t *testing.T) {
+	conferirBalde(t, NovaMemoria())
+}
+
+func TestMemoriaDescartaBaldesCheios(t *testing.T) {
+	m := NovaMemoria()
+	consumirVarias(t, m, "ana", Limite{Requisicoes: 1000, Periodo: time.Second}, 1)
+	m.limpar(time.Now().Add(2 * time.Second))
+	if len(m.baldes) != 0 {
+		t.Errorf("a bucket that would be full again should be discarded, %d left", len(m.baldes))
+	}
+}
+
+type storeQuebrado struct{}
+
+func (storeQuebrado) Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	return Resultado{}, errors.New("connection refused")
+}
+
+func TestMiddleware(t *testing.T) {
+	requisitar := func(cfg Config, caminho string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Use(Middleware(cfg))
+		e.GET(caminho, func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, caminho, nil))
+		return rec
+	}
+	cfg := Config{Nome: "ip", Store: NovaMemoria(), Identificador: PorIP,
+		Padrao: Limite{Requisicoes: 100, Periodo: time.Minute},
+		Grupos: map[string]Limite{"/auth": {Requisicoes: 1, Periodo: time.Minute}}}
+
+	if rec := requisitar(cfg, "/auth/login"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "1" {
+		t.Errorf("the /auth group limit should apply, got %d %v", rec.Code, rec.Header())
+	}
+	rec := requisitar(cfg, "/auth/login")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("the second request should get 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := requisitar(cfg, "/alunos"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "100" {
+		t.Errorf("other routes use the default limit, got %d %v", rec.Code, rec.Header())
+	}
+
+	//com o Store fora do ar, a requisição passa sem cabeçalhos
+	cfg.Store = storeQuebrado{}
+	if rec := requisitar(cfg, "/auth/login"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("a failing store should let the request through, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sony/gobreaker"
+)
+
+// scriptBalde aplica o balde de fichas atomicamente no servidor. Devolve {permitido, fichas restantes}.
+var scriptBalde = redis.NewScript(`
+local capacidade = tonumber(ARGV[1])
+local taxa = tonumber(ARGV[2])
+local agora = tonumber(ARGV[3])
+local balde = redis.call('HMGET', KEYS[1], 'fichas', 'visto')
+local fichas = tonumber(balde[1]) or capacidade
+local visto = tonumber(balde[2]) or agora
+fichas = math.min(capacidade, fichas + math.max(0, agora - visto) * taxa)
+local permitido = 0
+if fichas >= 1 then
+	fichas = fichas - 1
+	permitido = 1
+end
+redis.call('HMSET', KEYS[1], 'fichas', tostring(fichas), 'visto', tostring(agora))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacidade - fichas) / taxa) + 1000)
+return {permitido, tostring(fichas)}
+`)
+
+// Redis guarda os baldes em um servidor compatível com Redis, compartilhados por todas as instâncias. O
+// Disjuntor para de consultar o servidor depois de falhas seguidas, para que uma queda do Redis não some o
+// timeout a cada requisição; enquanto aberto, Consumir devolve gobreaker.ErrOpenState.
+type Redis struct {
+	Cliente   redis.Scripter //um *redis.Client, com seu pool de conexões
+	Prefixo   string         //separa as chaves desta API de outros usos do servidor
+	Disjuntor *gobreaker.CircuitBreaker
+}
+
+// NovoRedis monta o Store com um disjuntor que abre após 5 falhas seguidas e volta a testar o servidor
+// depois de 30 segundos.
+func NovoRedis(cliente redis.Scripter, prefixo string) *Redis {
+	disjuntor := gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:    "redis",
+		Timeout: 30 * time.Second,
+		ReadyToTrip: func(contagem gobreaker.Counts) bool {
+			return contagem.ConsecutiveFailures >= 5
+		},
+	})
+	return &Redis{Cliente: cliente, Prefixo: prefixo, Disjuntor: disjuntor}
+}
+
+func (r *Redis) Consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	if r.Disjuntor == nil {
+		return r.consumir(ctx, chave, limite)
+	}
+	res, err := r.Disjuntor.Execute(func() (interface{}, error) {
+		return r.consumir(ctx, chave, limite)
+	})
+	if err != nil {
+		return Resultado{}, err
+	}
+	return res.(Resultado), nil
+}
+
+func (r *Redis) consumir(ctx context.Context, chave string, limite Limite) (Resultado, error) {
+	agora := time.Now().UnixNano() / int64(time.Millisecond)
+	taxa := limite.taxa() / 1000 //fichas por milissegundo
+	resposta, err := scriptBalde.Run(ctx, r.Cliente, []string{r.Prefixo + chave},
+		strconv.FormatFloat(limite.capacidade(), 'f', -1, 64), strconv.FormatFloat(taxa, 'f', -1, 64), agora).Result()
+	if err != nil {
+		return Resultado{}, err
+	}
+	valores, ok := resposta.([]interface{})
+	if !ok || len(valores) != 2 {
+		return Resultado{}, fmt.Errorf("unexpected reply %v", resposta)
+	}
+	permitido, _ := valores[0].(int64)
+	texto, _ := valores[1].(string)
+	fichas, err := strconv.ParseFloat(texto, 64)
+	if err != nil {
+		return Resultado{}, fmt.Errorf("unexpected reply %v", resposta)
+	}
+	return resultado(limite, fichas, permitido == 1), nil
+}
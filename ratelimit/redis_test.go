@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/sony/gobreaker"
+)
+
+func novoRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	servidor := miniredis.RunT(t)
+	cliente := redis.NewClient(&redis.Options{Addr: servidor.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { cliente.Close() })
+	return NovoRedis(cliente, "teste:"), servidor
+}
+
+func TestRedis(t *testing.T) {
+	r, _ := novoRedis(t)
+	conferirBalde(t, r)
+}
+
+func TestRedisExpiraBaldes(t *testing.T) {
+	r, servidor := novoRedis(t)
+	consumirVarias(t, r, "ana", Limite{Requisicoes: 3, Periodo: time.Minute}, 1)
+	//o balde expira quando estaria cheio de novo: 20s para repor uma ficha, mais 1s de folga
+	if ttl := servidor.TTL("teste:ana"); ttl != 21*time.Second {
+		t.Errorf("TTL = %v, want 21s", ttl)
+	}
+	if servidor.HGet("teste:ana", "fichas") != "2" {
+		t.Errorf("the bucket should keep 2 tokens, got %q", servidor.HGet("teste:ana", "fichas"))
+	}
+}
+
+func TestRedisAbreDisjuntor(t *testing.T) {
+	r, servidor := novoRedis(t)
+	servidor.Close()
+	limite := Limite{Requisicoes: 3, Periodo: time.Minute}
+	for i := 0; i < 5; i++ {
+		if _, err := r.Consumir(context.Background(), "ana", limite); err == nil || err == gobreaker.ErrOpenState {
+			t.Fatalf("attempt %d: got %v, want the connection error", i+1, err)
+		}
+	}
+	if _, err := r.Consumir(context.Background(), "ana", limite); err != gobreaker.ErrOpenState {
+		t.Errorf("after 5 failures the breaker should open, got %v", err)
+	}
+}